@@ -0,0 +1,162 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// A SynonymMap maps sequences of terms to the sequences of terms they
+// are synonyms of. A sequence listed among its own synonyms is kept
+// when it is found in a token stream, otherwise it is replaced.
+type SynonymMap struct {
+	synonyms  map[string][][]string
+	maxLength int
+}
+
+func NewSynonymMap() *SynonymMap {
+	return &SynonymMap{
+		synonyms: make(map[string][][]string),
+	}
+}
+
+// LoadFile reads in synonym rules from a text file, one per line.
+// See LoadLine for the format of the rules.
+func (s *SynonymMap) LoadFile(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return s.LoadBytes(data)
+}
+
+// LoadBytes reads in synonym rules from memory, one per line.
+// See LoadLine for the format of the rules.
+func (s *SynonymMap) LoadBytes(data []byte) error {
+	bytesReader := bytes.NewReader(data)
+	bufioReader := bufio.NewReader(bytesReader)
+	line, err := bufioReader.ReadString('\n')
+	for err == nil {
+		lerr := s.LoadLine(line)
+		if lerr != nil {
+			return lerr
+		}
+		line, err = bufioReader.ReadString('\n')
+	}
+	// if the err was EOF we still need to process the last value
+	if err == io.EOF {
+		return s.LoadLine(line)
+	}
+	return err
+}
+
+// LoadLine reads in one synonym rule, using the Solr syntax:
+//
+// "tv, television, telly" makes each of the comma separated entries a
+// synonym of all the others.
+//
+// "usa, united states => united states of america" replaces each entry on
+// the left of the arrow with all the entries on its right.
+//
+// Entries may contain several whitespace separated terms. Comments start
+// with a "#" character, blank lines are ignored.
+func (s *SynonymMap) LoadLine(line string) error {
+	// find the start of a comment, if any
+	startComment := strings.IndexByte(line, '#')
+	if startComment >= 0 {
+		line = line[:startComment]
+	}
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+
+	sides := strings.Split(line, "=>")
+	switch len(sides) {
+	case 1:
+		entries := parseSynonymEntries(sides[0])
+		if len(entries) == 0 {
+			return fmt.Errorf("invalid synonym rule '%s'", strings.TrimSpace(line))
+		}
+		s.AddEquivalence(entries...)
+	case 2:
+		from := parseSynonymEntries(sides[0])
+		to := parseSynonymEntries(sides[1])
+		if len(from) == 0 || len(to) == 0 {
+			return fmt.Errorf("invalid synonym rule '%s'", strings.TrimSpace(line))
+		}
+		for _, entry := range from {
+			s.AddMapping(entry, to...)
+		}
+	default:
+		return fmt.Errorf("invalid synonym rule '%s'", strings.TrimSpace(line))
+	}
+	return nil
+}
+
+func parseSynonymEntries(s string) [][]string {
+	var rv [][]string
+	for _, entry := range strings.Split(s, ",") {
+		terms := strings.Fields(entry)
+		if len(terms) > 0 {
+			rv = append(rv, terms)
+		}
+	}
+	return rv
+}
+
+// AddEquivalence makes each of the entries a synonym of all the others.
+func (s *SynonymMap) AddEquivalence(entries ...[]string) {
+	for _, entry := range entries {
+		s.AddMapping(entry, entries...)
+	}
+}
+
+// AddMapping replaces the from entry with the to entries, which may
+// include from itself to keep it.
+func (s *SynonymMap) AddMapping(from []string, to ...[]string) {
+	key := strings.Join(from, " ")
+	existing := s.synonyms[key]
+OUTER:
+	for _, entry := range to {
+		for _, existingEntry := range existing {
+			if strings.Join(existingEntry, " ") == strings.Join(entry, " ") {
+				continue OUTER
+			}
+		}
+		existing = append(existing, entry)
+	}
+	s.synonyms[key] = existing
+	if len(from) > s.maxLength {
+		s.maxLength = len(from)
+	}
+}
+
+// Merge adds all the rules of other to this map.
+func (s *SynonymMap) Merge(other *SynonymMap) {
+	for key, entries := range other.synonyms {
+		s.AddMapping(strings.Fields(key), entries...)
+	}
+}
+
+// Synonyms returns the entries the sequence of terms maps to, or nil.
+func (s *SynonymMap) Synonyms(terms []string) [][]string {
+	return s.synonyms[strings.Join(terms, " ")]
+}
+
+// MaxLength returns the number of terms of the longest sequence having
+// synonyms.
+func (s *SynonymMap) MaxLength() int {
+	return s.maxLength
+}
@@ -0,0 +1,60 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// package synonym_map implements a generic SynonymMap, used by the synonym
+// token filter.
+//
+// Its constructor takes the following arguments:
+//
+// "filename" (string): the path of a file listing Solr-style synonym
+// rules, one per line, like "tv, television" or "usa => united states".
+// Comments start with a "#" character.
+//
+// "rules" ([]interface{}): if "filename" is not specified, rules can be
+// passed directly as a sequence of strings wrapped in a []interface{}.
+package synonym_map
+
+import (
+	"fmt"
+
+	"github.com/blevesearch/bleve/analysis"
+	"github.com/blevesearch/bleve/registry"
+)
+
+const Name = "custom"
+
+func GenericSynonymMapConstructor(config map[string]interface{}, cache *registry.Cache) (*analysis.SynonymMap, error) {
+	rv := analysis.NewSynonymMap()
+
+	// first: try to load by filename
+	filename, ok := config["filename"].(string)
+	if ok {
+		err := rv.LoadFile(filename)
+		return rv, err
+	}
+	// next: look for inline rules
+	rules, ok := config["rules"].([]interface{})
+	if ok {
+		for _, rule := range rules {
+			ruleStr, ok := rule.(string)
+			if ok {
+				err := rv.LoadLine(ruleStr)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		return rv, nil
+	}
+	return nil, fmt.Errorf("must specify filename or list of rules for synonym map")
+}
+
+func init() {
+	registry.RegisterSynonymMap(Name, GenericSynonymMapConstructor)
+}
@@ -0,0 +1,69 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSynonymMapLoadBytes(t *testing.T) {
+	synonymMap := NewSynonymMap()
+	err := synonymMap.LoadBytes([]byte(`# equivalences
+tv, television
+
+usa, united states => united states of america # explicit mapping
+tv => telly`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		terms    []string
+		expected [][]string
+	}{
+		{
+			terms:    []string{"tv"},
+			expected: [][]string{{"tv"}, {"television"}, {"telly"}},
+		},
+		{
+			terms:    []string{"television"},
+			expected: [][]string{{"tv"}, {"television"}},
+		},
+		{
+			terms:    []string{"united", "states"},
+			expected: [][]string{{"united", "states", "of", "america"}},
+		},
+		{
+			terms:    []string{"usa"},
+			expected: [][]string{{"united", "states", "of", "america"}},
+		},
+		{
+			terms:    []string{"united"},
+			expected: nil,
+		},
+	}
+	for _, test := range tests {
+		actual := synonymMap.Synonyms(test.terms)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("expected synonyms of %v to be %v, got %v", test.terms, test.expected, actual)
+		}
+	}
+	if synonymMap.MaxLength() != 2 {
+		t.Errorf("expected max length 2, got %d", synonymMap.MaxLength())
+	}
+
+	for _, invalid := range []string{"a => ", "=> b", "a => b => c", " , "} {
+		err := NewSynonymMap().LoadLine(invalid)
+		if err == nil {
+			t.Errorf("expected error loading rule '%s'", invalid)
+		}
+	}
+}
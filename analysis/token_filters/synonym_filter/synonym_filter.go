@@ -0,0 +1,161 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// package synonym_filter implements a TokenFilter adding or substituting
+// the synonyms found in a SynonymMap.
+//
+// Synonyms are emitted at the position of the tokens they match, the
+// terms of multi-term synonyms at consecutive positions from there, so
+// that phrase queries match them like the original tokens. They span the
+// same bytes as the tokens they match and have the Synonym type.
+//
+// Rules are matched literally against the terms of the tokens, the filter
+// is usually placed after the lower casing one.
+//
+// Its constructor takes the following arguments:
+//
+// "synonym_map" (string): the name of the synonym map holding the rules.
+//
+// "rules" ([]interface{}): Solr-style synonym rules, like "tv, television"
+// or "usa => united states", as a sequence of strings wrapped in a
+// []interface{}. They are added to those of "synonym_map", at least one of
+// the two arguments must be specified.
+package synonym_filter
+
+import (
+	"fmt"
+
+	"github.com/blevesearch/bleve/analysis"
+	"github.com/blevesearch/bleve/registry"
+)
+
+const Name = "synonym"
+
+type SynonymFilter struct {
+	synonyms *analysis.SynonymMap
+}
+
+func NewSynonymFilter(synonyms *analysis.SynonymMap) *SynonymFilter {
+	return &SynonymFilter{
+		synonyms: synonyms,
+	}
+}
+
+func (f *SynonymFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	rv := make(analysis.TokenStream, 0, len(input))
+
+	for i := 0; i < len(input); {
+		n, synonyms := f.longestMatch(input[i:])
+		if n == 0 {
+			rv = append(rv, input[i])
+			i++
+			continue
+		}
+
+		matched := input[i : i+n]
+		keepOriginal := false
+		var synonymTokens analysis.TokenStream
+		for _, synonym := range synonyms {
+			if sameTerms(matched, synonym) {
+				keepOriginal = true
+				continue
+			}
+			for j, term := range synonym {
+				synonymTokens = append(synonymTokens, &analysis.Token{
+					Start:    matched[0].Start,
+					End:      matched[n-1].End,
+					Term:     []byte(term),
+					Position: matched[0].Position + j,
+					Type:     analysis.Synonym,
+				})
+			}
+		}
+
+		if keepOriginal {
+			rv = append(rv, matched[0])
+			rv = append(rv, synonymTokens...)
+			rv = append(rv, matched[1:]...)
+		} else {
+			rv = append(rv, synonymTokens...)
+		}
+		i += n
+	}
+
+	return rv
+}
+
+// longestMatch returns the number of tokens at the start of input making
+// the longest sequence having synonyms, and these synonyms.
+func (f *SynonymFilter) longestMatch(input analysis.TokenStream) (int, [][]string) {
+	maxLength := f.synonyms.MaxLength()
+	if maxLength > len(input) {
+		maxLength = len(input)
+	}
+	terms := make([]string, maxLength)
+	for i := 0; i < maxLength; i++ {
+		if input[i].KeyWord {
+			// keywords are left untouched, and break multi-term matches
+			maxLength = i
+			break
+		}
+		terms[i] = string(input[i].Term)
+	}
+	for n := maxLength; n > 0; n-- {
+		synonyms := f.synonyms.Synonyms(terms[:n])
+		if synonyms != nil {
+			return n, synonyms
+		}
+	}
+	return 0, nil
+}
+
+func sameTerms(tokens analysis.TokenStream, terms []string) bool {
+	if len(tokens) != len(terms) {
+		return false
+	}
+	for i, token := range tokens {
+		if string(token.Term) != terms[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func SynonymFilterConstructor(config map[string]interface{}, cache *registry.Cache) (analysis.TokenFilter, error) {
+	synonyms := analysis.NewSynonymMap()
+	synonymMapName, hasSynonymMap := config["synonym_map"].(string)
+	if hasSynonymMap {
+		synonymMap, err := cache.SynonymMapNamed(synonymMapName)
+		if err != nil {
+			return nil, fmt.Errorf("error building synonym filter: %v", err)
+		}
+		synonyms.Merge(synonymMap)
+	}
+	rules, hasRules := config["rules"].([]interface{})
+	if hasRules {
+		for _, rule := range rules {
+			ruleStr, ok := rule.(string)
+			if !ok {
+				return nil, fmt.Errorf("synonym rules must be strings, not %T", rule)
+			}
+			err := synonyms.LoadLine(ruleStr)
+			if err != nil {
+				return nil, fmt.Errorf("error building synonym filter: %v", err)
+			}
+		}
+	}
+	if !hasSynonymMap && !hasRules {
+		return nil, fmt.Errorf("must specify synonym_map or rules")
+	}
+	return NewSynonymFilter(synonyms), nil
+}
+
+func init() {
+	registry.RegisterTokenFilter(Name, SynonymFilterConstructor)
+}
@@ -0,0 +1,109 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package synonym_filter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve/analysis"
+	"github.com/blevesearch/bleve/analysis/synonym_map"
+	"github.com/blevesearch/bleve/registry"
+)
+
+func tokens(terms ...string) analysis.TokenStream {
+	rv := make(analysis.TokenStream, 0, len(terms))
+	start := 0
+	for i, term := range terms {
+		rv = append(rv, &analysis.Token{
+			Start:    start,
+			End:      start + len(term),
+			Term:     []byte(term),
+			Position: i + 1,
+		})
+		start += len(term) + 1
+	}
+	return rv
+}
+
+func synonym(term string, start, end, position int) *analysis.Token {
+	return &analysis.Token{
+		Start:    start,
+		End:      end,
+		Term:     []byte(term),
+		Position: position,
+		Type:     analysis.Synonym,
+	}
+}
+
+func TestSynonymFilter(t *testing.T) {
+	cache := registry.NewCache()
+	_, err := cache.DefineSynonymMap("synonym_test", map[string]interface{}{
+		"type":  synonym_map.Name,
+		"rules": []interface{}{"tv, television"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	synonymFilter, err := cache.DefineTokenFilter("synonym_test", map[string]interface{}{
+		"type":        Name,
+		"synonym_map": "synonym_test",
+		"rules":       []interface{}{"usa, united states => united states of america"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input  analysis.TokenStream
+		output analysis.TokenStream
+	}{
+		// equivalent synonyms are added at the same position
+		{
+			input: tokens("a", "tv", "show"),
+			output: analysis.TokenStream{
+				tokens("a", "tv", "show")[0],
+				tokens("a", "tv", "show")[1],
+				synonym("television", 2, 4, 2),
+				tokens("a", "tv", "show")[2],
+			},
+		},
+		// explicit mappings replace the multi-term entries they match
+		{
+			input: tokens("the", "united", "states", "flag"),
+			output: analysis.TokenStream{
+				tokens("the", "united", "states", "flag")[0],
+				synonym("united", 4, 17, 2),
+				synonym("states", 4, 17, 3),
+				synonym("of", 4, 17, 4),
+				synonym("america", 4, 17, 5),
+				tokens("the", "united", "states", "flag")[3],
+			},
+		},
+		{
+			input:  tokens("united", "kingdom"),
+			output: tokens("united", "kingdom"),
+		},
+	}
+
+	for _, test := range tests {
+		actual := synonymFilter.Filter(test.input)
+		if !reflect.DeepEqual(actual, test.output) {
+			t.Errorf("expected:\n%v\ngot:\n%v", test.output, actual)
+		}
+	}
+
+	_, err = cache.DefineTokenFilter("invalid", map[string]interface{}{
+		"type": Name,
+	})
+	if err == nil {
+		t.Errorf("expected error defining synonym filter without rules")
+	}
+}
@@ -29,6 +29,7 @@ const (
 	Single
 	Double
 	Boolean
+	Synonym
 )
 
 // Token represents one occurrence of a term at a particular location in a
@@ -13,11 +13,15 @@ import (
 	// token maps
 	_ "github.com/blevesearch/bleve/analysis/token_map"
 
+	// synonym maps
+	_ "github.com/blevesearch/bleve/analysis/synonym_map"
+
 	// fragment formatters
 	_ "github.com/blevesearch/bleve/search/highlight/fragment_formatters/ansi"
 	_ "github.com/blevesearch/bleve/search/highlight/fragment_formatters/html"
 
 	// fragmenters
+	_ "github.com/blevesearch/bleve/search/highlight/fragmenters/sentence"
 	_ "github.com/blevesearch/bleve/search/highlight/fragmenters/simple"
 
 	// highlighters
@@ -25,6 +29,10 @@ import (
 	_ "github.com/blevesearch/bleve/search/highlight/highlighters/html"
 	_ "github.com/blevesearch/bleve/search/highlight/highlighters/simple"
 
+	// similarities
+	_ "github.com/blevesearch/bleve/search/similarities/bm25"
+	_ "github.com/blevesearch/bleve/search/similarities/tfidf"
+
 	// char filters
 	_ "github.com/blevesearch/bleve/analysis/char_filters/html_char_filter"
 	_ "github.com/blevesearch/bleve/analysis/char_filters/regexp_char_filter"
@@ -48,6 +56,7 @@ import (
 	_ "github.com/blevesearch/bleve/analysis/token_filters/ngram_filter"
 	_ "github.com/blevesearch/bleve/analysis/token_filters/shingle"
 	_ "github.com/blevesearch/bleve/analysis/token_filters/stop_tokens_filter"
+	_ "github.com/blevesearch/bleve/analysis/token_filters/synonym_filter"
 	_ "github.com/blevesearch/bleve/analysis/token_filters/truncate_token_filter"
 	_ "github.com/blevesearch/bleve/analysis/token_filters/unicode_normalize"
 
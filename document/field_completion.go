@@ -0,0 +1,127 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/blevesearch/bleve/analysis"
+)
+
+const DefaultCompletionIndexingOptions = StoreField | IndexField
+
+// A CompletionEntry is a weighted completion suggested for
+// its inputs, looked up by their normalized form in Keys.
+type CompletionEntry struct {
+	Inputs   []string            `json:"input"`
+	Keys     []string            `json:"keys"`
+	Weight   int64               `json:"weight,omitempty"`
+	Payload  json.RawMessage     `json:"payload,omitempty"`
+	Contexts map[string][]string `json:"contexts,omitempty"`
+}
+
+// A CompletionField holds a JSON encoded CompletionEntry.
+// Its keys are indexed as terms.
+type CompletionField struct {
+	name              string
+	arrayPositions    []uint64
+	options           IndexingOptions
+	value             []byte
+	numPlainTextBytes uint64
+}
+
+func (c *CompletionField) Name() string {
+	return c.name
+}
+
+func (c *CompletionField) ArrayPositions() []uint64 {
+	return c.arrayPositions
+}
+
+func (c *CompletionField) Options() IndexingOptions {
+	return c.options
+}
+
+func (c *CompletionField) Analyze() (int, analysis.TokenFrequencies) {
+	tokens := make(analysis.TokenStream, 0)
+	entry, err := c.Entry()
+	if err == nil {
+		for i, key := range entry.Keys {
+			tokens = append(tokens, &analysis.Token{
+				Start:    0,
+				End:      len(key),
+				Term:     []byte(key),
+				Position: i + 1,
+				Type:     analysis.AlphaNumeric,
+			})
+		}
+	}
+	fieldLength := len(tokens)
+	tokenFreqs := analysis.TokenFrequency(tokens, c.arrayPositions, c.options.IncludeTermVectors())
+	return fieldLength, tokenFreqs
+}
+
+func (c *CompletionField) Value() []byte {
+	return c.value
+}
+
+// Entry decodes the completion entry of the field.
+func (c *CompletionField) Entry() (*CompletionEntry, error) {
+	var rv CompletionEntry
+	err := json.Unmarshal(c.value, &rv)
+	if err != nil {
+		return nil, err
+	}
+	if len(rv.Inputs) != len(rv.Keys) {
+		return nil, fmt.Errorf("completion entry has %d inputs but %d keys", len(rv.Inputs), len(rv.Keys))
+	}
+	return &rv, nil
+}
+
+func (c *CompletionField) GoString() string {
+	return fmt.Sprintf("&document.CompletionField{Name:%s, Options: %s, Value: %s}", c.name, c.options, c.value)
+}
+
+func (c *CompletionField) NumPlainTextBytes() uint64 {
+	return c.numPlainTextBytes
+}
+
+func NewCompletionFieldFromBytes(name string, arrayPositions []uint64, value []byte) *CompletionField {
+	return &CompletionField{
+		name:              name,
+		arrayPositions:    arrayPositions,
+		value:             value,
+		options:           DefaultCompletionIndexingOptions,
+		numPlainTextBytes: uint64(len(value)),
+	}
+}
+
+func NewCompletionField(name string, arrayPositions []uint64, entry *CompletionEntry) (*CompletionField, error) {
+	return NewCompletionFieldWithIndexingOptions(name, arrayPositions, entry, DefaultCompletionIndexingOptions)
+}
+
+func NewCompletionFieldWithIndexingOptions(name string, arrayPositions []uint64, entry *CompletionEntry, options IndexingOptions) (*CompletionField, error) {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	numPlainTextBytes := 0
+	for _, input := range entry.Inputs {
+		numPlainTextBytes += len(input)
+	}
+	return &CompletionField{
+		name:              name,
+		arrayPositions:    arrayPositions,
+		value:             value,
+		options:           options,
+		numPlainTextBytes: uint64(numPlainTextBytes),
+	}, nil
+}
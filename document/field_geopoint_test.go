@@ -0,0 +1,38 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package document
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeoPointField(t *testing.T) {
+	gf := NewGeoPointField("loc", []uint64{}, 0.0015, 0.0015)
+	numTokens, tokenFreqs := gf.Analyze()
+	if numTokens != 8 {
+		t.Errorf("expected 8 tokens, got %d", numTokens)
+	}
+	if len(tokenFreqs) != 8 {
+		t.Errorf("expected 8 token freqs, got %d", len(tokenFreqs))
+	}
+
+	lon, err := gf.Lon()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat, err := gf.Lat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(lon-0.0015) > 1e-6 || math.Abs(lat-0.0015) > 1e-6 {
+		t.Errorf("expected lon/lat 0.0015, got %f/%f", lon, lat)
+	}
+}
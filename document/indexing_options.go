@@ -15,6 +15,8 @@ const (
 	IndexField IndexingOptions = 1 << iota
 	StoreField
 	IncludeTermVectors
+	DocValues
+	FieldLengths
 )
 
 func (o IndexingOptions) IsIndexed() bool {
@@ -29,6 +31,19 @@ func (o IndexingOptions) IncludeTermVectors() bool {
 	return o&IncludeTermVectors != 0
 }
 
+// IncludeDocValues reports whether the indexed terms of the field are
+// also recorded in a per-document column, for fast faceting and sorting.
+func (o IndexingOptions) IncludeDocValues() bool {
+	return o&DocValues != 0
+}
+
+// IncludeFieldLengths reports whether the index maintains the document
+// count and total length of the field, for similarities normalizing by
+// the average field length.
+func (o IndexingOptions) IncludeFieldLengths() bool {
+	return o&FieldLengths != 0
+}
+
 func (o IndexingOptions) String() string {
 	rv := ""
 	if o.IsIndexed() {
@@ -46,5 +61,17 @@ func (o IndexingOptions) String() string {
 		}
 		rv += "TV"
 	}
+	if o.IncludeDocValues() {
+		if rv != "" {
+			rv += ", "
+		}
+		rv += "DV"
+	}
+	if o.IncludeFieldLengths() {
+		if rv != "" {
+			rv += ", "
+		}
+		rv += "FL"
+	}
 	return rv
 }
@@ -19,6 +19,7 @@ func TestIndexingOptions(t *testing.T) {
 		isIndexed          bool
 		isStored           bool
 		includeTermVectors bool
+		includeDocValues   bool
 	}{
 		{
 			options:            IndexField | StoreField | IncludeTermVectors,
@@ -50,6 +51,13 @@ func TestIndexingOptions(t *testing.T) {
 			isStored:           true,
 			includeTermVectors: false,
 		},
+		{
+			options:            IndexField | DocValues,
+			isIndexed:          true,
+			isStored:           false,
+			includeTermVectors: false,
+			includeDocValues:   true,
+		},
 	}
 
 	for _, test := range tests {
@@ -65,5 +73,9 @@ func TestIndexingOptions(t *testing.T) {
 		if actuallyIncludeTermVectors != test.includeTermVectors {
 			t.Errorf("expected includeTermVectors to be %v, got %v for %d", test.includeTermVectors, actuallyIncludeTermVectors, test.options)
 		}
+		actuallyIncludeDocValues := test.options.IncludeDocValues()
+		if actuallyIncludeDocValues != test.includeDocValues {
+			t.Errorf("expected includeDocValues to be %v, got %v for %d", test.includeDocValues, actuallyIncludeDocValues, test.options)
+		}
 	}
 }
@@ -27,6 +27,11 @@ const (
 	ErrorUnknownIndexType
 	ErrorEmptyID
 	ErrorIndexReadInconsistency
+	ErrorSpanQueryNoClauses
+	ErrorSpanQueryFieldMismatch
+	ErrorBackupCorrupt
+	ErrorMoreLikeThisQueryNoLike
+	ErrorTermRangeQueryNoBounds
 )
 
 // Error represents a more strongly typed bleve error for detecting
@@ -54,4 +59,9 @@ var errorMessages = map[Error]string{
 	ErrorUnknownIndexType:                       "unknown index type",
 	ErrorEmptyID:                                "document ID cannot be empty",
 	ErrorIndexReadInconsistency:                 "index read inconsistency detected",
+	ErrorSpanQueryNoClauses:                     "span query must contain at least one clause",
+	ErrorSpanQueryFieldMismatch:                 "span query clauses must all search the same field",
+	ErrorBackupCorrupt:                          "cannot restore index, backup corrupt",
+	ErrorMoreLikeThisQueryNoLike:                "more like this query must specify either a document or text",
+	ErrorTermRangeQueryNoBounds:                 "term range query must specify min or max",
 }
@@ -0,0 +1,66 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseDistance(t *testing.T) {
+	tests := []struct {
+		dist    string
+		want    float64
+		wantErr bool
+	}{
+		{"5mi", 5 * 1609.344, false},
+		{"3", 3, false},
+		{"3m", 3, false},
+		{"3mm", 0.003, false},
+		{"5km", 5000, false},
+		{"5kilometers", 5000, false},
+		{"2nm", 3704, false},
+		{"7yd", 7 * 0.9144, false},
+		{"fivemi", 0, true},
+		{"fivemiles", 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseDistance(test.dist)
+		if err != nil && !test.wantErr {
+			t.Errorf("unexpected error parsing %s: %v", test.dist, err)
+		} else if err == nil && test.wantErr {
+			t.Errorf("expected error parsing %s", test.dist)
+		}
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("expected %f for %s, got %f", test.want, test.dist, got)
+		}
+	}
+}
+
+func TestHaversin(t *testing.T) {
+	tests := []struct {
+		lon1, lat1, lon2, lat2 float64
+		want                   float64
+	}{
+		{0, 0, 0, 0, 0},
+		// london to paris, roughly 344km
+		{-0.1278, 51.5074, 2.3522, 48.8566, 343900},
+		// quarter of the equator
+		{0, 0, 90, 0, math.Pi / 2 * earthMeanRadiusMeters},
+	}
+
+	for _, test := range tests {
+		got := Haversin(test.lon1, test.lat1, test.lon2, test.lat2)
+		if math.Abs(got-test.want) > 1000 {
+			t.Errorf("expected distance %f, got %f", test.want, got)
+		}
+	}
+}
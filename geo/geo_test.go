@@ -0,0 +1,67 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMortonHashMortonUnhash(t *testing.T) {
+	tests := []struct {
+		lon float64
+		lat float64
+	}{
+		{-180.0, -90.0},
+		{-5, 27.3},
+		{0, 0},
+		{1.0, 1.0},
+		{24.7, -80.4},
+		{180.0, 90.0},
+	}
+
+	for _, test := range tests {
+		hash := MortonHash(test.lon, test.lat)
+		lon := MortonUnhashLon(hash)
+		lat := MortonUnhashLat(hash)
+		if compareGeo(test.lon, lon) != 0 {
+			t.Errorf("expected lon %f, got %f, hash %x", test.lon, lon, hash)
+		}
+		if compareGeo(test.lat, lat) != 0 {
+			t.Errorf("expected lat %f, got %f, hash %x", test.lat, lat, hash)
+		}
+	}
+}
+
+func TestRectFromPointDistance(t *testing.T) {
+	// at the equator, one degree is roughly 111km
+	upperLeftLon, upperLeftLat, lowerRightLon, lowerRightLat, err := RectFromPointDistance(0, 0, 111195)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(upperLeftLon+1) > 0.01 || math.Abs(upperLeftLat-1) > 0.01 ||
+		math.Abs(lowerRightLon-1) > 0.01 || math.Abs(lowerRightLat+1) > 0.01 {
+		t.Errorf("unexpected box %f %f %f %f", upperLeftLon, upperLeftLat, lowerRightLon, lowerRightLat)
+	}
+
+	// near the date line, the box wraps around
+	upperLeftLon, _, lowerRightLon, _, err = RectFromPointDistance(179.9, 0, 111195)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upperLeftLon < lowerRightLon {
+		t.Errorf("expected box crossing the date line, got %f to %f", upperLeftLon, lowerRightLon)
+	}
+
+	_, _, _, _, err = RectFromPointDistance(200, 0, 10)
+	if err == nil {
+		t.Errorf("expected error for invalid longitude")
+	}
+}
@@ -0,0 +1,64 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package geo
+
+import "testing"
+
+func TestExtractGeoPoint(t *testing.T) {
+	tests := []struct {
+		in      interface{}
+		lon     float64
+		lat     float64
+		success bool
+	}{
+		// values that are not points
+		{in: nil},
+		{in: "cat"},
+		{in: 5.0},
+		{in: []interface{}{1.0}},
+		{in: []interface{}{"a", "b"}},
+		{in: map[string]interface{}{"lat": 1.0}},
+		{in: "95.0,1.0"},
+		// slice, lon first
+		{in: []interface{}{3.0, 4.0}, lon: 3, lat: 4, success: true},
+		{in: []float64{3.0, 4.0}, lon: 3, lat: 4, success: true},
+		// string, lat first
+		{in: "4.0, 3.0", lon: 3, lat: 4, success: true},
+		// map
+		{in: map[string]interface{}{"lat": 4.0, "lon": 3.0}, lon: 3, lat: 4, success: true},
+		{in: map[string]interface{}{"lat": 4, "lng": 3}, lon: 3, lat: 4, success: true},
+		// struct
+		{
+			in: struct {
+				Lon float64
+				Lat float64
+			}{Lon: 3.0, Lat: 4.0},
+			lon: 3, lat: 4, success: true,
+		},
+		{
+			in: struct {
+				Lng int
+				Lat int
+			}{Lng: 3, Lat: 4},
+			lon: 3, lat: 4, success: true,
+		},
+	}
+
+	for _, test := range tests {
+		lon, lat, success := ExtractGeoPoint(test.in)
+		if success != test.success {
+			t.Errorf("expected extract geo point success %t, got %t for %v", test.success, success, test.in)
+			continue
+		}
+		if success && (lon != test.lon || lat != test.lat) {
+			t.Errorf("expected lon %f lat %f, got lon %f lat %f for %v", test.lon, test.lat, lon, lat, test.in)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/blevesearch/bleve"
+)
+
+// CompletionHandler can handle completion requests
+// sent over HTTP
+type CompletionHandler struct {
+	defaultIndexName string
+	IndexNameLookup  varLookupFunc
+}
+
+func NewCompletionHandler(defaultIndexName string) *CompletionHandler {
+	return &CompletionHandler{
+		defaultIndexName: defaultIndexName,
+	}
+}
+
+func (h *CompletionHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+
+	// find the index to operate on
+	var indexName string
+	if h.IndexNameLookup != nil {
+		indexName = h.IndexNameLookup(req)
+	}
+	if indexName == "" {
+		indexName = h.defaultIndexName
+	}
+	index := IndexByName(indexName)
+	if index == nil {
+		showError(w, req, fmt.Sprintf("no such index '%s'", indexName), 404)
+		return
+	}
+
+	// read the request body
+	requestBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error reading request body: %v", err), 400)
+		return
+	}
+
+	logger.Printf("request body: %s", requestBody)
+
+	// parse the request
+	var completionRequest bleve.CompletionRequest
+	err = json.Unmarshal(requestBody, &completionRequest)
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error parsing completion request: %v", err), 400)
+		return
+	}
+
+	// validate the request
+	err = completionRequest.Validate()
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error validating completion request: %v", err), 400)
+		return
+	}
+
+	// compute the completions
+	completionResponse, err := index.Complete(&completionRequest)
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error executing completion request: %v", err), 500)
+		return
+	}
+
+	// encode the response
+	mustEncode(w, completionResponse)
+}
@@ -62,6 +62,12 @@ func TestHandlers(t *testing.T) {
 	searchHandler := NewSearchHandler("")
 	searchHandler.IndexNameLookup = indexNameLookup
 
+	suggestHandler := NewSuggestHandler("")
+	suggestHandler.IndexNameLookup = indexNameLookup
+
+	completionHandler := NewCompletionHandler("")
+	completionHandler.IndexNameLookup = indexNameLookup
+
 	listFieldsHandler := NewListFieldsHandler("")
 	listFieldsHandler.IndexNameLookup = indexNameLookup
 
@@ -71,6 +77,9 @@ func TestHandlers(t *testing.T) {
 
 	aliasHandler := NewAliasHandler()
 
+	updateMappingHandler := NewUpdateMappingHandler()
+	updateMappingHandler.IndexNameLookup = indexNameLookup
+
 	tests := []struct {
 		Desc          string
 		Handler       http.Handler
@@ -220,6 +229,40 @@ func TestHandlers(t *testing.T) {
 			Status:       http.StatusOK,
 			ResponseBody: []byte(`{"status":"ok"}`),
 		},
+		{
+			Desc:         "update mapping with new date time parser",
+			Handler:      updateMappingHandler,
+			Path:         "/ti1/_mapping",
+			Method:       "PUT",
+			Params:       url.Values{"indexName": []string{"ti1"}},
+			Body:         []byte(`{"analysis":{"date_time_parsers":{"day":{"type":"flexiblego","layouts":["2006-01-02"]}}}}`),
+			Status:       http.StatusOK,
+			ResponseBody: []byte(`{"status":"ok"}`),
+		},
+		{
+			Desc:    "update mapping incompatible",
+			Handler: updateMappingHandler,
+			Path:    "/ti1/_mapping",
+			Method:  "PUT",
+			Params:  url.Values{"indexName": []string{"ti1"}},
+			Body:    []byte(`{"type_field":"kind"}`),
+			Status:  http.StatusConflict,
+			ResponseMatch: map[string]bool{
+				`"status":"error"`: true,
+				`{"path":"type_field","old":"_type","new":"kind"}`: true,
+				`analysis.date_time_parsers.day`:                   true,
+			},
+		},
+		{
+			Desc:         "update mapping invalid index",
+			Handler:      updateMappingHandler,
+			Path:         "/tix/_mapping",
+			Method:       "PUT",
+			Params:       url.Values{"indexName": []string{"tix"}},
+			Body:         []byte(`{}`),
+			Status:       http.StatusNotFound,
+			ResponseBody: []byte(`no such index 'tix'`),
+		},
 		{
 			Desc:    "index doc invalid index",
 			Handler: docIndexHandler,
@@ -459,6 +502,77 @@ func TestHandlers(t *testing.T) {
 				`error validating query`: true,
 			},
 		},
+		{
+			Desc:    "suggest",
+			Handler: suggestHandler,
+			Path:    "/ti1/suggest",
+			Method:  "POST",
+			Params: url.Values{
+				"indexName": []string{"ti1"},
+			},
+			Body:   []byte(`{"text":"tast","field":"body"}`),
+			Status: http.StatusOK,
+			ResponseMatch: map[string]bool{
+				`"options":[{"term":"test","distance":1,"doc_freq":1}]`: true,
+				`"phrase":"test"`: true,
+			},
+		},
+		{
+			Desc:    "suggest invalid json",
+			Handler: suggestHandler,
+			Path:    "/ti1/suggest",
+			Method:  "POST",
+			Params: url.Values{
+				"indexName": []string{"ti1"},
+			},
+			Body:   []byte(`{`),
+			Status: http.StatusBadRequest,
+			ResponseMatch: map[string]bool{
+				`error parsing suggest request`: true,
+			},
+		},
+		{
+			Desc:    "suggest request does not validate",
+			Handler: suggestHandler,
+			Path:    "/ti1/suggest",
+			Method:  "POST",
+			Params: url.Values{
+				"indexName": []string{"ti1"},
+			},
+			Body:   []byte(`{"text":"tast","mode":"sometimes"}`),
+			Status: http.StatusBadRequest,
+			ResponseMatch: map[string]bool{
+				`error validating suggest request`: true,
+			},
+		},
+		{
+			Desc:    "complete without a completion field",
+			Handler: completionHandler,
+			Path:    "/ti1/complete",
+			Method:  "POST",
+			Params: url.Values{
+				"indexName": []string{"ti1"},
+			},
+			Body:   []byte(`{"prefix":"te","field":"body"}`),
+			Status: http.StatusOK,
+			ResponseMatch: map[string]bool{
+				`"completions":[]`: true,
+			},
+		},
+		{
+			Desc:    "complete without field",
+			Handler: completionHandler,
+			Path:    "/ti1/complete",
+			Method:  "POST",
+			Params: url.Values{
+				"indexName": []string{"ti1"},
+			},
+			Body:   []byte(`{"prefix":"te"}`),
+			Status: http.StatusBadRequest,
+			ResponseMatch: map[string]bool{
+				`error validating completion request`: true,
+			},
+		},
 		{
 			Desc:    "list fields",
 			Handler: listFieldsHandler,
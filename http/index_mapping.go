@@ -0,0 +1,92 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/blevesearch/bleve"
+)
+
+// UpdateMappingHandler replaces the mapping of an index with the one in the
+// request body. Changes which are not additive are rejected with a 409
+// response listing them.
+type UpdateMappingHandler struct {
+	IndexNameLookup varLookupFunc
+}
+
+func NewUpdateMappingHandler() *UpdateMappingHandler {
+	return &UpdateMappingHandler{}
+}
+
+func (h *UpdateMappingHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// find the name of the index to update
+	var indexName string
+	if h.IndexNameLookup != nil {
+		indexName = h.IndexNameLookup(req)
+	}
+	if indexName == "" {
+		showError(w, req, "index name is required", 400)
+		return
+	}
+
+	index := IndexByName(indexName)
+	if index == nil {
+		showError(w, req, fmt.Sprintf("no such index '%s'", indexName), 404)
+		return
+	}
+
+	// read the request body
+	requestBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error reading request body: %v", err), 400)
+		return
+	}
+
+	// interpret request body as index mapping
+	indexMapping := bleve.NewIndexMapping()
+	err = json.Unmarshal(requestBody, &indexMapping)
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error parsing index mapping: %v", err), 400)
+		return
+	}
+
+	err = index.UpdateMapping(indexMapping)
+	if conflictErr, ok := err.(*bleve.MappingConflictError); ok {
+		logger.Printf("Reporting error %v/%v", http.StatusConflict, err)
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		rv := struct {
+			Status    string                   `json:"status"`
+			Error     string                   `json:"error"`
+			Conflicts []*bleve.MappingConflict `json:"conflicts"`
+		}{
+			Status:    "error",
+			Error:     err.Error(),
+			Conflicts: conflictErr.Conflicts,
+		}
+		mustEncode(w, rv)
+		return
+	}
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error updating index mapping: %v", err), 400)
+		return
+	}
+
+	rv := struct {
+		Status string `json:"status"`
+	}{
+		Status: "ok",
+	}
+	mustEncode(w, rv)
+}
@@ -0,0 +1,83 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/blevesearch/bleve"
+)
+
+// SuggestHandler can handle spelling suggestion requests
+// sent over HTTP
+type SuggestHandler struct {
+	defaultIndexName string
+	IndexNameLookup  varLookupFunc
+}
+
+func NewSuggestHandler(defaultIndexName string) *SuggestHandler {
+	return &SuggestHandler{
+		defaultIndexName: defaultIndexName,
+	}
+}
+
+func (h *SuggestHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+
+	// find the index to operate on
+	var indexName string
+	if h.IndexNameLookup != nil {
+		indexName = h.IndexNameLookup(req)
+	}
+	if indexName == "" {
+		indexName = h.defaultIndexName
+	}
+	index := IndexByName(indexName)
+	if index == nil {
+		showError(w, req, fmt.Sprintf("no such index '%s'", indexName), 404)
+		return
+	}
+
+	// read the request body
+	requestBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error reading request body: %v", err), 400)
+		return
+	}
+
+	logger.Printf("request body: %s", requestBody)
+
+	// parse the request
+	var suggestRequest bleve.SuggestRequest
+	err = json.Unmarshal(requestBody, &suggestRequest)
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error parsing suggest request: %v", err), 400)
+		return
+	}
+
+	// validate the request
+	err = suggestRequest.Validate()
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error validating suggest request: %v", err), 400)
+		return
+	}
+
+	// compute the suggestions
+	suggestResponse, err := index.Suggest(&suggestRequest)
+	if err != nil {
+		showError(w, req, fmt.Sprintf("error executing suggest request: %v", err), 500)
+		return
+	}
+
+	// encode the response
+	mustEncode(w, suggestResponse)
+}
@@ -10,6 +10,8 @@
 package bleve
 
 import (
+	"io"
+
 	"github.com/blevesearch/bleve/document"
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/index/store"
@@ -170,6 +172,14 @@ type Index interface {
 	Search(req *SearchRequest) (*SearchResult, error)
 	SearchInContext(ctx context.Context, req *SearchRequest) (*SearchResult, error)
 
+	// Suggest returns spelling corrections for the terms of a text,
+	// chosen among the terms of the index.
+	Suggest(req *SuggestRequest) (*SuggestResult, error)
+
+	// Complete returns the completions of a prefix,
+	// among the inputs of a completion field.
+	Complete(req *CompletionRequest) (*CompletionResult, error)
+
 	Fields() ([]string, error)
 
 	FieldDict(field string) (index.FieldDict, error)
@@ -198,6 +208,15 @@ type Index interface {
 
 	Mapping() *IndexMapping
 
+	// UpdateMapping replaces the index mapping with one only adding new
+	// document types, properties, fields or custom analysis components to
+	// it, and persists it. Other changes would leave the documents already
+	// indexed inconsistent with the mapping, and are rejected with a
+	// *MappingConflictError listing them, as are new properties of dynamic
+	// document mappings and new document types while the default mapping
+	// indexes documents.
+	UpdateMapping(mapping *IndexMapping) error
+
 	Stats() *IndexStat
 	StatsMap() map[string]interface{}
 
@@ -213,6 +232,11 @@ type Index interface {
 	// Advanced returns the indexer and data store, exposing lower level
 	// methods to enumerate records and access data.
 	Advanced() (index.Index, store.KVStore, error)
+
+	// Backup writes a consistent snapshot of the index, including its
+	// mapping, to w. The archive does not depend on the KV store in use
+	// and can be restored with Restore or RestoreUsing.
+	Backup(w io.Writer) error
 }
 
 // A Classifier is an interface describing any object
@@ -253,3 +277,20 @@ func Open(path string) (Index, error) {
 func OpenUsing(path string, runtimeConfig map[string]interface{}) (Index, error) {
 	return openIndexUsing(path, runtimeConfig)
 }
+
+// Restore creates an index at the specified path,
+// which must not already exist, from a backup
+// written by Index.Backup, and opens it.
+// The kvstore implementation and configuration
+// of the backed up index will be used.
+func Restore(path string, r io.Reader) (Index, error) {
+	return restoreUsing(path, r, "", nil, true)
+}
+
+// RestoreUsing works like Restore but stores the
+// restored index in the specified kvstore
+// implementation, passing the provided kvconfig
+// to its constructor.
+func RestoreUsing(path string, r io.Reader, kvstore string, kvconfig map[string]interface{}) (Index, error) {
+	return restoreUsing(path, r, kvstore, kvconfig, false)
+}
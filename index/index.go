@@ -78,8 +78,18 @@ type IndexReader interface {
 	Document(id string) (*document.Document, error)
 	DocumentFieldTerms(id string) (FieldTerms, error)
 
+	// DocumentFieldTermsForFields returns the indexed terms of the listed
+	// fields only. Fields indexed with doc values are read from their
+	// column, others from the back index of the document.
+	DocumentFieldTermsForFields(id string, fields []string) (FieldTerms, error)
+
 	Fields() ([]string, error)
 
+	// FieldStats returns the length statistics of the field, which are
+	// empty when the field is unknown or indexed without the
+	// document.FieldLengths option.
+	FieldStats(field string) (*FieldStats, error)
+
 	GetInternal(key []byte) ([]byte, error)
 
 	DocCount() uint64
@@ -89,6 +99,24 @@ type IndexReader interface {
 
 type FieldTerms map[string][]string
 
+// FieldStats holds the length statistics of a field, the length of a field
+// being the number of its tokens in a document.
+type FieldStats struct {
+	// DocCount is the number of documents having terms in the field.
+	DocCount uint64
+	// TotalLength is the sum of the lengths of the field in these documents.
+	TotalLength uint64
+}
+
+// AverageLength returns the average length of the field in the documents
+// having terms in it, or 0 when there are none.
+func (s *FieldStats) AverageLength() float64 {
+	if s.DocCount == 0 {
+		return 0
+	}
+	return float64(s.TotalLength) / float64(s.DocCount)
+}
+
 type TermFieldVector struct {
 	Field          string
 	ArrayPositions []uint64
@@ -10,9 +10,12 @@
 package upside_down
 
 import (
+	"sort"
+
 	"github.com/blevesearch/bleve/analysis"
 	"github.com/blevesearch/bleve/document"
 	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/numeric_util"
 )
 
 func (udc *UpsideDownCouch) Analyze(d *document.Document) *index.AnalysisResult {
@@ -30,6 +33,8 @@ func (udc *UpsideDownCouch) Analyze(d *document.Document) *index.AnalysisResult
 	fieldTermFreqs := make(map[uint16]analysis.TokenFrequencies)
 	fieldLengths := make(map[uint16]int)
 	fieldIncludeTermVectors := make(map[uint16]bool)
+	fieldIncludeDocValues := make(map[uint16]bool)
+	fieldNumeric := make(map[uint16]bool)
 	fieldNames := make(map[uint16]string)
 
 	analyzeField := func(field document.Field, storable bool) {
@@ -50,6 +55,16 @@ func (udc *UpsideDownCouch) Analyze(d *document.Document) *index.AnalysisResult
 			}
 			fieldLengths[fieldIndex] += fieldLength
 			fieldIncludeTermVectors[fieldIndex] = field.Options().IncludeTermVectors()
+			if field.Options().IncludeFieldLengths() {
+				udc.addFieldLengthsField(fieldIndex)
+			}
+			if field.Options().IncludeDocValues() {
+				fieldIncludeDocValues[fieldIndex] = true
+				switch field.(type) {
+				case *document.NumericField, *document.DateTimeField, *document.GeoPointField:
+					fieldNumeric[fieldIndex] = true
+				}
+			}
 		}
 
 		if storable && field.Options().IsStored() {
@@ -95,6 +110,12 @@ func (udc *UpsideDownCouch) Analyze(d *document.Document) *index.AnalysisResult
 
 		// encode this field
 		rv.Rows, backIndexTermEntries = udc.indexField(docIDBytes, includeTermVectors, fieldIndex, fieldLength, tokenFreqs, rv.Rows, backIndexTermEntries)
+
+		// fields without tokens have no term entries in the back index
+		// row, which would leave their doc values row behind on delete
+		if fieldIncludeDocValues[fieldIndex] && len(tokenFreqs) > 0 {
+			rv.Rows = append(rv.Rows, udc.docValuesField(docIDBytes, fieldIndex, fieldNumeric[fieldIndex], tokenFreqs))
+		}
 	}
 
 	// build the back index row
@@ -103,3 +124,27 @@ func (udc *UpsideDownCouch) Analyze(d *document.Document) *index.AnalysisResult
 
 	return rv
 }
+
+// docValuesField builds the doc values row of a field, numeric values
+// only keep their full precision term.
+func (udc *UpsideDownCouch) docValuesField(docID []byte, fieldIndex uint16, numeric bool, tokenFreqs analysis.TokenFrequencies) *DocValuesRow {
+	udc.addDocValuesField(fieldIndex)
+
+	terms := make([]string, 0, len(tokenFreqs))
+	for term := range tokenFreqs {
+		if numeric {
+			shift, err := numeric_util.PrefixCoded(term).Shift()
+			if err != nil || shift != 0 {
+				continue
+			}
+		}
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	termBytes := make([][]byte, len(terms))
+	for i, term := range terms {
+		termBytes[i] = []byte(term)
+	}
+	return NewDocValuesRow(fieldIndex, docID, termBytes)
+}
@@ -0,0 +1,477 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package upside_down
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blevesearch/bleve/index/store"
+)
+
+// Kinds of violations found by Check.
+const (
+	// a row which cannot be parsed
+	ViolationCorruptRow = "corrupt_row"
+	// a field index without FieldRow
+	ViolationMissingField = "missing_field"
+	// a back index term entry without TermFrequencyRow
+	ViolationMissingTermFrequency = "missing_term_frequency"
+	// a back index stored entry without StoredRow
+	ViolationMissingStored = "missing_stored"
+	// a TermFrequencyRow not referenced by a back index row
+	ViolationOrphanedTermFrequency = "orphaned_term_frequency"
+	// a StoredRow not referenced by a back index row
+	ViolationOrphanedStored = "orphaned_stored"
+	// a DocValuesRow of a field the document has no terms in
+	ViolationOrphanedDocValues = "orphaned_doc_values"
+	// a DictionaryRow count differing from the number of
+	// TermFrequencyRows of its term
+	ViolationDictionaryCount = "dictionary_count"
+	// a FieldLengthRow differing from the lengths recorded by
+	// the norms of the TermFrequencyRows of its field
+	ViolationFieldLength = "field_length"
+)
+
+// checkRepairBatchSize is the number of rows written or
+// deleted per batch when repairing.
+const checkRepairBatchSize = 1000
+
+// A Violation is an inconsistency between the rows of the
+// index. Keys are those of the offending rows.
+type Violation struct {
+	Kind     string   `json:"kind"`
+	Message  string   `json:"message"`
+	Keys     [][]byte `json:"keys"`
+	Repaired bool     `json:"repaired"`
+}
+
+func (v *Violation) String() string {
+	rv := fmt.Sprintf("%s: %s", v.Kind, v.Message)
+	for _, key := range v.Keys {
+		rv += fmt.Sprintf(" [% x]", key)
+	}
+	if v.Repaired {
+		rv += " (repaired)"
+	}
+	return rv
+}
+
+// CheckReport is the result of Check.
+type CheckReport struct {
+	Rows       uint64       `json:"rows"`
+	Documents  uint64       `json:"documents"`
+	Violations []*Violation `json:"violations"`
+}
+
+// Check cross-validates the rows of the index: back index
+// rows against the term frequency, stored and doc values
+// rows of their documents, dictionary rows and the field
+// length rows of the fields whose lengths are maintained
+// against the term frequency rows, and the fields referenced
+// by all rows against the field rows. Writes are blocked
+// while it runs.
+//
+// If repair is true, dictionary and field length rows are
+// rewritten with the recomputed values, and orphaned and
+// corrupt rows are deleted. Indexes built before field
+// lengths were recorded get them by naming their fields to
+// MaintainFieldLengths before a repair. Missing rows cannot be repaired,
+// the documents they belong to must be indexed again.
+//
+// The keys of the rows referenced by back index rows are kept
+// in memory, so Check needs memory proportional to the number
+// of terms and stored fields of all documents.
+func (udc *UpsideDownCouch) Check(repair bool) (*CheckReport, error) {
+	udc.writeMutex.Lock()
+	defer udc.writeMutex.Unlock()
+
+	kvreader, err := udc.store.Reader()
+	if err != nil {
+		return nil, err
+	}
+	udc.m.RLock()
+	lengthFields := make(map[uint16]bool, len(udc.fieldLengthsFields))
+	for field := range udc.fieldLengthsFields {
+		lengthFields[field] = true
+	}
+	udc.m.RUnlock()
+	c := newChecker(lengthFields)
+	err = c.check(kvreader)
+	if cerr := kvreader.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if repair {
+		err = c.repair(udc.store)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.report, nil
+}
+
+// MaintainFieldLengths starts maintaining the lengths of the named
+// fields, as if they had been indexed with the FieldLengths option.
+// The lengths of the documents already indexed are only accounted for
+// by the next repair of Check. Unknown fields are ignored.
+func (udc *UpsideDownCouch) MaintainFieldLengths(fields ...string) {
+	udc.writeMutex.Lock()
+	defer udc.writeMutex.Unlock()
+
+	for _, name := range fields {
+		fieldIndex, exists := udc.fieldCache.FieldNamed(name, false)
+		if exists {
+			udc.addFieldLengthsField(fieldIndex)
+		}
+	}
+}
+
+// checkFieldLength accumulates the statistics of a field.
+type checkFieldLength struct {
+	docCount    uint64
+	totalLength uint64
+}
+
+type checker struct {
+	report *CheckReport
+
+	fields map[uint16]bool
+	// fields referenced by rows, with the key of a row
+	// referencing each
+	referencedFields map[uint16][]byte
+
+	// keys referenced by back index rows and not seen yet
+	expectedTerms  map[string]bool
+	expectedStored map[string]bool
+	// indexed fields of each document, true once the
+	// length of the field has been accounted for
+	docFields map[string]map[uint16]bool
+
+	dictionary       map[string]uint64
+	dictionaryCounts map[string]uint64
+	fieldLengthRows  map[uint16]*FieldLengthRow
+	fieldLengths     map[uint16]*checkFieldLength
+	// fields whose lengths are maintained
+	lengthFields map[uint16]bool
+
+	// repairs to apply, deletes being nil values
+	repairs []checkRepair
+}
+
+type checkRepair struct {
+	v        *Violation
+	key, val []byte
+}
+
+func newChecker(lengthFields map[uint16]bool) *checker {
+	return &checker{
+		lengthFields:     lengthFields,
+		report:           &CheckReport{Violations: []*Violation{}},
+		fields:           make(map[uint16]bool),
+		referencedFields: make(map[uint16][]byte),
+		expectedTerms:    make(map[string]bool),
+		expectedStored:   make(map[string]bool),
+		docFields:        make(map[string]map[uint16]bool),
+		dictionary:       make(map[string]uint64),
+		dictionaryCounts: make(map[string]uint64),
+		fieldLengthRows:  make(map[uint16]*FieldLengthRow),
+		fieldLengths:     make(map[uint16]*checkFieldLength),
+	}
+}
+
+func (c *checker) violation(kind, message string, keys ...[]byte) *Violation {
+	rv := &Violation{
+		Kind:    kind,
+		Message: message,
+		Keys:    keys,
+	}
+	c.report.Violations = append(c.report.Violations, rv)
+	return rv
+}
+
+// delete schedules the deletion of a row for repair.
+func (c *checker) delete(v *Violation, key []byte) {
+	c.repairs = append(c.repairs, checkRepair{v: v, key: key})
+}
+
+func (c *checker) set(v *Violation, key, val []byte) {
+	c.repairs = append(c.repairs, checkRepair{v: v, key: key, val: val})
+}
+
+func (c *checker) reference(field uint16, key []byte) {
+	if _, ok := c.referencedFields[field]; !ok {
+		c.referencedFields[field] = key
+	}
+}
+
+func (c *checker) check(kvreader store.KVReader) (err error) {
+	it := kvreader.RangeIterator(nil, nil)
+	defer func() {
+		if cerr := it.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	// rows are visited in key order, so back index rows come
+	// before the rows they reference, and dictionary rows before
+	// term frequency rows
+	key, val, valid := it.Current()
+	for valid {
+		c.report.Rows++
+		ck := make([]byte, len(key))
+		copy(ck, key)
+		cv := make([]byte, len(val))
+		copy(cv, val)
+		row, err := ParseFromKeyValue(ck, cv)
+		if err != nil {
+			v := c.violation(ViolationCorruptRow, err.Error(), ck)
+			c.delete(v, ck)
+		} else {
+			c.checkRow(row)
+		}
+		it.Next()
+		key, val, valid = it.Current()
+	}
+
+	c.checkMissing()
+	c.checkDictionary()
+	c.checkFieldLengths()
+	c.checkFields()
+	return nil
+}
+
+func (c *checker) checkRow(row UpsideDownCouchRow) {
+	switch row := row.(type) {
+	case *BackIndexRow:
+		c.report.Documents++
+		fields := make(map[uint16]bool)
+		for _, key := range row.AllTermKeys() {
+			c.expectedTerms[string(key)] = true
+		}
+		for _, entry := range row.termEntries {
+			fields[uint16(entry.GetField())] = false
+			c.reference(uint16(entry.GetField()), row.Key())
+		}
+		for _, key := range row.AllStoredKeys() {
+			c.expectedStored[string(key)] = true
+		}
+		for _, entry := range row.storedEntries {
+			c.reference(uint16(entry.GetField()), row.Key())
+		}
+		c.docFields[string(row.doc)] = fields
+	case *DocValuesRow:
+		c.reference(row.field, row.Key())
+		if _, ok := c.docFields[string(row.doc)][row.field]; !ok {
+			v := c.violation(ViolationOrphanedDocValues,
+				fmt.Sprintf("document '%s' has doc values but no terms in field %d", row.doc, row.field), row.Key())
+			c.delete(v, row.Key())
+		}
+	case *DictionaryRow:
+		c.dictionary[string(row.Key())] = row.count
+	case *FieldRow:
+		c.fields[row.index] = true
+	case *FieldLengthRow:
+		c.reference(row.field, row.Key())
+		c.fieldLengthRows[row.field] = row
+	case *StoredRow:
+		key := row.Key()
+		c.reference(row.field, key)
+		if c.expectedStored[string(key)] {
+			delete(c.expectedStored, string(key))
+		} else {
+			v := c.violation(ViolationOrphanedStored,
+				fmt.Sprintf("stored field %d of document '%s' is not in its back index row", row.field, row.doc), key)
+			c.delete(v, key)
+		}
+	case *TermFrequencyRow:
+		key := row.Key()
+		c.reference(row.field, key)
+		if !c.expectedTerms[string(key)] {
+			v := c.violation(ViolationOrphanedTermFrequency,
+				fmt.Sprintf("term '%s' of field %d of document '%s' is not in its back index row", row.term, row.field, row.doc), key)
+			c.delete(v, key)
+			return
+		}
+		delete(c.expectedTerms, string(key))
+		c.dictionaryCounts[string(row.DictionaryRowKey())]++
+
+		fields := c.docFields[string(row.doc)]
+		if !fields[row.field] {
+			fields[row.field] = true
+			fl := c.fieldLengths[row.field]
+			if fl == nil {
+				fl = &checkFieldLength{}
+				c.fieldLengths[row.field] = fl
+			}
+			fl.docCount++
+			fl.totalLength += fieldLengthFromNorm(row.norm)
+		}
+	}
+}
+
+// checkMissing reports the rows referenced by back index rows
+// which were not found.
+func (c *checker) checkMissing() {
+	for _, key := range sortedKeys(c.expectedTerms) {
+		tfr, err := NewTermFrequencyRowK(key)
+		if err != nil {
+			continue
+		}
+		c.violation(ViolationMissingTermFrequency,
+			fmt.Sprintf("term '%s' of field %d of document '%s' has no term frequency row", tfr.term, tfr.field, tfr.doc), key)
+	}
+	for _, key := range sortedKeys(c.expectedStored) {
+		sr, err := NewStoredRowK(key)
+		if err != nil {
+			continue
+		}
+		c.violation(ViolationMissingStored,
+			fmt.Sprintf("stored field %d of document '%s' has no stored row", sr.field, sr.doc), key)
+	}
+}
+
+func (c *checker) checkDictionary() {
+	keys := make(map[string]bool, len(c.dictionary))
+	for key := range c.dictionary {
+		keys[key] = true
+	}
+	for key := range c.dictionaryCounts {
+		keys[key] = true
+	}
+	for _, key := range sortedKeys(keys) {
+		count, expected := c.dictionary[string(key)], c.dictionaryCounts[string(key)]
+		if count == expected {
+			continue
+		}
+		dr, err := NewDictionaryRowK(key)
+		if err != nil {
+			continue
+		}
+		dr.count = expected
+		v := c.violation(ViolationDictionaryCount,
+			fmt.Sprintf("term '%s' of field %d has count %d, expected %d", dr.term, dr.field, count, expected), key)
+		c.set(v, key, dr.Value())
+	}
+}
+
+func (c *checker) checkFieldLengths() {
+	fields := make(map[uint16]bool)
+	for field := range c.fieldLengthRows {
+		fields[field] = true
+	}
+	for field := range c.lengthFields {
+		fields[field] = true
+	}
+	for _, field := range sortedFields(fields) {
+		expected := c.fieldLengths[field]
+		if expected == nil {
+			expected = &checkFieldLength{}
+		}
+		row := c.fieldLengthRows[field]
+		if row == nil {
+			row = NewFieldLengthRow(field, 0, 0)
+		}
+		if row.docCount == expected.docCount && row.totalLength == expected.totalLength {
+			continue
+		}
+		v := c.violation(ViolationFieldLength,
+			fmt.Sprintf("field %d has %d documents of total length %d, expected %d of total length %d",
+				field, row.docCount, row.totalLength, expected.docCount, expected.totalLength), row.Key())
+		if expected.docCount == 0 {
+			c.delete(v, row.Key())
+		} else {
+			c.set(v, row.Key(), NewFieldLengthRow(field, expected.docCount, expected.totalLength).Value())
+		}
+	}
+}
+
+func (c *checker) checkFields() {
+	fields := make(map[uint16]bool)
+	for field := range c.referencedFields {
+		fields[field] = true
+	}
+	for _, field := range sortedFields(fields) {
+		if !c.fields[field] {
+			c.violation(ViolationMissingField,
+				fmt.Sprintf("field %d has no field row", field), c.referencedFields[field])
+		}
+	}
+}
+
+func (c *checker) repair(kvstore store.KVStore) (err error) {
+	if len(c.repairs) == 0 {
+		return nil
+	}
+	kvwriter, err := kvstore.Writer()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := kvwriter.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	batch := kvwriter.NewBatch()
+	defer func() {
+		if cerr := batch.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+	// violations are marked repaired once the batch holding
+	// their repair has been executed
+	start := 0
+	for i, repair := range c.repairs {
+		if repair.val == nil {
+			batch.Delete(repair.key)
+		} else {
+			batch.Set(repair.key, repair.val)
+		}
+		if (i+1)%checkRepairBatchSize == 0 || i == len(c.repairs)-1 {
+			err = kvwriter.ExecuteBatch(batch)
+			if err != nil {
+				return err
+			}
+			batch.Reset()
+			for _, done := range c.repairs[start : i+1] {
+				done.v.Repaired = true
+			}
+			start = i + 1
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]bool) [][]byte {
+	rv := make([][]byte, 0, len(m))
+	for key := range m {
+		rv = append(rv, []byte(key))
+	}
+	sort.Sort(keyset(rv))
+	return rv
+}
+
+func sortedFields(m map[uint16]bool) []uint16 {
+	rv := make([]uint16, 0, len(m))
+	for field := range m {
+		rv = append(rv, field)
+	}
+	sort.Sort(fieldIndexes(rv))
+	return rv
+}
+
+type fieldIndexes []uint16
+
+func (f fieldIndexes) Len() int           { return len(f) }
+func (f fieldIndexes) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f fieldIndexes) Less(i, j int) bool { return f[i] < f[j] }
@@ -0,0 +1,267 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package upside_down
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/store/boltdb"
+)
+
+func TestIndexCheck(t *testing.T) {
+	defer func() {
+		err := DestroyTest()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	analysisQueue := index.NewAnalysisQueue(1)
+	idx, err := NewUpsideDownCouch(boltdb.Name, boltTestConfig, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	udc := idx.(*UpsideDownCouch)
+
+	doc := document.NewDocument("1")
+	doc.AddField(document.NewTextFieldCustom("name", []uint64{}, []byte("test mister"), document.IndexField|document.StoreField|document.DocValues|document.FieldLengths, testAnalyzer))
+	doc.AddField(document.NewTextFieldWithIndexingOptions("title", []uint64{}, []byte("sir"), document.IndexField|document.StoreField))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = document.NewDocument("2")
+	doc.AddField(document.NewTextFieldWithIndexingOptions("name", []uint64{}, []byte("test"), document.IndexField|document.StoreField|document.FieldLengths))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// doc values of a field without tokens
+	doc = document.NewDocument("4")
+	doc.AddField(document.NewTextFieldCustom("name", []uint64{}, []byte(""), document.IndexField|document.DocValues, testAnalyzer))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := udc.Check(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Violations) != 0 {
+		t.Fatalf("expected no violations, got %v", report.Violations)
+	}
+	if report.Documents != 3 {
+		t.Errorf("expected 3 documents, got %d", report.Documents)
+	}
+	rows, err := udc.rowCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != rows {
+		t.Errorf("expected %d rows, got %d", rows, report.Rows)
+	}
+
+	// damage the index
+	kvwriter, err := udc.store.Writer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := kvwriter.NewBatch()
+	// the term row of document 2, leaving the dictionary count too high
+	batch.Delete(NewTermFrequencyRow([]byte("test"), 0, []byte("2"), 0, 0).Key())
+	// rows of a document without back index row
+	batch.Set(NewTermFrequencyRow([]byte("sir"), 1, []byte("3"), 1, 1).Key(), NewTermFrequencyRow([]byte("sir"), 1, []byte("3"), 1, 1).Value())
+	batch.Set(NewStoredRow([]byte("3"), 1, []uint64{}, 't', []byte("sir")).Key(), []byte("tsir"))
+	batch.Set(NewDocValuesRow(1, []byte("2"), [][]byte{[]byte("sir")}).Key(), []byte{})
+	batch.Set(NewFieldLengthRow(7, 1, 1).Key(), NewFieldLengthRow(7, 1, 1).Value())
+	batch.Set([]byte{'z'}, []byte{})
+	err = kvwriter.ExecuteBatch(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = batch.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = kvwriter.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := func(report *CheckReport) map[string]int {
+		rv := make(map[string]int)
+		for _, v := range report.Violations {
+			rv[v.Kind]++
+		}
+		return rv
+	}
+
+	// checking without repair leaves the violations unrepaired
+	report, err = udc.Check(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Violations) != 9 {
+		t.Errorf("expected 9 violations, got %v", report.Violations)
+	}
+	for _, v := range report.Violations {
+		if v.Repaired {
+			t.Errorf("expected %v not to be repaired", v)
+		}
+	}
+
+	report, err = udc.Check(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int{
+		ViolationCorruptRow:            1,
+		ViolationMissingTermFrequency:  1,
+		ViolationOrphanedTermFrequency: 1,
+		ViolationOrphanedStored:        1,
+		ViolationOrphanedDocValues:     1,
+		ViolationDictionaryCount:       1,
+		ViolationFieldLength:           2,
+		ViolationMissingField:          1,
+	}
+	if !reflect.DeepEqual(kinds(report), expected) {
+		t.Errorf("expected violations %v, got %v", expected, report.Violations)
+	}
+	for _, v := range report.Violations {
+		repairable := v.Kind != ViolationMissingTermFrequency && v.Kind != ViolationMissingField
+		if v.Repaired != repairable {
+			t.Errorf("expected repaired %t for %v", repairable, v)
+		}
+		if len(v.Keys) == 0 {
+			t.Errorf("expected offending keys for %v", v)
+		}
+	}
+
+	// only the violations which cannot be repaired remain
+	report, err = udc.Check(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = map[string]int{
+		ViolationMissingTermFrequency: 1,
+	}
+	if !reflect.DeepEqual(kinds(report), expected) {
+		t.Errorf("expected violations %v, got %v", expected, report.Violations)
+	}
+	if report.Violations[0].Message != "term 'test' of field 0 of document '2' has no term frequency row" {
+		t.Errorf("unexpected message: %s", report.Violations[0].Message)
+	}
+}
+
+func TestIndexCheckMaintainFieldLengths(t *testing.T) {
+	defer func() {
+		err := DestroyTest()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	analysisQueue := index.NewAnalysisQueue(1)
+	idx, err := NewUpsideDownCouch(boltdb.Name, boltTestConfig, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	udc := idx.(*UpsideDownCouch)
+
+	fieldStats := func() index.FieldStats {
+		indexReader, err := idx.Reader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			err := indexReader.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}()
+		stats, err := indexReader.FieldStats("name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *stats
+	}
+
+	// documents indexed without field lengths, as by older versions
+	doc := document.NewDocument("1")
+	doc.AddField(document.NewTextFieldWithAnalyzer("name", []uint64{}, []byte("test mister"), testAnalyzer))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = document.NewDocument("2")
+	doc.AddField(document.NewTextFieldWithAnalyzer("name", []uint64{}, []byte("test"), testAnalyzer))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := udc.Check(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Violations) != 0 {
+		t.Fatalf("expected no violations, got %v", report.Violations)
+	}
+	if stats := fieldStats(); stats != (index.FieldStats{}) {
+		t.Errorf("expected no stats, got %v", stats)
+	}
+
+	udc.MaintainFieldLengths("name", "unknown")
+	report, err = udc.Check(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Kind != ViolationFieldLength || !report.Violations[0].Repaired {
+		t.Fatalf("expected a repaired field length violation, got %v", report.Violations)
+	}
+	expected := index.FieldStats{DocCount: 2, TotalLength: 3}
+	if stats := fieldStats(); stats != expected {
+		t.Errorf("expected stats %v, got %v", expected, stats)
+	}
+
+	// from now on the lengths are maintained
+	err = idx.Delete("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = index.FieldStats{DocCount: 1, TotalLength: 1}
+	if stats := fieldStats(); stats != expected {
+		t.Errorf("expected stats %v, got %v", expected, stats)
+	}
+}
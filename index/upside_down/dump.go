@@ -17,7 +17,7 @@ import (
 )
 
 // the functions in this file are only intended to be used by
-// the bleve_dump utility and the debug http handlers
+// the bleve dump command and the debug http handlers
 // if your application relies on them, you're doing something wrong
 // they may change or be removed at any time
 
@@ -174,6 +174,24 @@ func (udc *UpsideDownCouch) DumpDoc(id string) chan interface{} {
 		storedRowPrefix := NewStoredRow(idBytes, 0, []uint64{}, 'x', []byte{}).ScanPrefixForDoc()
 		udc.dumpPrefix(kvreader, rv, storedRowPrefix)
 
+		// then the doc values rows
+		for _, key := range udc.docValuesKeys(back) {
+			val, err := kvreader.Get(key)
+			if err != nil {
+				rv <- err
+				return
+			}
+			if val == nil {
+				continue
+			}
+			row, err := NewDocValuesRowKV(key, val)
+			if err != nil {
+				rv <- err
+				return
+			}
+			rv <- row
+		}
+
 		// now walk term keys in order and add them as well
 		if len(keys) > 0 {
 			it := kvreader.RangeIterator(keys[0], nil)
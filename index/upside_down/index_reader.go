@@ -110,6 +110,61 @@ func (i *IndexReader) DocumentFieldTerms(id string) (index.FieldTerms, error) {
 	return rv, nil
 }
 
+func (i *IndexReader) DocumentFieldTermsForFields(id string, fields []string) (index.FieldTerms, error) {
+	rv := make(index.FieldTerms, len(fields))
+	var fallback []string
+	for _, field := range fields {
+		fieldIndex, fieldExists := i.index.fieldCache.FieldNamed(field, false)
+		if !fieldExists {
+			continue
+		}
+		if !i.index.hasDocValues(fieldIndex) {
+			fallback = append(fallback, field)
+			continue
+		}
+		val, err := i.kvreader.Get(NewDocValuesRow(fieldIndex, []byte(id), nil).Key())
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			// the document was indexed without doc values
+			// for this field
+			fallback = append(fallback, field)
+			continue
+		}
+		row := NewDocValuesRow(fieldIndex, []byte(id), nil)
+		err = row.parseV(val)
+		if err != nil {
+			return nil, err
+		}
+		terms := make([]string, len(row.terms))
+		for j, term := range row.terms {
+			terms[j] = string(term)
+		}
+		rv[field] = terms
+	}
+	if len(fallback) > 0 {
+		back, err := i.index.backIndexRowForDoc(i.kvreader, id)
+		if err != nil {
+			return nil, err
+		}
+		if back == nil {
+			return rv, nil
+		}
+		fallbackIndexes := make(map[uint16]string, len(fallback))
+		for _, field := range fallback {
+			fieldIndex, _ := i.index.fieldCache.FieldNamed(field, false)
+			fallbackIndexes[fieldIndex] = field
+		}
+		for _, entry := range back.termEntries {
+			if field, ok := fallbackIndexes[uint16(*entry.Field)]; ok {
+				rv[field] = append(rv[field], *entry.Term)
+			}
+		}
+	}
+	return rv, nil
+}
+
 func (i *IndexReader) Fields() (fields []string, err error) {
 	fields = make([]string, 0)
 	it := i.kvreader.PrefixIterator([]byte{'f'})
@@ -139,6 +194,29 @@ func (i *IndexReader) Fields() (fields []string, err error) {
 	return
 }
 
+func (i *IndexReader) FieldStats(fieldName string) (*index.FieldStats, error) {
+	fieldIndex, fieldExists := i.index.fieldCache.FieldNamed(fieldName, false)
+	if !fieldExists {
+		return &index.FieldStats{}, nil
+	}
+	key := NewFieldLengthRow(fieldIndex, 0, 0).Key()
+	value, err := i.kvreader.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return &index.FieldStats{}, nil
+	}
+	row, err := NewFieldLengthRowKV(key, value)
+	if err != nil {
+		return nil, err
+	}
+	return &index.FieldStats{
+		DocCount:    row.docCount,
+		TotalLength: row.totalLength,
+	}, nil
+}
+
 func (i *IndexReader) GetInternal(key []byte) ([]byte, error) {
 	internalRow := NewInternalRow(key, nil)
 	return i.kvreader.Get(internalRow.Key())
@@ -49,6 +49,10 @@ func ParseFromKeyValue(key, value []byte) (UpsideDownCouchRow, error) {
 			return NewStoredRowKV(key, value)
 		case 'i':
 			return NewInternalRowKV(key, value)
+		case 'c':
+			return NewDocValuesRowKV(key, value)
+		case 'l':
+			return NewFieldLengthRowKV(key, value)
 		}
 		return nil, fmt.Errorf("Unknown field type '%s'", string(key[0]))
 	}
@@ -818,3 +822,214 @@ func NewStoredRowKV(key, value []byte) (*StoredRow, error) {
 	rv.value = value[1:]
 	return rv, nil
 }
+
+// DOC VALUES
+
+// DocValuesRow holds the indexed terms of one field of one document.
+// Rows are keyed by field first, so that the values of a field form
+// a column.
+type DocValuesRow struct {
+	field uint16
+	doc   []byte
+	terms [][]byte
+}
+
+func (dv *DocValuesRow) Key() []byte {
+	buf := make([]byte, dv.KeySize())
+	size, _ := dv.KeyTo(buf)
+	return buf[:size]
+}
+
+func (dv *DocValuesRow) KeySize() int {
+	return 3 + len(dv.doc)
+}
+
+func (dv *DocValuesRow) KeyTo(buf []byte) (int, error) {
+	buf[0] = 'c'
+	binary.LittleEndian.PutUint16(buf[1:3], dv.field)
+	used := copy(buf[3:], dv.doc)
+	return used + 3, nil
+}
+
+func (dv *DocValuesRow) Value() []byte {
+	buf := make([]byte, dv.ValueSize())
+	size, _ := dv.ValueTo(buf)
+	return buf[:size]
+}
+
+func (dv *DocValuesRow) ValueSize() int {
+	rv := 0
+	for _, term := range dv.terms {
+		rv += binary.MaxVarintLen64 + len(term)
+	}
+	return rv
+}
+
+func (dv *DocValuesRow) ValueTo(buf []byte) (int, error) {
+	used := 0
+	for _, term := range dv.terms {
+		used += binary.PutUvarint(buf[used:], uint64(len(term)))
+		used += copy(buf[used:], term)
+	}
+	return used, nil
+}
+
+func (dv *DocValuesRow) String() string {
+	return fmt.Sprintf("DocValues Field: %d Document: %s Terms: %q", dv.field, dv.doc, dv.terms)
+}
+
+// ScanPrefixForField returns the key prefix shared by all the doc
+// values rows of the field.
+func (dv *DocValuesRow) ScanPrefixForField() []byte {
+	buf := make([]byte, 3)
+	buf[0] = 'c'
+	binary.LittleEndian.PutUint16(buf[1:3], dv.field)
+	return buf
+}
+
+func NewDocValuesRow(field uint16, docID []byte, terms [][]byte) *DocValuesRow {
+	return &DocValuesRow{
+		field: field,
+		doc:   docID,
+		terms: terms,
+	}
+}
+
+func NewDocValuesRowK(key []byte) (*DocValuesRow, error) {
+	if len(key) < 4 {
+		return nil, fmt.Errorf("invalid doc values row key length %d", len(key))
+	}
+	rv := DocValuesRow{
+		field: binary.LittleEndian.Uint16(key[1:3]),
+		doc:   key[3:],
+	}
+	return &rv, nil
+}
+
+func NewDocValuesRowKV(key, value []byte) (*DocValuesRow, error) {
+	rv, err := NewDocValuesRowK(key)
+	if err != nil {
+		return nil, err
+	}
+	err = rv.parseV(value)
+	if err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+func (dv *DocValuesRow) parseV(value []byte) error {
+	dv.terms = nil
+	for len(value) > 0 {
+		termLen, n := binary.Uvarint(value)
+		if n <= 0 || uint64(len(value)-n) < termLen {
+			return fmt.Errorf("invalid doc values row value")
+		}
+		value = value[n:]
+		dv.terms = append(dv.terms, value[:termLen])
+		value = value[termLen:]
+	}
+	return nil
+}
+
+// FIELD LENGTH
+
+// FieldLengthRow holds the number of documents having terms in a field
+// and the total length of the field in these documents. It is updated
+// with merges of FieldLengthRowDeltaSize bytes operands.
+type FieldLengthRow struct {
+	field       uint16
+	docCount    uint64
+	totalLength uint64
+}
+
+const FieldLengthRowMaxValueSize = 2 * binary.MaxVarintLen64
+const FieldLengthRowDeltaSize = 16
+
+func (fl *FieldLengthRow) Key() []byte {
+	buf := make([]byte, fl.KeySize())
+	size, _ := fl.KeyTo(buf)
+	return buf[:size]
+}
+
+func (fl *FieldLengthRow) KeySize() int {
+	return 3
+}
+
+func (fl *FieldLengthRow) KeyTo(buf []byte) (int, error) {
+	buf[0] = 'l'
+	binary.LittleEndian.PutUint16(buf[1:3], fl.field)
+	return 3, nil
+}
+
+func (fl *FieldLengthRow) Value() []byte {
+	buf := make([]byte, fl.ValueSize())
+	size, _ := fl.ValueTo(buf)
+	return buf[:size]
+}
+
+func (fl *FieldLengthRow) ValueSize() int {
+	return FieldLengthRowMaxValueSize
+}
+
+func (fl *FieldLengthRow) ValueTo(buf []byte) (int, error) {
+	used := binary.PutUvarint(buf, fl.docCount)
+	used += binary.PutUvarint(buf[used:], fl.totalLength)
+	return used, nil
+}
+
+func (fl *FieldLengthRow) String() string {
+	return fmt.Sprintf("FieldLength Field: %d DocCount: %d TotalLength: %d", fl.field, fl.docCount, fl.totalLength)
+}
+
+func NewFieldLengthRow(field uint16, docCount, totalLength uint64) *FieldLengthRow {
+	return &FieldLengthRow{
+		field:       field,
+		docCount:    docCount,
+		totalLength: totalLength,
+	}
+}
+
+func NewFieldLengthRowK(key []byte) (*FieldLengthRow, error) {
+	if len(key) != 3 {
+		return nil, fmt.Errorf("invalid field length row key length %d", len(key))
+	}
+	rv := FieldLengthRow{
+		field: binary.LittleEndian.Uint16(key[1:3]),
+	}
+	return &rv, nil
+}
+
+func NewFieldLengthRowKV(key, value []byte) (*FieldLengthRow, error) {
+	rv, err := NewFieldLengthRowK(key)
+	if err != nil {
+		return nil, err
+	}
+	err = rv.parseV(value)
+	if err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+func (fl *FieldLengthRow) parseV(value []byte) error {
+	docCount, n := binary.Uvarint(value)
+	if n <= 0 {
+		return fmt.Errorf("invalid field length row value")
+	}
+	totalLength, m := binary.Uvarint(value[n:])
+	if m <= 0 {
+		return fmt.Errorf("invalid field length row value")
+	}
+	fl.docCount = docCount
+	fl.totalLength = totalLength
+	return nil
+}
+
+// fieldLengthDelta encodes the changes of the document count and total
+// length of a field as a FieldLengthRow merge operand.
+func fieldLengthDelta(buf []byte, docCount, totalLength int64) []byte {
+	binary.LittleEndian.PutUint64(buf[0:8], uint64(docCount))
+	binary.LittleEndian.PutUint64(buf[8:16], uint64(totalLength))
+	return buf[:FieldLengthRowDeltaSize]
+}
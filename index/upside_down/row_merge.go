@@ -29,6 +29,10 @@ func init() {
 type upsideDownMerge struct{}
 
 func (m *upsideDownMerge) FullMerge(key, existingValue []byte, operands [][]byte) ([]byte, bool) {
+	if len(key) > 0 && key[0] == 'l' {
+		return m.fullMergeFieldLength(key, existingValue, operands)
+	}
+
 	// set up record based on key
 	dr, err := NewDictionaryRowK(key)
 	if err != nil {
@@ -59,6 +63,15 @@ func (m *upsideDownMerge) FullMerge(key, existingValue []byte, operands [][]byte
 }
 
 func (m *upsideDownMerge) PartialMerge(key, leftOperand, rightOperand []byte) ([]byte, bool) {
+	if len(key) > 0 && key[0] == 'l' {
+		if len(leftOperand) != FieldLengthRowDeltaSize || len(rightOperand) != FieldLengthRowDeltaSize {
+			return nil, false
+		}
+		rv := make([]byte, FieldLengthRowDeltaSize)
+		return fieldLengthDelta(rv,
+			int64(binary.LittleEndian.Uint64(leftOperand[0:8]))+int64(binary.LittleEndian.Uint64(rightOperand[0:8])),
+			int64(binary.LittleEndian.Uint64(leftOperand[8:16]))+int64(binary.LittleEndian.Uint64(rightOperand[8:16]))), true
+	}
 	left := int64(binary.LittleEndian.Uint64(leftOperand))
 	right := int64(binary.LittleEndian.Uint64(rightOperand))
 	rv := make([]byte, 8)
@@ -66,6 +79,39 @@ func (m *upsideDownMerge) PartialMerge(key, leftOperand, rightOperand []byte) ([
 	return rv, true
 }
 
+func (m *upsideDownMerge) fullMergeFieldLength(key, existingValue []byte, operands [][]byte) ([]byte, bool) {
+	fl, err := NewFieldLengthRowK(key)
+	if err != nil {
+		return nil, false
+	}
+	if len(existingValue) > 0 {
+		err = fl.parseV(existingValue)
+		if err != nil {
+			return nil, false
+		}
+	}
+
+	for _, operand := range operands {
+		if len(operand) != FieldLengthRowDeltaSize {
+			return nil, false
+		}
+		fl.docCount = addDelta(fl.docCount, int64(binary.LittleEndian.Uint64(operand[0:8])))
+		fl.totalLength = addDelta(fl.totalLength, int64(binary.LittleEndian.Uint64(operand[8:16])))
+	}
+
+	return fl.Value(), true
+}
+
+// addDelta adds next to a counter, stopping at 0.
+func addDelta(count uint64, next int64) uint64 {
+	if next < 0 && uint64(-next) > count {
+		return 0
+	} else if next < 0 {
+		return count - uint64(-next)
+	}
+	return count + uint64(next)
+}
+
 func (m *upsideDownMerge) Name() string {
 	return "upsideDownMerge"
 }
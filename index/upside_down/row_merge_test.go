@@ -50,3 +50,28 @@ func decodeCount(in []byte) uint64 {
 	count, _ := binary.ReadUvarint(buf)
 	return count
 }
+
+func TestFieldLengthMerge(t *testing.T) {
+	mo := &upsideDownMerge{}
+	key := NewFieldLengthRow(1, 0, 0).Key()
+
+	left := fieldLengthDelta(make([]byte, FieldLengthRowDeltaSize), 2, 10)
+	right := fieldLengthDelta(make([]byte, FieldLengthRowDeltaSize), -1, -4)
+	partial, ok := mo.PartialMerge(key, left, right)
+	if !ok {
+		t.Fatalf("expected partial merge ok")
+	}
+
+	existing := NewFieldLengthRow(1, 5, 20).Value()
+	merged, ok := mo.FullMerge(key, existing, [][]byte{partial})
+	if !ok {
+		t.Fatalf("expected full merge ok")
+	}
+	row, err := NewFieldLengthRowKV(key, merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.docCount != 6 || row.totalLength != 26 {
+		t.Errorf("expected 6 docs and length 26, got %d and %d", row.docCount, row.totalLength)
+	}
+}
@@ -100,6 +100,16 @@ func TestRows(t *testing.T) {
 			[]byte{'s', 'b', 'u', 'd', 'w', 'e', 'i', 's', 'e', 'r', ByteSeparator, 0, 0, 2, 166, 2, 134, 24},
 			[]byte{'t', 'a', 'n', ' ', 'a', 'm', 'e', 'r', 'i', 'c', 'a', 'n', ' ', 'b', 'e', 'e', 'r'},
 		},
+		{
+			NewDocValuesRow(1, []byte("budweiser"), [][]byte{[]byte("beer"), []byte("lager")}),
+			[]byte{'c', 1, 0, 'b', 'u', 'd', 'w', 'e', 'i', 's', 'e', 'r'},
+			[]byte{4, 'b', 'e', 'e', 'r', 5, 'l', 'a', 'g', 'e', 'r'},
+		},
+		{
+			NewFieldLengthRow(1, 3, 300),
+			[]byte{'l', 1, 0},
+			[]byte{3, 172, 2},
+		},
 		{
 			NewInternalRow([]byte("mapping"), []byte(`{"mapping":"json content"}`)),
 			[]byte{'i', 'm', 'a', 'p', 'p', 'i', 'n', 'g'},
@@ -222,6 +232,16 @@ func TestInvalidRows(t *testing.T) {
 			[]byte{'s'},
 			[]byte{'t', 'a', 'n', ' ', 'a', 'm', 'e', 'r', 'i', 'c', 'a', 'n', ' ', 'b', 'e', 'e', 'r'},
 		},
+		// type c, invalid key (missing id)
+		{
+			[]byte{'c', 1, 0},
+			[]byte{4, 'b', 'e', 'e', 'r'},
+		},
+		// type c, invalid val (truncated term)
+		{
+			[]byte{'c', 1, 0, 'b', 'u', 'd', 'w', 'e', 'i', 's', 'e', 'r'},
+			[]byte{5, 'b', 'e', 'e', 'r'},
+		},
 		// type b, invalid val (missing field)
 		{
 			[]byte{'s', 'b', 'u', 'd', 'w', 'e', 'i', 's', 'e', 'r', ByteSeparator},
@@ -54,7 +54,9 @@ type UpsideDownCouch struct {
 
 	m sync.RWMutex
 	// fields protected by m
-	docCount uint64
+	docCount           uint64
+	docValuesFields    map[uint16]bool
+	fieldLengthsFields map[uint16]bool
 
 	writeMutex sync.Mutex
 }
@@ -63,15 +65,41 @@ type docBackIndexRow struct {
 	docID        string
 	doc          *document.Document // If deletion, doc will be nil.
 	backIndexRow *BackIndexRow
+	fieldLengths map[uint16]uint64
+}
+
+// fieldLengthChange is the change of the document count and total length
+// of a field brought by a batch.
+type fieldLengthChange struct {
+	docCount    int64
+	totalLength int64
+}
+
+type fieldLengthChanges map[uint16]*fieldLengthChange
+
+// add records the addition of the field lengths of a document, or their
+// removal when sign is negative.
+func (c fieldLengthChanges) add(fieldLengths map[uint16]uint64, sign int64) {
+	for field, length := range fieldLengths {
+		change := c[field]
+		if change == nil {
+			change = &fieldLengthChange{}
+			c[field] = change
+		}
+		change.docCount += sign
+		change.totalLength += sign * int64(length)
+	}
 }
 
 func NewUpsideDownCouch(storeName string, storeConfig map[string]interface{}, analysisQueue *index.AnalysisQueue) (index.Index, error) {
 	rv := &UpsideDownCouch{
-		version:       Version,
-		fieldCache:    index.NewFieldCache(),
-		storeName:     storeName,
-		storeConfig:   storeConfig,
-		analysisQueue: analysisQueue,
+		version:            Version,
+		fieldCache:         index.NewFieldCache(),
+		docValuesFields:    make(map[uint16]bool),
+		fieldLengthsFields: make(map[uint16]bool),
+		storeName:          storeName,
+		storeConfig:        storeConfig,
+		analysisQueue:      analysisQueue,
 	}
 	rv.stats = &indexStat{i: rv}
 	return rv, nil
@@ -83,7 +111,7 @@ func (udc *UpsideDownCouch) init(kvwriter store.KVWriter) (err error) {
 		{NewVersionRow(udc.version)},
 	}
 
-	err = udc.batchRows(kvwriter, nil, rowsAll, nil)
+	err = udc.batchRows(kvwriter, nil, rowsAll, nil, nil)
 	return
 }
 
@@ -96,6 +124,7 @@ func (udc *UpsideDownCouch) loadSchema(kvreader store.KVReader) (err error) {
 		}
 	}()
 
+	var fieldIndexes []uint16
 	key, val, valid := it.Current()
 	for valid {
 		var fieldRow *FieldRow
@@ -104,11 +133,36 @@ func (udc *UpsideDownCouch) loadSchema(kvreader store.KVReader) (err error) {
 			return
 		}
 		udc.fieldCache.AddExisting(fieldRow.name, fieldRow.index)
+		fieldIndexes = append(fieldIndexes, fieldRow.index)
 
 		it.Next()
 		key, val, valid = it.Current()
 	}
 
+	// fields with doc values are those having at least one row in their column
+	for _, fieldIndex := range fieldIndexes {
+		var hasDocValues bool
+		hasDocValues, err = udc.hasDocValuesRows(kvreader, fieldIndex)
+		if err != nil {
+			return
+		}
+		if hasDocValues {
+			udc.addDocValuesField(fieldIndex)
+		}
+	}
+
+	// fields with lengths are those having a field length row
+	for _, fieldIndex := range fieldIndexes {
+		var fieldLengths []byte
+		fieldLengths, err = kvreader.Get(NewFieldLengthRow(fieldIndex, 0, 0).Key())
+		if err != nil {
+			return
+		}
+		if fieldLengths != nil {
+			udc.addFieldLengthsField(fieldIndex)
+		}
+	}
+
 	val, err = kvreader.Get([]byte{'v'})
 	if err != nil {
 		return
@@ -126,6 +180,72 @@ func (udc *UpsideDownCouch) loadSchema(kvreader store.KVReader) (err error) {
 	return
 }
 
+func (udc *UpsideDownCouch) hasDocValuesRows(kvreader store.KVReader, fieldIndex uint16) (rv bool, err error) {
+	it := kvreader.PrefixIterator(NewDocValuesRow(fieldIndex, nil, nil).ScanPrefixForField())
+	defer func() {
+		if cerr := it.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+	_, _, rv = it.Current()
+	return
+}
+
+func (udc *UpsideDownCouch) addDocValuesField(fieldIndex uint16) {
+	udc.m.RLock()
+	exists := udc.docValuesFields[fieldIndex]
+	udc.m.RUnlock()
+	if !exists {
+		udc.m.Lock()
+		udc.docValuesFields[fieldIndex] = true
+		udc.m.Unlock()
+	}
+}
+
+func (udc *UpsideDownCouch) hasDocValues(fieldIndex uint16) bool {
+	udc.m.RLock()
+	defer udc.m.RUnlock()
+	return udc.docValuesFields[fieldIndex]
+}
+
+func (udc *UpsideDownCouch) addFieldLengthsField(fieldIndex uint16) {
+	udc.m.RLock()
+	exists := udc.fieldLengthsFields[fieldIndex]
+	udc.m.RUnlock()
+	if !exists {
+		udc.m.Lock()
+		udc.fieldLengthsFields[fieldIndex] = true
+		udc.m.Unlock()
+	}
+}
+
+func (udc *UpsideDownCouch) hasFieldLengths(fieldIndex uint16) bool {
+	udc.m.RLock()
+	defer udc.m.RUnlock()
+	return udc.fieldLengthsFields[fieldIndex]
+}
+
+// docValuesKeys returns the keys of the doc values rows which may exist
+// for the document described by the back index row
+func (udc *UpsideDownCouch) docValuesKeys(backIndexRow *BackIndexRow) [][]byte {
+	if backIndexRow == nil {
+		return nil
+	}
+	var rv [][]byte
+	seen := make(map[uint16]bool)
+	for _, termEntry := range backIndexRow.termEntries {
+		fieldIndex := uint16(termEntry.GetField())
+		if seen[fieldIndex] {
+			continue
+		}
+		seen[fieldIndex] = true
+		if udc.hasDocValues(fieldIndex) {
+			rv = append(rv, NewDocValuesRow(fieldIndex, backIndexRow.doc, nil).Key())
+		}
+	}
+	return rv
+}
+
 var rowBufferPool sync.Pool
 
 func GetRowBuffer() []byte {
@@ -140,7 +260,7 @@ func PutRowBuffer(buf []byte) {
 	rowBufferPool.Put(buf)
 }
 
-func (udc *UpsideDownCouch) batchRows(writer store.KVWriter, addRowsAll [][]UpsideDownCouchRow, updateRowsAll [][]UpsideDownCouchRow, deleteRowsAll [][]UpsideDownCouchRow) (err error) {
+func (udc *UpsideDownCouch) batchRows(writer store.KVWriter, addRowsAll [][]UpsideDownCouchRow, updateRowsAll [][]UpsideDownCouchRow, deleteRowsAll [][]UpsideDownCouchRow, fieldLengths fieldLengthChanges) (err error) {
 	dictionaryDeltas := make(map[string]int64)
 
 	// count up bytes needed for buffering.
@@ -205,13 +325,15 @@ func (udc *UpsideDownCouch) batchRows(writer store.KVWriter, addRowsAll [][]Upsi
 
 	PutRowBuffer(rowBuf)
 
-	mergeNum := len(dictionaryDeltas)
+	mergeNum := len(dictionaryDeltas) + len(fieldLengths)
 	mergeKeyBytes := 0
-	mergeValBytes := mergeNum * DictionaryRowMaxValueSize
+	mergeValBytes := len(dictionaryDeltas)*DictionaryRowMaxValueSize +
+		len(fieldLengths)*FieldLengthRowDeltaSize
 
 	for dictRowKey := range dictionaryDeltas {
 		mergeKeyBytes += len(dictRowKey)
 	}
+	mergeKeyBytes += len(fieldLengths) * (&FieldLengthRow{}).KeySize()
 
 	// prepare batch
 	totBytes := addKeyBytes + addValBytes +
@@ -281,6 +403,16 @@ func (udc *UpsideDownCouch) batchRows(writer store.KVWriter, addRowsAll [][]Upsi
 		buf = buf[dictRowKeyLen+DictionaryRowMaxValueSize:]
 	}
 
+	for field, change := range fieldLengths {
+		fieldLengthRowKeyLen, err := NewFieldLengthRow(field, 0, 0).KeyTo(buf)
+		if err != nil {
+			return err
+		}
+		delta := fieldLengthDelta(buf[fieldLengthRowKeyLen:], change.docCount, change.totalLength)
+		wb.Merge(buf[:fieldLengthRowKeyLen], delta)
+		buf = buf[fieldLengthRowKeyLen+len(delta):]
+	}
+
 	// write out the batch
 	return writer.ExecuteBatch(wb)
 }
@@ -446,6 +578,15 @@ func (udc *UpsideDownCouch) Update(doc *document.Document) (err error) {
 		return
 	}
 
+	// and the lengths of its fields, to update the field statistics
+	var oldFieldLengths map[uint16]uint64
+	oldFieldLengths, err = udc.fieldLengthsForDoc(kvreader, backIndexRow)
+	if err != nil {
+		_ = kvreader.Close()
+		atomic.AddUint64(&udc.stats.errors, 1)
+		return
+	}
+
 	err = kvreader.Close()
 	if err != nil {
 		return
@@ -480,7 +621,11 @@ func (udc *UpsideDownCouch) Update(doc *document.Document) (err error) {
 		deleteRowsAll = append(deleteRowsAll, deleteRows)
 	}
 
-	err = udc.batchRows(kvwriter, addRowsAll, updateRowsAll, deleteRowsAll)
+	fieldLengths := make(fieldLengthChanges)
+	fieldLengths.add(oldFieldLengths, -1)
+	fieldLengths.add(udc.fieldLengthsFromRows(result.Rows), 1)
+
+	err = udc.batchRows(kvwriter, addRowsAll, updateRowsAll, deleteRowsAll, fieldLengths)
 	if err == nil && backIndexRow == nil {
 		udc.m.Lock()
 		udc.docCount++
@@ -511,6 +656,11 @@ func (udc *UpsideDownCouch) mergeOldAndNew(backIndexRow *BackIndexRow, rows []in
 		existingStoredKeys[string(key)] = true
 	}
 
+	existingDocValuesKeys := make(map[string]bool)
+	for _, key := range udc.docValuesKeys(backIndexRow) {
+		existingDocValuesKeys[string(key)] = true
+	}
+
 	keyBuf := GetRowBuffer()
 	for _, row := range rows {
 		switch row := row.(type) {
@@ -536,6 +686,17 @@ func (udc *UpsideDownCouch) mergeOldAndNew(backIndexRow *BackIndexRow, rows []in
 			} else {
 				addRows = append(addRows, row)
 			}
+		case *DocValuesRow:
+			if row.KeySize() > len(keyBuf) {
+				keyBuf = make([]byte, row.KeySize())
+			}
+			keySize, _ := row.KeyTo(keyBuf)
+			if _, ok := existingDocValuesKeys[string(keyBuf[:keySize])]; ok {
+				updateRows = append(updateRows, row)
+				delete(existingDocValuesKeys, string(keyBuf[:keySize]))
+			} else {
+				addRows = append(addRows, row)
+			}
 		default:
 			updateRows = append(updateRows, row)
 		}
@@ -558,6 +719,14 @@ func (udc *UpsideDownCouch) mergeOldAndNew(backIndexRow *BackIndexRow, rows []in
 		}
 	}
 
+	// any of the existing doc values that weren't updated need to be deleted
+	for existingDocValuesKey := range existingDocValuesKeys {
+		docValuesRow, err := NewDocValuesRowK([]byte(existingDocValuesKey))
+		if err == nil {
+			deleteRows = append(deleteRows, docValuesRow)
+		}
+	}
+
 	return addRows, updateRows, deleteRows
 }
 
@@ -582,6 +751,10 @@ func encodeFieldType(f document.Field) byte {
 		fieldType = 'd'
 	case *document.BooleanField:
 		fieldType = 'b'
+	case *document.GeoPointField:
+		fieldType = 'g'
+	case *document.CompletionField:
+		fieldType = 'o'
 	case *document.CompositeField:
 		fieldType = 'c'
 	}
@@ -634,6 +807,14 @@ func (udc *UpsideDownCouch) Delete(id string) (err error) {
 		return
 	}
 
+	var oldFieldLengths map[uint16]uint64
+	oldFieldLengths, err = udc.fieldLengthsForDoc(kvreader, backIndexRow)
+	if err != nil {
+		_ = kvreader.Close()
+		atomic.AddUint64(&udc.stats.errors, 1)
+		return
+	}
+
 	err = kvreader.Close()
 	if err != nil {
 		return
@@ -663,7 +844,10 @@ func (udc *UpsideDownCouch) Delete(id string) (err error) {
 		deleteRowsAll = append(deleteRowsAll, deleteRows)
 	}
 
-	err = udc.batchRows(kvwriter, nil, nil, deleteRowsAll)
+	fieldLengths := make(fieldLengthChanges)
+	fieldLengths.add(oldFieldLengths, -1)
+
+	err = udc.batchRows(kvwriter, nil, nil, deleteRowsAll, fieldLengths)
 	if err == nil {
 		udc.m.Lock()
 		udc.docCount--
@@ -689,6 +873,12 @@ func (udc *UpsideDownCouch) deleteSingle(id string, backIndexRow *BackIndexRow,
 		sf := NewStoredRow(idBytes, uint16(*se.Field), se.ArrayPositions, 'x', nil)
 		deleteRows = append(deleteRows, sf)
 	}
+	for _, key := range udc.docValuesKeys(backIndexRow) {
+		dvr, err := NewDocValuesRowK(key)
+		if err == nil {
+			deleteRows = append(deleteRows, dvr)
+		}
+	}
 
 	// also delete the back entry itself
 	deleteRows = append(deleteRows, backIndexRow)
@@ -725,6 +915,60 @@ func (udc *UpsideDownCouch) backIndexRowForDoc(kvreader store.KVReader, docID st
 	return backIndexRow, nil
 }
 
+// fieldLengthsForDoc returns the lengths of the indexed fields of the
+// document whose lengths are maintained, recovered from the norms of its
+// term frequency rows.
+func (udc *UpsideDownCouch) fieldLengthsForDoc(kvreader store.KVReader, backIndexRow *BackIndexRow) (map[uint16]uint64, error) {
+	if backIndexRow == nil {
+		return nil, nil
+	}
+	rv := make(map[uint16]uint64)
+	for _, entry := range backIndexRow.termEntries {
+		field := uint16(*entry.Field)
+		if _, ok := rv[field]; ok || !udc.hasFieldLengths(field) {
+			continue
+		}
+		key := NewTermFrequencyRow([]byte(*entry.Term), field, backIndexRow.doc, 0, 0).Key()
+		value, err := kvreader.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		tfr, err := NewTermFrequencyRowKV(key, value)
+		if err != nil {
+			return nil, err
+		}
+		rv[field] = fieldLengthFromNorm(tfr.norm)
+	}
+	return rv, nil
+}
+
+// fieldLengthsFromRows returns the lengths of the fields indexed by rows
+// whose lengths are maintained.
+func (udc *UpsideDownCouch) fieldLengthsFromRows(rows []index.IndexRow) map[uint16]uint64 {
+	rv := make(map[uint16]uint64)
+	for _, row := range rows {
+		tfr, ok := row.(*TermFrequencyRow)
+		if !ok || !udc.hasFieldLengths(tfr.field) {
+			continue
+		}
+		if _, ok := rv[tfr.field]; !ok {
+			rv[tfr.field] = fieldLengthFromNorm(tfr.norm)
+		}
+	}
+	return rv
+}
+
+// fieldLengthFromNorm inverts the norm computed by indexField.
+func fieldLengthFromNorm(norm float32) uint64 {
+	if norm <= 0 || math.IsInf(float64(norm), 0) {
+		return 0
+	}
+	return uint64(1/(float64(norm)*float64(norm)) + 0.5)
+}
+
 func decodeFieldType(typ byte, name string, pos []uint64, value []byte) document.Field {
 	switch typ {
 	case 't':
@@ -735,6 +979,10 @@ func decodeFieldType(typ byte, name string, pos []uint64, value []byte) document
 		return document.NewDateTimeFieldFromBytes(name, pos, value)
 	case 'b':
 		return document.NewBooleanFieldFromBytes(name, pos, value)
+	case 'g':
+		return document.NewGeoPointFieldFromBytes(name, pos, value)
+	case 'o':
+		return document.NewCompletionFieldFromBytes(name, pos, value)
 	}
 	return nil
 }
@@ -835,7 +1083,13 @@ func (udc *UpsideDownCouch) Batch(batch *index.Batch) (err error) {
 				return
 			}
 
-			docBackIndexRowCh <- &docBackIndexRow{docID, doc, backIndexRow}
+			fieldLengths, err := udc.fieldLengthsForDoc(kvreader, backIndexRow)
+			if err != nil {
+				docBackIndexRowErr = err
+				return
+			}
+
+			docBackIndexRowCh <- &docBackIndexRow{docID, doc, backIndexRow, fieldLengths}
 		}
 
 		err = kvreader.Close()
@@ -889,8 +1143,15 @@ func (udc *UpsideDownCouch) Batch(batch *index.Batch) (err error) {
 		deleteRowsAll = append(deleteRowsAll, deleteRows)
 	}
 
+	fieldLengths := make(fieldLengthChanges)
+
 	// process back index rows as they arrive
 	for dbir := range docBackIndexRowCh {
+		fieldLengths.add(dbir.fieldLengths, -1)
+		if dbir.doc != nil {
+			fieldLengths.add(udc.fieldLengthsFromRows(newRowsMap[dbir.docID]), 1)
+		}
+
 		if dbir.doc == nil && dbir.backIndexRow != nil {
 			// delete
 			deleteRows := udc.deleteSingle(dbir.docID, dbir.backIndexRow, nil)
@@ -926,7 +1187,7 @@ func (udc *UpsideDownCouch) Batch(batch *index.Batch) (err error) {
 		return
 	}
 
-	err = udc.batchRows(kvwriter, addRowsAll, updateRowsAll, deleteRowsAll)
+	err = udc.batchRows(kvwriter, addRowsAll, updateRowsAll, deleteRowsAll, fieldLengths)
 	if err != nil {
 		_ = kvwriter.Close()
 		atomic.AddUint64(&udc.stats.errors, 1)
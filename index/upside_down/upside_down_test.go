@@ -25,6 +25,7 @@ import (
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/index/store/boltdb"
 	"github.com/blevesearch/bleve/index/store/null"
+	"github.com/blevesearch/bleve/numeric_util"
 	"github.com/blevesearch/bleve/registry"
 )
 
@@ -1192,6 +1193,138 @@ func TestIndexDocumentFieldTerms(t *testing.T) {
 	}
 }
 
+func countDocValuesRows(t *testing.T, idx index.Index) int {
+	rv := 0
+	for row := range idx.DumpAll() {
+		switch row := row.(type) {
+		case error:
+			t.Fatal(row)
+		case *DocValuesRow:
+			rv++
+		}
+	}
+	return rv
+}
+
+func TestIndexDocValues(t *testing.T) {
+	defer func() {
+		err := DestroyTest()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	analysisQueue := index.NewAnalysisQueue(1)
+	idx, err := NewUpsideDownCouch(boltdb.Name, boltTestConfig, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Errorf("error opening index: %v", err)
+	}
+
+	doc := document.NewDocument("1")
+	doc.AddField(document.NewTextFieldCustom("name", []uint64{}, []byte("test mister test"), document.IndexField|document.DocValues, testAnalyzer))
+	doc.AddField(document.NewNumericFieldWithIndexingOptions("age", []uint64{}, 35.0, document.IndexField|document.DocValues))
+	doc.AddField(document.NewTextFieldWithIndexingOptions("title", []uint64{}, []byte("sir"), document.IndexField))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Errorf("Error updating index: %v", err)
+	}
+	doc = document.NewDocument("2")
+	doc.AddField(document.NewNumericFieldWithIndexingOptions("age", []uint64{}, 27.0, document.IndexField|document.DocValues))
+	doc.AddField(document.NewTextFieldWithIndexingOptions("name", []uint64{}, []byte("madam"), document.IndexField))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Errorf("Error updating index: %v", err)
+	}
+
+	if count := countDocValuesRows(t, idx); count != 3 {
+		t.Errorf("expected 3 doc values rows, got %d", count)
+	}
+
+	indexReader, err := idx.Reader()
+	if err != nil {
+		t.Error(err)
+	}
+	fieldTerms, err := indexReader.DocumentFieldTermsForFields("1", []string{"name", "age", "title", "unknown"})
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFieldTerms := index.FieldTerms{
+		"name":  []string{"mister", "test"},
+		"age":   []string{string(numeric_util.MustNewPrefixCodedInt64(numeric_util.Float64ToInt64(35.0), 0))},
+		"title": []string{"sir"},
+	}
+	if !reflect.DeepEqual(fieldTerms, expectedFieldTerms) {
+		t.Errorf("expected field terms: %#v, got: %#v", expectedFieldTerms, fieldTerms)
+	}
+	// name has no doc values in document 2, its terms come
+	// from the back index row
+	fieldTerms, err = indexReader.DocumentFieldTermsForFields("2", []string{"name"})
+	if err != nil {
+		t.Error(err)
+	}
+	expectedFieldTerms = index.FieldTerms{
+		"name": []string{"madam"},
+	}
+	if !reflect.DeepEqual(fieldTerms, expectedFieldTerms) {
+		t.Errorf("expected field terms: %#v, got: %#v", expectedFieldTerms, fieldTerms)
+	}
+	err = indexReader.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// updating without the name field removes its doc values
+	doc = document.NewDocument("1")
+	doc.AddField(document.NewNumericFieldWithIndexingOptions("age", []uint64{}, 36.0, document.IndexField|document.DocValues))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Errorf("Error updating index: %v", err)
+	}
+	if count := countDocValuesRows(t, idx); count != 2 {
+		t.Errorf("expected 2 doc values rows, got %d", count)
+	}
+
+	err = idx.Delete("1")
+	if err != nil {
+		t.Errorf("Error deleting entry from index: %v", err)
+	}
+	if count := countDocValuesRows(t, idx); count != 1 {
+		t.Errorf("expected 1 doc values rows, got %d", count)
+	}
+
+	// doc values fields are found again after reopening
+	err = idx.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err = NewUpsideDownCouch(boltdb.Name, boltTestConfig, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Errorf("error opening index: %v", err)
+	}
+	defer func() {
+		err := idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	ageIndex, _ := idx.(*UpsideDownCouch).fieldCache.FieldNamed("age", false)
+	if !idx.(*UpsideDownCouch).hasDocValues(ageIndex) {
+		t.Errorf("expected age field to have doc values after reopening")
+	}
+	nameIndex, _ := idx.(*UpsideDownCouch).fieldCache.FieldNamed("name", false)
+	if idx.(*UpsideDownCouch).hasDocValues(nameIndex) {
+		t.Errorf("expected name field to have no doc values after reopening")
+	}
+}
+
 func BenchmarkBatch(b *testing.B) {
 
 	cache := registry.NewCache()
@@ -1228,6 +1361,103 @@ func BenchmarkBatch(b *testing.B) {
 	}
 }
 
+func TestIndexFieldStats(t *testing.T) {
+	defer func() {
+		err := DestroyTest()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	analysisQueue := index.NewAnalysisQueue(1)
+	idx, err := NewUpsideDownCouch(boltdb.Name, boltTestConfig, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Errorf("error opening index: %v", err)
+	}
+	defer func() {
+		err := idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	checkStats := func(field string, expected index.FieldStats) {
+		indexReader, err := idx.Reader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			err := indexReader.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}()
+		stats, err := indexReader.FieldStats(field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *stats != expected {
+			t.Errorf("expected %s stats %v, got %v", field, expected, *stats)
+		}
+	}
+
+	fieldLengthsOptions := document.IndexField | document.FieldLengths
+
+	doc := document.NewDocument("1")
+	doc.AddField(document.NewTextFieldCustom("name", []uint64{}, []byte("test mister test"), fieldLengthsOptions, testAnalyzer))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Errorf("Error updating index: %v", err)
+	}
+	doc = document.NewDocument("2")
+	doc.AddField(document.NewTextFieldCustom("name", []uint64{}, []byte("one two"), fieldLengthsOptions, testAnalyzer))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Errorf("Error updating index: %v", err)
+	}
+	checkStats("name", index.FieldStats{DocCount: 2, TotalLength: 5})
+
+	// updates replace the length of the previous version
+	doc = document.NewDocument("1")
+	doc.AddField(document.NewTextFieldCustom("name", []uint64{}, []byte("a"), fieldLengthsOptions, testAnalyzer))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Errorf("Error updating index: %v", err)
+	}
+	checkStats("name", index.FieldStats{DocCount: 2, TotalLength: 3})
+
+	batch := index.NewBatch()
+	batch.Delete("2")
+	doc = document.NewDocument("3")
+	doc.AddField(document.NewTextFieldCustom("name", []uint64{}, []byte("x y z w"), fieldLengthsOptions, testAnalyzer))
+	batch.Update(doc)
+	err = idx.Batch(batch)
+	if err != nil {
+		t.Errorf("Error running batch: %v", err)
+	}
+	checkStats("name", index.FieldStats{DocCount: 2, TotalLength: 5})
+
+	err = idx.Delete("1")
+	if err != nil {
+		t.Errorf("Error deleting entry from index: %v", err)
+	}
+	checkStats("name", index.FieldStats{DocCount: 1, TotalLength: 4})
+	checkStats("unknown", index.FieldStats{})
+
+	// lengths are only maintained for fields needing them
+	doc = document.NewDocument("4")
+	doc.AddField(document.NewTextFieldWithAnalyzer("desc", []uint64{}, []byte("no lengths"), testAnalyzer))
+	err = idx.Update(doc)
+	if err != nil {
+		t.Errorf("Error updating index: %v", err)
+	}
+	checkStats("desc", index.FieldStats{})
+}
+
 func TestConcurrentUpdate(t *testing.T) {
 	defer func() {
 		err := DestroyTest()
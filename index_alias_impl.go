@@ -10,7 +10,8 @@
 package bleve
 
 import (
-	"sort"
+	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -157,6 +158,102 @@ func (i *indexAliasImpl) SearchInContext(ctx context.Context, req *SearchRequest
 	return MultiSearch(ctx, req, i.indexes...)
 }
 
+func (i *indexAliasImpl) Suggest(req *SuggestRequest) (*SuggestResult, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if !i.open {
+		return nil, ErrorIndexClosed
+	}
+
+	if len(i.indexes) < 1 {
+		return nil, ErrorAliasEmpty
+	}
+
+	// short circuit the simple case
+	if len(i.indexes) == 1 {
+		return i.indexes[0].Suggest(req)
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	terms, err := i.mergedTermSuggestions(req)
+	if err != nil {
+		return nil, err
+	}
+	return newSuggestResult(req, terms), nil
+}
+
+func (i *indexAliasImpl) termSuggestions(req *SuggestRequest) ([]*TermSuggestion, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if !i.open {
+		return nil, ErrorIndexClosed
+	}
+
+	if len(i.indexes) < 1 {
+		return nil, ErrorAliasEmpty
+	}
+
+	return i.mergedTermSuggestions(req)
+}
+
+// mergedTermSuggestions merges the candidate corrections
+// found in each index of the alias.
+func (i *indexAliasImpl) mergedTermSuggestions(req *SuggestRequest) ([]*TermSuggestion, error) {
+	var rv []*TermSuggestion
+	for _, index := range i.indexes {
+		suggester, ok := index.(termSuggester)
+		if !ok {
+			return nil, fmt.Errorf("index %s cannot suggest terms within an alias", index.Name())
+		}
+		terms, err := suggester.termSuggestions(req)
+		if err != nil {
+			return nil, err
+		}
+		rv = mergeTermSuggestions(rv, terms)
+	}
+	return rv, nil
+}
+
+func (i *indexAliasImpl) Complete(req *CompletionRequest) (*CompletionResult, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if !i.open {
+		return nil, ErrorIndexClosed
+	}
+
+	if len(i.indexes) < 1 {
+		return nil, ErrorAliasEmpty
+	}
+
+	// short circuit the simple case
+	if len(i.indexes) == 1 {
+		return i.indexes[0].Complete(req)
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	var completions []*Completion
+	for _, index := range i.indexes {
+		cr, err := index.Complete(req)
+		if err != nil {
+			return nil, err
+		}
+		completions = append(completions, cr.Completions...)
+	}
+	return &CompletionResult{
+		Request:     req,
+		Completions: mergeCompletions(completions, req.size()),
+	}, nil
+}
+
 func (i *indexAliasImpl) Fields() ([]string, error) {
 	i.mutex.RLock()
 	defer i.mutex.RUnlock()
@@ -323,6 +420,22 @@ func (i *indexAliasImpl) Mapping() *IndexMapping {
 	return i.indexes[0].Mapping()
 }
 
+func (i *indexAliasImpl) UpdateMapping(mapping *IndexMapping) error {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if !i.open {
+		return ErrorIndexClosed
+	}
+
+	err := i.isAliasToSingleIndex()
+	if err != nil {
+		return err
+	}
+
+	return i.indexes[0].UpdateMapping(mapping)
+}
+
 func (i *indexAliasImpl) Stats() *IndexStat {
 	i.mutex.RLock()
 	defer i.mutex.RUnlock()
@@ -419,6 +532,22 @@ func (i *indexAliasImpl) Advanced() (index.Index, store.KVStore, error) {
 	return i.indexes[0].Advanced()
 }
 
+func (i *indexAliasImpl) Backup(w io.Writer) error {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if !i.open {
+		return ErrorIndexClosed
+	}
+
+	err := i.isAliasToSingleIndex()
+	if err != nil {
+		return err
+	}
+
+	return i.indexes[0].Backup(w)
+}
+
 func (i *indexAliasImpl) Add(indexes ...Index) {
 	i.mutex.Lock()
 	defer i.mutex.Unlock()
@@ -467,17 +596,39 @@ func (i *indexAliasImpl) Swap(in, out []Index) {
 // could be slower in remote usages.
 func createChildSearchRequest(req *SearchRequest) *SearchRequest {
 	rv := SearchRequest{
-		Query:     req.Query,
-		Size:      req.Size + req.From,
-		From:      0,
-		Highlight: req.Highlight,
-		Fields:    req.Fields,
-		Facets:    req.Facets,
-		Explain:   req.Explain,
+		Query:        req.Query,
+		Size:         req.Size + req.From,
+		From:         0,
+		Highlight:    req.Highlight,
+		Fields:       req.Fields,
+		Facets:       createChildFacetsRequest(req.Facets),
+		Aggregations: req.Aggregations,
+		Explain:      req.Explain,
+		Sort:         req.Sort,
+		SearchAfter:  req.SearchAfter,
 	}
 	return &rv
 }
 
+// createChildFacetsRequest marks the histogram facets of a
+// child request as partial, so that they keep all their
+// buckets until the results of the children are merged.
+func createChildFacetsRequest(facets FacetsRequest) FacetsRequest {
+	if facets == nil {
+		return nil
+	}
+	rv := make(FacetsRequest, len(facets))
+	for name, fr := range facets {
+		if fr.isHistogram() {
+			child := *fr
+			child.partial = true
+			fr = &child
+		}
+		rv[name] = fr
+	}
+	return rv
+}
+
 type asyncSearchResult struct {
 	Name   string
 	Result *SearchResult
@@ -568,8 +719,8 @@ func MultiSearch(ctx context.Context, req *SearchRequest, indexes ...Index) (*Se
 		}
 	}
 
-	// first sort it by score
-	sort.Sort(sr.Hits)
+	// first sort it by the requested order, score by default
+	search.SortDocumentMatches(sr.Hits, req.sortOrder())
 
 	// now skip over the correct From
 	if req.From > 0 && len(sr.Hits) > req.From {
@@ -585,9 +736,19 @@ func MultiSearch(ctx context.Context, req *SearchRequest, indexes ...Index) (*Se
 
 	// fix up facets
 	for name, fr := range req.Facets {
-		sr.Facets.Fixup(name, fr.Size)
+		if fr.isHistogram() {
+			err := fr.fixupHistogram(sr.Facets[name])
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			sr.Facets.Fixup(name, fr.Size)
+		}
 	}
 
+	// fix up aggregations
+	req.Aggregations.fixup(sr.Aggregations)
+
 	// fix up original request
 	sr.Request = req
 	searchDuration := time.Since(searchStart)
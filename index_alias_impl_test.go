@@ -2,7 +2,10 @@ package bleve
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -1241,6 +1244,10 @@ func (i *stubIndex) Mapping() *IndexMapping {
 	return nil
 }
 
+func (i *stubIndex) UpdateMapping(mapping *IndexMapping) error {
+	return i.err
+}
+
 func (i *stubIndex) Stats() *IndexStat {
 	return nil
 }
@@ -1265,6 +1272,18 @@ func (i *stubIndex) Advanced() (index.Index, store.KVStore, error) {
 	return nil, nil, nil
 }
 
+func (i *stubIndex) Backup(w io.Writer) error {
+	return i.err
+}
+
+func (i *stubIndex) Suggest(req *SuggestRequest) (*SuggestResult, error) {
+	return nil, i.err
+}
+
+func (i *stubIndex) Complete(req *CompletionRequest) (*CompletionResult, error) {
+	return nil, i.err
+}
+
 func (i *stubIndex) NewBatch() *Batch {
 	return &Batch{}
 }
@@ -1276,3 +1295,218 @@ func (i *stubIndex) Name() string {
 func (i *stubIndex) SetName(name string) {
 	i.name = name
 }
+
+func TestMultiSearchSort(t *testing.T) {
+	ei1 := &stubIndex{
+		searchResult: &SearchResult{
+			Status: &SearchStatus{
+				Total:      1,
+				Successful: 1,
+				Errors:     make(map[string]error),
+			},
+			Total: 2,
+			Hits: search.DocumentMatchCollection{
+				&search.DocumentMatch{ID: "a", Score: 1.0, Sort: []string{"apple"}},
+				&search.DocumentMatch{ID: "c", Score: 2.0, Sort: []string{"cherry"}},
+			},
+			MaxScore: 2.0,
+		},
+	}
+	ei2 := &stubIndex{
+		searchResult: &SearchResult{
+			Status: &SearchStatus{
+				Total:      1,
+				Successful: 1,
+				Errors:     make(map[string]error),
+			},
+			Total: 2,
+			Hits: search.DocumentMatchCollection{
+				&search.DocumentMatch{ID: "b", Score: 3.0, Sort: []string{"banana"}},
+				&search.DocumentMatch{ID: "d", Score: 4.0, Sort: []string{"date"}},
+			},
+			MaxScore: 4.0,
+		},
+	}
+
+	sr := NewSearchRequest(NewTermQuery("test"))
+	sr.SortBy([]string{"-fruit"})
+	results, err := MultiSearch(context.Background(), sr, ei1, ei2)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expectedIDs := []string{"d", "c", "b", "a"}
+	if len(results.Hits) != len(expectedIDs) {
+		t.Fatalf("expected %d hits, got %d", len(expectedIDs), len(results.Hits))
+	}
+	for i, hit := range results.Hits {
+		if hit.ID != expectedIDs[i] {
+			t.Errorf("expected hit %d to be %s, got %s", i, expectedIDs[i], hit.ID)
+		}
+	}
+}
+
+func TestMultiSearchSearchAfter(t *testing.T) {
+	checkRequest := func(sr *SearchRequest) error {
+		if len(sr.SearchAfter) != 1 || sr.SearchAfter[0] != "b" {
+			return fmt.Errorf("child request should have search after [b], got %v", sr.SearchAfter)
+		}
+		return nil
+	}
+	ei1 := &stubIndex{
+		searchResult: &SearchResult{
+			Status: &SearchStatus{
+				Total:      1,
+				Successful: 1,
+				Errors:     make(map[string]error),
+			},
+			Total: 2,
+			Hits: search.DocumentMatchCollection{
+				&search.DocumentMatch{ID: "c", Score: 1.0, Sort: []string{"c"}},
+				&search.DocumentMatch{ID: "e", Score: 1.0, Sort: []string{"e"}},
+			},
+		},
+		checkRequest: checkRequest,
+	}
+	ei2 := &stubIndex{
+		searchResult: &SearchResult{
+			Status: &SearchStatus{
+				Total:      1,
+				Successful: 1,
+				Errors:     make(map[string]error),
+			},
+			Total: 2,
+			Hits: search.DocumentMatchCollection{
+				&search.DocumentMatch{ID: "d", Score: 1.0, Sort: []string{"d"}},
+				&search.DocumentMatch{ID: "f", Score: 1.0, Sort: []string{"f"}},
+			},
+		},
+		checkRequest: checkRequest,
+	}
+
+	sr := NewSearchRequestOptions(NewTermQuery("test"), 2, 0, false)
+	sr.SortBy([]string{"_id"})
+	sr.SetSearchAfter([]string{"b"})
+	results, err := MultiSearch(context.Background(), sr, ei1, ei2)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expectedIDs := []string{"c", "d"}
+	if len(results.Hits) != len(expectedIDs) {
+		t.Fatalf("expected %d hits, got %d", len(expectedIDs), len(results.Hits))
+	}
+	for i, hit := range results.Hits {
+		if hit.ID != expectedIDs[i] {
+			t.Errorf("expected hit %d to be %s, got %s", i, expectedIDs[i], hit.ID)
+		}
+	}
+}
+
+func TestMultiSearchAggregations(t *testing.T) {
+	newResult := func(buckets search.AggregationBuckets) *SearchResult {
+		return &SearchResult{
+			Status: &SearchStatus{
+				Total:      1,
+				Successful: 1,
+				Errors:     make(map[string]error),
+			},
+			Aggregations: search.AggregationResults{
+				"types": &search.AggregationResult{
+					Field:   "type",
+					Type:    "terms",
+					Buckets: buckets,
+				},
+			},
+		}
+	}
+	ei1 := &stubIndex{
+		searchResult: newResult(search.AggregationBuckets{
+			{Key: "ale", Count: 3},
+			{Key: "stout", Count: 2},
+		}),
+	}
+	ei2 := &stubIndex{
+		searchResult: newResult(search.AggregationBuckets{
+			{Key: "lager", Count: 4},
+			{Key: "stout", Count: 2},
+		}),
+	}
+
+	sr := NewSearchRequest(NewTermQuery("test"))
+	types := NewAggregationRequest("terms", "type")
+	types.Size = 2
+	sr.AddAggregation("types", types)
+	results, err := MultiSearch(context.Background(), sr, ei1, ei2)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	merged := results.Aggregations["types"]
+	var keys []string
+	for _, bucket := range merged.Buckets {
+		keys = append(keys, fmt.Sprintf("%s:%d", bucket.Key, bucket.Count))
+	}
+	expectedKeys := []string{"lager:4", "stout:4"}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("expected buckets %v, got %v", expectedKeys, keys)
+	}
+	if merged.Other != 3 {
+		t.Errorf("expected 3 others, got %d", merged.Other)
+	}
+}
+
+func TestIndexAliasHistogramMinDocCount(t *testing.T) {
+	paths := []string{"testidx1", "testidx2", "testidx3"}
+	defer func() {
+		for _, path := range paths {
+			err := os.RemoveAll(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}()
+
+	// every index holds a single value of the 100 bucket
+	docs := [][]float64{{120, 310}, {130}, {150}}
+	alias := NewIndexAlias()
+	for i, path := range paths {
+		index, err := New(path, NewIndexMapping())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			err := index.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}()
+		for j, pages := range docs[i] {
+			err = index.Index(strconv.Itoa(j), map[string]interface{}{"pages": pages})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		alias.Add(index)
+	}
+
+	req := NewSearchRequest(NewMatchAllQuery())
+	histogram := NewNumericHistogramFacetRequest("pages", 100)
+	histogram.MinDocCount = 2
+	req.AddFacet("pages", histogram)
+	res, err := alias.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buckets []string
+	for _, nr := range res.Facets["pages"].NumericRanges {
+		buckets = append(buckets, fmt.Sprintf("%s:%d", nr.Name, nr.Count))
+	}
+	expected := []string{"100:3"}
+	if !reflect.DeepEqual(buckets, expected) {
+		t.Errorf("expected buckets %v, got %v", expected, buckets)
+	}
+	if histogram.partial {
+		t.Errorf("expected the request not to be modified")
+	}
+}
@@ -0,0 +1,391 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/blevesearch/bleve/index"
+)
+
+// A backup archive is laid out as follows, all lengths and
+// counts being encoded as uvarints:
+//
+//	magic    "bleve-backup"
+//	version  1
+//	header   length, JSON encoded backupHeader
+//	rows     key length, key, value length, value, for every
+//	         row of the KV store in key order
+//	end      0, keys are never empty
+//	count    number of rows
+//	checksum big endian CRC-32 (IEEE) of everything before it
+//
+// The rows are those of the index type, so an archive can only be
+// restored using the same index type, but with any KV store.
+const (
+	backupMagic   = "bleve-backup"
+	backupVersion = 1
+
+	// larger keys or values denote a corrupt archive
+	backupMaxLength = 1 << 30
+)
+
+type backupHeader struct {
+	IndexType string                 `json:"index_type"`
+	Storage   string                 `json:"storage"`
+	Config    map[string]interface{} `json:"config,omitempty"`
+	Mapping   *IndexMapping          `json:"mapping"`
+}
+
+// Backup writes a consistent copy of the index to w,
+// which can be restored using Restore or RestoreUsing.
+func (i *indexImpl) Backup(w io.Writer) (err error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if !i.open {
+		return ErrorIndexClosed
+	}
+
+	kvstore, err := i.i.Advanced()
+	if err != nil {
+		return err
+	}
+	// the mapping cannot change while the read lock is held,
+	// so it matches the one persisted in the snapshot
+	kvreader, err := kvstore.Reader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := kvreader.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	bw := newBackupWriter(w)
+	err = bw.header(&backupHeader{
+		IndexType: i.meta.IndexType,
+		Storage:   i.meta.Storage,
+		Config:    backupConfig(i.meta.Config),
+		Mapping:   i.m,
+	})
+	if err != nil {
+		return err
+	}
+
+	it := kvreader.PrefixIterator([]byte{})
+	defer func() {
+		if cerr := it.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+	key, val, valid := it.Current()
+	for valid {
+		err = bw.row(key, val)
+		if err != nil {
+			return err
+		}
+		it.Next()
+		key, val, valid = it.Current()
+	}
+	return bw.close()
+}
+
+// backupConfig returns the KV store configuration without
+// the settings added when opening the index at a path.
+func backupConfig(config map[string]interface{}) map[string]interface{} {
+	if len(config) == 0 {
+		return nil
+	}
+	rv := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		switch k {
+		case "path", "create_if_missing", "error_if_exists":
+		default:
+			rv[k] = v
+		}
+	}
+	return rv
+}
+
+type backupWriter struct {
+	w    *bufio.Writer
+	out  io.Writer
+	crc  hash.Hash32
+	rows uint64
+	buf  [binary.MaxVarintLen64]byte
+}
+
+func newBackupWriter(w io.Writer) *backupWriter {
+	rv := &backupWriter{
+		w:   bufio.NewWriter(w),
+		crc: crc32.NewIEEE(),
+	}
+	rv.out = io.MultiWriter(rv.w, rv.crc)
+	return rv
+}
+
+func (w *backupWriter) uvarint(v uint64) error {
+	n := binary.PutUvarint(w.buf[:], v)
+	_, err := w.out.Write(w.buf[:n])
+	return err
+}
+
+func (w *backupWriter) bytes(b []byte) error {
+	err := w.uvarint(uint64(len(b)))
+	if err != nil {
+		return err
+	}
+	_, err = w.out.Write(b)
+	return err
+}
+
+func (w *backupWriter) header(h *backupHeader) error {
+	headerBytes, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w.out, backupMagic)
+	if err != nil {
+		return err
+	}
+	err = w.uvarint(backupVersion)
+	if err != nil {
+		return err
+	}
+	return w.bytes(headerBytes)
+}
+
+func (w *backupWriter) row(key, val []byte) error {
+	err := w.bytes(key)
+	if err != nil {
+		return err
+	}
+	w.rows++
+	return w.bytes(val)
+}
+
+func (w *backupWriter) close() error {
+	err := w.uvarint(0)
+	if err != nil {
+		return err
+	}
+	err = w.uvarint(w.rows)
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w.w, binary.BigEndian, w.crc.Sum32())
+	if err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+type backupReader struct {
+	r   *bufio.Reader
+	crc hash.Hash32
+}
+
+func newBackupReader(r io.Reader) *backupReader {
+	return &backupReader{
+		r:   bufio.NewReader(r),
+		crc: crc32.NewIEEE(),
+	}
+}
+
+func (r *backupReader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return 0, backupReadError(err)
+	}
+	_, _ = r.crc.Write([]byte{b})
+	return b, nil
+}
+
+func (r *backupReader) uvarint() (uint64, error) {
+	return binary.ReadUvarint(r)
+}
+
+func (r *backupReader) read(n uint64) ([]byte, error) {
+	if n > backupMaxLength {
+		return nil, ErrorBackupCorrupt
+	}
+	rv := make([]byte, n)
+	_, err := io.ReadFull(r.r, rv)
+	if err != nil {
+		return nil, backupReadError(err)
+	}
+	_, _ = r.crc.Write(rv)
+	return rv, nil
+}
+
+func (r *backupReader) bytes() ([]byte, error) {
+	n, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	return r.read(n)
+}
+
+func (r *backupReader) header() (*backupHeader, error) {
+	magic, err := r.read(uint64(len(backupMagic)))
+	if err != nil {
+		return nil, err
+	}
+	if string(magic) != backupMagic {
+		return nil, ErrorBackupCorrupt
+	}
+	version, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if version != backupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", version)
+	}
+	headerBytes, err := r.bytes()
+	if err != nil {
+		return nil, err
+	}
+	var rv backupHeader
+	err = json.Unmarshal(headerBytes, &rv)
+	if err != nil {
+		return nil, ErrorBackupCorrupt
+	}
+	return &rv, nil
+}
+
+// row returns the next row of the archive, or a nil key
+// once all have been read.
+func (r *backupReader) row() (key, val []byte, err error) {
+	n, err := r.uvarint()
+	if err != nil || n == 0 {
+		return nil, nil, err
+	}
+	key, err = r.read(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	val, err = r.bytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, val, nil
+}
+
+// check verifies the trailer of the archive once all
+// rows have been read.
+func (r *backupReader) check(rows uint64) error {
+	count, err := r.uvarint()
+	if err != nil {
+		return err
+	}
+	sum := r.crc.Sum32()
+	var checksum uint32
+	err = binary.Read(r.r, binary.BigEndian, &checksum)
+	if err != nil {
+		return backupReadError(err)
+	}
+	if count != rows || checksum != sum {
+		return ErrorBackupCorrupt
+	}
+	return nil
+}
+
+func backupReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrorBackupCorrupt
+	}
+	return err
+}
+
+func restoreUsing(path string, r io.Reader, kvstore string, kvconfig map[string]interface{}, useArchiveStore bool) (rv Index, err error) {
+	if path == "" {
+		return nil, fmt.Errorf("cannot restore a backup in memory, a path is required")
+	}
+
+	br := newBackupReader(r)
+	header, err := br.header()
+	if err != nil {
+		return nil, err
+	}
+	if useArchiveStore {
+		kvstore = header.Storage
+		kvconfig = header.Config
+	}
+	if kvconfig == nil {
+		kvconfig = map[string]interface{}{}
+	}
+
+	meta := newIndexMeta(header.IndexType, kvstore, kvconfig)
+	err = meta.Save(path)
+	if err != nil {
+		return nil, err
+	}
+	// do not leave a partially restored index behind
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(path)
+		}
+	}()
+
+	i, err := openIndexStore(header.IndexType, kvstore, kvconfig, indexStorePath(path), true)
+	if err != nil {
+		return nil, err
+	}
+	err = restoreRows(i, br)
+	if cerr := i.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// reopen the index so that it loads the restored rows
+	return openIndexUsing(path, nil)
+}
+
+func restoreRows(i index.Index, br *backupReader) (err error) {
+	w, err := newRowWriter(i)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := w.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	for {
+		key, val, err := br.row()
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			break
+		}
+		err = w.Set(key, val)
+		if err != nil {
+			return err
+		}
+	}
+	err = br.check(w.rows)
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
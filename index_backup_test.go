@@ -0,0 +1,214 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve/index/store/boltdb"
+	"github.com/blevesearch/bleve/index/store/goleveldb"
+)
+
+func TestIndexBackupRestore(t *testing.T) {
+	defer func() {
+		for _, path := range []string{"testidx", "testidx-restored", "testidx-copy"} {
+			err := os.RemoveAll(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}()
+
+	mapping := NewIndexMapping()
+	mapping.DefaultMapping.AddFieldMappingsAt("name", NewTextFieldMapping())
+	index, err := NewUsing("testidx", mapping, Config.DefaultIndexType, boltdb.Name, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := index.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	docs := map[string]interface{}{
+		"a": map[string]interface{}{"name": "marty", "age": 19},
+		"b": map[string]interface{}{"name": "steve", "age": 21},
+		"c": map[string]interface{}{"name": "marty schoch", "age": 36},
+	}
+	for id, doc := range docs {
+		err = index.Index(id, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = index.SetInternal([]byte("key"), []byte("value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var backup bytes.Buffer
+	err = index.Backup(&backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := backup.Bytes()
+
+	// changes made after the backup must not be restored
+	err = index.Delete("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := RestoreUsing("testidx-restored", bytes.NewReader(archive), goleveldb.Name, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := restored.DocCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 documents, got %d", count)
+	}
+	res, err := restored.Search(NewSearchRequest(NewMatchQuery("marty").SetField("name")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 2 {
+		t.Errorf("expected 2 hits, got %d", res.Total)
+	}
+	val, err := restored.GetInternal([]byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(val) != "value" {
+		t.Errorf("expected internal value 'value', got '%s'", val)
+	}
+	if !reflect.DeepEqual(restored.Mapping(), index.Mapping()) {
+		t.Errorf("expected restored mapping %v, got %v", index.Mapping(), restored.Mapping())
+	}
+	err = restored.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the restored index is reopened using its own store
+	restored, err = Open("testidx-restored")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = restored.Index("d", map[string]interface{}{"name": "marty"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err = restored.DocCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Errorf("expected 4 documents, got %d", count)
+	}
+	err = restored.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Restore keeps the store of the backed up index
+	copied, err := Restore("testidx-copy", bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, err := openIndexMeta("testidx-copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Storage != boltdb.Name {
+		t.Errorf("expected storage %s, got %s", boltdb.Name, meta.Storage)
+	}
+	err = copied.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// restoring over an existing index fails
+	_, err = Restore("testidx-copy", bytes.NewReader(archive))
+	if err != ErrorIndexPathExists {
+		t.Errorf("expected %v, got %v", ErrorIndexPathExists, err)
+	}
+}
+
+func TestIndexRestoreCorrupt(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("", NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = index.Index("a", map[string]interface{}{"name": "marty"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var backup bytes.Buffer
+	err = index.Backup(&backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := backup.Bytes()
+
+	flipped := append([]byte(nil), archive...)
+	flipped[len(flipped)-10] ^= 0xff
+
+	tests := map[string][]byte{
+		"empty":     nil,
+		"magic":     []byte("not-a-backup-at-all"),
+		"truncated": archive[:len(archive)-3],
+		"flipped":   flipped,
+	}
+	for name, archive := range tests {
+		_, err = RestoreUsing("testidx", bytes.NewReader(archive), boltdb.Name, nil)
+		if err != ErrorBackupCorrupt {
+			t.Errorf("%s: expected %v, got %v", name, ErrorBackupCorrupt, err)
+		}
+		if _, err := os.Stat("testidx"); !os.IsNotExist(err) {
+			t.Errorf("%s: expected the partially restored index to be removed", name)
+		}
+	}
+
+	// a memory index can be restored on disk
+	restored, err := RestoreUsing("testidx", bytes.NewReader(archive), boltdb.Name, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := restored.Document("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc == nil {
+		t.Errorf("expected document a to be restored")
+	}
+	err = restored.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
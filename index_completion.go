@@ -0,0 +1,390 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search/completion"
+)
+
+const defaultCompletionSize = 5
+
+// A CompletionRequest describes a request for the
+// completions of Prefix indexed in Field, a field
+// mapped with the "completion" type.
+// Size is the maximum number of completions returned,
+// 5 by default.
+// Fuzziness is the maximum Levenshtein edit distance
+// between Prefix and the beginning of the completions.
+// Contexts restricts the completions to the ones having,
+// for every named context, one of the listed values.
+type CompletionRequest struct {
+	Prefix    string              `json:"prefix"`
+	Field     string              `json:"field"`
+	Size      int                 `json:"size,omitempty"`
+	Fuzziness int                 `json:"fuzziness,omitempty"`
+	Contexts  map[string][]string `json:"contexts,omitempty"`
+}
+
+// NewCompletionRequest creates a CompletionRequest
+// for the completions of prefix in field.
+func NewCompletionRequest(prefix, field string) *CompletionRequest {
+	return &CompletionRequest{
+		Prefix: prefix,
+		Field:  field,
+	}
+}
+
+func (r *CompletionRequest) Validate() error {
+	if r.Field == "" {
+		return fmt.Errorf("completion field must be specified")
+	}
+	if r.Size < 0 {
+		return fmt.Errorf("completion size must not be negative")
+	}
+	if r.Fuzziness < 0 {
+		return fmt.Errorf("completion fuzziness must not be negative")
+	}
+	return nil
+}
+
+func (r *CompletionRequest) size() int {
+	if r.Size == 0 {
+		return defaultCompletionSize
+	}
+	return r.Size
+}
+
+// A Completion is an input of the completion field of
+// document ID. Distance is the number of edits between
+// the prefix and the beginning of the input.
+type Completion struct {
+	ID       string              `json:"id"`
+	Text     string              `json:"text"`
+	Weight   int64               `json:"weight"`
+	Distance int                 `json:"distance"`
+	Payload  json.RawMessage     `json:"payload,omitempty"`
+	Contexts map[string][]string `json:"contexts,omitempty"`
+}
+
+// A CompletionResult holds the completions found for a
+// CompletionRequest, nearest first and then heaviest first.
+type CompletionResult struct {
+	Request     *CompletionRequest `json:"request"`
+	Completions []*Completion      `json:"completions"`
+}
+
+func (cr *CompletionResult) String() string {
+	rv := fmt.Sprintf("%d completions of '%s'\n", len(cr.Completions), cr.Request.Prefix)
+	for i, c := range cr.Completions {
+		rv += fmt.Sprintf("%5d. %s (%s) weight %d distance %d\n", i+1, c.Text, c.ID, c.Weight, c.Distance)
+	}
+	return rv
+}
+
+type completionsByRank []*Completion
+
+func (c completionsByRank) Len() int      { return len(c) }
+func (c completionsByRank) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c completionsByRank) Less(i, j int) bool {
+	if c[i].Distance != c[j].Distance {
+		return c[i].Distance < c[j].Distance
+	}
+	if c[i].Weight != c[j].Weight {
+		return c[i].Weight > c[j].Weight
+	}
+	if c[i].Text != c[j].Text {
+		return c[i].Text < c[j].Text
+	}
+	return c[i].ID < c[j].ID
+}
+
+// mergeCompletions ranks the completions found in
+// several indexes and keeps the best size of them.
+func mergeCompletions(completions []*Completion, size int) []*Completion {
+	sort.Stable(completionsByRank(completions))
+	if len(completions) > size {
+		completions = completions[:size]
+	}
+	return completions
+}
+
+// completionPrefix returns the key prefix looked up for prefix
+// in field, normalized like the inputs of the field.
+func completionPrefix(m *IndexMapping, field, prefix string) string {
+	analyzer := m.completionAnalyzerForPath(field)
+	if analyzer == nil {
+		return completion.NormalizePrefix(prefix)
+	}
+	rv := completionKey(analyzer, prefix)
+	if r, _ := utf8.DecodeLastRuneInString(prefix); rv != "" && unicode.IsSpace(r) {
+		rv += " "
+	}
+	return rv
+}
+
+// A completionCache holds the completion tries of the fields of
+// an index. The trie of a field is built from the documents of the
+// index on first use, then kept current by the writes of the index.
+type completionCache struct {
+	mutex sync.RWMutex
+	tries map[string]*completion.Trie
+	// the fields whose trie is being built
+	building map[string]*completionBuild
+}
+
+type completionBuild struct {
+	done chan struct{}
+	// the documents written meanwhile
+	ids []string
+}
+
+// update refreshes the completion entries of the documents ids once
+// their writes are done. The entries are read back from the index
+// rather than taken from the written documents, so that concurrent
+// writes of a document leave the entries of the last one committed,
+// whatever the order of their updates.
+func (c *completionCache) update(i index.Index, ids ...string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, build := range c.building {
+		build.ids = append(build.ids, ids...)
+	}
+	if len(c.tries) == 0 {
+		return nil
+	}
+	return refreshCompletions(i, c.tries, ids)
+}
+
+// refreshCompletions sets the entries of the documents ids in the
+// tries of their fields, as stored in i.
+func refreshCompletions(i index.Index, tries map[string]*completion.Trie, ids []string) (err error) {
+	r, err := i.Reader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := r.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	for _, id := range ids {
+		doc, err := r.Document(id)
+		if err != nil {
+			return err
+		}
+		entries, err := completionEntries(id, doc)
+		if err != nil {
+			return err
+		}
+		for field, trie := range tries {
+			trie.Set(id, entries[field])
+		}
+	}
+	return nil
+}
+
+// lookup returns the completions of prefix in field, building the
+// trie of field from the documents of i when it is not cached.
+func (c *completionCache) lookup(i index.Index, field, prefix string, fuzziness, size int, contexts map[string][]string) ([]*completion.Completion, error) {
+	for {
+		c.mutex.RLock()
+		if trie, ok := c.tries[field]; ok {
+			rv := trie.Lookup(prefix, fuzziness, size, contexts)
+			c.mutex.RUnlock()
+			return rv, nil
+		}
+		c.mutex.RUnlock()
+
+		err := c.build(i, field)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// build builds the trie of field, or waits for the build in
+// progress. The documents written while reading the index are
+// refreshed once the trie is built.
+func (c *completionCache) build(i index.Index, field string) error {
+	c.mutex.Lock()
+	if _, ok := c.tries[field]; ok {
+		c.mutex.Unlock()
+		return nil
+	}
+	if build, ok := c.building[field]; ok {
+		c.mutex.Unlock()
+		<-build.done
+		return nil
+	}
+	if c.building == nil {
+		c.building = make(map[string]*completionBuild)
+	}
+	build := &completionBuild{done: make(chan struct{})}
+	c.building[field] = build
+	c.mutex.Unlock()
+
+	trie, err := buildCompletionTrie(i, field)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.building, field)
+	close(build.done)
+	if err != nil {
+		return err
+	}
+	err = refreshCompletions(i, map[string]*completion.Trie{field: trie}, build.ids)
+	if err != nil {
+		return err
+	}
+	if c.tries == nil {
+		c.tries = make(map[string]*completion.Trie)
+	}
+	c.tries[field] = trie
+	return nil
+}
+
+// completionEntries returns the completion entries of doc by field.
+func completionEntries(id string, doc *document.Document) (map[string][]*completion.Entry, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	var rv map[string][]*completion.Entry
+	for _, f := range doc.Fields {
+		cf, ok := f.(*document.CompletionField)
+		if !ok {
+			continue
+		}
+		ce, err := cf.Entry()
+		if err != nil {
+			return nil, err
+		}
+		if rv == nil {
+			rv = make(map[string][]*completion.Entry)
+		}
+		rv[cf.Name()] = append(rv[cf.Name()], &completion.Entry{
+			ID:       id,
+			Inputs:   ce.Inputs,
+			Keys:     ce.Keys,
+			Weight:   ce.Weight,
+			Payload:  ce.Payload,
+			Contexts: ce.Contexts,
+		})
+	}
+	return rv, nil
+}
+
+// buildCompletionTrie collects the completion entries of field
+// from the stored fields of the documents indexing its keys.
+func buildCompletionTrie(i index.Index, field string) (rv *completion.Trie, err error) {
+	r, err := i.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := r.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	dict, err := r.FieldDict(field)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]struct{})
+	entry, err := dict.Next()
+	for err == nil && entry != nil {
+		var reader index.TermFieldReader
+		reader, err = r.TermFieldReader([]byte(entry.Term), field)
+		if err != nil {
+			break
+		}
+		tfd, nerr := reader.Next()
+		for nerr == nil && tfd != nil {
+			ids[string(tfd.ID)] = struct{}{}
+			tfd, nerr = reader.Next()
+		}
+		err = reader.Close()
+		if nerr != nil {
+			err = nerr
+		}
+		if err != nil {
+			break
+		}
+		entry, err = dict.Next()
+	}
+	if cerr := dict.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rv = completion.New()
+	for id := range ids {
+		doc, err := r.Document(id)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := completionEntries(id, doc)
+		if err != nil {
+			return nil, err
+		}
+		rv.Set(id, entries[field])
+	}
+	return rv, nil
+}
+
+// Complete returns the completions of the prefix
+// of req indexed in its completion field.
+func (i *indexImpl) Complete(req *CompletionRequest) (*CompletionResult, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if !i.open {
+		return nil, ErrorIndexClosed
+	}
+
+	prefix := completionPrefix(i.m, req.Field, req.Prefix)
+	completions, err := i.completions.lookup(i.i, req.Field, prefix, req.Fuzziness, req.size(), req.Contexts)
+	if err != nil {
+		return nil, err
+	}
+	rv := &CompletionResult{
+		Request:     req,
+		Completions: make([]*Completion, len(completions)),
+	}
+	for j, c := range completions {
+		rv.Completions[j] = &Completion{
+			ID:       c.Entry.ID,
+			Text:     c.Input,
+			Weight:   c.Entry.Weight,
+			Distance: c.Distance,
+			Payload:  c.Entry.Payload,
+			Contexts: c.Entry.Contexts,
+		}
+	}
+	return rv, nil
+}
@@ -0,0 +1,296 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func completionMapping() *IndexMapping {
+	mapping := NewIndexMapping()
+	mapping.DefaultMapping.AddFieldMappingsAt("suggest", NewCompletionFieldMapping())
+	return mapping
+}
+
+func completionTexts(res *CompletionResult) []string {
+	rv := []string{}
+	for _, c := range res.Completions {
+		rv = append(rv, c.Text)
+	}
+	return rv
+}
+
+func TestIndexComplete(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", completionMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := map[string]interface{}{
+		"nirvana": map[string]interface{}{
+			"suggest": map[string]interface{}{
+				"input":    []string{"Nirvana", "Nevermind"},
+				"weight":   34,
+				"payload":  map[string]interface{}{"label": "DGC"},
+				"contexts": map[string]interface{}{"genre": "grunge"},
+			},
+		},
+		"nin": map[string]interface{}{
+			"suggest": map[string]interface{}{
+				"input":    "Nine Inch Nails",
+				"weight":   12,
+				"contexts": map[string]interface{}{"genre": []string{"industrial", "rock"}},
+			},
+		},
+		"nico": map[string]interface{}{
+			"suggest": "Nico",
+		},
+	}
+	for id, doc := range docs {
+		err = index.Index(id, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		req   *CompletionRequest
+		texts []string
+	}{
+		{
+			req:   NewCompletionRequest("ni", "suggest"),
+			texts: []string{"Nirvana", "Nine Inch Nails", "Nico"},
+		},
+		{
+			req:   NewCompletionRequest("NINE i", "suggest"),
+			texts: []string{"Nine Inch Nails"},
+		},
+		{
+			req:   NewCompletionRequest("nine ", "suggest"),
+			texts: []string{"Nine Inch Nails"},
+		},
+		{
+			req:   NewCompletionRequest("nin ", "suggest"),
+			texts: []string{},
+		},
+		{
+			// a document is completed once, by its best input
+			req:   &CompletionRequest{Prefix: "n", Field: "suggest", Size: 2},
+			texts: []string{"Nevermind", "Nine Inch Nails"},
+		},
+		{
+			// exact matches rank before fuzzy ones
+			req:   &CompletionRequest{Prefix: "nin", Field: "suggest", Fuzziness: 1},
+			texts: []string{"Nine Inch Nails", "Nirvana", "Nico"},
+		},
+		{
+			req:   &CompletionRequest{Prefix: "n", Field: "suggest", Contexts: map[string][]string{"genre": {"rock", "pop"}}},
+			texts: []string{"Nine Inch Nails"},
+		},
+		{
+			req:   NewCompletionRequest("x", "suggest"),
+			texts: []string{},
+		},
+	}
+
+	for i, test := range tests {
+		res, err := index.Complete(test.req)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(completionTexts(res), test.texts) {
+			t.Errorf("test %d: expected completions %v, got %v", i, test.texts, completionTexts(res))
+		}
+	}
+
+	res, err := index.Complete(NewCompletionRequest("nir", "suggest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := res.Completions[0]
+	if c.ID != "nirvana" || c.Weight != 34 || string(c.Payload) != `{"label":"DGC"}` ||
+		!reflect.DeepEqual(c.Contexts, map[string][]string{"genre": {"grunge"}}) {
+		t.Errorf("unexpected completion %#v", c)
+	}
+
+	// updates are visible to the next completions
+	err = index.Delete("nirvana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = index.Index("nick", map[string]interface{}{"suggest": "Nick Cave"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = index.Complete(NewCompletionRequest("ni", "suggest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Nine Inch Nails", "Nick Cave", "Nico"}
+	if !reflect.DeepEqual(completionTexts(res), expected) {
+		t.Errorf("expected completions %v, got %v", expected, completionTexts(res))
+	}
+
+	_, err = index.Complete(NewCompletionRequest("ni", ""))
+	if err == nil {
+		t.Errorf("expected error for missing completion field")
+	}
+
+	// the tries are rebuilt from the documents after reopening
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err = Open("testidx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := index.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	res, err = index.Complete(NewCompletionRequest("ni", "suggest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(completionTexts(res), expected) {
+		t.Errorf("expected completions %v after reopening, got %v", expected, completionTexts(res))
+	}
+
+	// and kept current by batches
+	batch := index.NewBatch()
+	err = batch.Index("nico", map[string]interface{}{"suggest": "Nico and the Velvet Underground"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch.Delete("nin")
+	err = index.Batch(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = index.Complete(NewCompletionRequest("ni", "suggest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"Nick Cave", "Nico and the Velvet Underground"}
+	if !reflect.DeepEqual(completionTexts(res), expected) {
+		t.Errorf("expected completions %v, got %v", expected, completionTexts(res))
+	}
+}
+
+func TestIndexCompleteLateUpdate(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", completionMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := index.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	err = index.Index("nick", map[string]interface{}{"suggest": "Nick Drake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = index.Complete(NewCompletionRequest("ni", "suggest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = index.Index("nick", map[string]interface{}{"suggest": "Nick Cave"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the update of an earlier write of the document, arriving
+	// last, keeps the entries of the last write
+	impl := index.(*indexImpl)
+	err = impl.completions.update(impl.i, "nick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := index.Complete(NewCompletionRequest("ni", "suggest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Nick Cave"}
+	if !reflect.DeepEqual(completionTexts(res), expected) {
+		t.Errorf("expected completions %v, got %v", expected, completionTexts(res))
+	}
+}
+
+func TestIndexAliasComplete(t *testing.T) {
+	defer func() {
+		for _, path := range []string{"testidx1", "testidx2"} {
+			err := os.RemoveAll(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}()
+
+	docs := []map[string]interface{}{
+		{
+			"a": map[string]interface{}{"suggest": map[string]interface{}{"input": "Blur", "weight": 5}},
+			"b": map[string]interface{}{"suggest": map[string]interface{}{"input": "Bloc Party", "weight": 1}},
+		},
+		{
+			"c": map[string]interface{}{"suggest": map[string]interface{}{"input": "Blondie", "weight": 3}},
+		},
+	}
+	alias := NewIndexAlias()
+	for i, paths := range []string{"testidx1", "testidx2"} {
+		index, err := New(paths, completionMapping())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			err := index.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}()
+		for id, doc := range docs[i] {
+			err = index.Index(id, doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		alias.Add(index)
+	}
+
+	res, err := alias.Complete(&CompletionRequest{Prefix: "bl", Field: "suggest", Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Blur", "Blondie"}
+	if !reflect.DeepEqual(completionTexts(res), expected) {
+		t.Errorf("expected completions %v, got %v", expected, completionTexts(res))
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/blevesearch/bleve/analysis"
 	"github.com/blevesearch/bleve/document"
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/index/store"
@@ -26,6 +27,7 @@ import (
 	"github.com/blevesearch/bleve/index/upside_down"
 	"github.com/blevesearch/bleve/registry"
 	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/aggregations"
 	"github.com/blevesearch/bleve/search/collectors"
 	"github.com/blevesearch/bleve/search/facets"
 	"github.com/blevesearch/bleve/search/highlight"
@@ -40,6 +42,8 @@ type indexImpl struct {
 	mutex sync.RWMutex
 	open  bool
 	stats *IndexStat
+
+	completions completionCache
 }
 
 const storePath = "store"
@@ -261,9 +265,50 @@ func (i *indexImpl) Advanced() (index.Index, store.KVStore, error) {
 // Mapping returns the IndexMapping in use by this
 // Index.
 func (i *indexImpl) Mapping() *IndexMapping {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
 	return i.m
 }
 
+// UpdateMapping replaces the IndexMapping in use by
+// this Index, if the new one only adds to it.
+func (i *indexImpl) UpdateMapping(mapping *IndexMapping) error {
+	err := mapping.Validate()
+	if err != nil {
+		return err
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if !i.open {
+		return ErrorIndexClosed
+	}
+
+	err = i.m.checkUpdate(mapping)
+	if err != nil {
+		return err
+	}
+
+	// use the mapping as it will be loaded when the index is
+	// opened again, rather than the caller's instance
+	mappingBytes, err := json.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+	var im IndexMapping
+	err = json.Unmarshal(mappingBytes, &im)
+	if err != nil {
+		return err
+	}
+	err = i.i.SetInternal(mappingInternalKey, mappingBytes)
+	if err != nil {
+		return err
+	}
+	i.m = &im
+	return nil
+}
+
 // Index the object with the specified identifier.
 // The IndexMapping for this index will determine
 // how the object is indexed.
@@ -285,6 +330,10 @@ func (i *indexImpl) Index(id string, data interface{}) (err error) {
 		return
 	}
 	err = i.i.Update(doc)
+	if err != nil {
+		return
+	}
+	err = i.completions.update(i.i, id)
 	return
 }
 
@@ -303,6 +352,10 @@ func (i *indexImpl) Delete(id string) (err error) {
 	}
 
 	err = i.i.Delete(id)
+	if err != nil {
+		return
+	}
+	err = i.completions.update(i.i, id)
 	return
 }
 
@@ -318,7 +371,15 @@ func (i *indexImpl) Batch(b *Batch) error {
 		return ErrorIndexClosed
 	}
 
-	return i.i.Batch(b.internal)
+	err := i.i.Batch(b.internal)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(b.internal.IndexOps))
+	for id := range b.internal.IndexOps {
+		ids = append(ids, id)
+	}
+	return i.completions.update(i.i, ids...)
 }
 
 // Document is used to find the values of all the
@@ -380,7 +441,12 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 		return nil, ErrorIndexClosed
 	}
 
-	collector := collectors.NewTopScorerSkipCollector(req.Size, req.From)
+	if req.Highlight != nil {
+		err = req.Highlight.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// open a reader for this search
 	indexReader, err := i.i.Reader()
@@ -393,6 +459,20 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 		}
 	}()
 
+	var collector *collectors.TopNCollector
+	if req.SearchAfter != nil {
+		if req.From != 0 {
+			return nil, fmt.Errorf("cannot use search after with from != 0")
+		}
+		after, err := req.sortOrder().DocumentMatchAfter(req.SearchAfter)
+		if err != nil {
+			return nil, err
+		}
+		collector = collectors.NewTopNCollectorAfter(req.Size, req.sortOrder(), after, indexReader)
+	} else {
+		collector = collectors.NewTopNCollector(req.Size, req.From, req.sortOrder(), indexReader)
+	}
+
 	searcher, err := req.Query.Searcher(indexReader, i.m, req.Explain)
 	if err != nil {
 		return nil, err
@@ -406,7 +486,21 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 	if req.Facets != nil {
 		facetsBuilder := search.NewFacetsBuilder(indexReader)
 		for facetName, facetRequest := range req.Facets {
-			if facetRequest.NumericRanges != nil {
+			if facetRequest.NumericInterval > 0 {
+				// build numeric histogram facet
+				facetBuilder := facets.NewNumericFacetBuilder(facetRequest.Field, facetRequest.Size)
+				facetBuilder.SetHistogram(facetRequest.NumericInterval)
+				facetsBuilder.Add(facetName, facetBuilder)
+			} else if facetRequest.DateTimeInterval != "" {
+				// build date histogram facet
+				interval, location, err := facetRequest.dateHistogram()
+				if err != nil {
+					return nil, err
+				}
+				facetBuilder := facets.NewDateTimeFacetBuilder(facetRequest.Field, facetRequest.Size)
+				facetBuilder.SetHistogram(interval, location)
+				facetsBuilder.Add(facetName, facetBuilder)
+			} else if facetRequest.NumericRanges != nil {
 				// build numeric range facet
 				facetBuilder := facets.NewNumericFacetBuilder(facetRequest.Field, facetRequest.Size)
 				for _, nr := range facetRequest.NumericRanges {
@@ -431,6 +525,15 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 		collector.SetFacetsBuilder(facetsBuilder)
 	}
 
+	if req.Aggregations != nil {
+		dateTimeParser := i.m.dateTimeParserNamed(i.m.DefaultDateTimeParser)
+		aggregationsBuilder := search.NewAggregationsBuilder(indexReader)
+		for aggregationName, aggregationRequest := range req.Aggregations {
+			aggregationsBuilder.Add(aggregationName, newAggregationBuilder(aggregationRequest, dateTimeParser))
+		}
+		collector.SetAggregationsBuilder(aggregationsBuilder)
+	}
+
 	err = collector.Collect(ctx, searcher)
 	if err != nil {
 		return nil, err
@@ -484,6 +587,19 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 									if err == nil {
 										value = boolean
 									}
+								case *document.GeoPointField:
+									lon, err := docF.Lon()
+									if err == nil {
+										lat, err := docF.Lat()
+										if err == nil {
+											value = []float64{lon, lat}
+										}
+									}
+								case *document.CompletionField:
+									entry, err := docF.Entry()
+									if err == nil {
+										value = entry.Inputs
+									}
 								}
 								if value != nil {
 									hit.AddFieldValue(docF.Name(), value)
@@ -501,8 +617,18 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 							highlightFields = append(highlightFields, k)
 						}
 					}
+					options := req.Highlight.fragmentOptions()
+					optionsHighlighter, hasOptions := highlighter.(highlight.OptionsHighlighter)
+					count := options.Count
+					if count <= 0 {
+						count = 1
+					}
 					for _, hf := range highlightFields {
-						highlighter.BestFragmentsInField(hit, doc, hf, 1)
+						if hasOptions {
+							optionsHighlighter.BestFragmentsInFieldWithOptions(hit, doc, hf, options)
+						} else {
+							highlighter.BestFragmentsInField(hit, doc, hf, count)
+						}
 					}
 				}
 			} else if doc == nil {
@@ -516,6 +642,16 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 		}
 	}
 
+	facetResults := collector.FacetResults()
+	for name, fr := range req.Facets {
+		if fr.isHistogram() && !fr.partial {
+			err = fr.fixupHistogram(facetResults[name])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	atomic.AddUint64(&i.stats.searches, 1)
 	searchDuration := time.Since(searchStart)
 	atomic.AddUint64(&i.stats.searchTime, uint64(searchDuration))
@@ -536,10 +672,83 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 		Total:    collector.Total(),
 		MaxScore: collector.MaxScore(),
 		Took:     searchDuration,
-		Facets:   collector.FacetResults(),
+		Facets:   facetResults,
+
+		Aggregations: collector.AggregationResults(),
 	}, nil
 }
 
+// newAggregationBuilder builds the aggregation described by the
+// request, bucket aggregations build fresh sub-aggregations for each
+// of their buckets.
+func newAggregationBuilder(ar *AggregationRequest, dateTimeParser analysis.DateTimeParser) search.AggregationBuilder {
+	var newSubs aggregations.SubAggregations
+	if len(ar.Aggregations) > 0 {
+		newSubs = func() map[string]search.AggregationBuilder {
+			rv := make(map[string]search.AggregationBuilder, len(ar.Aggregations))
+			for name, sub := range ar.Aggregations {
+				rv[name] = newAggregationBuilder(sub, dateTimeParser)
+			}
+			return rv
+		}
+	}
+	switch ar.Type {
+	case "numeric_range":
+		aggregationBuilder := aggregations.NewNumericRangeAggregationBuilder(ar.Field, newSubs)
+		for _, nr := range ar.NumericRanges {
+			aggregationBuilder.AddRange(nr.Name, nr.Min, nr.Max)
+		}
+		return aggregationBuilder
+	case "date_range":
+		aggregationBuilder := aggregations.NewDateTimeRangeAggregationBuilder(ar.Field, newSubs)
+		for _, dr := range ar.DateTimeRanges {
+			dr.ParseDates(dateTimeParser)
+			aggregationBuilder.AddRange(dr.Name, dr.Start, dr.End)
+		}
+		return aggregationBuilder
+	case "cardinality":
+		return aggregations.NewCardinalityAggregationBuilder(ar.Field)
+	case "min", "max", "sum", "avg", "stats":
+		return aggregations.NewMetricAggregationBuilder(ar.Type, ar.Field)
+	}
+	return aggregations.NewTermsAggregationBuilder(ar.Field, ar.size(), newSubs)
+}
+
+// Suggest returns spelling corrections for the terms
+// of the text of req, found in the index.
+func (i *indexImpl) Suggest(req *SuggestRequest) (*SuggestResult, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	terms, err := i.termSuggestions(req)
+	if err != nil {
+		return nil, err
+	}
+	return newSuggestResult(req, terms), nil
+}
+
+func (i *indexImpl) termSuggestions(req *SuggestRequest) (terms []*TermSuggestion, err error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if !i.open {
+		return nil, ErrorIndexClosed
+	}
+
+	indexReader, err := i.i.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := indexReader.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	return suggestTerms(indexReader, i.m, req)
+}
+
 // Fields returns the name of all the fields this
 // Index has operated on.
 func (i *indexImpl) Fields() (fields []string, err error) {
@@ -86,6 +86,27 @@ func (i *indexMeta) Save(path string) (err error) {
 	return nil
 }
 
+// WriteTemp writes the metadata of the index at path to a
+// temporary file, which commitIndexMetaTemp renames over the
+// existing one.
+func (i *indexMeta) WriteTemp(path string) error {
+	metaBytes, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(indexMetaTempPath(path), metaBytes, 0666)
+}
+
+// commitIndexMetaTemp replaces the metadata of the index at
+// path with the one written by WriteTemp.
+func commitIndexMetaTemp(path string) error {
+	return os.Rename(indexMetaTempPath(path), indexMetaPath(path))
+}
+
+func indexMetaTempPath(path string) string {
+	return indexMetaPath(path) + ".tmp"
+}
+
 func indexMetaPath(path string) string {
 	return path + string(os.PathSeparator) + metaFilename
 }
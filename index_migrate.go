@@ -0,0 +1,369 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/store"
+	"github.com/blevesearch/bleve/registry"
+)
+
+const (
+	// rows are copied between stores in batches holding
+	// at most this many rows or bytes
+	copyBatchRows  = 1000
+	copyBatchBytes = 16 << 20
+
+	migrateSuffix = ".migrate"
+	oldSuffix     = ".old"
+)
+
+// Migrate copies every row of the index at the specified
+// path, which must not be open, into a new store of the
+// specified kvstore implementation, passing the provided
+// kvconfig to its constructor. Once the row and document
+// counts of both stores are verified to match, the new
+// store replaces the old one and the index metadata is
+// rewritten. If kvstore is empty the current store
+// implementation and configuration are used, which
+// compacts the index.
+//
+// The new metadata is written to a temporary file before
+// the stores are swapped, so that the swap only consists of
+// renaming the old store aside, the new store in its place
+// and the temporary metadata file over the existing one.
+// If a migration is interrupted during the swap, the index
+// may not open until Migrate is called again on its path,
+// which first completes the interrupted migration if the
+// new store is in place, or restores the old store.
+func Migrate(path string, kvstore string, kvconfig map[string]interface{}) (err error) {
+	err = recoverMigration(path)
+	if err != nil {
+		return err
+	}
+	meta, err := openIndexMeta(path)
+	if err != nil {
+		return err
+	}
+	if kvstore == "" {
+		kvstore = meta.Storage
+		kvconfig = meta.Config
+	}
+	if kvconfig == nil {
+		kvconfig = map[string]interface{}{}
+	}
+
+	src, err := openIndexStore(meta.IndexType, meta.Storage, meta.Config, indexStorePath(path), false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if src == nil {
+			return
+		}
+		if cerr := src.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	migratePath := indexStorePath(path) + migrateSuffix
+	// a previous migration may have been interrupted
+	err = os.RemoveAll(migratePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(migratePath)
+		}
+	}()
+
+	dst, err := openIndexStore(meta.IndexType, kvstore, kvconfig, migratePath, true)
+	if err != nil {
+		return err
+	}
+	rows, err := copyIndexRows(src, dst)
+	if cerr := dst.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	docCount, err := src.DocCount()
+	if err != nil {
+		return err
+	}
+
+	// reopen the new store to verify what was persisted
+	dst, err = openIndexStore(meta.IndexType, kvstore, kvconfig, migratePath, false)
+	if err != nil {
+		return err
+	}
+	err = verifyIndexCounts(dst, rows, docCount)
+	if cerr := dst.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = src.Close()
+	src = nil
+	if err != nil {
+		return err
+	}
+
+	err = newIndexMeta(meta.IndexType, kvstore, kvconfig).WriteTemp(path)
+	if err != nil {
+		_ = os.Remove(indexMetaTempPath(path))
+		return err
+	}
+	storePath := indexStorePath(path)
+	oldPath := storePath + oldSuffix
+	err = os.Rename(storePath, oldPath)
+	if err != nil {
+		_ = os.Remove(indexMetaTempPath(path))
+		return err
+	}
+	err = os.Rename(migratePath, storePath)
+	if err != nil {
+		return restoreStore(path, err)
+	}
+	err = commitIndexMetaTemp(path)
+	if err != nil {
+		if rerr := os.Rename(storePath, migratePath); rerr != nil {
+			return fmt.Errorf("error replacing index metadata: %v, migrate again to complete: %v", err, rerr)
+		}
+		return restoreStore(path, err)
+	}
+	return os.RemoveAll(oldPath)
+}
+
+// restoreStore moves the old store of the index at path back
+// in place after the swap of a migration failed with err.
+func restoreStore(path string, err error) error {
+	oldPath := indexStorePath(path) + oldSuffix
+	if rerr := os.Rename(oldPath, indexStorePath(path)); rerr != nil {
+		return fmt.Errorf("error replacing store: %v, old store left at %s, migrate again to restore it: %v", err, oldPath, rerr)
+	}
+	_ = os.Remove(indexMetaTempPath(path))
+	return err
+}
+
+// recoverMigration completes or rolls back a migration of
+// the index at path interrupted while swapping its stores.
+// The old store is renamed aside only once the new metadata
+// has been written, so if the old store is found aside
+// either the new store is in place and the metadata is
+// committed, or the old store is moved back.
+func recoverMigration(path string) error {
+	storePath := indexStorePath(path)
+	oldPath := storePath + oldSuffix
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		// not interrupted during the swap, the new metadata
+		// may not be complete
+		err = os.Remove(indexMetaTempPath(path))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if _, err := os.Stat(storePath); os.IsNotExist(err) {
+		_ = os.Remove(indexMetaTempPath(path))
+		return os.Rename(oldPath, storePath)
+	}
+	if _, err := os.Stat(indexMetaTempPath(path)); err == nil {
+		err = commitIndexMetaTemp(path)
+		if err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(oldPath)
+}
+
+// openIndexStore opens an index of the specified type
+// using the kvstore stored at storePath.
+func openIndexStore(indexType, kvstore string, kvconfig map[string]interface{}, storePath string, create bool) (index.Index, error) {
+	indexTypeConstructor := registry.IndexTypeConstructorByName(indexType)
+	if indexTypeConstructor == nil {
+		return nil, ErrorUnknownIndexType
+	}
+
+	storeConfig := map[string]interface{}{}
+	for k, v := range kvconfig {
+		storeConfig[k] = v
+	}
+	storeConfig["create_if_missing"] = create
+	storeConfig["error_if_exists"] = create
+	storeConfig["path"] = storePath
+
+	rv, err := indexTypeConstructor(kvstore, storeConfig, Config.analysisQueue)
+	if err != nil {
+		return nil, err
+	}
+	err = rv.Open()
+	if err != nil {
+		if err == index.ErrorUnknownStorageType {
+			return nil, ErrorUnknownStorageType
+		}
+		return nil, err
+	}
+	return rv, nil
+}
+
+// copyIndexRows copies every row of the store of src
+// into the store of dst, returning the number of rows.
+func copyIndexRows(src, dst index.Index) (rows uint64, err error) {
+	srcStore, err := src.Advanced()
+	if err != nil {
+		return 0, err
+	}
+	kvreader, err := srcStore.Reader()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := kvreader.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	w, err := newRowWriter(dst)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := w.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	it := kvreader.PrefixIterator([]byte{})
+	defer func() {
+		if cerr := it.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+	key, val, valid := it.Current()
+	for valid {
+		err = w.Set(key, val)
+		if err != nil {
+			return 0, err
+		}
+		it.Next()
+		key, val, valid = it.Current()
+	}
+	return w.rows, w.Flush()
+}
+
+// verifyIndexCounts checks that the index holds the
+// expected number of rows and documents.
+func verifyIndexCounts(i index.Index, rows, docCount uint64) (err error) {
+	kvstore, err := i.Advanced()
+	if err != nil {
+		return err
+	}
+	kvreader, err := kvstore.Reader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := kvreader.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+
+	var count uint64
+	it := kvreader.PrefixIterator([]byte{})
+	for _, _, valid := it.Current(); valid; _, _, valid = it.Current() {
+		count++
+		it.Next()
+	}
+	err = it.Close()
+	if err != nil {
+		return err
+	}
+	if count != rows {
+		return fmt.Errorf("migrated store has %d rows, expected %d", count, rows)
+	}
+
+	n, err := i.DocCount()
+	if err != nil {
+		return err
+	}
+	if n != docCount {
+		return fmt.Errorf("migrated index has %d documents, expected %d", n, docCount)
+	}
+	return nil
+}
+
+// rowWriter writes rows to the store of an index in
+// batches of bounded size.
+type rowWriter struct {
+	w       store.KVWriter
+	batch   store.KVBatch
+	rows    uint64
+	pending int
+	bytes   int
+}
+
+func newRowWriter(i index.Index) (*rowWriter, error) {
+	kvstore, err := i.Advanced()
+	if err != nil {
+		return nil, err
+	}
+	w, err := kvstore.Writer()
+	if err != nil {
+		return nil, err
+	}
+	return &rowWriter{
+		w:     w,
+		batch: w.NewBatch(),
+	}, nil
+}
+
+func (w *rowWriter) Set(key, val []byte) error {
+	w.batch.Set(key, val)
+	w.rows++
+	w.pending++
+	w.bytes += len(key) + len(val)
+	if w.pending >= copyBatchRows || w.bytes >= copyBatchBytes {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush executes the pending batch.
+func (w *rowWriter) Flush() error {
+	if w.pending == 0 {
+		return nil
+	}
+	err := w.w.ExecuteBatch(w.batch)
+	if err != nil {
+		return err
+	}
+	w.batch.Reset()
+	w.pending = 0
+	w.bytes = 0
+	return nil
+}
+
+// Close releases the batch and writer, discarding rows
+// which were not flushed.
+func (w *rowWriter) Close() error {
+	err := w.batch.Close()
+	if cerr := w.w.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	return err
+}
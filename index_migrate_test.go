@@ -0,0 +1,148 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/blevesearch/bleve/index/store/boltdb"
+	"github.com/blevesearch/bleve/index/store/goleveldb"
+)
+
+func TestMigrate(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := NewUsing("testidx", NewIndexMapping(), Config.DefaultIndexType, boltdb.Name, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// enough documents to need several batches
+	batch := index.NewBatch()
+	for i := 0; i < 500; i++ {
+		err = batch.Index(fmt.Sprintf("doc%d", i), map[string]interface{}{
+			"name": fmt.Sprintf("name %d", i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = index.Batch(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(storage string) {
+		meta, err := openIndexMeta("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta.Storage != storage {
+			t.Errorf("expected storage %s, got %s", storage, meta.Storage)
+		}
+		for _, suffix := range []string{migrateSuffix, oldSuffix} {
+			if _, err := os.Stat(indexStorePath("testidx") + suffix); !os.IsNotExist(err) {
+				t.Errorf("expected %s store to be removed", suffix)
+			}
+		}
+
+		index, err := Open("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			err := index.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}()
+		count, err := index.DocCount()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 500 {
+			t.Errorf("expected 500 documents, got %d", count)
+		}
+		res, err := index.Search(NewSearchRequest(NewMatchQuery("42").SetField("name")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Total != 1 || res.Hits[0].ID != "doc42" {
+			t.Errorf("expected doc42 to match, got %v", res.Hits)
+		}
+	}
+
+	err = Migrate("testidx", goleveldb.Name, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(goleveldb.Name)
+
+	// compact keeping the current store
+	err = Migrate("testidx", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(goleveldb.Name)
+
+	err = Migrate("testidx", "unknown", nil)
+	if err != ErrorUnknownStorageType {
+		t.Errorf("expected %v, got %v", ErrorUnknownStorageType, err)
+	}
+	check(goleveldb.Name)
+
+	// interrupted after the old store was renamed aside
+	err = os.Rename(indexStorePath("testidx"), indexStorePath("testidx")+oldSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = newIndexMeta(Config.DefaultIndexType, boltdb.Name, nil).WriteTemp("testidx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Migrate("testidx", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(goleveldb.Name)
+
+	// interrupted after the new store was renamed in place
+	err = os.Mkdir(indexStorePath("testidx")+oldSuffix, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = newIndexMeta(Config.DefaultIndexType, goleveldb.Name, map[string]interface{}{}).WriteTemp("testidx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = recoverMigration("testidx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(indexMetaTempPath("testidx")); !os.IsNotExist(err) {
+		t.Errorf("expected the new index metadata to be committed")
+	}
+	check(goleveldb.Name)
+
+	err = Migrate("testidx-missing", boltdb.Name, nil)
+	if err != ErrorIndexPathDoesNotExist {
+		t.Errorf("expected %v, got %v", ErrorIndexPathDoesNotExist, err)
+	}
+}
@@ -10,9 +10,11 @@
 package bleve
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
 	"reflect"
 	"sort"
@@ -25,10 +27,14 @@ import (
 
 	"strconv"
 
+	"github.com/blevesearch/bleve/analysis/analyzers/custom_analyzer"
 	"github.com/blevesearch/bleve/analysis/analyzers/keyword_analyzer"
+	"github.com/blevesearch/bleve/analysis/synonym_map"
+	"github.com/blevesearch/bleve/analysis/token_filters/synonym_filter"
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/index/store/null"
 	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/similarities/bm25"
 )
 
 func TestCrud(t *testing.T) {
@@ -1564,3 +1570,1172 @@ func BenchmarkBatchOverhead(b *testing.B) {
 		batch.Reset()
 	}
 }
+
+func TestSortMatchSearch(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"Noam", "Uri", "David", "Yosef", "Eitan", "Itay", "Ariel", "Daniel", "Omer", "Yogev", "Yehonatan", "Moshe", "Mohammed", "Yusuf", "Omar"}
+	days := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	numbers := []string{"One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten", "Eleven", "Twelve"}
+	b := index.NewBatch()
+	for i := 0; i < 200; i++ {
+		doc := make(map[string]interface{})
+		doc["Name"] = names[i%len(names)]
+		doc["Day"] = days[i%len(days)]
+		doc["Number"] = numbers[i%len(numbers)]
+		doc["Age"] = float64(i % 37)
+		err = b.Index(fmt.Sprintf("%d", i), doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = index.Batch(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := NewSearchRequest(NewMatchAllQuery())
+	req.SortBy([]string{"Day", "-Age", "_id"})
+	req.Size = 200
+	req.Fields = []string{"Day", "Age"}
+	sr, err := index.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sr.Hits) != 200 {
+		t.Fatalf("expected 200 hits, got %d", len(sr.Hits))
+	}
+	prev := sr.Hits[0]
+	for _, hit := range sr.Hits[1:] {
+		prevDay := prev.Fields["Day"].(string)
+		day := hit.Fields["Day"].(string)
+		if prevDay > day {
+			t.Errorf("hits not ordered by day: %s before %s", prevDay, day)
+		} else if prevDay == day {
+			prevAge := prev.Fields["Age"].(float64)
+			age := hit.Fields["Age"].(float64)
+			if prevAge < age {
+				t.Errorf("hits not ordered by descending age: %f before %f", prevAge, age)
+			} else if prevAge == age && prev.ID > hit.ID {
+				t.Errorf("hits not ordered by id: %s before %s", prev.ID, hit.ID)
+			}
+		}
+		prev = hit
+	}
+
+	// a missing field sorts last in both directions
+	err = index.Index("nameless", map[string]interface{}{"Age": 1.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sortField := range []string{"Name", "-Name"} {
+		req = NewSearchRequest(NewMatchAllQuery())
+		req.SortBy([]string{sortField})
+		req.Size = 201
+		sr, err = index.Search(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sr.Hits[len(sr.Hits)-1].ID != "nameless" {
+			t.Errorf("expected doc missing the field to sort last for %s, got %s", sortField, sr.Hits[len(sr.Hits)-1].ID)
+		}
+	}
+
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchAfterPaging(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := index.NewBatch()
+	for i := 0; i < 50; i++ {
+		err = b.Index(fmt.Sprintf("%02d", i), map[string]interface{}{
+			"Rank": float64(i % 7),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = index.Batch(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reference order, fetched in a single page
+	req := NewSearchRequestOptions(NewMatchAllQuery(), 50, 0, false)
+	req.SortBy([]string{"-Rank", "_id"})
+	all, err := index.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paged []string
+	var after []string
+	for {
+		req = NewSearchRequestOptions(NewMatchAllQuery(), 8, 0, false)
+		req.SortBy([]string{"-Rank", "_id"})
+		req.SetSearchAfter(after)
+		err = req.Validate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sr, err := index.Search(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sr.Total != 50 {
+			t.Errorf("expected total of 50, got %d", sr.Total)
+		}
+		if len(sr.Hits) == 0 {
+			break
+		}
+		for _, hit := range sr.Hits {
+			paged = append(paged, hit.ID)
+		}
+		after = sr.Hits[len(sr.Hits)-1].Sort
+	}
+
+	if len(paged) != len(all.Hits) {
+		t.Fatalf("expected %d paged hits, got %d", len(all.Hits), len(paged))
+	}
+	for i, hit := range all.Hits {
+		if paged[i] != hit.ID {
+			t.Errorf("expected hit %d to be %s, got %s", i, hit.ID, paged[i])
+		}
+	}
+
+	// from cannot be combined with search after
+	req = NewSearchRequestOptions(NewMatchAllQuery(), 8, 8, false)
+	req.SortBy([]string{"-Rank", "_id"})
+	req.SetSearchAfter(all.Hits[0].Sort)
+	if req.Validate() == nil {
+		t.Errorf("expected validation error for search after with from")
+	}
+	_, err = index.Search(req)
+	if err == nil {
+		t.Errorf("expected error for search after with from")
+	}
+
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGeoSearch(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	cityMapping := NewDocumentMapping()
+	cityMapping.AddFieldMappingsAt("loc", NewGeoPointFieldMapping())
+	mapping := NewIndexMapping()
+	mapping.DefaultMapping = cityMapping
+
+	index, err := New("testidx", mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the supported point formats
+	cities := map[string]interface{}{
+		"paris":     map[string]interface{}{"lat": 48.8566, "lon": 2.3522},
+		"lyon":      []interface{}{4.8357, 45.7640},
+		"marseille": "43.2965,5.3698",
+		"brussels":  map[string]interface{}{"lat": 50.8503, "lng": 4.3517},
+		"tokyo":     []interface{}{139.6917, 35.6895},
+	}
+	for name, loc := range cities {
+		err = index.Index(name, map[string]interface{}{
+			"loc": loc,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// france, roughly
+	req := NewSearchRequest(NewGeoBoundingBoxQuery(-5, 50, 8, 42).SetField("loc"))
+	req.SortBy([]string{"_id"})
+	req.Fields = []string{"loc"}
+	res, err := index.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.ID)
+	}
+	expectedIDs := []string{"lyon", "marseille", "paris"}
+	if !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("expected %v, got %v", expectedIDs, ids)
+	}
+	if len(res.Hits) > 0 {
+		loc, ok := res.Hits[0].Fields["loc"].([]float64)
+		if !ok || len(loc) != 2 || math.Abs(loc[0]-4.8357) > 1e-6 || math.Abs(loc[1]-45.7640) > 1e-6 {
+			t.Errorf("expected stored location of lyon, got %v", res.Hits[0].Fields["loc"])
+		}
+	}
+
+	// within 400km of paris, closest first
+	query := NewGeoDistanceQuery(2.3522, 48.8566, "400km").SetField("loc")
+	req = NewSearchRequest(query)
+	sortGeo, err := search.NewSortGeoDistance("loc", "km", 2.3522, 48.8566, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.SortByCustom(search.SortOrder{sortGeo})
+	res, err = index.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids = nil
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.ID)
+	}
+	expectedIDs = []string{"paris", "brussels", "lyon"}
+	if !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("expected %v, got %v", expectedIDs, ids)
+	}
+
+	// all cities sorted by decreasing distance from paris
+	req = NewSearchRequest(NewMatchAllQuery())
+	sortGeo, err = search.NewSortGeoDistance("loc", "", 2.3522, 48.8566, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.SortByCustom(search.SortOrder{sortGeo})
+	res, err = index.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids = nil
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.ID)
+	}
+	expectedIDs = []string{"tokyo", "marseille", "lyon", "brussels", "paris"}
+	if !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("expected %v, got %v", expectedIDs, ids)
+	}
+
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDocValuesFacetsAndSort(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	categoryMapping := NewTextFieldMapping()
+	categoryMapping.Analyzer = keyword_analyzer.Name
+	categoryMapping.DocValues = true
+	priceMapping := NewNumericFieldMapping()
+	priceMapping.DocValues = true
+	productMapping := NewDocumentMapping()
+	productMapping.AddFieldMappingsAt("category", categoryMapping)
+	productMapping.AddFieldMappingsAt("price", priceMapping)
+	mapping := NewIndexMapping()
+	mapping.DefaultMapping = productMapping
+
+	index, err := New("testidx", mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	products := []map[string]interface{}{
+		{"category": "book", "price": 12.0, "name": "go"},
+		{"category": "book", "price": 35.0, "name": "rust"},
+		{"category": "music", "price": 8.0, "name": "jazz"},
+		{"category": "film", "price": 20.0, "name": "noir"},
+		{"name": "unknown"},
+	}
+	for i, product := range products {
+		err = index.Index(strconv.Itoa(i), product)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := NewSearchRequest(NewMatchAllQuery())
+	req.AddFacet("categories", NewFacetRequest("category", 10))
+	cheap := 15.0
+	priceFacet := NewFacetRequest("price", 10)
+	priceFacet.AddNumericRange("cheap", nil, &cheap)
+	priceFacet.AddNumericRange("expensive", &cheap, nil)
+	req.AddFacet("prices", priceFacet)
+	req.SortBy([]string{"-price"})
+	res, err := index.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.ID)
+	}
+	expectedIDs := []string{"1", "3", "0", "2", "4"}
+	if !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("expected %v, got %v", expectedIDs, ids)
+	}
+
+	categories := res.Facets["categories"]
+	if categories.Total != 4 || categories.Missing != 1 {
+		t.Errorf("expected 4 categories and 1 missing, got %d and %d", categories.Total, categories.Missing)
+	}
+	if len(categories.Terms) != 3 || categories.Terms[0].Term != "book" || categories.Terms[0].Count != 2 {
+		t.Errorf("expected book to be the top category with 2 products, got %v", categories.Terms)
+	}
+
+	prices := res.Facets["prices"]
+	counts := make(map[string]int)
+	for _, r := range prices.NumericRanges {
+		counts[r.Name] = r.Count
+	}
+	expectedCounts := map[string]int{"cheap": 2, "expensive": 2}
+	if !reflect.DeepEqual(counts, expectedCounts) {
+		t.Errorf("expected price ranges %v, got %v", expectedCounts, counts)
+	}
+
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAggregations(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	typeMapping := NewTextFieldMapping()
+	typeMapping.Analyzer = keyword_analyzer.Name
+	beerMapping := NewDocumentMapping()
+	beerMapping.AddFieldMappingsAt("type", typeMapping)
+	beerMapping.AddFieldMappingsAt("brewer", typeMapping)
+	mapping := NewIndexMapping()
+	mapping.DefaultMapping = beerMapping
+
+	index, err := New("testidx", mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []map[string]interface{}{
+		{"type": "ale", "brewer": "a", "abv": 5.0},
+		{"type": "ale", "brewer": "b", "abv": 7.0},
+		{"type": "stout", "brewer": "a", "abv": 9.0},
+		{"type": "ale", "brewer": "a", "abv": 4.5},
+		{"type": "lager", "brewer": "c"},
+	}
+	for i, doc := range docs {
+		err = index.Index(strconv.Itoa(i), doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var req *SearchRequest
+	err = json.Unmarshal([]byte(`{
+		"query": {"match_all": {}},
+		"size": 0,
+		"aggregations": {
+			"types": {
+				"type": "terms",
+				"field": "type",
+				"size": 2,
+				"aggregations": {
+					"abv": {"type": "stats", "field": "abv"},
+					"brewers": {"type": "cardinality", "field": "brewer"}
+				}
+			},
+			"strength": {
+				"type": "numeric_range",
+				"field": "abv",
+				"numeric_ranges": [{"name": "light", "max": 6}, {"name": "strong", "min": 6}],
+				"aggregations": {
+					"max_abv": {"type": "max", "field": "abv"}
+				}
+			}
+		}
+	}`), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = req.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := index.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := res.Aggregations["types"]
+	if types == nil || len(types.Buckets) != 2 || types.Other != 1 {
+		t.Fatalf("expected 2 type buckets and 1 other, got %#v", types)
+	}
+	ale := types.Buckets[0]
+	if ale.Key != "ale" || ale.Count != 3 {
+		t.Errorf("expected 3 ales, got %d %s", ale.Count, ale.Key)
+	}
+	expectedStats := &search.AggregationStats{Count: 3, Sum: 16.5, Min: 4.5, Max: 7, Avg: 5.5}
+	if !reflect.DeepEqual(ale.Aggregations["abv"].Stats, expectedStats) {
+		t.Errorf("expected ale stats %#v, got %#v", expectedStats, ale.Aggregations["abv"].Stats)
+	}
+	if *ale.Aggregations["brewers"].Value != 2 {
+		t.Errorf("expected 2 ale brewers, got %f", *ale.Aggregations["brewers"].Value)
+	}
+
+	strength := res.Aggregations["strength"]
+	if strength == nil || len(strength.Buckets) != 2 || strength.Missing != 1 {
+		t.Fatalf("expected 2 strength buckets and 1 missing, got %#v", strength)
+	}
+	if strength.Buckets[0].Count != 2 || *strength.Buckets[0].Aggregations["max_abv"].Value != 5 {
+		t.Errorf("unexpected light bucket %#v", strength.Buckets[0])
+	}
+	if strength.Buckets[1].Count != 2 || *strength.Buckets[1].Aggregations["max_abv"].Value != 9 {
+		t.Errorf("unexpected strong bucket %#v", strength.Buckets[1])
+	}
+
+	// invalid requests are rejected
+	req = NewSearchRequest(NewMatchAllQuery())
+	sum := NewAggregationRequest("sum", "abv")
+	sum.AddAggregation("nested", NewAggregationRequest("max", "abv"))
+	req.AddAggregation("sum", sum)
+	if req.Validate() == nil {
+		t.Errorf("expected metric aggregation with sub-aggregations to be invalid")
+	}
+
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHistogramFacets(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []map[string]interface{}{
+		{"published": "2016-01-10T10:00:00Z", "pages": 120.0},
+		{"published": "2016-01-31T23:30:00Z", "pages": 180.0},
+		{"published": "2016-03-02T08:00:00Z", "pages": 410.0},
+		{"pages": 99.0},
+	}
+	for i, doc := range docs {
+		err = index.Index(strconv.Itoa(i), doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var req *SearchRequest
+	err = json.Unmarshal([]byte(`{
+		"query": {"match_all": {}},
+		"size": 0,
+		"facets": {
+			"monthly": {"field": "published", "date_interval": "month", "time_zone": "Asia/Tokyo"}
+		}
+	}`), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddFacet("pages", NewNumericHistogramFacetRequest("pages", 100))
+	err = req.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := index.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// in Tokyo the second document is published in february
+	monthly := res.Facets["monthly"]
+	var months []string
+	var counts []int
+	for _, dr := range monthly.DateRanges {
+		months = append(months, dr.Name)
+		counts = append(counts, dr.Count)
+	}
+	expectedMonths := []string{"2016-01-01T00:00:00+09:00", "2016-02-01T00:00:00+09:00", "2016-03-01T00:00:00+09:00"}
+	if !reflect.DeepEqual(months, expectedMonths) {
+		t.Errorf("expected months %v, got %v", expectedMonths, months)
+	}
+	if !reflect.DeepEqual(counts, []int{1, 1, 1}) || monthly.Missing != 1 {
+		t.Errorf("unexpected monthly counts %v, missing %d", counts, monthly.Missing)
+	}
+
+	pages := res.Facets["pages"]
+	counts = nil
+	for _, nr := range pages.NumericRanges {
+		counts = append(counts, nr.Count)
+	}
+	if !reflect.DeepEqual(counts, []int{1, 2, 0, 0, 1}) || pages.NumericRanges[0].Name != "0" {
+		t.Errorf("unexpected pages buckets %v", counts)
+	}
+
+	// histograms of too many buckets fail
+	req = NewSearchRequest(NewMatchAllQuery())
+	req.AddFacet("pages", NewNumericHistogramFacetRequest("pages", 0.001))
+	_, err = index.Search(req)
+	if err == nil {
+		t.Errorf("expected error for a histogram of too many buckets")
+	}
+
+	// invalid histograms are rejected
+	invalid := []*FacetRequest{
+		NewDateHistogramFacetRequest("published", "fortnight"),
+		{Field: "published", DateTimeInterval: "1d", TimeZone: "Nowhere/Special"},
+		{Field: "pages", NumericInterval: 10, DateTimeInterval: "1d"},
+		{Field: "pages", TimeZone: "UTC"},
+	}
+	for _, fr := range invalid {
+		if fr.Validate() == nil {
+			t.Errorf("expected facet request %#v to be invalid", fr)
+		}
+	}
+
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSynonymPhraseSearch(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	mapping := NewIndexMapping()
+	err := mapping.AddCustomSynonymMap("media", map[string]interface{}{
+		"type":  synonym_map.Name,
+		"rules": []interface{}{"tv, television"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mapping.AddCustomTokenFilter("media_synonyms", map[string]interface{}{
+		"type":        synonym_filter.Name,
+		"synonym_map": "media",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mapping.AddCustomAnalyzer("synonyms", map[string]interface{}{
+		"type":          custom_analyzer.Name,
+		"tokenizer":     "unicode",
+		"token_filters": []interface{}{"to_lower", "media_synonyms"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapping.DefaultAnalyzer = "synonyms"
+
+	index, err := New("testidx", mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = index.Index("a", map[string]interface{}{"desc": "a Television show about cooking"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = index.Index("b", map[string]interface{}{"desc": "a TV set"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query Query
+		ids   []string
+	}{
+		{query: NewPhraseQuery([]string{"tv", "show"}, "desc"), ids: []string{"a"}},
+		{query: NewMatchPhraseQuery("television set").SetField("desc"), ids: []string{"b"}},
+		{query: NewMatchQuery("tv").SetField("desc"), ids: []string{"a", "b"}},
+	}
+	for _, test := range tests {
+		req := NewSearchRequest(test.query)
+		req.SortBy([]string{"_id"})
+		res, err := index.Search(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, hit := range res.Hits {
+			ids = append(ids, hit.ID)
+		}
+		if !reflect.DeepEqual(ids, test.ids) {
+			t.Errorf("expected %v for %#v, got %v", test.ids, test.query, ids)
+		}
+	}
+
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFunctionScoreQuery(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []map[string]interface{}{
+		{"name": "old favorite", "popularity": 90.0, "published": "2014-01-01T00:00:00Z"},
+		{"name": "new release", "popularity": 10.0, "published": "2016-06-01T00:00:00Z"},
+		{"name": "new hit", "popularity": 50.0, "published": "2016-05-25T00:00:00Z"},
+	}
+	for i, doc := range docs {
+		err = index.Index(strconv.Itoa(i), doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var query Query
+	query, err = ParseQuery([]byte(`{
+		"query": {"match_all": {}},
+		"functions": [
+			{"field_value_factor": {"field": "popularity"}},
+			{"decay": {"function": "gauss", "field": "published", "origin": "2016-06-01T00:00:00Z", "scale": "30d"}}
+		],
+		"boost_mode": "replace"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := NewSearchRequest(query)
+	req.Explain = true
+	res, err := index.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.ID)
+	}
+	expectedIDs := []string{"2", "1", "0"}
+	if !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("expected %v, got %v", expectedIDs, ids)
+	}
+	if res.Hits[1].Score != 10 {
+		t.Errorf("expected score 10 for the new release, got %f", res.Hits[1].Score)
+	}
+	if res.Hits[0].Expl == nil || res.Hits[0].Expl.Value != res.Hits[0].Score {
+		t.Errorf("expected explanation of the score, got %v", res.Hits[0].Expl)
+	}
+
+	// invalid functions are reported
+	req = NewSearchRequest(NewFunctionScoreQuery(NewMatchAllQuery()).
+		AddFunction(NewNumericDecayFunction("cubic", "popularity", 0, 10, 0)))
+	_, err = index.Search(req)
+	if err == nil {
+		t.Errorf("expected error for unknown decay function")
+	}
+
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBM25Similarity(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	mapping := NewIndexMapping()
+	mapping.DefaultSimilarity = bm25.Name
+	err := mapping.AddCustomSimilarity("flat", map[string]interface{}{
+		"type": bm25.Name,
+		"b":    0.0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagMapping := NewTextFieldMapping()
+	tagMapping.Similarity = "flat"
+	mapping.DefaultMapping.AddFieldMappingsAt("tag", tagMapping)
+	err = mapping.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := New("testidx", mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := index.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	docs := map[string]map[string]interface{}{
+		"short": {"title": "beer", "tag": "beer"},
+		"long":  {"title": "beer from a small brewery in the hills", "tag": "beer from a small brewery in the hills"},
+		"other": {"title": "wine", "tag": "wine"},
+	}
+	for id, doc := range docs {
+		err = index.Index(id, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scores := func(q Query) map[string]float64 {
+		req := NewSearchRequest(q)
+		req.Explain = true
+		res, err := index.Search(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rv := make(map[string]float64)
+		for _, hit := range res.Hits {
+			rv[hit.ID] = hit.Score
+			if !strings.Contains(hit.Expl.String(), "tfNorm") {
+				t.Errorf("expected a bm25 explanation, got %s", hit.Expl)
+			}
+		}
+		return rv
+	}
+
+	titleScores := scores(NewTermQuery("beer").SetField("title"))
+	if len(titleScores) != 2 || titleScores["short"] <= titleScores["long"] {
+		t.Errorf("expected the short title to score higher, got %v", titleScores)
+	}
+	// term-expanding queries score their terms with the similarity too
+	prefixScores := scores(NewPrefixQuery("bee").SetField("title"))
+	if len(prefixScores) != 2 || prefixScores["short"] <= prefixScores["long"] {
+		t.Errorf("expected the short title to score higher, got %v", prefixScores)
+	}
+	tagScores := scores(NewTermQuery("beer").SetField("tag"))
+	if len(tagScores) != 2 || math.Abs(tagScores["short"]-tagScores["long"]) > 1e-9 {
+		t.Errorf("expected equal tag scores without length normalization, got %v", tagScores)
+	}
+
+	// lengths are only maintained for the fields whose similarity uses them
+	i, _, err := index.Advanced()
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexReader, err := i.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for field, expected := range map[string]uint64{"title": 3, "tag": 0} {
+		stats, err := indexReader.FieldStats(field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.DocCount != expected {
+			t.Errorf("expected %s lengths of %d documents, got %d", field, expected, stats.DocCount)
+		}
+	}
+	err = indexReader.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapping.DefaultSimilarity = "unknown"
+	err = mapping.Validate()
+	if err == nil {
+		t.Errorf("expected error for unknown similarity")
+	}
+}
+
+func TestSloppyPhraseAndSpanQueries(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := index.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	docs := map[string]map[string]interface{}{
+		"a": {"desc": "the quick brown fox"},
+		"b": {"desc": "quick red and brown fox"},
+		"c": {"desc": "fox quick"},
+	}
+	for id, doc := range docs {
+		err = index.Index(id, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query Query
+		hits  []string
+	}{
+		{
+			query: NewMatchPhraseQuery("quick fox").SetField("desc"),
+			hits:  []string{},
+		},
+		{
+			query: NewMatchPhraseQuery("quick fox").SetSlop(1).SetField("desc"),
+			hits:  []string{"a"},
+		},
+		{
+			query: NewMatchPhraseQuery("quick fox").SetSlop(3).SetField("desc"),
+			hits:  []string{"a", "b"},
+		},
+		{
+			query: NewQueryStringQuery(`desc:"quick fox"~3`),
+			hits:  []string{"a", "b"},
+		},
+		{
+			// the removed stop word leaves a gap which does
+			// not count against the slop
+			query: NewMatchPhraseQuery("quick and fox").SetSlop(1).SetField("desc"),
+			hits:  []string{"a"},
+		},
+		{
+			query: NewSpanNearQuery([]SpanQuery{NewSpanTermQuery("fox"), NewSpanTermQuery("quick")}, 0, false).SetField("desc"),
+			hits:  []string{"c"},
+		},
+		{
+			query: NewSpanFirstQuery(NewSpanTermQuery("fox"), 1).SetField("desc"),
+			hits:  []string{"c"},
+		},
+		{
+			query: NewSpanNotQuery(NewSpanTermQuery("fox"), NewSpanFirstQuery(NewSpanTermQuery("fox"), 1)).SetField("desc"),
+			hits:  []string{"a", "b"},
+		},
+	}
+
+	for i, test := range tests {
+		req := NewSearchRequest(test.query)
+		req.SortBy([]string{"-_score", "_id"})
+		res, err := index.Search(req)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		hits := []string{}
+		for _, hit := range res.Hits {
+			hits = append(hits, hit.ID)
+		}
+		if !reflect.DeepEqual(hits, test.hits) {
+			t.Errorf("test %d: expected hits %v, got %v", i, test.hits, hits)
+		}
+	}
+}
+
+func TestHighlightFragmentOptions(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := index.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	err = index.Index("a", map[string]interface{}{
+		"desc":  "the quick brown fox jumped over the lazy dog while another quick brown fox slept in the sun",
+		"title": "a story of two foxes",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query     Query
+		fragments []string
+	}{
+		{
+			query:     NewMatchPhraseQuery("brown fox").SetField("desc"),
+			fragments: []string{"…uick [brown] [fox] jumpe…", "…uick [brown] [fox] slept…"},
+		},
+		{
+			query:     NewFuzzyQuery("fux").SetFuzziness(1).SetField("desc"),
+			fragments: []string{"…k brown [fox] jumped o…", "…k brown [fox] slept in…"},
+		},
+		{
+			query:     NewRegexpQuery("f.x").SetField("desc"),
+			fragments: []string{"…k brown [fox] jumped o…", "…k brown [fox] slept in…"},
+		},
+		{
+			query:     NewWildcardQuery("fo*").SetField("desc"),
+			fragments: []string{"…k brown [fox] jumped o…", "…k brown [fox] slept in…"},
+		},
+	}
+
+	for i, test := range tests {
+		req := NewSearchRequest(test.query)
+		req.Highlight = NewHighlight()
+		req.Highlight.AddField("desc")
+		req.Highlight.AddField("title")
+		req.Highlight.FragmentCount = 2
+		req.Highlight.FragmentSize = 20
+		req.Highlight.PreTag = "["
+		req.Highlight.PostTag = "]"
+		req.Highlight.Order = "document"
+		req.Highlight.NoMatchSize = -1
+		res, err := index.Search(req)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if len(res.Hits) != 1 {
+			t.Fatalf("test %d: expected 1 hit, got %d", i, len(res.Hits))
+		}
+		fragments := res.Hits[0].Fragments
+		if !reflect.DeepEqual(fragments["desc"], test.fragments) {
+			t.Errorf("test %d: expected fragments %q, got %q", i, test.fragments, fragments["desc"])
+		}
+		if _, ok := fragments["title"]; ok {
+			t.Errorf("test %d: expected no fragments for title, got %q", i, fragments["title"])
+		}
+	}
+
+	req := NewSearchRequest(NewMatchQuery("fox"))
+	req.Highlight = NewHighlight()
+	req.Highlight.Order = "random"
+	_, err = index.Search(req)
+	if err == nil {
+		t.Errorf("expected error for unknown highlight order")
+	}
+}
+
+func TestMoreLikeThisQuery(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := index.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	docs := map[string]map[string]interface{}{
+		"a": {"title": "brewing beer", "desc": "hops and malt make beer, hops give beer its bitterness"},
+		"b": {"title": "hops", "desc": "hops are the flowers used to bitter beer"},
+		"c": {"title": "malt", "desc": "malt is germinated cereal grain used to make beer"},
+		"d": {"title": "wine", "desc": "wine is made from fermented grapes"},
+		"e": {"title": "cider", "desc": "cider is made from fermented apples"},
+	}
+	for id, doc := range docs {
+		err = index.Index(id, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(q Query) []string {
+		req := NewSearchRequest(q)
+		res, err := index.Search(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rv := []string{}
+		for _, hit := range res.Hits {
+			rv = append(rv, hit.ID)
+		}
+		sort.Strings(rv)
+		return rv
+	}
+
+	tests := []struct {
+		query Query
+		hits  []string
+	}{
+		{
+			// the liked document itself is excluded
+			query: &moreLikeThisQuery{LikeID: "a", Fields: []string{"desc"}, MinTermFreq: 1, MinDocFreq: 1, BoostVal: 1},
+			hits:  []string{"b", "c"},
+		},
+		{
+			// only hops and beer occur twice in a
+			query: &moreLikeThisQuery{LikeID: "a", Fields: []string{"desc"}, MinTermFreq: 2, MinDocFreq: 1, BoostVal: 1},
+			hits:  []string{"b", "c"},
+		},
+		{
+			// only beer is found in 3 documents
+			query: &moreLikeThisQuery{LikeID: "a", Fields: []string{"desc"}, MinTermFreq: 2, MinDocFreq: 3, BoostVal: 1},
+			hits:  []string{"b", "c"},
+		},
+		{
+			// made, from and fermented tie, fermented is searched for
+			query: &moreLikeThisQuery{LikeID: "d", Fields: []string{"desc"}, MinTermFreq: 1, MinDocFreq: 2, MaxQueryTerms: 1, BoostVal: 1},
+			hits:  []string{"e"},
+		},
+		{
+			query: &moreLikeThisQuery{LikeID: "d", Fields: []string{"desc"}, MinTermFreq: 2, MinDocFreq: 1, BoostVal: 1},
+			hits:  []string{},
+		},
+		{
+			query: &moreLikeThisQuery{Like: "fermented apples", FieldVal: "desc", MinTermFreq: 1, MinDocFreq: 1, BoostVal: 1},
+			hits:  []string{"d", "e"},
+		},
+		{
+			// terms are taken from the default field
+			query: &moreLikeThisQuery{LikeID: "b", MinTermFreq: 2, MinDocFreq: 1, BoostVal: 1},
+			hits:  []string{"a"},
+		},
+	}
+
+	for i, test := range tests {
+		hits := ids(test.query)
+		if !reflect.DeepEqual(hits, test.hits) {
+			t.Errorf("test %d: expected hits %v, got %v", i, test.hits, hits)
+		}
+	}
+}
+
+func TestQueryStringRanges(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	mapping := NewIndexMapping()
+	nameMapping := NewTextFieldMapping()
+	nameMapping.Analyzer = "keyword"
+	mapping.DefaultMapping.AddFieldMappingsAt("name", nameMapping)
+	index, err := New("testidx", mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := index.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	docs := map[string]map[string]interface{}{
+		"a": {"name": "apple", "price": 10, "created": "2016-01-01T00:00:00Z"},
+		"b": {"name": "apricot", "price": 15, "created": "2016-03-01T00:00:00Z"},
+		"c": {"name": "banana", "price": 20, "created": "2016-07-01T00:00:00Z"},
+		"d": {"name": "bananas", "price": 25, "created": "2016-09-01T00:00:00Z"},
+	}
+	for id, doc := range docs {
+		err = index.Index(id, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		hits  []string
+	}{
+		{query: `price:[10 TO 20}`, hits: []string{"a", "b"}},
+		{query: `price:{10 TO 20]`, hits: []string{"b", "c"}},
+		{query: `price:{15 TO *]`, hits: []string{"c", "d"}},
+		{query: `created:["2016-01-01" TO "2016-07-01"}`, hits: []string{"a", "b"}},
+		{query: `created:>="2016-07-01"`, hits: []string{"c", "d"}},
+		{query: `name:[apple TO banana]`, hits: []string{"a", "b", "c"}},
+		{query: `name:{apple TO banana}`, hits: []string{"b"}},
+		{query: `name:[b TO *]`, hits: []string{"c", "d"}},
+		{query: `name:["ap" TO "b"]`, hits: []string{"a", "b"}},
+		{query: `name:[* TO apricot} price:[25 TO 25]`, hits: []string{"a", "d"}},
+	}
+
+	for i, test := range tests {
+		req := NewSearchRequest(NewQueryStringQuery(test.query))
+		req.SortBy([]string{"_id"})
+		res, err := index.Search(req)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		hits := []string{}
+		for _, hit := range res.Hits {
+			hits = append(hits, hit.ID)
+		}
+		if !reflect.DeepEqual(hits, test.hits) {
+			t.Errorf("test %d: expected hits %v for %s, got %v", i, test.hits, test.query, hits)
+		}
+	}
+}
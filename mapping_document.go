@@ -15,6 +15,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/blevesearch/bleve/geo"
 	"github.com/blevesearch/bleve/registry"
 )
 
@@ -66,8 +67,14 @@ func (dm *DocumentMapping) Validate(cache *registry.Cache) error {
 				return err
 			}
 		}
+		if field.Similarity != "" {
+			_, err = cache.SimilarityNamed(field.Similarity)
+			if err != nil {
+				return err
+			}
+		}
 		switch field.Type {
-		case "text", "datetime", "number", "boolean":
+		case "text", "datetime", "number", "boolean", "geopoint", "completion":
 		default:
 			return fmt.Errorf("unknown field type: '%s'", field.Type)
 		}
@@ -392,6 +399,33 @@ func (dm *DocumentMapping) processProperty(property interface{}, path []string,
 		// cannot do anything with the zero value
 		return
 	}
+
+	// geo points can be objects, arrays or strings, and completions
+	// objects, so they have to be recognized before descending into
+	// the property
+	if subDocMapping != nil {
+		found := false
+		for _, fieldMapping := range subDocMapping.Fields {
+			switch fieldMapping.Type {
+			case "geopoint":
+				lon, lat, ok := geo.ExtractGeoPoint(property)
+				if ok {
+					fieldMapping.processGeoPoint(lon, lat, pathString, path, indexes, context)
+					found = true
+				}
+			case "completion":
+				entry, ok := extractCompletion(property)
+				if ok {
+					fieldMapping.processCompletion(entry, pathString, path, indexes, context)
+					found = true
+				}
+			}
+		}
+		if found {
+			return
+		}
+	}
+
 	propertyType := propertyValue.Type()
 	switch propertyType.Kind() {
 	case reflect.String:
@@ -12,10 +12,13 @@ package bleve
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/blevesearch/bleve/analysis"
 	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/search/completion"
 )
 
 // control the default behavior for dynamic fields (those not explicitly mapped)
@@ -48,6 +51,17 @@ type FieldMapping struct {
 	IncludeTermVectors bool   `json:"include_term_vectors,omitempty"`
 	IncludeInAll       bool   `json:"include_in_all,omitempty"`
 	DateFormat         string `json:"date_format,omitempty"`
+
+	// DocValues, if true, makes the indexed terms of this field to be also
+	// recorded in a compact per-document column. Faceting and sorting on the
+	// field then read this column instead of the whole back index of each
+	// hit. It must be enabled consistently for all documents of a field.
+	DocValues bool `json:"docvalues,omitempty"`
+
+	// Similarity specifies the name of the similarity scoring the terms of
+	// this field, like "tfidf" or "bm25". If Similarity is empty, the
+	// IndexMapping.DefaultSimilarity is used.
+	Similarity string `json:"similarity,omitempty"`
 }
 
 // NewTextFieldMapping returns a default field mapping for text
@@ -119,6 +133,27 @@ func newBooleanFieldMappingDynamic(im *IndexMapping) *FieldMapping {
 	return rv
 }
 
+// NewGeoPointFieldMapping returns a default field mapping for geo points
+func NewGeoPointFieldMapping() *FieldMapping {
+	return &FieldMapping{
+		Type:         "geopoint",
+		Store:        true,
+		Index:        true,
+		IncludeInAll: true,
+	}
+}
+
+// NewCompletionFieldMapping returns a default field mapping for
+// completions. Inputs are lower cased and their spaces collapsed
+// unless an analyzer is configured.
+func NewCompletionFieldMapping() *FieldMapping {
+	return &FieldMapping{
+		Type:  "completion",
+		Store: true,
+		Index: true,
+	}
+}
+
 // Options returns the indexing options for this field.
 func (fm *FieldMapping) Options() document.IndexingOptions {
 	var rv document.IndexingOptions
@@ -131,12 +166,25 @@ func (fm *FieldMapping) Options() document.IndexingOptions {
 	if fm.IncludeTermVectors {
 		rv |= document.IncludeTermVectors
 	}
+	if fm.DocValues {
+		rv |= document.DocValues
+	}
+	return rv
+}
+
+// indexingOptions returns the options the field is indexed with, which
+// also maintain its lengths when its similarity uses them.
+func (fm *FieldMapping) indexingOptions(im *IndexMapping) document.IndexingOptions {
+	rv := fm.Options()
+	if rv.IsIndexed() && im.similarityUsesFieldLengths(fm.Similarity) {
+		rv |= document.FieldLengths
+	}
 	return rv
 }
 
 func (fm *FieldMapping) processString(propertyValueString string, pathString string, path []string, indexes []uint64, context *walkContext) {
 	fieldName := getFieldName(pathString, path, fm)
-	options := fm.Options()
+	options := fm.indexingOptions(context.im)
 	if fm.Type == "text" {
 		analyzer := fm.analyzerForField(path, context)
 		field := document.NewTextFieldCustom(fieldName, indexes, []byte(propertyValueString), options, analyzer)
@@ -145,6 +193,9 @@ func (fm *FieldMapping) processString(propertyValueString string, pathString str
 		if !fm.IncludeInAll {
 			context.excludedFromAll = append(context.excludedFromAll, fieldName)
 		}
+	} else if fm.Type == "completion" {
+		entry := &document.CompletionEntry{Inputs: []string{propertyValueString}}
+		fm.processCompletion(entry, pathString, path, indexes, context)
 	} else if fm.Type == "datetime" {
 		dateTimeFormat := context.im.DefaultDateTimeParser
 		if fm.DateFormat != "" {
@@ -163,7 +214,7 @@ func (fm *FieldMapping) processString(propertyValueString string, pathString str
 func (fm *FieldMapping) processFloat64(propertyValFloat float64, pathString string, path []string, indexes []uint64, context *walkContext) {
 	fieldName := getFieldName(pathString, path, fm)
 	if fm.Type == "number" {
-		options := fm.Options()
+		options := fm.indexingOptions(context.im)
 		field := document.NewNumericFieldWithIndexingOptions(fieldName, indexes, propertyValFloat, options)
 		context.doc.AddField(field)
 
@@ -176,7 +227,7 @@ func (fm *FieldMapping) processFloat64(propertyValFloat float64, pathString stri
 func (fm *FieldMapping) processTime(propertyValueTime time.Time, pathString string, path []string, indexes []uint64, context *walkContext) {
 	fieldName := getFieldName(pathString, path, fm)
 	if fm.Type == "datetime" {
-		options := fm.Options()
+		options := fm.indexingOptions(context.im)
 		field, err := document.NewDateTimeFieldWithIndexingOptions(fieldName, indexes, propertyValueTime, options)
 		if err == nil {
 			context.doc.AddField(field)
@@ -193,7 +244,7 @@ func (fm *FieldMapping) processTime(propertyValueTime time.Time, pathString stri
 func (fm *FieldMapping) processBoolean(propertyValueBool bool, pathString string, path []string, indexes []uint64, context *walkContext) {
 	fieldName := getFieldName(pathString, path, fm)
 	if fm.Type == "boolean" {
-		options := fm.Options()
+		options := fm.indexingOptions(context.im)
 		field := document.NewBooleanFieldWithIndexingOptions(fieldName, indexes, propertyValueBool, options)
 		context.doc.AddField(field)
 
@@ -203,6 +254,119 @@ func (fm *FieldMapping) processBoolean(propertyValueBool bool, pathString string
 	}
 }
 
+func (fm *FieldMapping) processGeoPoint(lon, lat float64, pathString string, path []string, indexes []uint64, context *walkContext) {
+	fieldName := getFieldName(pathString, path, fm)
+	if fm.Type == "geopoint" {
+		options := fm.indexingOptions(context.im)
+		field := document.NewGeoPointFieldWithIndexingOptions(fieldName, indexes, lon, lat, options)
+		context.doc.AddField(field)
+
+		if !fm.IncludeInAll {
+			context.excludedFromAll = append(context.excludedFromAll, fieldName)
+		}
+	}
+}
+
+func (fm *FieldMapping) processCompletion(entry *document.CompletionEntry, pathString string, path []string, indexes []uint64, context *walkContext) {
+	fieldName := getFieldName(pathString, path, fm)
+	if fm.Type == "completion" {
+		var analyzer *analysis.Analyzer
+		if fm.Analyzer != "" {
+			analyzer = context.im.analyzerNamed(fm.Analyzer)
+		}
+		entry.Keys = make([]string, len(entry.Inputs))
+		for i, input := range entry.Inputs {
+			entry.Keys[i] = completionKey(analyzer, input)
+		}
+		options := fm.indexingOptions(context.im)
+		field, err := document.NewCompletionFieldWithIndexingOptions(fieldName, indexes, entry, options)
+		if err == nil {
+			context.doc.AddField(field)
+		} else {
+			logger.Printf("could not build completion %v", err)
+		}
+
+		if !fm.IncludeInAll {
+			context.excludedFromAll = append(context.excludedFromAll, fieldName)
+		}
+	}
+}
+
+// completionKey returns the key a completion input is looked up by,
+// the terms produced by analyzer or the normalized input when nil.
+func completionKey(analyzer *analysis.Analyzer, input string) string {
+	if analyzer == nil {
+		return completion.Normalize(input)
+	}
+	tokens := analyzer.Analyze([]byte(input))
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = string(token.Term)
+	}
+	return strings.Join(terms, " ")
+}
+
+// extractCompletion recognizes an object describing a completion:
+// its input, a string or an array of strings, with an optional
+// weight, payload and contexts, each a string or an array of strings.
+func extractCompletion(property interface{}) (*document.CompletionEntry, bool) {
+	kind := reflect.Indirect(reflect.ValueOf(property)).Kind()
+	if kind != reflect.Map && kind != reflect.Struct {
+		return nil, false
+	}
+	buf, err := json.Marshal(property)
+	if err != nil {
+		return nil, false
+	}
+	var tmp struct {
+		Input    json.RawMessage            `json:"input"`
+		Weight   int64                      `json:"weight"`
+		Payload  json.RawMessage            `json:"payload"`
+		Contexts map[string]json.RawMessage `json:"contexts"`
+	}
+	err = json.Unmarshal(buf, &tmp)
+	if err != nil {
+		return nil, false
+	}
+	inputs, ok := stringOrStrings(tmp.Input)
+	if !ok || len(inputs) == 0 {
+		return nil, false
+	}
+	rv := &document.CompletionEntry{
+		Inputs:  inputs,
+		Weight:  tmp.Weight,
+		Payload: tmp.Payload,
+	}
+	for name, value := range tmp.Contexts {
+		values, ok := stringOrStrings(value)
+		if !ok {
+			return nil, false
+		}
+		if rv.Contexts == nil {
+			rv.Contexts = make(map[string][]string)
+		}
+		rv.Contexts[name] = values
+	}
+	return rv, true
+}
+
+func stringOrStrings(data json.RawMessage) ([]string, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err == nil {
+		return []string{s}, true
+	}
+	var rv []string
+	err = json.Unmarshal(data, &rv)
+	if err != nil {
+		return nil, false
+	}
+	return rv, true
+}
+
 func (fm *FieldMapping) analyzerForField(path []string, context *walkContext) *analysis.Analyzer {
 	analyzerName := fm.Analyzer
 	if analyzerName == "" {
@@ -278,6 +442,16 @@ func (fm *FieldMapping) UnmarshalJSON(data []byte) error {
 			if err != nil {
 				return err
 			}
+		case "docvalues":
+			err := json.Unmarshal(v, &fm.DocValues)
+			if err != nil {
+				return err
+			}
+		case "similarity":
+			err := json.Unmarshal(v, &fm.Similarity)
+			if err != nil {
+				return err
+			}
 		default:
 			invalidKeys = append(invalidKeys, k)
 		}
@@ -18,6 +18,8 @@ import (
 	"github.com/blevesearch/bleve/analysis/datetime_parsers/datetime_optional"
 	"github.com/blevesearch/bleve/document"
 	"github.com/blevesearch/bleve/registry"
+	"github.com/blevesearch/bleve/search/scorers"
+	"github.com/blevesearch/bleve/search/similarities/tfidf"
 )
 
 var MappingJSONStrict = false
@@ -27,14 +29,17 @@ const defaultType = "_default"
 const defaultField = "_all"
 const defaultAnalyzer = standard_analyzer.Name
 const defaultDateTimeParser = datetime_optional.Name
+const defaultSimilarity = tfidf.Name
 
 type customAnalysis struct {
 	CharFilters     map[string]map[string]interface{} `json:"char_filters,omitempty"`
 	Tokenizers      map[string]map[string]interface{} `json:"tokenizers,omitempty"`
 	TokenMaps       map[string]map[string]interface{} `json:"token_maps,omitempty"`
+	SynonymMaps     map[string]map[string]interface{} `json:"synonym_maps,omitempty"`
 	TokenFilters    map[string]map[string]interface{} `json:"token_filters,omitempty"`
 	Analyzers       map[string]map[string]interface{} `json:"analyzers,omitempty"`
 	DateTimeParsers map[string]map[string]interface{} `json:"date_time_parsers,omitempty"`
+	Similarities    map[string]map[string]interface{} `json:"similarities,omitempty"`
 }
 
 func (c *customAnalysis) registerAll(i *IndexMapping) error {
@@ -79,6 +84,12 @@ func (c *customAnalysis) registerAll(i *IndexMapping) error {
 			return err
 		}
 	}
+	for name, config := range c.SynonymMaps {
+		_, err := i.cache.DefineSynonymMap(name, config)
+		if err != nil {
+			return err
+		}
+	}
 	for name, config := range c.TokenFilters {
 		_, err := i.cache.DefineTokenFilter(name, config)
 		if err != nil {
@@ -97,6 +108,12 @@ func (c *customAnalysis) registerAll(i *IndexMapping) error {
 			return err
 		}
 	}
+	for name, config := range c.Similarities {
+		_, err := i.cache.DefineSimilarity(name, config)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -105,9 +122,11 @@ func newCustomAnalysis() *customAnalysis {
 		CharFilters:     make(map[string]map[string]interface{}),
 		Tokenizers:      make(map[string]map[string]interface{}),
 		TokenMaps:       make(map[string]map[string]interface{}),
+		SynonymMaps:     make(map[string]map[string]interface{}),
 		TokenFilters:    make(map[string]map[string]interface{}),
 		Analyzers:       make(map[string]map[string]interface{}),
 		DateTimeParsers: make(map[string]map[string]interface{}),
+		Similarities:    make(map[string]map[string]interface{}),
 	}
 	return &rv
 }
@@ -126,6 +145,7 @@ type IndexMapping struct {
 	DefaultType           string                      `json:"default_type"`
 	DefaultAnalyzer       string                      `json:"default_analyzer"`
 	DefaultDateTimeParser string                      `json:"default_datetime_parser"`
+	DefaultSimilarity     string                      `json:"default_similarity"`
 	DefaultField          string                      `json:"default_field"`
 	StoreDynamic          bool                        `json:"store_dynamic"`
 	IndexDynamic          bool                        `json:"index_dynamic"`
@@ -163,6 +183,16 @@ func (im *IndexMapping) AddCustomTokenMap(name string, config map[string]interfa
 	return nil
 }
 
+// AddCustomSynonymMap defines a custom synonym map for use in this mapping
+func (im *IndexMapping) AddCustomSynonymMap(name string, config map[string]interface{}) error {
+	_, err := im.cache.DefineSynonymMap(name, config)
+	if err != nil {
+		return err
+	}
+	im.CustomAnalysis.SynonymMaps[name] = config
+	return nil
+}
+
 // AddCustomTokenFilter defines a custom token filter for use in this mapping
 func (im *IndexMapping) AddCustomTokenFilter(name string, config map[string]interface{}) error {
 	_, err := im.cache.DefineTokenFilter(name, config)
@@ -221,6 +251,17 @@ func (im *IndexMapping) AddCustomDateTimeParser(name string, config map[string]i
 	return nil
 }
 
+// AddCustomSimilarity defines a custom similarity for use in this mapping,
+// like a BM25 similarity with its own "k1" and "b" parameters.
+func (im *IndexMapping) AddCustomSimilarity(name string, config map[string]interface{}) error {
+	_, err := im.cache.DefineSimilarity(name, config)
+	if err != nil {
+		return err
+	}
+	im.CustomAnalysis.Similarities[name] = config
+	return nil
+}
+
 // NewIndexMapping creates a new IndexMapping that will use all the default indexing rules
 func NewIndexMapping() *IndexMapping {
 	return &IndexMapping{
@@ -230,6 +271,7 @@ func NewIndexMapping() *IndexMapping {
 		DefaultType:           defaultType,
 		DefaultAnalyzer:       defaultAnalyzer,
 		DefaultDateTimeParser: defaultDateTimeParser,
+		DefaultSimilarity:     defaultSimilarity,
 		DefaultField:          defaultField,
 		IndexDynamic:          IndexDynamic,
 		StoreDynamic:          StoreDynamic,
@@ -249,6 +291,10 @@ func (im *IndexMapping) Validate() error {
 	if err != nil {
 		return err
 	}
+	_, err = im.cache.SimilarityNamed(im.DefaultSimilarity)
+	if err != nil {
+		return err
+	}
 	err = im.DefaultMapping.Validate(im.cache)
 	if err != nil {
 		return err
@@ -291,6 +337,7 @@ func (im *IndexMapping) UnmarshalJSON(data []byte) error {
 	im.DefaultType = defaultType
 	im.DefaultAnalyzer = defaultAnalyzer
 	im.DefaultDateTimeParser = defaultDateTimeParser
+	im.DefaultSimilarity = defaultSimilarity
 	im.DefaultField = defaultField
 	im.DefaultMapping = NewDocumentMapping()
 	im.TypeMapping = make(map[string]*DocumentMapping)
@@ -325,6 +372,11 @@ func (im *IndexMapping) UnmarshalJSON(data []byte) error {
 			if err != nil {
 				return err
 			}
+		case "default_similarity":
+			err := json.Unmarshal(v, &im.DefaultSimilarity)
+			if err != nil {
+				return err
+			}
 		case "default_field":
 			err := json.Unmarshal(v, &im.DefaultField)
 			if err != nil {
@@ -393,7 +445,11 @@ func (im *IndexMapping) mapDocument(doc *document.Document, data interface{}) er
 		// see if the _all field was disabled
 		allMapping := docMapping.documentMappingForPath("_all")
 		if allMapping == nil || (allMapping.Enabled != false) {
-			field := document.NewCompositeFieldWithIndexingOptions("_all", true, []string{}, walkContext.excludedFromAll, document.IndexField|document.IncludeTermVectors)
+			options := document.IndexField | document.IncludeTermVectors
+			if similarity, err := im.similarityForPath("_all"); err == nil && usesFieldLengths(similarity) {
+				options |= document.FieldLengths
+			}
+			field := document.NewCompositeFieldWithIndexingOptions("_all", true, []string{}, walkContext.excludedFromAll, options)
 			doc.AddField(field)
 		}
 	}
@@ -487,6 +543,70 @@ func (im *IndexMapping) datetimeParserNameForPath(path string) string {
 	return im.DefaultDateTimeParser
 }
 
+// similarityForPath returns the similarity scoring the terms of a field,
+// the one explicitly mapped to it or the default one.
+func (im *IndexMapping) similarityForPath(path string) (scorers.Similarity, error) {
+	name := ""
+	for _, docMapping := range im.TypeMapping {
+		field := docMapping.fieldDescribedByPath(path)
+		if field != nil && field.Similarity != "" {
+			name = field.Similarity
+			break
+		}
+	}
+	if name == "" {
+		field := im.DefaultMapping.fieldDescribedByPath(path)
+		if field != nil {
+			name = field.Similarity
+		}
+	}
+	if name == "" {
+		name = im.DefaultSimilarity
+	}
+	if name == "" {
+		name = defaultSimilarity
+	}
+	return im.cache.SimilarityNamed(name)
+}
+
+// similarityUsesFieldLengths reports whether the named similarity, or
+// the default one when empty, reads the field statistics of the index.
+func (im *IndexMapping) similarityUsesFieldLengths(name string) bool {
+	if name == "" {
+		name = im.DefaultSimilarity
+	}
+	if name == "" {
+		name = defaultSimilarity
+	}
+	similarity, err := im.cache.SimilarityNamed(name)
+	if err != nil {
+		return false
+	}
+	return usesFieldLengths(similarity)
+}
+
+func usesFieldLengths(similarity scorers.Similarity) bool {
+	s, ok := similarity.(scorers.FieldLengthsSimilarity)
+	return ok && s.UsesFieldLengths()
+}
+
+// completionAnalyzerForPath returns the analyzer configured on the
+// completion field of a path, nil when its inputs are normalized.
+func (im *IndexMapping) completionAnalyzerForPath(path string) *analysis.Analyzer {
+	field := im.DefaultMapping.fieldDescribedByPath(path)
+	for _, docMapping := range im.TypeMapping {
+		f := docMapping.fieldDescribedByPath(path)
+		if f != nil && f.Type == "completion" {
+			field = f
+			break
+		}
+	}
+	if field == nil || field.Type != "completion" || field.Analyzer == "" {
+		return nil
+	}
+	return im.analyzerNamed(field.Analyzer)
+}
+
 func (im *IndexMapping) AnalyzeText(analyzerName string, text []byte) (analysis.TokenStream, error) {
 	analyzer, err := im.cache.AnalyzerNamed(analyzerName)
 	if err != nil {
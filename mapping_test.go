@@ -33,7 +33,8 @@ var mappingSource = []byte(`{
     						"store": true,
     						"index": true,
                             "include_term_vectors": true,
-                            "include_in_all": true
+                            "include_in_all": true,
+                            "docvalues": true
     					}
     				]
     			}
@@ -50,6 +51,7 @@ func buildMapping() *IndexMapping {
 	nameFieldMapping := NewTextFieldMapping()
 	nameFieldMapping.Name = "name"
 	nameFieldMapping.Analyzer = "standard"
+	nameFieldMapping.DocValues = true
 
 	beerMapping := NewDocumentMapping()
 	beerMapping.AddFieldMappingsAt("name", nameFieldMapping)
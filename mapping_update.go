@@ -0,0 +1,246 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A MappingConflict is a change between two index mappings
+// which cannot be applied to an index already holding
+// documents. Path locates the changed setting in the JSON
+// representation of the mapping, Old and New are its JSON
+// values, nil when the setting is missing.
+type MappingConflict struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+func (c *MappingConflict) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Path, conflictValue(c.Old), conflictValue(c.New))
+}
+
+func conflictValue(v interface{}) string {
+	if v == nil {
+		return "(none)"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// MappingConflictError is returned when updating the mapping
+// of an index with one which does not only add to it.
+type MappingConflictError struct {
+	Conflicts []*MappingConflict
+}
+
+func (e *MappingConflictError) Error() string {
+	conflicts := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		conflicts[i] = conflict.String()
+	}
+	return "index mapping changes are not additive: " + strings.Join(conflicts, "; ")
+}
+
+// checkUpdate returns a *MappingConflictError if update
+// changes or removes anything from im. Only new document
+// types, new properties or fields of a document mapping and
+// new custom analysis components can be added, as they do not
+// alter how documents already in the index were indexed.
+// Properties cannot be added to dynamic document mappings,
+// nor document types while the default mapping indexes
+// anything, as documents already in the index may have been
+// indexed differently by them.
+func (im *IndexMapping) checkUpdate(update *IndexMapping) error {
+	oldMap, err := mappingMap(im)
+	if err != nil {
+		return err
+	}
+	newMap, err := mappingMap(update)
+	if err != nil {
+		return err
+	}
+
+	d := mappingDiff{}
+	d.object("", oldMap, newMap, map[string]func(string, interface{}, interface{}){
+		"default_mapping": d.document,
+		"types": func(path string, o, n interface{}) {
+			d.additive(path, o, n, d.document)
+			if indexesDocuments(oldMap["default_mapping"]) {
+				d.added(path, o, n)
+			}
+		},
+		"analysis": func(path string, o, n interface{}) {
+			d.additive(path, o, n, func(path string, o, n interface{}) {
+				d.additive(path, o, n, d.value)
+			})
+		},
+	})
+	if len(d.conflicts) > 0 {
+		return &MappingConflictError{Conflicts: d.conflicts}
+	}
+	return nil
+}
+
+// mappingMap returns the generic JSON representation of a
+// mapping.
+func mappingMap(im *IndexMapping) (map[string]interface{}, error) {
+	b, err := json.Marshal(im)
+	if err != nil {
+		return nil, err
+	}
+	var rv map[string]interface{}
+	err = json.Unmarshal(b, &rv)
+	return rv, err
+}
+
+type mappingDiff struct {
+	conflicts []*MappingConflict
+}
+
+func (d *mappingDiff) conflict(path string, o, n interface{}) {
+	d.conflicts = append(d.conflicts, &MappingConflict{
+		Path: path,
+		Old:  o,
+		New:  n,
+	})
+}
+
+// object compares the entries of two JSON objects, using
+// the function registered for a key if any and requiring
+// them to be equal otherwise.
+func (d *mappingDiff) object(path string, o, n map[string]interface{}, compare map[string]func(string, interface{}, interface{})) {
+	for _, key := range unionKeys(o, n) {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		if f, ok := compare[key]; ok {
+			f(keyPath, o[key], n[key])
+		} else {
+			d.value(keyPath, o[key], n[key])
+		}
+	}
+}
+
+// value requires two JSON values to be equal, reporting
+// the innermost differences of objects and arrays.
+func (d *mappingDiff) value(path string, o, n interface{}) {
+	if reflect.DeepEqual(o, n) {
+		return
+	}
+	oMap, oIsMap := o.(map[string]interface{})
+	nMap, nIsMap := n.(map[string]interface{})
+	if oIsMap && nIsMap {
+		d.object(path, oMap, nMap, nil)
+		return
+	}
+	oSlice, oIsSlice := o.([]interface{})
+	nSlice, nIsSlice := n.([]interface{})
+	if oIsSlice && nIsSlice && len(oSlice) == len(nSlice) {
+		for i := range oSlice {
+			d.value(fmt.Sprintf("%s[%d]", path, i), oSlice[i], nSlice[i])
+		}
+		return
+	}
+	d.conflict(path, o, n)
+}
+
+// additive compares two JSON objects whose entries can be
+// added but not removed, existing entries being compared with
+// compare.
+func (d *mappingDiff) additive(path string, o, n interface{}, compare func(string, interface{}, interface{})) {
+	oMap, _ := o.(map[string]interface{})
+	nMap, nIsMap := n.(map[string]interface{})
+	if n != nil && !nIsMap {
+		d.conflict(path, o, n)
+		return
+	}
+	for _, key := range unionKeys(oMap, nil) {
+		compare(path+"."+key, oMap[key], nMap[key])
+	}
+}
+
+// added reports the entries of the JSON object n which are
+// not in o.
+func (d *mappingDiff) added(path string, o, n interface{}) {
+	oMap, _ := o.(map[string]interface{})
+	nMap, _ := n.(map[string]interface{})
+	for _, key := range unionKeys(nil, nMap) {
+		if _, ok := oMap[key]; !ok {
+			d.conflict(path+"."+key, nil, nMap[key])
+		}
+	}
+}
+
+// indexesDocuments tells if a document mapping indexes any
+// property of the documents it applies to.
+func indexesDocuments(m interface{}) bool {
+	dm, _ := m.(map[string]interface{})
+	if dm["enabled"] != true {
+		return false
+	}
+	return dm["dynamic"] == true || dm["properties"] != nil || dm["fields"] != nil
+}
+
+// document compares two document mappings.
+func (d *mappingDiff) document(path string, o, n interface{}) {
+	oMap, oIsMap := o.(map[string]interface{})
+	nMap, nIsMap := n.(map[string]interface{})
+	if !oIsMap || !nIsMap {
+		d.value(path, o, n)
+		return
+	}
+	d.object(path, oMap, nMap, map[string]func(string, interface{}, interface{}){
+		"properties": func(path string, o, n interface{}) {
+			d.additive(path, o, n, d.document)
+			if oMap["enabled"] == true && oMap["dynamic"] == true {
+				d.added(path, o, n)
+			}
+		},
+		"fields": d.fields,
+	})
+}
+
+// fields compares the field mappings of a document mapping,
+// which can be appended to.
+func (d *mappingDiff) fields(path string, o, n interface{}) {
+	oSlice, _ := o.([]interface{})
+	nSlice, nIsSlice := n.([]interface{})
+	if (n != nil && !nIsSlice) || len(nSlice) < len(oSlice) {
+		d.conflict(path, o, n)
+		return
+	}
+	for i := range oSlice {
+		d.value(fmt.Sprintf("%s[%d]", path, i), oSlice[i], nSlice[i])
+	}
+}
+
+func unionKeys(o, n map[string]interface{}) []string {
+	keys := make([]string, 0, len(o)+len(n))
+	for key := range o {
+		keys = append(keys, key)
+	}
+	for key := range n {
+		if _, ok := o[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,192 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve/analysis/tokenizers/regexp_tokenizer"
+)
+
+func updateTestMapping() *IndexMapping {
+	m := NewIndexMapping()
+	err := m.AddCustomTokenizer("words", map[string]interface{}{
+		"type":   regexp_tokenizer.Name,
+		"regexp": `\w+`,
+	})
+	if err != nil {
+		panic(err)
+	}
+	m.DefaultMapping.Dynamic = false
+	beer := NewDocumentMapping()
+	beer.AddFieldMappingsAt("name", NewTextFieldMapping())
+	m.AddDocumentMapping("beer", beer)
+	return m
+}
+
+func TestMappingCheckUpdate(t *testing.T) {
+	tests := []struct {
+		// dynamic makes the default mapping dynamic
+		dynamic   bool
+		update    func(m *IndexMapping)
+		conflicts []string
+	}{
+		{
+			update: func(m *IndexMapping) {},
+		},
+		{
+			update: func(m *IndexMapping) {
+				m.AddDocumentMapping("brewery", NewDocumentMapping())
+			},
+		},
+		{
+			update: func(m *IndexMapping) {
+				m.DefaultMapping.AddFieldMappingsAt("abv", NewNumericFieldMapping())
+				m.TypeMapping["beer"].AddFieldMappingsAt("name", NewTextFieldMapping())
+			},
+		},
+		{
+			update: func(m *IndexMapping) {
+				err := m.AddCustomTokenizer("letters", map[string]interface{}{
+					"type":   regexp_tokenizer.Name,
+					"regexp": `\pL+`,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			update: func(m *IndexMapping) {
+				m.TypeMapping["beer"].Properties["name"].Fields[0].Analyzer = "simple"
+				m.DefaultAnalyzer = "simple"
+			},
+			conflicts: []string{
+				`default_analyzer: "standard" -> "simple"`,
+				`types.beer.properties.name.fields[0].analyzer: (none) -> "simple"`,
+			},
+		},
+		{
+			update: func(m *IndexMapping) {
+				delete(m.TypeMapping, "beer")
+				m.CustomAnalysis.Tokenizers["words"]["regexp"] = `\S+`
+			},
+			conflicts: []string{
+				`analysis.tokenizers.words.regexp: "\\w+" -> "\\S+"`,
+				`types.beer: {"default_analyzer":"","dynamic":true,"enabled":true,"properties":{"name":{"default_analyzer":"","dynamic":true,"enabled":true,"fields":[{"include_in_all":true,"include_term_vectors":true,"index":true,"store":true,"type":"text"}]}}} -> (none)`,
+			},
+		},
+		{
+			update: func(m *IndexMapping) {
+				m.TypeMapping["beer"].Properties["name"].Fields = nil
+			},
+			conflicts: []string{
+				`types.beer.properties.name.fields: [{"include_in_all":true,"include_term_vectors":true,"index":true,"store":true,"type":"text"}] -> (none)`,
+			},
+		},
+		{
+			// documents of a new type may have been indexed
+			// dynamically by the default mapping
+			dynamic: true,
+			update: func(m *IndexMapping) {
+				m.AddDocumentMapping("brewery", NewDocumentMapping())
+			},
+			conflicts: []string{
+				`types.brewery: (none) -> {"default_analyzer":"","dynamic":true,"enabled":true}`,
+			},
+		},
+		{
+			update: func(m *IndexMapping) {
+				m.TypeMapping["beer"].AddFieldMappingsAt("abv", NewNumericFieldMapping())
+			},
+			conflicts: []string{
+				`types.beer.properties.abv: (none) -> {"default_analyzer":"","dynamic":true,"enabled":true,"fields":[{"include_in_all":true,"index":true,"store":true,"type":"number"}]}`,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		old := updateTestMapping()
+		update := updateTestMapping()
+		old.DefaultMapping.Dynamic = test.dynamic
+		update.DefaultMapping.Dynamic = test.dynamic
+		test.update(update)
+		err := old.checkUpdate(update)
+		var conflicts []string
+		if err != nil {
+			conflictErr, ok := err.(*MappingConflictError)
+			if !ok {
+				t.Fatalf("test %d: unexpected error: %v", i, err)
+			}
+			for _, conflict := range conflictErr.Conflicts {
+				conflicts = append(conflicts, conflict.String())
+			}
+		}
+		if !reflect.DeepEqual(conflicts, test.conflicts) {
+			t.Errorf("test %d: expected conflicts %q, got %q", i, test.conflicts, conflicts)
+		}
+	}
+}
+
+func TestIndexUpdateMapping(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", updateTestMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := updateTestMapping()
+	update.TypeField = "kind"
+	err = index.UpdateMapping(update)
+	if _, ok := err.(*MappingConflictError); !ok {
+		t.Fatalf("expected a mapping conflict, got %v", err)
+	}
+
+	update = updateTestMapping()
+	brewery := NewDocumentMapping()
+	brewery.AddFieldMappingsAt("city", NewTextFieldMapping())
+	update.AddDocumentMapping("brewery", brewery)
+	err = index.UpdateMapping(update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Mapping().TypeMapping["brewery"] == nil {
+		t.Errorf("expected the brewery type to be mapped")
+	}
+
+	err = index.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err = Open("testidx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := index.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	if index.Mapping().TypeMapping["brewery"] == nil {
+		t.Errorf("expected the brewery type to be mapped after reopening")
+	}
+	if index.Mapping().TypeField != defaultTypeField {
+		t.Errorf("expected the rejected update not to be persisted")
+	}
+}
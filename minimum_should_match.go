@@ -0,0 +1,92 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// minShouldMatch returns how many of clauses optional clauses
+// must match according to spec, which is either:
+//   - an integer, "3", the number of clauses, or when negative,
+//     "-2", the number of clauses which may not match
+//   - a percentage, "75%", of the clauses rounded down, or when
+//     negative, "-25%", of the clauses which may not match
+//   - conditions, "3<75%", requiring all the clauses up to 3 of
+//     them, and 75% of them above. Several conditions can be
+//     separated by spaces, "2<-25% 9<-3", the one with the
+//     greatest number of clauses below clauses applying.
+//
+// The result is always between 0 and clauses.
+func minShouldMatch(spec string, clauses int) (int, error) {
+	if !strings.Contains(spec, "<") {
+		return minShouldMatchValue(spec, clauses)
+	}
+	rv := clauses
+	applied := -1
+	for _, condition := range strings.Fields(spec) {
+		parts := strings.SplitN(condition, "<", 2)
+		if len(parts) != 2 {
+			return 0, fmt.Errorf("invalid minimum should match '%s'", spec)
+		}
+		n, err := strconv.Atoi(parts[0])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid minimum should match '%s'", spec)
+		}
+		value, err := minShouldMatchValue(parts[1], clauses)
+		if err != nil {
+			return 0, fmt.Errorf("invalid minimum should match '%s'", spec)
+		}
+		if clauses > n && n > applied {
+			rv = value
+			applied = n
+		}
+	}
+	return rv, nil
+}
+
+// minShouldMatchValue returns how many of clauses optional
+// clauses must match according to value, an integer or a
+// percentage.
+func minShouldMatchValue(value string, clauses int) (int, error) {
+	value = strings.TrimSpace(value)
+	var rv int
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid minimum should match '%s'", value)
+		}
+		if percent < 0 {
+			// the clauses which may not match are rounded down,
+			// before being subtracted
+			rv = clauses - clauses*-percent/100
+		} else {
+			rv = clauses * percent / 100
+		}
+	} else {
+		var err error
+		rv, err = strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid minimum should match '%s'", value)
+		}
+		if rv < 0 {
+			rv += clauses
+		}
+	}
+	if rv < 0 {
+		return 0, nil
+	}
+	if rv > clauses {
+		return clauses, nil
+	}
+	return rv, nil
+}
@@ -0,0 +1,67 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"testing"
+)
+
+func TestMinShouldMatch(t *testing.T) {
+	tests := []struct {
+		spec    string
+		clauses int
+		min     int
+		err     bool
+	}{
+		{spec: "3", clauses: 5, min: 3},
+		{spec: "7", clauses: 5, min: 5},
+		{spec: "-2", clauses: 5, min: 3},
+		{spec: "-7", clauses: 5, min: 0},
+		{spec: "75%", clauses: 5, min: 3},
+		{spec: "100%", clauses: 5, min: 5},
+		{spec: "-25%", clauses: 5, min: 4},
+		{spec: "-25%", clauses: 4, min: 3},
+		{spec: "-25%", clauses: 3, min: 3},
+		{spec: "-10%", clauses: 5, min: 5},
+		{spec: "-50%", clauses: 1, min: 1},
+		{spec: "-150%", clauses: 4, min: 0},
+		{spec: "3<75%", clauses: 2, min: 2},
+		{spec: "3<75%", clauses: 3, min: 3},
+		{spec: "3<75%", clauses: 8, min: 6},
+		{spec: "2<-25% 9<-3", clauses: 2, min: 2},
+		{spec: "2<-25% 9<-3", clauses: 3, min: 3},
+		{spec: "2<-25% 9<-3", clauses: 8, min: 6},
+		{spec: "2<-25% 9<-3", clauses: 12, min: 9},
+		{spec: "9<-3 2<-25%", clauses: 12, min: 9},
+		{spec: "", clauses: 3, err: true},
+		{spec: "three", clauses: 3, err: true},
+		{spec: "75.5%", clauses: 3, err: true},
+		{spec: "3<", clauses: 3, err: true},
+		{spec: "a<75%", clauses: 3, err: true},
+		{spec: "3<75% 5", clauses: 3, err: true},
+	}
+
+	for _, test := range tests {
+		actual, err := minShouldMatch(test.spec, test.clauses)
+		if test.err {
+			if err == nil {
+				t.Errorf("expected error for '%s'", test.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("error %v for '%s'", err, test.spec)
+			continue
+		}
+		if actual != test.min {
+			t.Errorf("expected %d of %d clauses for '%s', got %d", test.min, test.clauses, test.spec, actual)
+		}
+	}
+}
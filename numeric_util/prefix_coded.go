@@ -72,3 +72,26 @@ func (p PrefixCoded) Int64() (int64, error) {
 	}
 	return int64(uint64((sortableBits << shift)) ^ 0x8000000000000000), nil
 }
+
+// ValidPrefixCodedTerm reports whether the term is a well-formed
+// prefix coded value and, if so, the number of bits it is shifted
+// by.
+func ValidPrefixCodedTerm(p string) (bool, int) {
+	if len(p) > 0 {
+		if p[0] < ShiftStartInt64 || p[0] > ShiftStartInt64+63 {
+			return false, 0
+		}
+		shift := p[0] - ShiftStartInt64
+		nChars := ((63 - int(shift)) / 7) + 1
+		if len(p) != nChars+1 {
+			return false, 0
+		}
+		for i := 1; i < len(p); i++ {
+			if p[i] > 0x7f {
+				return false, 0
+			}
+		}
+		return true, int(shift)
+	}
+	return false, 0
+}
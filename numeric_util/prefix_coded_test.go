@@ -85,6 +85,10 @@ func TestPrefixCoded(t *testing.T) {
 		if checkedShift != test.shift {
 			t.Errorf("expected %d, got %d", test.shift, checkedShift)
 		}
+		valid, validShift := ValidPrefixCodedTerm(string(actual))
+		if !valid || uint(validShift) != test.shift {
+			t.Errorf("expected valid term with shift %d, got %t %d", test.shift, valid, validShift)
+		}
 		// if the shift was 0, make sure we can go back to the original
 		if test.shift == 0 {
 			backToLong, err := actual.Int64()
@@ -98,6 +102,15 @@ func TestPrefixCoded(t *testing.T) {
 	}
 }
 
+func TestValidPrefixCodedTermInvalid(t *testing.T) {
+	invalid := []string{"", "hello", string([]byte{0x20, 0x1}), string([]byte{0x20, 0x80, 0, 0, 0, 0, 0, 0, 0, 0})}
+	for _, term := range invalid {
+		if valid, _ := ValidPrefixCodedTerm(term); valid {
+			t.Errorf("expected %q to be invalid", term)
+		}
+	}
+}
+
 func BenchmarkTestPrefixCoded(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
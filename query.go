@@ -12,9 +12,13 @@ package bleve
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searchers"
 )
 
 // A Query represents a description of the type
@@ -28,14 +32,117 @@ type Query interface {
 	Validate() error
 }
 
+// A SpanQuery is a Query matching spans of term
+// positions. Span queries can be nested to build
+// positional queries.
+type SpanQuery interface {
+	Query
+	SpanSearcher(i index.IndexReader, m *IndexMapping, explain bool) (searchers.SpanSearcher, error)
+}
+
+// queryTypes maps the names of query types, as set in the
+// "type" key of explicitly typed JSON queries, to a function
+// returning an empty query of the type.
+var queryTypes = map[string]func() Query{
+	"bool_field":       func() Query { return &boolFieldQuery{} },
+	"boolean":          func() Query { return &booleanQuery{} },
+	"conjunction":      func() Query { return &conjunctionQuery{} },
+	"date_range":       func() Query { return &dateRangeQuery{} },
+	"disjunction":      func() Query { return &disjunctionQuery{} },
+	"docid":            func() Query { return &docIDQuery{} },
+	"function_score":   func() Query { return &functionScoreQuery{} },
+	"fuzzy":            func() Query { return &fuzzyQuery{} },
+	"geo_bounding_box": func() Query { return &geoBoundingBoxQuery{} },
+	"geo_distance":     func() Query { return &geoDistanceQuery{} },
+	"match":            func() Query { return &matchQuery{} },
+	"match_all":        func() Query { return &matchAllQuery{} },
+	"match_none":       func() Query { return &matchNoneQuery{} },
+	"match_phrase":     func() Query { return &matchPhraseQuery{} },
+	"more_like_this":   func() Query { return &moreLikeThisQuery{} },
+	"numeric_range":    func() Query { return &numericRangeQuery{} },
+	"phrase":           func() Query { return &phraseQuery{} },
+	"prefix":           func() Query { return &prefixQuery{} },
+	"query_string":     func() Query { return &queryStringQuery{} },
+	"regexp":           func() Query { return &regexpQuery{} },
+	"span_first":       func() Query { return &spanFirstQuery{} },
+	"span_near":        func() Query { return &spanNearQuery{} },
+	"span_not":         func() Query { return &spanNotQuery{} },
+	"span_or":          func() Query { return &spanOrQuery{} },
+	"span_term":        func() Query { return &spanTermQuery{} },
+	"term":             func() Query { return &termQuery{} },
+	"term_range":       func() Query { return &termRangeQuery{} },
+	"wildcard":         func() Query { return &wildcardQuery{} },
+}
+
+// marshalQuery serializes fields, the JSON object of a
+// query, adding typ, the type of the query, to it.
+func marshalQuery(typ string, fields interface{}) ([]byte, error) {
+	typeJSON, err := json.Marshal(typ)
+	if err != nil {
+		return nil, err
+	}
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	rv := append([]byte(`{"type":`), typeJSON...)
+	if len(fieldsJSON) > 2 {
+		rv = append(rv, ',')
+	}
+	return append(rv, fieldsJSON[1:]...), nil
+}
+
+// A QueryPathError is returned when parsing a JSON query
+// fails on one of its clauses. Path locates the first
+// invalid clause within the query, as in
+// "must.conjuncts[1]".
+type QueryPathError struct {
+	Path string
+	Err  error
+}
+
+func (e *QueryPathError) Error() string {
+	return fmt.Sprintf("invalid query at %s: %v", e.Path, e.Err)
+}
+
+// queryPathError returns err, the error of the clause at
+// path, with the path of the clause prepended to its own.
+func queryPathError(path string, err error) error {
+	if perr, ok := err.(*QueryPathError); ok {
+		if !strings.HasPrefix(perr.Path, "[") {
+			path += "."
+		}
+		return &QueryPathError{Path: path + perr.Path, Err: perr.Err}
+	}
+	return &QueryPathError{Path: path, Err: err}
+}
+
+// parseQueryAt deserializes the JSON representation of
+// the clause at path within a compound query.
+func parseQueryAt(path string, input []byte) (Query, error) {
+	rv, err := ParseQuery(input)
+	if err != nil {
+		return nil, queryPathError(path, err)
+	}
+	return rv, nil
+}
+
 // ParseQuery deserializes a JSON representation of
 // a Query object.
+//
+// The type of the query is taken from its "type" key, as
+// written when marshaling a Query. Without it, the type is
+// guessed from the other keys, for example "match" or
+// "must".
 func ParseQuery(input []byte) (Query, error) {
 	var tmp map[string]interface{}
 	err := json.Unmarshal(input, &tmp)
 	if err != nil {
 		return nil, err
 	}
+	if typ, hasType := tmp["type"]; hasType {
+		return parseTypedQuery(typ, tmp, input)
+	}
 	_, isMatchQuery := tmp["match"]
 	_, hasFuzziness := tmp["fuzziness"]
 	if hasFuzziness && !isMatchQuery {
@@ -134,6 +241,91 @@ func ParseQuery(input []byte) (Query, error) {
 		}
 		return &rv, nil
 	}
+	_, isSpanTermQuery := tmp["span_term"]
+	if isSpanTermQuery {
+		var rv spanTermQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		if rv.Boost() == 0 {
+			rv.SetBoost(1)
+		}
+		return &rv, nil
+	}
+	_, isSpanNearQuery := tmp["span_near"]
+	if isSpanNearQuery {
+		var rv spanNearQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		if rv.Boost() == 0 {
+			rv.SetBoost(1)
+		}
+		return &rv, nil
+	}
+	_, isSpanOrQuery := tmp["span_or"]
+	if isSpanOrQuery {
+		var rv spanOrQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		if rv.Boost() == 0 {
+			rv.SetBoost(1)
+		}
+		return &rv, nil
+	}
+	_, isSpanNotQuery := tmp["span_not"]
+	if isSpanNotQuery {
+		var rv spanNotQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		if rv.Boost() == 0 {
+			rv.SetBoost(1)
+		}
+		return &rv, nil
+	}
+	_, isSpanFirstQuery := tmp["span_first"]
+	if isSpanFirstQuery {
+		var rv spanFirstQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		if rv.Boost() == 0 {
+			rv.SetBoost(1)
+		}
+		return &rv, nil
+	}
+	_, hasLike := tmp["like"]
+	_, hasLikeID := tmp["like_id"]
+	if hasLike || hasLikeID {
+		var rv moreLikeThisQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		if rv.Boost() == 0 {
+			rv.SetBoost(1)
+		}
+		return &rv, nil
+	}
+	_, hasFunctions := tmp["functions"]
+	if hasFunctions {
+		var rv functionScoreQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		if rv.Boost() == 0 {
+			rv.SetBoost(1)
+		}
+		return &rv, nil
+	}
 
 	_, hasSyntaxQuery := tmp["query"]
 	if hasSyntaxQuery {
@@ -149,6 +341,19 @@ func ParseQuery(input []byte) (Query, error) {
 	}
 	_, hasMin := tmp["min"]
 	_, hasMax := tmp["max"]
+	_, hasMinTerm := tmp["min"].(string)
+	_, hasMaxTerm := tmp["max"].(string)
+	if hasMinTerm || hasMaxTerm {
+		var rv termRangeQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		if rv.Boost() == 0 {
+			rv.SetBoost(1)
+		}
+		return &rv, nil
+	}
 	if hasMin || hasMax {
 		var rv numericRangeQuery
 		err := json.Unmarshal(input, &rv)
@@ -233,6 +438,32 @@ func ParseQuery(input []byte) (Query, error) {
 		}
 		return &rv, nil
 	}
+	_, hasTopLeft := tmp["top_left"]
+	_, hasBottomRight := tmp["bottom_right"]
+	if hasTopLeft && hasBottomRight {
+		var rv geoBoundingBoxQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		if rv.Boost() == 0 {
+			rv.SetBoost(1)
+		}
+		return &rv, nil
+	}
+	_, hasLocation := tmp["location"]
+	_, hasDistance := tmp["distance"]
+	if hasLocation && hasDistance {
+		var rv geoDistanceQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		if rv.Boost() == 0 {
+			rv.SetBoost(1)
+		}
+		return &rv, nil
+	}
 	_, hasDocIds := tmp["ids"]
 	if hasDocIds {
 		var rv docIDQuery
@@ -248,6 +479,119 @@ func ParseQuery(input []byte) (Query, error) {
 	return nil, ErrorUnknownQueryType
 }
 
+// parseTypedQuery deserializes the JSON representation
+// of a query of type typ, made of the keys of tmp, which
+// must all belong to the type.
+func parseTypedQuery(typ interface{}, tmp map[string]interface{}, input []byte) (Query, error) {
+	name, _ := typ.(string)
+	newQuery, ok := queryTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown query type '%v'", typ)
+	}
+	rv := newQuery()
+	keys := queryKeys(rv)
+	unknown := make([]string, 0)
+	for key := range tmp {
+		if !keys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, &QueryPathError{
+			Path: unknown[0],
+			Err:  fmt.Errorf("unknown key for query type '%s'", name),
+		}
+	}
+	err := json.Unmarshal(input, rv)
+	if err != nil {
+		return nil, err
+	}
+	if rv.Boost() == 0 {
+		rv.SetBoost(1)
+	}
+	return rv, nil
+}
+
+// queryKeys returns the keys of the JSON representation
+// of q, its type and the fields of its struct.
+func queryKeys(q Query) map[string]bool {
+	rv := map[string]bool{
+		"type": true,
+	}
+	switch q.(type) {
+	case *matchAllQuery:
+		rv["match_all"] = true
+	case *matchNoneQuery:
+		rv["match_none"] = true
+	}
+	t := reflect.TypeOf(q).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			rv[name] = true
+		}
+	}
+	return rv
+}
+
+// parseSpanQuery deserializes the JSON representation of
+// the SpanQuery at path within a span query.
+func parseSpanQuery(path string, input []byte) (SpanQuery, error) {
+	q, err := parseQueryAt(path, input)
+	if err != nil {
+		return nil, err
+	}
+	rv, ok := q.(SpanQuery)
+	if !ok {
+		return nil, queryPathError(path, fmt.Errorf("span queries can only contain span queries, got %T", q))
+	}
+	return rv, nil
+}
+
+func parseSpanQueries(path string, inputs []json.RawMessage) ([]SpanQuery, error) {
+	rv := make([]SpanQuery, len(inputs))
+	for i, input := range inputs {
+		var err error
+		rv[i], err = parseSpanQuery(fmt.Sprintf("%s[%d]", path, i), input)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+// spanSearchers returns the span searchers of queries.
+func spanSearchers(queries []SpanQuery, i index.IndexReader, m *IndexMapping, explain bool) ([]searchers.SpanSearcher, error) {
+	rv := make([]searchers.SpanSearcher, len(queries))
+	for in, query := range queries {
+		var err error
+		rv[in], err = query.SpanSearcher(i, m, explain)
+		if err != nil {
+			for _, searcher := range rv[:in] {
+				_ = searcher.Close()
+			}
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+// validateSpanQueries validates queries and checks they
+// all search the same field.
+func validateSpanQueries(queries ...SpanQuery) error {
+	for _, query := range queries {
+		err := query.Validate()
+		if err != nil {
+			return err
+		}
+		if query.Field() != queries[0].Field() {
+			return ErrorSpanQueryFieldMismatch
+		}
+	}
+	return nil
+}
+
 // expandQuery traverses the input query tree and returns a new tree where
 // query string queries have been expanded into base queries. Returned tree may
 // reference queries from the input tree or new queries.
@@ -271,7 +615,7 @@ func expandQuery(m *IndexMapping, query Query) (Query, error) {
 		switch query.(type) {
 		case *queryStringQuery:
 			q := query.(*queryStringQuery)
-			parsed, err := parseQuerySyntax(q.Query)
+			parsed, err := q.parse()
 			if err != nil {
 				return nil, fmt.Errorf("could not parse '%s': %s", q.Query, err)
 			}
@@ -56,9 +56,18 @@ func (q *boolFieldQuery) Searcher(i index.IndexReader, m *IndexMapping, explain
 	if q.Bool {
 		term = "T"
 	}
-	return searchers.NewTermSearcher(i, term, field, q.BoostVal, explain)
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewTermSearcherWithSimilarity(i, term, field, q.BoostVal, similarity, explain)
 }
 
 func (q *boolFieldQuery) Validate() error {
 	return nil
 }
+
+func (q *boolFieldQuery) MarshalJSON() ([]byte, error) {
+	type _boolFieldQuery boolFieldQuery
+	return marshalQuery("bool_field", (*_boolFieldQuery)(q))
+}
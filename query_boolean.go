@@ -65,6 +65,28 @@ func (q *booleanQuery) SetMinShould(minShould float64) {
 	q.Should.(*disjunctionQuery).SetMin(minShould)
 }
 
+// SetMinimumShouldMatch requires that the number of
+// should Queries satisfied matches spec, as described
+// by the disjunction SetMinimumShouldMatch. It can be
+// set before adding the should Queries.
+func (q *booleanQuery) SetMinimumShouldMatch(spec string) Query {
+	if q.Should == nil {
+		q.Should = NewDisjunctionQuery([]Query{})
+	}
+	q.Should.(*disjunctionQuery).SetMinimumShouldMatch(spec)
+	return q
+}
+
+// hasShould returns whether q has should Queries, the
+// disjunction created by SetMinimumShouldMatch being
+// empty until some are added.
+func (q *booleanQuery) hasShould() bool {
+	if should, ok := q.Should.(*disjunctionQuery); ok {
+		return len(should.Disjuncts) > 0
+	}
+	return q.Should != nil
+}
+
 func (q *booleanQuery) AddMust(m Query) {
 	if q.Must == nil {
 		q.Must = NewConjunctionQuery([]Query{})
@@ -103,7 +125,7 @@ func (q *booleanQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bo
 		if err != nil {
 			return nil, err
 		}
-		if q.Must == nil && q.Should == nil {
+		if q.Must == nil && !q.hasShould() {
 			q.Must = NewMatchAllQuery()
 		}
 	}
@@ -117,7 +139,7 @@ func (q *booleanQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bo
 	}
 
 	var shouldSearcher search.Searcher
-	if q.Should != nil {
+	if q.hasShould() {
 		shouldSearcher, err = q.Should.Searcher(i, m, explain)
 		if err != nil {
 			return nil, err
@@ -145,7 +167,7 @@ func (q *booleanQuery) Validate() error {
 			return err
 		}
 	}
-	if q.Must == nil && q.Should == nil && q.MustNot == nil {
+	if q.Must == nil && !q.hasShould() && q.MustNot == nil {
 		return ErrorBooleanQueryNeedsMustOrShouldOrNotMust
 	}
 	return nil
@@ -164,35 +186,35 @@ func (q *booleanQuery) UnmarshalJSON(data []byte) error {
 	}
 
 	if tmp.Must != nil {
-		q.Must, err = ParseQuery(tmp.Must)
+		q.Must, err = parseQueryAt("must", tmp.Must)
 		if err != nil {
 			return err
 		}
 		_, isConjunctionQuery := q.Must.(*conjunctionQuery)
 		if !isConjunctionQuery {
-			return fmt.Errorf("must clause must be conjunction")
+			return queryPathError("must", fmt.Errorf("must clause must be conjunction"))
 		}
 	}
 
 	if tmp.Should != nil {
-		q.Should, err = ParseQuery(tmp.Should)
+		q.Should, err = parseQueryAt("should", tmp.Should)
 		if err != nil {
 			return err
 		}
 		_, isDisjunctionQuery := q.Should.(*disjunctionQuery)
 		if !isDisjunctionQuery {
-			return fmt.Errorf("should clause must be disjunction")
+			return queryPathError("should", fmt.Errorf("should clause must be disjunction"))
 		}
 	}
 
 	if tmp.MustNot != nil {
-		q.MustNot, err = ParseQuery(tmp.MustNot)
+		q.MustNot, err = parseQueryAt("must_not", tmp.MustNot)
 		if err != nil {
 			return err
 		}
 		_, isDisjunctionQuery := q.MustNot.(*disjunctionQuery)
 		if !isDisjunctionQuery {
-			return fmt.Errorf("must not clause must be disjunction")
+			return queryPathError("must_not", fmt.Errorf("must not clause must be disjunction"))
 		}
 	}
 
@@ -210,3 +232,8 @@ func (q *booleanQuery) Field() string {
 func (q *booleanQuery) SetField(f string) Query {
 	return q
 }
+
+func (q *booleanQuery) MarshalJSON() ([]byte, error) {
+	type _booleanQuery booleanQuery
+	return marshalQuery("boolean", (*_booleanQuery)(q))
+}
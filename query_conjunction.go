@@ -11,6 +11,7 @@ package bleve
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/search"
@@ -78,7 +79,7 @@ func (q *conjunctionQuery) UnmarshalJSON(data []byte) error {
 	}
 	q.Conjuncts = make([]Query, len(tmp.Conjuncts))
 	for i, term := range tmp.Conjuncts {
-		query, err := ParseQuery(term)
+		query, err := parseQueryAt(fmt.Sprintf("conjuncts[%d]", i), term)
 		if err != nil {
 			return err
 		}
@@ -98,3 +99,8 @@ func (q *conjunctionQuery) Field() string {
 func (q *conjunctionQuery) SetField(f string) Query {
 	return q
 }
+
+func (q *conjunctionQuery) MarshalJSON() ([]byte, error) {
+	type _conjunctionQuery conjunctionQuery
+	return marshalQuery("conjunction", (*_conjunctionQuery)(q))
+}
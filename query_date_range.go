@@ -83,7 +83,11 @@ func (q *dateRangeQuery) Searcher(i index.IndexReader, m *IndexMapping, explain
 		field = m.DefaultField
 	}
 
-	return searchers.NewNumericRangeSearcher(i, min, max, q.InclusiveStart, q.InclusiveEnd, field, q.BoostVal, explain)
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewNumericRangeSearcherWithSimilarity(i, min, max, q.InclusiveStart, q.InclusiveEnd, field, q.BoostVal, similarity, explain)
 }
 
 func (q *dateRangeQuery) parseEndpoints() (*float64, *float64, error) {
@@ -123,3 +127,8 @@ func (q *dateRangeQuery) Validate() error {
 	}
 	return nil
 }
+
+func (q *dateRangeQuery) MarshalJSON() ([]byte, error) {
+	type _dateRangeQuery dateRangeQuery
+	return marshalQuery("date_range", (*_dateRangeQuery)(q))
+}
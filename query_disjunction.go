@@ -11,6 +11,7 @@ package bleve
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/search"
@@ -18,9 +19,10 @@ import (
 )
 
 type disjunctionQuery struct {
-	Disjuncts []Query `json:"disjuncts"`
-	BoostVal  float64 `json:"boost,omitempty"`
-	MinVal    float64 `json:"min"`
+	Disjuncts          []Query `json:"disjuncts"`
+	BoostVal           float64 `json:"boost,omitempty"`
+	MinVal             float64 `json:"min"`
+	MinimumShouldMatch string  `json:"minimum_should_match,omitempty"`
 }
 
 // NewDisjunctionQuery creates a new compound Query.
@@ -65,20 +67,49 @@ func (q *disjunctionQuery) SetMin(m float64) Query {
 	return q
 }
 
+// SetMinimumShouldMatch sets how many of the Queries
+// result documents must satisfy relatively to their
+// number, overriding the min. The spec is an absolute
+// number, "3", or the number which may not be satisfied,
+// "-2", a percentage, "75%" or "-25%", or conditions
+// such as "3<75%", requiring all the Queries up to 3 of
+// them and 75% above.
+func (q *disjunctionQuery) SetMinimumShouldMatch(spec string) Query {
+	q.MinimumShouldMatch = spec
+	return q
+}
+
+// minClauses returns how many of the Queries
+// result documents must satisfy.
+func (q *disjunctionQuery) minClauses() (float64, error) {
+	if q.MinimumShouldMatch == "" {
+		return q.MinVal, nil
+	}
+	min, err := minShouldMatch(q.MinimumShouldMatch, len(q.Disjuncts))
+	return float64(min), err
+}
+
 func (q *disjunctionQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	min, err := q.minClauses()
+	if err != nil {
+		return nil, err
+	}
 	ss := make([]search.Searcher, len(q.Disjuncts))
 	for in, disjunct := range q.Disjuncts {
-		var err error
 		ss[in], err = disjunct.Searcher(i, m, explain)
 		if err != nil {
 			return nil, err
 		}
 	}
-	return searchers.NewDisjunctionSearcher(i, ss, q.MinVal, explain)
+	return searchers.NewDisjunctionSearcher(i, ss, min, explain)
 }
 
 func (q *disjunctionQuery) Validate() error {
-	if int(q.MinVal) > len(q.Disjuncts) {
+	min, err := q.minClauses()
+	if err != nil {
+		return err
+	}
+	if int(min) > len(q.Disjuncts) {
 		return ErrorDisjunctionFewerThanMinClauses
 	}
 	for _, q := range q.Disjuncts {
@@ -92,9 +123,10 @@ func (q *disjunctionQuery) Validate() error {
 
 func (q *disjunctionQuery) UnmarshalJSON(data []byte) error {
 	tmp := struct {
-		Disjuncts []json.RawMessage `json:"disjuncts"`
-		BoostVal  float64           `json:"boost,omitempty"`
-		MinVal    float64           `json:"min"`
+		Disjuncts          []json.RawMessage `json:"disjuncts"`
+		BoostVal           float64           `json:"boost,omitempty"`
+		MinVal             float64           `json:"min"`
+		MinimumShouldMatch string            `json:"minimum_should_match,omitempty"`
 	}{}
 	err := json.Unmarshal(data, &tmp)
 	if err != nil {
@@ -102,7 +134,7 @@ func (q *disjunctionQuery) UnmarshalJSON(data []byte) error {
 	}
 	q.Disjuncts = make([]Query, len(tmp.Disjuncts))
 	for i, term := range tmp.Disjuncts {
-		query, err := ParseQuery(term)
+		query, err := parseQueryAt(fmt.Sprintf("disjuncts[%d]", i), term)
 		if err != nil {
 			return err
 		}
@@ -113,6 +145,7 @@ func (q *disjunctionQuery) UnmarshalJSON(data []byte) error {
 		q.BoostVal = 1
 	}
 	q.MinVal = tmp.MinVal
+	q.MinimumShouldMatch = tmp.MinimumShouldMatch
 	return nil
 }
 
@@ -123,3 +156,8 @@ func (q *disjunctionQuery) Field() string {
 func (q *disjunctionQuery) SetField(f string) Query {
 	return q
 }
+
+func (q *disjunctionQuery) MarshalJSON() ([]byte, error) {
+	type _disjunctionQuery disjunctionQuery
+	return marshalQuery("disjunction", (*_disjunctionQuery)(q))
+}
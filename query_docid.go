@@ -54,3 +54,8 @@ func (q *docIDQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool
 func (q *docIDQuery) Validate() error {
 	return nil
 }
+
+func (q *docIDQuery) MarshalJSON() ([]byte, error) {
+	type _docIDQuery docIDQuery
+	return marshalQuery("docid", (*_docIDQuery)(q))
+}
@@ -0,0 +1,363 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searchers"
+)
+
+// FieldValueFactorFunction scores documents with
+// Modifier(Factor * value) where value is the smallest value of the numeric
+// Field. Modifier is one of none (the default), log, log1p, log2p, ln,
+// ln1p, ln2p, square, sqrt and reciprocal. Documents without a value use
+// Missing, or are left out of the function when it is nil.
+type FieldValueFactorFunction struct {
+	Field    string   `json:"field"`
+	Factor   float64  `json:"factor,omitempty"`
+	Modifier string   `json:"modifier,omitempty"`
+	Missing  *float64 `json:"missing,omitempty"`
+}
+
+// DecayFunction scores documents by the distance of the closest value of
+// Field to Origin: 1 up to Offset, then decaying to Decay (0.5 when not
+// set) at Offset+Scale, following the "gauss", "linear" or "exp" Function.
+// For numeric fields, Origin, Scale and Offset are numbers. For date
+// fields, Origin is a date string, or "now", and Scale and Offset are
+// durations like "36h" or "10d".
+type DecayFunction struct {
+	Function string      `json:"function"`
+	Field    string      `json:"field"`
+	Origin   interface{} `json:"origin"`
+	Scale    interface{} `json:"scale"`
+	Offset   interface{} `json:"offset,omitempty"`
+	Decay    float64     `json:"decay,omitempty"`
+}
+
+// RandomScoreFunction gives each document a random score in [0, 1), the
+// same for all the searches using the same Seed.
+type RandomScoreFunction struct {
+	Seed int64 `json:"seed"`
+}
+
+// A ScoreFunction computes a value for the documents matched by a
+// FunctionScoreQuery. At most one of FieldValueFactor, Decay and
+// RandomScore is set, Weight multiplies the value of the function. A
+// ScoreFunction with only a Weight gives that value to all documents.
+type ScoreFunction struct {
+	FieldValueFactor *FieldValueFactorFunction `json:"field_value_factor,omitempty"`
+	Decay            *DecayFunction            `json:"decay,omitempty"`
+	RandomScore      *RandomScoreFunction      `json:"random_score,omitempty"`
+	Weight           float64                   `json:"weight,omitempty"`
+}
+
+// NewFieldValueFactorFunction creates a ScoreFunction using the values
+// of a numeric field, see FieldValueFactorFunction.
+func NewFieldValueFactorFunction(field string, factor float64, modifier string) *ScoreFunction {
+	return &ScoreFunction{
+		FieldValueFactor: &FieldValueFactorFunction{
+			Field:    field,
+			Factor:   factor,
+			Modifier: modifier,
+		},
+	}
+}
+
+// NewNumericDecayFunction creates a ScoreFunction decaying with the
+// distance of the values of a numeric field to origin, see DecayFunction.
+func NewNumericDecayFunction(function, field string, origin, scale, offset float64) *ScoreFunction {
+	return &ScoreFunction{
+		Decay: &DecayFunction{
+			Function: function,
+			Field:    field,
+			Origin:   origin,
+			Scale:    scale,
+			Offset:   offset,
+		},
+	}
+}
+
+// NewDateTimeDecayFunction creates a ScoreFunction decaying with the
+// distance of the values of a date field to origin, see DecayFunction.
+func NewDateTimeDecayFunction(function, field string, origin string, scale, offset string) *ScoreFunction {
+	return &ScoreFunction{
+		Decay: &DecayFunction{
+			Function: function,
+			Field:    field,
+			Origin:   origin,
+			Scale:    scale,
+			Offset:   offset,
+		},
+	}
+}
+
+// NewRandomScoreFunction creates a ScoreFunction giving random values
+// to documents, see RandomScoreFunction.
+func NewRandomScoreFunction(seed int64) *ScoreFunction {
+	return &ScoreFunction{
+		RandomScore: &RandomScoreFunction{
+			Seed: seed,
+		},
+	}
+}
+
+// NewWeightFunction creates a ScoreFunction giving the same value to all
+// documents.
+func NewWeightFunction(weight float64) *ScoreFunction {
+	return &ScoreFunction{
+		Weight: weight,
+	}
+}
+
+func (f *ScoreFunction) SetWeight(weight float64) *ScoreFunction {
+	f.Weight = weight
+	return f
+}
+
+func (f *ScoreFunction) searcherFunction() (searchers.ScoreFunction, error) {
+	var rv searchers.ScoreFunction
+	var err error
+	functions := 0
+	if f.FieldValueFactor != nil {
+		functions++
+		factor := f.FieldValueFactor.Factor
+		if factor == 0 {
+			factor = 1
+		}
+		rv, err = searchers.NewFieldValueFactorFunction(f.FieldValueFactor.Field, factor, f.FieldValueFactor.Modifier, f.FieldValueFactor.Missing)
+	}
+	if f.Decay != nil {
+		functions++
+		rv, err = f.Decay.searcherFunction()
+	}
+	if f.RandomScore != nil {
+		functions++
+		rv = searchers.NewRandomScoreFunction(f.RandomScore.Seed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if functions > 1 {
+		return nil, fmt.Errorf("score function can only have one of field_value_factor, decay and random_score")
+	}
+	if f.Weight != 0 {
+		return searchers.NewWeightFunction(rv, f.Weight), nil
+	}
+	if rv == nil {
+		return nil, fmt.Errorf("score function must have a function or a weight")
+	}
+	return rv, nil
+}
+
+func (f *DecayFunction) searcherFunction() (searchers.ScoreFunction, error) {
+	decay := f.Decay
+	if decay == 0 {
+		decay = 0.5
+	}
+
+	if originString, ok := f.Origin.(string); ok {
+		var origin time.Time
+		if originString == "now" {
+			origin = time.Now()
+		} else {
+			dateTimeParser, err := Config.Cache.DateTimeParserNamed(Config.QueryDateTimeParser)
+			if err != nil {
+				return nil, err
+			}
+			origin, err = dateTimeParser.ParseDateTime(originString)
+			if err != nil {
+				return nil, err
+			}
+		}
+		scale, err := parseDecayDuration(f.Scale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decay scale: %v", err)
+		}
+		offset, err := parseDecayDuration(f.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decay offset: %v", err)
+		}
+		return searchers.NewDateTimeDecayFunction(f.Function, f.Field, origin, scale, offset, decay)
+	}
+
+	origin, ok := f.Origin.(float64)
+	if !ok {
+		return nil, fmt.Errorf("decay origin must be a number or a date string")
+	}
+	scale, ok := f.Scale.(float64)
+	if !ok {
+		return nil, fmt.Errorf("numeric decay scale must be a number")
+	}
+	var offset float64
+	if f.Offset != nil {
+		offset, ok = f.Offset.(float64)
+		if !ok {
+			return nil, fmt.Errorf("numeric decay offset must be a number")
+		}
+	}
+	return searchers.NewDecayFunction(f.Function, f.Field, origin, scale, offset, decay)
+}
+
+// parseDecayDuration parses a time.Duration string, also accepting a
+// number of days like "10d".
+func parseDecayDuration(d interface{}) (time.Duration, error) {
+	if d == nil {
+		return 0, nil
+	}
+	s, ok := d.(string)
+	if !ok {
+		return 0, fmt.Errorf("date durations must be strings")
+	}
+	if s == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.ParseFloat(s[:len(s)-1], 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+type functionScoreQuery struct {
+	Query     Query            `json:"query"`
+	Functions []*ScoreFunction `json:"functions"`
+	ScoreMode string           `json:"score_mode,omitempty"`
+	BoostMode string           `json:"boost_mode,omitempty"`
+	BoostVal  float64          `json:"boost,omitempty"`
+}
+
+// NewFunctionScoreQuery creates a new Query matching the same documents
+// as query, but scoring them with the score functions added to it.
+// The score mode combines the values of the functions applying to a
+// document: "multiply" (the default), "sum", "avg", "first", "max" or
+// "min". The boost mode then combines this with the score of the query:
+// "multiply" (the default), "replace", "sum", "avg", "max" or "min".
+func NewFunctionScoreQuery(query Query) *functionScoreQuery {
+	return &functionScoreQuery{
+		Query:    query,
+		BoostVal: 1.0,
+	}
+}
+
+func (q *functionScoreQuery) Boost() float64 {
+	return q.BoostVal
+}
+
+func (q *functionScoreQuery) SetBoost(b float64) Query {
+	q.BoostVal = b
+	return q
+}
+
+func (q *functionScoreQuery) AddFunction(f *ScoreFunction) *functionScoreQuery {
+	q.Functions = append(q.Functions, f)
+	return q
+}
+
+func (q *functionScoreQuery) SetScoreMode(mode string) *functionScoreQuery {
+	q.ScoreMode = mode
+	return q
+}
+
+func (q *functionScoreQuery) SetBoostMode(mode string) *functionScoreQuery {
+	q.BoostMode = mode
+	return q
+}
+
+func (q *functionScoreQuery) searcherFunctions() ([]searchers.ScoreFunction, error) {
+	rv := make([]searchers.ScoreFunction, len(q.Functions))
+	for i, f := range q.Functions {
+		var err error
+		rv[i], err = f.searcherFunction()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+func (q *functionScoreQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	functions, err := q.searcherFunctions()
+	if err != nil {
+		return nil, err
+	}
+	err = searchers.ValidateFunctionScoreModes(q.ScoreMode, q.BoostMode)
+	if err != nil {
+		return nil, err
+	}
+	searcher, err := q.Query.Searcher(i, m, explain)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewFunctionScoreSearcher(i, searcher, functions, q.ScoreMode, q.BoostMode, q.BoostVal, explain)
+}
+
+func (q *functionScoreQuery) Validate() error {
+	if q.Query == nil {
+		return fmt.Errorf("function score query must have a query")
+	}
+	err := q.Query.Validate()
+	if err != nil {
+		return err
+	}
+	_, err = q.searcherFunctions()
+	if err != nil {
+		return err
+	}
+	return searchers.ValidateFunctionScoreModes(q.ScoreMode, q.BoostMode)
+}
+
+func (q *functionScoreQuery) UnmarshalJSON(data []byte) error {
+	tmp := struct {
+		Query     json.RawMessage  `json:"query"`
+		Functions []*ScoreFunction `json:"functions"`
+		ScoreMode string           `json:"score_mode,omitempty"`
+		BoostMode string           `json:"boost_mode,omitempty"`
+		BoostVal  float64          `json:"boost,omitempty"`
+	}{}
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+	q.Query, err = parseQueryAt("query", tmp.Query)
+	if err != nil {
+		return err
+	}
+	q.Functions = tmp.Functions
+	q.ScoreMode = tmp.ScoreMode
+	q.BoostMode = tmp.BoostMode
+	q.BoostVal = tmp.BoostVal
+	if q.BoostVal == 0 {
+		q.BoostVal = 1
+	}
+	return nil
+}
+
+func (q *functionScoreQuery) Field() string {
+	return ""
+}
+
+func (q *functionScoreQuery) SetField(f string) Query {
+	return q
+}
+
+func (q *functionScoreQuery) MarshalJSON() ([]byte, error) {
+	type _functionScoreQuery functionScoreQuery
+	return marshalQuery("function_score", (*_functionScoreQuery)(q))
+}
@@ -80,9 +80,18 @@ func (q *fuzzyQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool
 	if q.FieldVal == "" {
 		field = m.DefaultField
 	}
-	return searchers.NewFuzzySearcher(i, q.Term, q.PrefixVal, q.FuzzinessVal, field, q.BoostVal, explain)
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewFuzzySearcherWithSimilarity(i, q.Term, q.PrefixVal, q.FuzzinessVal, field, q.BoostVal, similarity, explain)
 }
 
 func (q *fuzzyQuery) Validate() error {
 	return nil
 }
+
+func (q *fuzzyQuery) MarshalJSON() ([]byte, error) {
+	type _fuzzyQuery fuzzyQuery
+	return marshalQuery("fuzzy", (*_fuzzyQuery)(q))
+}
@@ -0,0 +1,123 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/blevesearch/bleve/geo"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searchers"
+)
+
+type geoBoundingBoxQuery struct {
+	TopLeft     []float64 `json:"top_left,omitempty"`
+	BottomRight []float64 `json:"bottom_right,omitempty"`
+	FieldVal    string    `json:"field,omitempty"`
+	BoostVal    float64   `json:"boost,omitempty"`
+}
+
+// NewGeoBoundingBoxQuery creates a new Query for performing
+// geo bounding box searches. The arguments describe the position
+// of the box and documents which have an indexed geo point inside
+// the box will be returned.
+// A top left longitude greater than the bottom right longitude
+// describes a box crossing the date line.
+func NewGeoBoundingBoxQuery(topLeftLon, topLeftLat, bottomRightLon, bottomRightLat float64) *geoBoundingBoxQuery {
+	return &geoBoundingBoxQuery{
+		TopLeft:     []float64{topLeftLon, topLeftLat},
+		BottomRight: []float64{bottomRightLon, bottomRightLat},
+		BoostVal:    1.0,
+	}
+}
+
+func (q *geoBoundingBoxQuery) Boost() float64 {
+	return q.BoostVal
+}
+
+func (q *geoBoundingBoxQuery) SetBoost(b float64) Query {
+	q.BoostVal = b
+	return q
+}
+
+func (q *geoBoundingBoxQuery) Field() string {
+	return q.FieldVal
+}
+
+func (q *geoBoundingBoxQuery) SetField(f string) Query {
+	q.FieldVal = f
+	return q
+}
+
+func (q *geoBoundingBoxQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	field := q.FieldVal
+	if q.FieldVal == "" {
+		field = m.DefaultField
+	}
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewGeoBoundingBoxSearcherWithSimilarity(i, q.TopLeft[0], q.BottomRight[1], q.BottomRight[0], q.TopLeft[1], field, q.BoostVal, similarity, explain)
+}
+
+func (q *geoBoundingBoxQuery) Validate() error {
+	if len(q.TopLeft) != 2 || len(q.BottomRight) != 2 {
+		return fmt.Errorf("geo bounding box query must specify top_left and bottom_right")
+	}
+	for _, point := range [][]float64{q.TopLeft, q.BottomRight} {
+		err := geo.CheckLongitude(point[0])
+		if err != nil {
+			return err
+		}
+		err = geo.CheckLatitude(point[1])
+		if err != nil {
+			return err
+		}
+	}
+	if q.TopLeft[1] < q.BottomRight[1] {
+		return fmt.Errorf("geo bounding box top_left latitude must not be below bottom_right latitude")
+	}
+	return nil
+}
+
+func (q *geoBoundingBoxQuery) UnmarshalJSON(data []byte) error {
+	tmp := struct {
+		TopLeft     interface{} `json:"top_left,omitempty"`
+		BottomRight interface{} `json:"bottom_right,omitempty"`
+		FieldVal    string      `json:"field,omitempty"`
+		BoostVal    float64     `json:"boost,omitempty"`
+	}{}
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+	// now use our generic point parsing code from the geo package
+	lon, lat, found := geo.ExtractGeoPoint(tmp.TopLeft)
+	if !found {
+		return fmt.Errorf("geo location top_left not in a valid format")
+	}
+	q.TopLeft = []float64{lon, lat}
+	lon, lat, found = geo.ExtractGeoPoint(tmp.BottomRight)
+	if !found {
+		return fmt.Errorf("geo location bottom_right not in a valid format")
+	}
+	q.BottomRight = []float64{lon, lat}
+	q.FieldVal = tmp.FieldVal
+	q.BoostVal = tmp.BoostVal
+	return nil
+}
+
+func (q *geoBoundingBoxQuery) MarshalJSON() ([]byte, error) {
+	type _geoBoundingBoxQuery geoBoundingBoxQuery
+	return marshalQuery("geo_bounding_box", (*_geoBoundingBoxQuery)(q))
+}
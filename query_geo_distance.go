@@ -0,0 +1,120 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/blevesearch/bleve/geo"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searchers"
+)
+
+type geoDistanceQuery struct {
+	Location []float64 `json:"location,omitempty"`
+	Distance string    `json:"distance,omitempty"`
+	FieldVal string    `json:"field,omitempty"`
+	BoostVal float64   `json:"boost,omitempty"`
+}
+
+// NewGeoDistanceQuery creates a new Query for performing
+// geo distance searches. The arguments describe a position
+// and a distance. Documents which have an indexed geo point
+// which is less than or equal to the provided distance from
+// the given position will be returned.
+// The distance is a number followed by a unit, for example
+// "5km" or "10mi", meters are assumed when no unit is given.
+func NewGeoDistanceQuery(lon, lat float64, distance string) *geoDistanceQuery {
+	return &geoDistanceQuery{
+		Location: []float64{lon, lat},
+		Distance: distance,
+		BoostVal: 1.0,
+	}
+}
+
+func (q *geoDistanceQuery) Boost() float64 {
+	return q.BoostVal
+}
+
+func (q *geoDistanceQuery) SetBoost(b float64) Query {
+	q.BoostVal = b
+	return q
+}
+
+func (q *geoDistanceQuery) Field() string {
+	return q.FieldVal
+}
+
+func (q *geoDistanceQuery) SetField(f string) Query {
+	q.FieldVal = f
+	return q
+}
+
+func (q *geoDistanceQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	field := q.FieldVal
+	if q.FieldVal == "" {
+		field = m.DefaultField
+	}
+	dist, err := geo.ParseDistance(q.Distance)
+	if err != nil {
+		return nil, err
+	}
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewGeoPointDistanceSearcherWithSimilarity(i, q.Location[0], q.Location[1], dist, field, q.BoostVal, similarity, explain)
+}
+
+func (q *geoDistanceQuery) Validate() error {
+	if len(q.Location) != 2 {
+		return fmt.Errorf("geo distance query must specify a location")
+	}
+	err := geo.CheckLongitude(q.Location[0])
+	if err != nil {
+		return err
+	}
+	err = geo.CheckLatitude(q.Location[1])
+	if err != nil {
+		return err
+	}
+	_, err = geo.ParseDistance(q.Distance)
+	return err
+}
+
+func (q *geoDistanceQuery) UnmarshalJSON(data []byte) error {
+	tmp := struct {
+		Location interface{} `json:"location,omitempty"`
+		Distance string      `json:"distance,omitempty"`
+		FieldVal string      `json:"field,omitempty"`
+		BoostVal float64     `json:"boost,omitempty"`
+	}{}
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+	// now use our generic point parsing code from the geo package
+	lon, lat, found := geo.ExtractGeoPoint(tmp.Location)
+	if !found {
+		return fmt.Errorf("geo location not in a valid format")
+	}
+	q.Location = []float64{lon, lat}
+	q.Distance = tmp.Distance
+	q.FieldVal = tmp.FieldVal
+	q.BoostVal = tmp.BoostVal
+	return nil
+}
+
+func (q *geoDistanceQuery) MarshalJSON() ([]byte, error) {
+	type _geoDistanceQuery geoDistanceQuery
+	return marshalQuery("geo_distance", (*_geoDistanceQuery)(q))
+}
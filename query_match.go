@@ -16,13 +16,21 @@ import (
 	"github.com/blevesearch/bleve/search"
 )
 
+// Operators combining the terms of a match query.
+const (
+	MatchQueryOperatorOr  = "or"
+	MatchQueryOperatorAnd = "and"
+)
+
 type matchQuery struct {
-	Match        string  `json:"match"`
-	FieldVal     string  `json:"field,omitempty"`
-	Analyzer     string  `json:"analyzer,omitempty"`
-	BoostVal     float64 `json:"boost,omitempty"`
-	PrefixVal    int     `json:"prefix_length"`
-	FuzzinessVal int     `json:"fuzziness"`
+	Match              string  `json:"match"`
+	FieldVal           string  `json:"field,omitempty"`
+	Analyzer           string  `json:"analyzer,omitempty"`
+	BoostVal           float64 `json:"boost,omitempty"`
+	PrefixVal          int     `json:"prefix_length"`
+	FuzzinessVal       int     `json:"fuzziness"`
+	Operator           string  `json:"operator,omitempty"`
+	MinimumShouldMatch string  `json:"minimum_should_match,omitempty"`
 }
 
 // NewMatchQuery creates a Query for matching text.
@@ -30,7 +38,9 @@ type matchQuery struct {
 // Input text is analyzed using this analyzer.
 // Token terms resulting from this analysis are
 // used to perform term searches.  Result documents
-// must satisfy at least one of these term searches.
+// must satisfy at least one of these term searches,
+// unless the operator or the minimum should match
+// require more.
 func NewMatchQuery(match string) *matchQuery {
 	return &matchQuery{
 		Match:    match,
@@ -74,6 +84,23 @@ func (q *matchQuery) SetPrefix(p int) Query {
 	return q
 }
 
+// SetOperator sets the operator combining the term
+// searches, MatchQueryOperatorOr by default, or
+// MatchQueryOperatorAnd to require all of them.
+func (q *matchQuery) SetOperator(operator string) Query {
+	q.Operator = operator
+	return q
+}
+
+// SetMinimumShouldMatch sets how many of the term
+// searches result documents must satisfy when the
+// operator is MatchQueryOperatorOr, as described by
+// the disjunction SetMinimumShouldMatch.
+func (q *matchQuery) SetMinimumShouldMatch(spec string) Query {
+	q.MinimumShouldMatch = spec
+	return q
+}
+
 func (q *matchQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
 
 	field := q.FieldVal
@@ -114,8 +141,13 @@ func (q *matchQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool
 			}
 		}
 
-		shouldQuery := NewDisjunctionQueryMin(tqs, 1).
-			SetBoost(q.BoostVal)
+		shouldQuery := NewDisjunctionQueryMin(tqs, 1)
+		if q.Operator == MatchQueryOperatorAnd {
+			shouldQuery.SetMin(float64(len(tqs)))
+		} else if q.MinimumShouldMatch != "" {
+			shouldQuery.SetMinimumShouldMatch(q.MinimumShouldMatch)
+		}
+		shouldQuery.SetBoost(q.BoostVal)
 
 		return shouldQuery.Searcher(i, m, explain)
 	}
@@ -124,5 +156,21 @@ func (q *matchQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool
 }
 
 func (q *matchQuery) Validate() error {
+	switch q.Operator {
+	case "", MatchQueryOperatorOr, MatchQueryOperatorAnd:
+	default:
+		return fmt.Errorf("unknown match query operator '%s'", q.Operator)
+	}
+	if q.MinimumShouldMatch != "" {
+		_, err := minShouldMatch(q.MinimumShouldMatch, 0)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+func (q *matchQuery) MarshalJSON() ([]byte, error) {
+	type _matchQuery matchQuery
+	return marshalQuery("match", (*_matchQuery)(q))
+}
@@ -10,8 +10,6 @@
 package bleve
 
 import (
-	"encoding/json"
-
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/search"
 	"github.com/blevesearch/bleve/search/searchers"
@@ -59,5 +57,5 @@ func (q *matchAllQuery) MarshalJSON() ([]byte, error) {
 		"boost":     q.BoostVal,
 		"match_all": map[string]interface{}{},
 	}
-	return json.Marshal(tmp)
+	return marshalQuery("match_all", tmp)
 }
@@ -22,6 +22,7 @@ type matchPhraseQuery struct {
 	FieldVal    string  `json:"field,omitempty"`
 	Analyzer    string  `json:"analyzer,omitempty"`
 	BoostVal    float64 `json:"boost,omitempty"`
+	Slop        int     `json:"slop,omitempty"`
 }
 
 // NewMatchPhraseQuery creates a new Query object
@@ -48,6 +49,15 @@ func (q *matchPhraseQuery) SetBoost(b float64) Query {
 	return q
 }
 
+// SetSlop sets the number of extra positions allowed
+// between the phrase terms, which must still appear
+// in order. The default of 0 matches exact phrases
+// only.
+func (q *matchPhraseQuery) SetSlop(s int) Query {
+	q.Slop = s
+	return q
+}
+
 func (q *matchPhraseQuery) Field() string {
 	return q.FieldVal
 }
@@ -77,6 +87,9 @@ func (q *matchPhraseQuery) Searcher(i index.IndexReader, m *IndexMapping, explai
 	tokens := analyzer.Analyze([]byte(q.MatchPhrase))
 	if len(tokens) > 0 {
 		phrase := tokenStreamToPhrase(tokens)
+		if q.Slop > 0 {
+			return newSloppyPhraseQuery(phrase, field, q.Slop, q.BoostVal).Searcher(i, m, explain)
+		}
 		phraseQuery := NewPhraseQuery(phrase, field).SetBoost(q.BoostVal)
 		return phraseQuery.Searcher(i, m, explain)
 	}
@@ -110,6 +123,34 @@ func tokenStreamToPhrase(tokens analysis.TokenStream) []string {
 	return nil
 }
 
+// newSloppyPhraseQuery returns a SpanQuery matching the
+// terms of phrase in order, with at most slop extra
+// positions between them. Gaps left in the phrase by
+// removed tokens are allowed on top of slop.
+func newSloppyPhraseQuery(phrase []string, field string, slop int, boost float64) SpanQuery {
+	clauses := make([]SpanQuery, 0, len(phrase))
+	for _, term := range phrase {
+		if term == "" {
+			slop++
+			continue
+		}
+		clauses = append(clauses, &spanTermQuery{
+			SpanTerm: term,
+			FieldVal: field,
+			BoostVal: boost,
+		})
+	}
+	return NewSpanNearQuery(clauses, slop, true)
+}
+
 func (q *matchPhraseQuery) Validate() error {
+	if q.Slop < 0 {
+		return fmt.Errorf("phrase slop must not be negative, got %d", q.Slop)
+	}
 	return nil
 }
+
+func (q *matchPhraseQuery) MarshalJSON() ([]byte, error) {
+	type _matchPhraseQuery matchPhraseQuery
+	return marshalQuery("match_phrase", (*_matchPhraseQuery)(q))
+}
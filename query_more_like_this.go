@@ -0,0 +1,316 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+const (
+	defaultMoreLikeThisMinTermFreq   = 2
+	defaultMoreLikeThisMinDocFreq    = 5
+	defaultMoreLikeThisMaxQueryTerms = 25
+)
+
+type moreLikeThisQuery struct {
+	LikeID        string   `json:"like_id,omitempty"`
+	Like          string   `json:"like,omitempty"`
+	Fields        []string `json:"fields,omitempty"`
+	FieldVal      string   `json:"field,omitempty"`
+	Analyzer      string   `json:"analyzer,omitempty"`
+	MinTermFreq   int      `json:"min_term_freq"`
+	MinDocFreq    int      `json:"min_doc_freq"`
+	MaxQueryTerms int      `json:"max_query_terms"`
+	BoostVal      float64  `json:"boost,omitempty"`
+}
+
+// NewMoreLikeThisQuery creates a new Query which finds
+// documents similar to the indexed document with the
+// specified identifier. The document itself is not
+// part of the results.
+//
+// The most significant terms of the document are
+// selected, scoring them by their frequency in the
+// document and their rarity in the index, and
+// searched for in a disjunction weighted by these
+// scores. By default terms are taken from the field of
+// the query, terms occurring fewer than 2 times in the
+// document or found in fewer than 5 documents are
+// ignored and at most 25 terms are searched for.
+func NewMoreLikeThisQuery(id string) *moreLikeThisQuery {
+	return &moreLikeThisQuery{
+		LikeID:        id,
+		MinTermFreq:   defaultMoreLikeThisMinTermFreq,
+		MinDocFreq:    defaultMoreLikeThisMinDocFreq,
+		MaxQueryTerms: defaultMoreLikeThisMaxQueryTerms,
+		BoostVal:      1.0,
+	}
+}
+
+// NewMoreLikeThisTextQuery creates a new Query which
+// works like NewMoreLikeThisQuery, selecting the most
+// significant terms of the specified text, analyzed
+// with the analyzer of each field.
+func NewMoreLikeThisTextQuery(text string) *moreLikeThisQuery {
+	rv := NewMoreLikeThisQuery("")
+	rv.Like = text
+	return rv
+}
+
+func (q *moreLikeThisQuery) Boost() float64 {
+	return q.BoostVal
+}
+
+func (q *moreLikeThisQuery) SetBoost(b float64) Query {
+	q.BoostVal = b
+	return q
+}
+
+func (q *moreLikeThisQuery) Field() string {
+	return q.FieldVal
+}
+
+func (q *moreLikeThisQuery) SetField(f string) Query {
+	q.FieldVal = f
+	return q
+}
+
+// SetFields sets the fields the terms are taken from
+// and searched in, instead of the field of the query.
+func (q *moreLikeThisQuery) SetFields(fields []string) Query {
+	q.Fields = fields
+	return q
+}
+
+// SetAnalyzer sets the analyzer of the text, instead
+// of the analyzer of each field.
+func (q *moreLikeThisQuery) SetAnalyzer(a string) Query {
+	q.Analyzer = a
+	return q
+}
+
+// SetMinTermFreq sets the number of times a term must
+// occur in the document or text to be selected.
+func (q *moreLikeThisQuery) SetMinTermFreq(n int) Query {
+	q.MinTermFreq = n
+	return q
+}
+
+// SetMinDocFreq sets the number of documents a term
+// must be found in to be selected.
+func (q *moreLikeThisQuery) SetMinDocFreq(n int) Query {
+	q.MinDocFreq = n
+	return q
+}
+
+// SetMaxQueryTerms sets the maximum number of terms
+// searched for.
+func (q *moreLikeThisQuery) SetMaxQueryTerms(n int) Query {
+	q.MaxQueryTerms = n
+	return q
+}
+
+func (q *moreLikeThisQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	fields := q.Fields
+	if len(fields) == 0 {
+		field := q.FieldVal
+		if q.FieldVal == "" {
+			field = m.DefaultField
+		}
+		fields = []string{field}
+	}
+
+	var terms []*likeTerm
+	var err error
+	if q.LikeID != "" {
+		terms, err = q.documentTerms(i, fields)
+	} else {
+		terms, err = q.textTerms(i, m, fields)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	terms = q.bestTerms(terms, i.DocCount())
+	if len(terms) == 0 {
+		return NewMatchNoneQuery().Searcher(i, m, explain)
+	}
+
+	// weight the terms relative to the best one
+	tqs := make([]Query, len(terms))
+	for in, term := range terms {
+		tqs[in] = NewTermQuery(term.term).
+			SetField(term.field).
+			SetBoost(q.BoostVal * term.score / terms[0].score)
+	}
+	var rv Query = NewDisjunctionQueryMin(tqs, 1)
+	if q.LikeID != "" {
+		rv = NewBooleanQuery([]Query{rv}, nil, []Query{NewDocIDQuery([]string{q.LikeID})})
+	}
+	return rv.Searcher(i, m, explain)
+}
+
+func (q *moreLikeThisQuery) Validate() error {
+	if (q.LikeID == "") == (q.Like == "") {
+		return ErrorMoreLikeThisQueryNoLike
+	}
+	return nil
+}
+
+// likeTerm is a candidate term of a more like this
+// query, with its frequencies.
+type likeTerm struct {
+	field   string
+	term    string
+	freq    uint64
+	docFreq uint64
+	score   float64
+}
+
+// documentTerms returns the terms of the fields of the
+// liked document.
+func (q *moreLikeThisQuery) documentTerms(i index.IndexReader, fields []string) ([]*likeTerm, error) {
+	fieldTerms, err := i.DocumentFieldTermsForFields(q.LikeID, fields)
+	if err != nil {
+		return nil, err
+	}
+	rv := make([]*likeTerm, 0)
+	for _, field := range fields {
+		for _, term := range fieldTerms[field] {
+			reader, err := i.TermFieldReader([]byte(term), field)
+			if err != nil {
+				return nil, err
+			}
+			tfd, err := reader.Advance(q.LikeID)
+			docFreq := reader.Count()
+			if cerr := reader.Close(); err == nil && cerr != nil {
+				err = cerr
+			}
+			if err != nil {
+				return nil, err
+			}
+			if tfd == nil || tfd.ID != q.LikeID {
+				continue
+			}
+			rv = append(rv, &likeTerm{
+				field:   field,
+				term:    term,
+				freq:    tfd.Freq,
+				docFreq: docFreq,
+			})
+		}
+	}
+	return rv, nil
+}
+
+// textTerms returns the terms of the liked text,
+// analyzed for each field.
+func (q *moreLikeThisQuery) textTerms(i index.IndexReader, m *IndexMapping, fields []string) ([]*likeTerm, error) {
+	rv := make([]*likeTerm, 0)
+	for _, field := range fields {
+		analyzerName := q.Analyzer
+		if analyzerName == "" {
+			analyzerName = m.analyzerNameForPath(field)
+		}
+		analyzer := m.analyzerNamed(analyzerName)
+		if analyzer == nil {
+			return nil, fmt.Errorf("no analyzer named '%s' registered", analyzerName)
+		}
+
+		freqs := make(map[string]uint64)
+		for _, token := range analyzer.Analyze([]byte(q.Like)) {
+			freqs[string(token.Term)]++
+		}
+		for term, freq := range freqs {
+			reader, err := i.TermFieldReader([]byte(term), field)
+			if err != nil {
+				return nil, err
+			}
+			docFreq := reader.Count()
+			err = reader.Close()
+			if err != nil {
+				return nil, err
+			}
+			rv = append(rv, &likeTerm{
+				field:   field,
+				term:    term,
+				freq:    freq,
+				docFreq: docFreq,
+			})
+		}
+	}
+	return rv, nil
+}
+
+// bestTerms scores the terms passing the frequency
+// thresholds and returns the best ones, best first.
+func (q *moreLikeThisQuery) bestTerms(terms []*likeTerm, docCount uint64) []*likeTerm {
+	rv := make([]*likeTerm, 0, len(terms))
+	for _, term := range terms {
+		if term.freq < uint64(q.MinTermFreq) || term.docFreq < uint64(q.MinDocFreq) || term.docFreq == 0 {
+			continue
+		}
+		idf := 1.0 + math.Log(float64(docCount)/float64(term.docFreq+1))
+		if idf <= 0 {
+			continue
+		}
+		term.score = float64(term.freq) * idf
+		rv = append(rv, term)
+	}
+	sort.Sort(likeTermsByScore(rv))
+
+	maxQueryTerms := q.MaxQueryTerms
+	if maxQueryTerms <= 0 {
+		maxQueryTerms = defaultMoreLikeThisMaxQueryTerms
+	}
+	if len(rv) > maxQueryTerms {
+		rv = rv[:maxQueryTerms]
+	}
+	return rv
+}
+
+type likeTermsByScore []*likeTerm
+
+func (t likeTermsByScore) Len() int      { return len(t) }
+func (t likeTermsByScore) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t likeTermsByScore) Less(i, j int) bool {
+	if t[i].score != t[j].score {
+		return t[i].score > t[j].score
+	}
+	if t[i].field != t[j].field {
+		return t[i].field < t[j].field
+	}
+	return t[i].term < t[j].term
+}
+
+// UnmarshalJSON starts from the defaults of
+// NewMoreLikeThisQuery, which the JSON representation
+// can override.
+func (q *moreLikeThisQuery) UnmarshalJSON(data []byte) error {
+	type _moreLikeThisQuery moreLikeThisQuery
+	tmp := _moreLikeThisQuery(*NewMoreLikeThisQuery(""))
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+	*q = moreLikeThisQuery(tmp)
+	return nil
+}
+
+func (q *moreLikeThisQuery) MarshalJSON() ([]byte, error) {
+	type _moreLikeThisQuery moreLikeThisQuery
+	return marshalQuery("more_like_this", (*_moreLikeThisQuery)(q))
+}
@@ -70,7 +70,11 @@ func (q *numericRangeQuery) Searcher(i index.IndexReader, m *IndexMapping, expla
 	if q.FieldVal == "" {
 		field = m.DefaultField
 	}
-	return searchers.NewNumericRangeSearcher(i, q.Min, q.Max, q.InclusiveMin, q.InclusiveMax, field, q.BoostVal, explain)
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewNumericRangeSearcherWithSimilarity(i, q.Min, q.Max, q.InclusiveMin, q.InclusiveMax, field, q.BoostVal, similarity, explain)
 }
 
 func (q *numericRangeQuery) Validate() error {
@@ -79,3 +83,8 @@ func (q *numericRangeQuery) Validate() error {
 	}
 	return nil
 }
+
+func (q *numericRangeQuery) MarshalJSON() ([]byte, error) {
+	type _numericRangeQuery numericRangeQuery
+	return marshalQuery("numeric_range", (*_numericRangeQuery)(q))
+}
@@ -31,26 +31,36 @@ type phraseQuery struct {
 // specified field. Queried field must have been indexed with
 // IncludeTermVectors set to true.
 func NewPhraseQuery(terms []string, field string) *phraseQuery {
-	termQueries := make([]Query, 0)
-	for _, term := range terms {
-		if term != "" {
-			termQueries = append(termQueries, NewTermQuery(term).SetField(field))
-		}
-	}
 	return &phraseQuery{
 		Terms:       terms,
 		FieldVal:    field,
 		BoostVal:    1.0,
-		termQueries: termQueries,
+		termQueries: phraseTermQueries(terms, field, 1.0),
 	}
 }
 
+// phraseTermQueries returns the term queries
+// of the non empty terms of a phrase, boosted
+// like the phrase.
+func phraseTermQueries(terms []string, field string, boost float64) []Query {
+	rv := make([]Query, 0)
+	for _, term := range terms {
+		if term != "" {
+			rv = append(rv, NewTermQuery(term).SetField(field).SetBoost(boost))
+		}
+	}
+	return rv
+}
+
 func (q *phraseQuery) Boost() float64 {
 	return q.BoostVal
 }
 
 func (q *phraseQuery) SetBoost(b float64) Query {
 	q.BoostVal = b
+	for _, termQuery := range q.termQueries {
+		termQuery.SetBoost(b)
+	}
 	return q
 }
 
@@ -84,10 +94,7 @@ func (q *phraseQuery) UnmarshalJSON(data []byte) error {
 	if q.BoostVal == 0 {
 		q.BoostVal = 1
 	}
-	q.termQueries = make([]Query, len(q.Terms))
-	for i, term := range q.Terms {
-		q.termQueries[i] = &termQuery{Term: term, FieldVal: q.FieldVal, BoostVal: q.BoostVal}
-	}
+	q.termQueries = phraseTermQueries(q.Terms, q.FieldVal, q.BoostVal)
 	return nil
 }
 
@@ -98,3 +105,8 @@ func (q *phraseQuery) Field() string {
 func (q *phraseQuery) SetField(f string) Query {
 	return q
 }
+
+func (q *phraseQuery) MarshalJSON() ([]byte, error) {
+	type _phraseQuery phraseQuery
+	return marshalQuery("phrase", (*_phraseQuery)(q))
+}
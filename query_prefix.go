@@ -54,9 +54,18 @@ func (q *prefixQuery) Searcher(i index.IndexReader, m *IndexMapping, explain boo
 	if q.FieldVal == "" {
 		field = m.DefaultField
 	}
-	return searchers.NewTermPrefixSearcher(i, q.Prefix, field, q.BoostVal, explain)
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewTermPrefixSearcherWithSimilarity(i, q.Prefix, field, q.BoostVal, similarity, explain)
 }
 
 func (q *prefixQuery) Validate() error {
 	return nil
 }
+
+func (q *prefixQuery) MarshalJSON() ([]byte, error) {
+	type _prefixQuery prefixQuery
+	return marshalQuery("prefix", (*_prefixQuery)(q))
+}
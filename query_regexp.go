@@ -63,7 +63,11 @@ func (q *regexpQuery) Searcher(i index.IndexReader, m *IndexMapping, explain boo
 		return nil, err
 	}
 
-	return searchers.NewRegexpSearcher(i, q.compiled, field, q.BoostVal, explain)
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewRegexpSearcherWithSimilarity(i, q.compiled, field, q.BoostVal, similarity, explain)
 }
 
 func (q *regexpQuery) Validate() error {
@@ -88,3 +92,8 @@ func (q *regexpQuery) compile() error {
 	}
 	return nil
 }
+
+func (q *regexpQuery) MarshalJSON() ([]byte, error) {
+	type _regexpQuery regexpQuery
+	return marshalQuery("regexp", (*_regexpQuery)(q))
+}
@@ -0,0 +1,108 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searchers"
+)
+
+type spanFirstQuery struct {
+	Clause   SpanQuery `json:"span_first"`
+	End      int       `json:"end"`
+	BoostVal float64   `json:"boost,omitempty"`
+}
+
+// NewSpanFirstQuery creates a new SpanQuery matching
+// the spans of clause lying entirely within the
+// first end positions of the field.
+func NewSpanFirstQuery(clause SpanQuery, end int) *spanFirstQuery {
+	return &spanFirstQuery{
+		Clause:   clause,
+		End:      end,
+		BoostVal: 1.0,
+	}
+}
+
+func (q *spanFirstQuery) Boost() float64 {
+	return q.BoostVal
+}
+
+func (q *spanFirstQuery) SetBoost(b float64) Query {
+	q.BoostVal = b
+	return q
+}
+
+func (q *spanFirstQuery) Field() string {
+	return q.Clause.Field()
+}
+
+func (q *spanFirstQuery) SetField(f string) Query {
+	q.Clause.SetField(f)
+	return q
+}
+
+func (q *spanFirstQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	return q.SpanSearcher(i, m, explain)
+}
+
+func (q *spanFirstQuery) SpanSearcher(i index.IndexReader, m *IndexMapping, explain bool) (searchers.SpanSearcher, error) {
+	s, err := q.Clause.SpanSearcher(i, m, explain)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := searchers.NewSpanFirstSearcher(i, s, q.End, explain)
+	if err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	return rv, nil
+}
+
+func (q *spanFirstQuery) Validate() error {
+	if q.Clause == nil {
+		return ErrorSpanQueryNoClauses
+	}
+	if q.End < 1 {
+		return fmt.Errorf("span first end must be positive, got %d", q.End)
+	}
+	return q.Clause.Validate()
+}
+
+func (q *spanFirstQuery) UnmarshalJSON(data []byte) error {
+	tmp := struct {
+		Clause   json.RawMessage `json:"span_first"`
+		End      int             `json:"end"`
+		BoostVal float64         `json:"boost,omitempty"`
+	}{}
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+	q.Clause, err = parseSpanQuery("span_first", tmp.Clause)
+	if err != nil {
+		return err
+	}
+	q.End = tmp.End
+	q.BoostVal = tmp.BoostVal
+	if q.BoostVal == 0 {
+		q.BoostVal = 1
+	}
+	return nil
+}
+
+func (q *spanFirstQuery) MarshalJSON() ([]byte, error) {
+	type _spanFirstQuery spanFirstQuery
+	return marshalQuery("span_first", (*_spanFirstQuery)(q))
+}
@@ -0,0 +1,122 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searchers"
+)
+
+type spanNearQuery struct {
+	Clauses  []SpanQuery `json:"span_near"`
+	Slop     int         `json:"slop"`
+	InOrder  bool        `json:"in_order"`
+	BoostVal float64     `json:"boost,omitempty"`
+}
+
+// NewSpanNearQuery creates a new SpanQuery matching
+// one span of each clause, with at most slop
+// unmatched positions between them. When inOrder
+// is set, the spans must also appear in the order
+// of the clauses. All clauses must search the
+// same field.
+func NewSpanNearQuery(clauses []SpanQuery, slop int, inOrder bool) *spanNearQuery {
+	return &spanNearQuery{
+		Clauses:  clauses,
+		Slop:     slop,
+		InOrder:  inOrder,
+		BoostVal: 1.0,
+	}
+}
+
+func (q *spanNearQuery) Boost() float64 {
+	return q.BoostVal
+}
+
+func (q *spanNearQuery) SetBoost(b float64) Query {
+	q.BoostVal = b
+	return q
+}
+
+func (q *spanNearQuery) Field() string {
+	if len(q.Clauses) > 0 {
+		return q.Clauses[0].Field()
+	}
+	return ""
+}
+
+func (q *spanNearQuery) SetField(f string) Query {
+	for _, clause := range q.Clauses {
+		clause.SetField(f)
+	}
+	return q
+}
+
+func (q *spanNearQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	return q.SpanSearcher(i, m, explain)
+}
+
+func (q *spanNearQuery) SpanSearcher(i index.IndexReader, m *IndexMapping, explain bool) (searchers.SpanSearcher, error) {
+	ss, err := spanSearchers(q.Clauses, i, m, explain)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := searchers.NewSpanNearSearcher(i, ss, q.Slop, q.InOrder, explain)
+	if err != nil {
+		for _, s := range ss {
+			_ = s.Close()
+		}
+		return nil, err
+	}
+	return rv, nil
+}
+
+func (q *spanNearQuery) Validate() error {
+	if len(q.Clauses) < 1 {
+		return ErrorSpanQueryNoClauses
+	}
+	if q.Slop < 0 {
+		return fmt.Errorf("span near slop must not be negative, got %d", q.Slop)
+	}
+	return validateSpanQueries(q.Clauses...)
+}
+
+func (q *spanNearQuery) UnmarshalJSON(data []byte) error {
+	tmp := struct {
+		Clauses  []json.RawMessage `json:"span_near"`
+		Slop     int               `json:"slop"`
+		InOrder  bool              `json:"in_order"`
+		BoostVal float64           `json:"boost,omitempty"`
+	}{}
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+	q.Clauses, err = parseSpanQueries("span_near", tmp.Clauses)
+	if err != nil {
+		return err
+	}
+	q.Slop = tmp.Slop
+	q.InOrder = tmp.InOrder
+	q.BoostVal = tmp.BoostVal
+	if q.BoostVal == 0 {
+		q.BoostVal = 1
+	}
+	return nil
+}
+
+func (q *spanNearQuery) MarshalJSON() ([]byte, error) {
+	type _spanNearQuery spanNearQuery
+	return marshalQuery("span_near", (*_spanNearQuery)(q))
+}
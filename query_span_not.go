@@ -0,0 +1,103 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searchers"
+)
+
+type spanNotQuery struct {
+	Include  SpanQuery `json:"span_not"`
+	Exclude  SpanQuery `json:"exclude"`
+	BoostVal float64   `json:"boost,omitempty"`
+}
+
+// NewSpanNotQuery creates a new SpanQuery matching
+// the spans of include which do not overlap any
+// span of exclude. Both must search the same field.
+func NewSpanNotQuery(include, exclude SpanQuery) *spanNotQuery {
+	return &spanNotQuery{
+		Include:  include,
+		Exclude:  exclude,
+		BoostVal: 1.0,
+	}
+}
+
+func (q *spanNotQuery) Boost() float64 {
+	return q.BoostVal
+}
+
+func (q *spanNotQuery) SetBoost(b float64) Query {
+	q.BoostVal = b
+	return q
+}
+
+func (q *spanNotQuery) Field() string {
+	return q.Include.Field()
+}
+
+func (q *spanNotQuery) SetField(f string) Query {
+	q.Include.SetField(f)
+	q.Exclude.SetField(f)
+	return q
+}
+
+func (q *spanNotQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	return q.SpanSearcher(i, m, explain)
+}
+
+func (q *spanNotQuery) SpanSearcher(i index.IndexReader, m *IndexMapping, explain bool) (searchers.SpanSearcher, error) {
+	ss, err := spanSearchers([]SpanQuery{q.Include, q.Exclude}, i, m, explain)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewSpanNotSearcher(i, ss[0], ss[1], explain)
+}
+
+func (q *spanNotQuery) Validate() error {
+	if q.Include == nil || q.Exclude == nil {
+		return ErrorSpanQueryNoClauses
+	}
+	return validateSpanQueries(q.Include, q.Exclude)
+}
+
+func (q *spanNotQuery) UnmarshalJSON(data []byte) error {
+	tmp := struct {
+		Include  json.RawMessage `json:"span_not"`
+		Exclude  json.RawMessage `json:"exclude"`
+		BoostVal float64         `json:"boost,omitempty"`
+	}{}
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+	q.Include, err = parseSpanQuery("span_not", tmp.Include)
+	if err != nil {
+		return err
+	}
+	q.Exclude, err = parseSpanQuery("exclude", tmp.Exclude)
+	if err != nil {
+		return err
+	}
+	q.BoostVal = tmp.BoostVal
+	if q.BoostVal == 0 {
+		q.BoostVal = 1
+	}
+	return nil
+}
+
+func (q *spanNotQuery) MarshalJSON() ([]byte, error) {
+	type _spanNotQuery spanNotQuery
+	return marshalQuery("span_not", (*_spanNotQuery)(q))
+}
@@ -0,0 +1,100 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"encoding/json"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searchers"
+)
+
+type spanOrQuery struct {
+	Clauses  []SpanQuery `json:"span_or"`
+	BoostVal float64     `json:"boost,omitempty"`
+}
+
+// NewSpanOrQuery creates a new SpanQuery matching
+// the spans of any of its clauses. All clauses must
+// search the same field.
+func NewSpanOrQuery(clauses []SpanQuery) *spanOrQuery {
+	return &spanOrQuery{
+		Clauses:  clauses,
+		BoostVal: 1.0,
+	}
+}
+
+func (q *spanOrQuery) Boost() float64 {
+	return q.BoostVal
+}
+
+func (q *spanOrQuery) SetBoost(b float64) Query {
+	q.BoostVal = b
+	return q
+}
+
+func (q *spanOrQuery) Field() string {
+	if len(q.Clauses) > 0 {
+		return q.Clauses[0].Field()
+	}
+	return ""
+}
+
+func (q *spanOrQuery) SetField(f string) Query {
+	for _, clause := range q.Clauses {
+		clause.SetField(f)
+	}
+	return q
+}
+
+func (q *spanOrQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	return q.SpanSearcher(i, m, explain)
+}
+
+func (q *spanOrQuery) SpanSearcher(i index.IndexReader, m *IndexMapping, explain bool) (searchers.SpanSearcher, error) {
+	ss, err := spanSearchers(q.Clauses, i, m, explain)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewSpanOrSearcher(i, ss, explain)
+}
+
+func (q *spanOrQuery) Validate() error {
+	if len(q.Clauses) < 1 {
+		return ErrorSpanQueryNoClauses
+	}
+	return validateSpanQueries(q.Clauses...)
+}
+
+func (q *spanOrQuery) UnmarshalJSON(data []byte) error {
+	tmp := struct {
+		Clauses  []json.RawMessage `json:"span_or"`
+		BoostVal float64           `json:"boost,omitempty"`
+	}{}
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+	q.Clauses, err = parseSpanQueries("span_or", tmp.Clauses)
+	if err != nil {
+		return err
+	}
+	q.BoostVal = tmp.BoostVal
+	if q.BoostVal == 0 {
+		q.BoostVal = 1
+	}
+	return nil
+}
+
+func (q *spanOrQuery) MarshalJSON() ([]byte, error) {
+	type _spanOrQuery spanOrQuery
+	return marshalQuery("span_or", (*_spanOrQuery)(q))
+}
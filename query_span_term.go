@@ -0,0 +1,76 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searchers"
+)
+
+type spanTermQuery struct {
+	SpanTerm string  `json:"span_term"`
+	FieldVal string  `json:"field,omitempty"`
+	BoostVal float64 `json:"boost,omitempty"`
+}
+
+// NewSpanTermQuery creates a new SpanQuery matching
+// every occurrence of an exact term in the index.
+// Queried field must have been indexed with
+// IncludeTermVectors set to true.
+func NewSpanTermQuery(term string) *spanTermQuery {
+	return &spanTermQuery{
+		SpanTerm: term,
+		BoostVal: 1.0,
+	}
+}
+
+func (q *spanTermQuery) Boost() float64 {
+	return q.BoostVal
+}
+
+func (q *spanTermQuery) SetBoost(b float64) Query {
+	q.BoostVal = b
+	return q
+}
+
+func (q *spanTermQuery) Field() string {
+	return q.FieldVal
+}
+
+func (q *spanTermQuery) SetField(f string) Query {
+	q.FieldVal = f
+	return q
+}
+
+func (q *spanTermQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	return q.SpanSearcher(i, m, explain)
+}
+
+func (q *spanTermQuery) SpanSearcher(i index.IndexReader, m *IndexMapping, explain bool) (searchers.SpanSearcher, error) {
+	field := q.FieldVal
+	if q.FieldVal == "" {
+		field = m.DefaultField
+	}
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewSpanTermSearcher(i, q.SpanTerm, field, q.BoostVal, similarity, explain)
+}
+
+func (q *spanTermQuery) Validate() error {
+	return nil
+}
+
+func (q *spanTermQuery) MarshalJSON() ([]byte, error) {
+	type _spanTermQuery spanTermQuery
+	return marshalQuery("span_term", (*_spanTermQuery)(q))
+}
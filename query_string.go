@@ -10,18 +10,35 @@
 package bleve
 
 import (
+	"fmt"
+
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/search"
 )
 
+// Operators combining the clauses of a query string
+// written without an explicit operator.
+const (
+	QueryStringOperatorOr  = "or"
+	QueryStringOperatorAnd = "and"
+)
+
 type queryStringQuery struct {
-	Query    string  `json:"query"`
-	BoostVal float64 `json:"boost,omitempty"`
+	Query              string  `json:"query"`
+	DefaultOperator    string  `json:"default_operator,omitempty"`
+	MinimumShouldMatch string  `json:"minimum_should_match,omitempty"`
+	BoostVal           float64 `json:"boost,omitempty"`
 }
 
 // NewQueryStringQuery creates a new Query used for
 // finding documents that satisfy a query string.  The
 // query string is a small query language for humans.
+// Clauses can be grouped with parentheses, optionally
+// prefixed by a field they search by default, and
+// combined with the AND, OR and NOT operators, NOT
+// binding tighter than AND, itself tighter than OR.
+// Clauses written one after the other are combined with
+// the default operator, QueryStringOperatorOr unless set.
 func NewQueryStringQuery(query string) *queryStringQuery {
 	return &queryStringQuery{
 		Query:    query,
@@ -38,8 +55,47 @@ func (q *queryStringQuery) SetBoost(b float64) Query {
 	return q
 }
 
+// SetDefaultOperator sets the operator combining the
+// clauses without explicit operator, QueryStringOperatorOr
+// or QueryStringOperatorAnd.
+func (q *queryStringQuery) SetDefaultOperator(operator string) Query {
+	q.DefaultOperator = operator
+	return q
+}
+
+// SetMinimumShouldMatch sets how many of the optional
+// clauses at the top level of the query string result
+// documents must satisfy, as described by the
+// disjunction SetMinimumShouldMatch.
+func (q *queryStringQuery) SetMinimumShouldMatch(spec string) Query {
+	q.MinimumShouldMatch = spec
+	return q
+}
+
+func (q *queryStringQuery) parse() (Query, error) {
+	switch q.DefaultOperator {
+	case "", QueryStringOperatorOr, QueryStringOperatorAnd:
+	default:
+		return nil, fmt.Errorf("unknown query string default operator '%s'", q.DefaultOperator)
+	}
+	if q.MinimumShouldMatch != "" {
+		_, err := minShouldMatch(q.MinimumShouldMatch, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rv, err := parseQuerySyntaxWithOperator(q.Query, q.DefaultOperator)
+	if err != nil {
+		return nil, err
+	}
+	if bq, ok := rv.(*booleanQuery); ok && bq.Should != nil && q.MinimumShouldMatch != "" {
+		bq.SetMinimumShouldMatch(q.MinimumShouldMatch)
+	}
+	return rv, nil
+}
+
 func (q *queryStringQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
-	newQuery, err := parseQuerySyntax(q.Query)
+	newQuery, err := q.parse()
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +103,7 @@ func (q *queryStringQuery) Searcher(i index.IndexReader, m *IndexMapping, explai
 }
 
 func (q *queryStringQuery) Validate() error {
-	newQuery, err := parseQuerySyntax(q.Query)
+	newQuery, err := q.parse()
 	if err != nil {
 		return err
 	}
@@ -61,3 +117,8 @@ func (q *queryStringQuery) Field() string {
 func (q *queryStringQuery) SetField(f string) Query {
 	return q
 }
+
+func (q *queryStringQuery) MarshalJSON() ([]byte, error) {
+	type _queryStringQuery queryStringQuery
+	return marshalQuery("query_string", (*_queryStringQuery)(q))
+}
@@ -13,10 +13,15 @@ func logDebugGrammar(format string, v ...interface{}) {
 s string
 n int
 f float64
-q Query}
+q Query
+c *queryClause
+cs []*queryClause
+b bool
+rb *rangeBound}
 
 %token tSTRING tPHRASE tPLUS tMINUS tCOLON tBOOST tLPAREN tRPAREN tNUMBER tSTRING tGREATER tLESS
-tEQUAL tTILDE tTILDENUMBER tREGEXP tWILD
+tEQUAL tTILDE tTILDENUMBER tREGEXP tWILD tAND tOR tNOT tLBRACKET tRBRACKET tLBRACE
+tRBRACE tTO tSTAR
 
 %type <s>                tSTRING
 %type <s>                tWILD
@@ -29,35 +34,69 @@ tEQUAL tTILDE tTILDENUMBER tREGEXP tWILD
 %type <n>                searchPrefix
 %type <n>                searchMustMustNot
 %type <f>                searchBoost
+%type <cs>               searchParts
+%type <c>                searchPart
+%type <cs>               searchOr
+%type <cs>               searchAnd
+%type <c>                searchNot
+%type <b>                rangeStart
+%type <b>                rangeEnd
+%type <rb>               rangeBound
 
 %%
 
 input:
 searchParts {
 	logDebugGrammar("INPUT")
+	yylex.(*lexerWrapper).query = yylex.(*lexerWrapper).booleanQuery($1)
 };
 
 searchParts:
-searchPart searchParts {
+searchParts searchPart {
 	logDebugGrammar("SEARCH PARTS")
+	$$ = append($1, $2)
 }
 |
 searchPart {
 	logDebugGrammar("SEARCH PART")
+	$$ = []*queryClause{$1}
 };
 
 searchPart:
+searchOr {
+	$$ = disjunctionClause($1)
+};
+
+searchOr:
+searchOr tOR searchAnd {
+	logDebugGrammar("OR")
+	$$ = append($1, conjunctionClause($3))
+}
+|
+searchAnd {
+	$$ = []*queryClause{conjunctionClause($1)}
+};
+
+searchAnd:
+searchAnd tAND searchNot {
+	logDebugGrammar("AND")
+	$$ = append($1, $3)
+}
+|
+searchNot {
+	$$ = []*queryClause{$1}
+};
+
+searchNot:
+tNOT searchNot {
+	logDebugGrammar("NOT")
+	$$ = &queryClause{occur: queryMustNot, query: $2.clauseQuery()}
+}
+|
 searchPrefix searchBase searchSuffix {
 	query := $2
 	query.SetBoost($3)
-	switch($1) {
-		case queryShould:
-			yylex.(*lexerWrapper).query.AddShould(query)
-		case queryMust:
-			yylex.(*lexerWrapper).query.AddMust(query)
-		case queryMustNot:
-			yylex.(*lexerWrapper).query.AddMustNot(query)
-	}
+	$$ = &queryClause{occur: $1, query: query}
 };
 
 
@@ -83,6 +122,19 @@ tMINUS {
 };
 
 searchBase:
+tLPAREN searchParts tRPAREN {
+	logDebugGrammar("GROUP")
+	$$ = yylex.(*lexerWrapper).groupQuery($2)
+}
+|
+tSTRING tCOLON tLPAREN searchParts tRPAREN {
+	field := $1
+	logDebugGrammar("FIELD - %s GROUP", field)
+	q := yylex.(*lexerWrapper).groupQuery($4)
+	setDefaultField(q, field)
+	$$ = q
+}
+|
 tSTRING {
 	str := $1
 	logDebugGrammar("STRING - %s", str)
@@ -174,6 +226,15 @@ tPHRASE {
 	$$ = q
 }
 |
+tPHRASE tTILDENUMBER {
+	phrase := $1
+	slop, _ := strconv.ParseFloat($2, 64)
+	logDebugGrammar("PHRASE - %s SLOP - %f", phrase, slop)
+	q := NewMatchPhraseQuery(phrase)
+	q.SetSlop(int(slop))
+	$$ = q
+}
+|
 tSTRING tCOLON tSTRING {
 	field := $1
 	str := $3
@@ -198,6 +259,17 @@ tSTRING tCOLON tPHRASE {
 	$$ = q
 }
 |
+tSTRING tCOLON tPHRASE tTILDENUMBER {
+	field := $1
+	phrase := $3
+	slop, _ := strconv.ParseFloat($4, 64)
+	logDebugGrammar("FIELD - %s PHRASE - %s SLOP - %f", field, phrase, slop)
+	q := NewMatchPhraseQuery(phrase)
+	q.SetSlop(int(slop))
+	q.SetField(field)
+	$$ = q
+}
+|
 tSTRING tCOLON tGREATER tNUMBER {
 	field := $1
 	min, _ := strconv.ParseFloat($4, 64)
@@ -272,6 +344,53 @@ tSTRING tCOLON tLESS tEQUAL tPHRASE {
 	logDebugGrammar("FIELD - LESS THAN OR EQUAL DATE %s", phrase)
 	q := NewDateRangeInclusiveQuery(nil, &phrase, nil, &maxInclusive).SetField(field)
 	$$ = q
+}
+|
+tSTRING tCOLON rangeStart rangeBound tTO rangeBound rangeEnd {
+	field := $1
+	logDebugGrammar("FIELD - %s RANGE", field)
+	q, err := newRangeQuery($4, $6, $3, $7)
+	if err != nil {
+		yylex.(*lexerWrapper).Error(err.Error())
+		q = NewMatchNoneQuery()
+	}
+	q.SetField(field)
+	$$ = q
+};
+
+rangeStart:
+tLBRACKET {
+	$$ = true
+}
+|
+tLBRACE {
+	$$ = false
+};
+
+rangeEnd:
+tRBRACKET {
+	$$ = true
+}
+|
+tRBRACE {
+	$$ = false
+};
+
+rangeBound:
+tSTAR {
+	$$ = &rangeBound{kind: rangeBoundOpen}
+}
+|
+tNUMBER {
+	$$ = &rangeBound{kind: rangeBoundNumber, value: $1}
+}
+|
+tPHRASE {
+	$$ = newQuotedRangeBound($1)
+}
+|
+tSTRING {
+	$$ = &rangeBound{kind: rangeBoundTerm, value: $1}
 };
 
 searchBoost:
@@ -18,6 +18,10 @@ type yySymType struct {
 	n   int
 	f   float64
 	q   Query
+	c   *queryClause
+	cs  []*queryClause
+	b   bool
+	rb  *rangeBound
 }
 
 const tSTRING = 57346
@@ -36,6 +40,15 @@ const tTILDE = 57358
 const tTILDENUMBER = 57359
 const tREGEXP = 57360
 const tWILD = 57361
+const tAND = 57362
+const tOR = 57363
+const tNOT = 57364
+const tLBRACKET = 57365
+const tRBRACKET = 57366
+const tLBRACE = 57367
+const tRBRACE = 57368
+const tTO = 57369
+const tSTAR = 57370
 
 var yyToknames = [...]string{
 	"$end",
@@ -57,7 +70,17 @@ var yyToknames = [...]string{
 	"tTILDENUMBER",
 	"tREGEXP",
 	"tWILD",
+	"tAND",
+	"tOR",
+	"tNOT",
+	"tLBRACKET",
+	"tRBRACKET",
+	"tLBRACE",
+	"tRBRACE",
+	"tTO",
+	"tSTAR",
 }
+
 var yyStatenames = [...]string{}
 
 const yyEofCode = 1
@@ -65,82 +88,98 @@ const yyErrCode = 2
 const yyInitialStackSize = 16
 
 //line yacctab:1
-var yyExca = [...]int{
+var yyExca = [...]int8{
 	-1, 1,
 	1, -1,
 	-2, 0,
-	-1, 3,
-	1, 3,
-	-2, 5,
+	-1, 2,
+	1, 1,
+	-2, 11,
 }
 
-const yyNprod = 34
 const yyPrivate = 57344
 
-var yyTokenNames []string
-var yyStates []string
-
-const yyLast = 40
-
-var yyAct = [...]int{
-
-	22, 26, 21, 10, 14, 29, 30, 17, 25, 27,
-	28, 13, 19, 3, 23, 24, 36, 11, 12, 1,
-	18, 20, 33, 34, 40, 16, 35, 38, 5, 31,
-	4, 39, 32, 2, 37, 6, 7, 8, 15, 9,
+const yyLast = 81
+
+var yyAct = [...]int8{
+	56, 3, 2, 69, 12, 70, 13, 36, 40, 66,
+	60, 59, 14, 35, 49, 39, 41, 42, 58, 32,
+	28, 37, 38, 5, 10, 11, 44, 33, 45, 61,
+	12, 10, 11, 27, 57, 1, 34, 23, 46, 68,
+	7, 10, 11, 43, 18, 22, 29, 7, 12, 4,
+	17, 26, 21, 9, 30, 31, 55, 7, 19, 20,
+	47, 48, 52, 53, 65, 6, 54, 67, 63, 50,
+	8, 64, 51, 15, 25, 62, 16, 0, 0, 0,
+	24,
 }
-var yyPact = [...]int{
 
-	29, -1000, -1000, 29, -1, -1000, -1000, -1000, -1000, -2,
-	4, -1000, -1000, -1000, -1000, -1000, -1000, -10, -1000, -4,
-	-1000, -1000, -11, -1000, -1000, -1000, -1000, 17, 11, -1000,
-	-1000, -1000, 22, -1000, -1000, 19, -1000, -1000, -1000, -1000,
+var yyPact = [...]int16{
+	35, -1000, 35, -1000, -15, -8, -1000, 35, 40, -1000,
+	-1000, -1000, -1000, 35, 35, -1000, 24, 35, 38, -1000,
+	-1000, -1000, 2, -8, -1000, -1000, -1000, 15, 25, 3,
+	-1000, -1000, -1000, -1000, -1000, 35, 44, -1000, -1000, -1000,
+	-3, 57, 51, 6, -1000, -1000, 18, -1000, -1000, -1000,
+	-1000, 63, -1000, -1000, 59, -1000, -18, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, 6, -21, -1000, -1000,
 	-1000,
 }
-var yyPgo = [...]int{
 
-	0, 39, 38, 30, 28, 25, 19, 33, 13,
+var yyPgo = [...]int8{
+	0, 76, 74, 70, 53, 51, 2, 1, 49, 23,
+	65, 43, 39, 0, 35,
 }
-var yyR1 = [...]int{
 
-	0, 6, 7, 7, 8, 3, 3, 4, 4, 1,
+var yyR1 = [...]int8{
+	0, 14, 6, 6, 7, 8, 8, 9, 9, 10,
+	10, 3, 3, 4, 4, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 5, 2, 2,
+	1, 1, 11, 11, 12, 12, 13, 13, 13, 13,
+	5, 2, 2,
 }
-var yyR2 = [...]int{
 
-	0, 1, 2, 1, 3, 0, 1, 1, 1, 1,
-	1, 1, 2, 4, 2, 4, 3, 3, 1, 1,
-	3, 3, 3, 4, 5, 4, 5, 4, 5, 4,
-	5, 2, 0, 1,
+var yyR2 = [...]int8{
+	0, 1, 2, 1, 1, 3, 1, 3, 1, 2,
+	3, 0, 1, 1, 1, 3, 5, 1, 1, 1,
+	2, 4, 2, 4, 3, 3, 1, 1, 2, 3,
+	3, 3, 4, 4, 5, 4, 5, 4, 5, 4,
+	5, 7, 1, 1, 1, 1, 1, 1, 1, 1,
+	2, 0, 1,
 }
-var yyChk = [...]int{
 
-	-1000, -6, -7, -8, -3, -4, 6, 7, -7, -1,
-	4, 18, 19, 12, 5, -2, -5, 9, 16, 8,
-	17, 12, 4, 18, 19, 12, 5, 13, 14, 16,
-	17, 12, 15, 5, 12, 15, 5, 12, 5, 12,
-	5,
+var yyChk = [...]int16{
+	-1000, -14, -6, -7, -8, -9, -10, 22, -3, -4,
+	6, 7, -7, 21, 20, -10, -1, 10, 4, 18,
+	19, 12, 5, -9, -10, -2, -5, 9, -6, 8,
+	16, 17, 17, 12, 11, 10, 4, 18, 19, 12,
+	5, 13, 14, -11, 23, 25, -6, 16, 17, 17,
+	12, 15, 5, 12, 15, 5, -13, 28, 12, 5,
+	4, 11, 12, 5, 12, 5, 27, -13, -12, 24,
+	26,
 }
-var yyDef = [...]int{
 
-	5, -2, 1, -2, 0, 6, 7, 8, 2, 32,
-	9, 10, 11, 18, 19, 4, 33, 0, 12, 0,
-	14, 31, 20, 16, 17, 21, 22, 0, 0, 13,
-	15, 23, 0, 27, 25, 0, 29, 24, 28, 26,
-	30,
+var yyDef = [...]int8{
+	11, -2, -2, 3, 4, 6, 8, 11, 0, 12,
+	13, 14, 2, 11, 11, 9, 51, 11, 17, 18,
+	19, 26, 27, 5, 7, 10, 52, 0, 11, 0,
+	20, 22, 28, 50, 15, 11, 29, 24, 25, 30,
+	31, 0, 0, 0, 42, 43, 11, 21, 23, 32,
+	33, 0, 37, 35, 0, 39, 0, 46, 47, 48,
+	49, 16, 34, 38, 36, 40, 0, 0, 41, 44,
+	45,
 }
-var yyTok1 = [...]int{
 
+var yyTok1 = [...]int8{
 	1,
 }
-var yyTok2 = [...]int{
 
+var yyTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
-	12, 13, 14, 15, 16, 17, 18, 19,
+	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
+	22, 23, 24, 25, 26, 27, 28,
 }
-var yyTok3 = [...]int{
+
+var yyTok3 = [...]int8{
 	0,
 }
 
@@ -222,9 +261,9 @@ func yyErrorMessage(state, lookAhead int) string {
 	expected := make([]int, 0, 4)
 
 	// Look for shiftable tokens.
-	base := yyPact[state]
+	base := int(yyPact[state])
 	for tok := TOKSTART; tok-1 < len(yyToknames); tok++ {
-		if n := base + tok; n >= 0 && n < yyLast && yyChk[yyAct[n]] == tok {
+		if n := base + tok; n >= 0 && n < yyLast && int(yyChk[int(yyAct[n])]) == tok {
 			if len(expected) == cap(expected) {
 				return res
 			}
@@ -234,13 +273,13 @@ func yyErrorMessage(state, lookAhead int) string {
 
 	if yyDef[state] == -2 {
 		i := 0
-		for yyExca[i] != -1 || yyExca[i+1] != state {
+		for yyExca[i] != -1 || int(yyExca[i+1]) != state {
 			i += 2
 		}
 
 		// Look for tokens that we accept or reduce.
 		for i += 2; yyExca[i] >= 0; i += 2 {
-			tok := yyExca[i]
+			tok := int(yyExca[i])
 			if tok < TOKSTART || yyExca[i+1] == 0 {
 				continue
 			}
@@ -271,30 +310,30 @@ func yylex1(lex yyLexer, lval *yySymType) (char, token int) {
 	token = 0
 	char = lex.Lex(lval)
 	if char <= 0 {
-		token = yyTok1[0]
+		token = int(yyTok1[0])
 		goto out
 	}
 	if char < len(yyTok1) {
-		token = yyTok1[char]
+		token = int(yyTok1[char])
 		goto out
 	}
 	if char >= yyPrivate {
 		if char < yyPrivate+len(yyTok2) {
-			token = yyTok2[char-yyPrivate]
+			token = int(yyTok2[char-yyPrivate])
 			goto out
 		}
 	}
 	for i := 0; i < len(yyTok3); i += 2 {
-		token = yyTok3[i+0]
+		token = int(yyTok3[i+0])
 		if token == char {
-			token = yyTok3[i+1]
+			token = int(yyTok3[i+1])
 			goto out
 		}
 	}
 
 out:
 	if token == 0 {
-		token = yyTok2[1] /* unknown char */
+		token = int(yyTok2[1]) /* unknown char */
 	}
 	if yyDebug >= 3 {
 		__yyfmt__.Printf("lex %s(%d)\n", yyTokname(token), uint(char))
@@ -349,7 +388,7 @@ yystack:
 	yyS[yyp].yys = yystate
 
 yynewstate:
-	yyn = yyPact[yystate]
+	yyn = int(yyPact[yystate])
 	if yyn <= yyFlag {
 		goto yydefault /* simple state */
 	}
@@ -360,8 +399,8 @@ yynewstate:
 	if yyn < 0 || yyn >= yyLast {
 		goto yydefault
 	}
-	yyn = yyAct[yyn]
-	if yyChk[yyn] == yytoken { /* valid shift */
+	yyn = int(yyAct[yyn])
+	if int(yyChk[yyn]) == yytoken { /* valid shift */
 		yyrcvr.char = -1
 		yytoken = -1
 		yyVAL = yyrcvr.lval
@@ -374,7 +413,7 @@ yynewstate:
 
 yydefault:
 	/* default state action */
-	yyn = yyDef[yystate]
+	yyn = int(yyDef[yystate])
 	if yyn == -2 {
 		if yyrcvr.char < 0 {
 			yyrcvr.char, yytoken = yylex1(yylex, &yyrcvr.lval)
@@ -383,18 +422,18 @@ yydefault:
 		/* look through exception table */
 		xi := 0
 		for {
-			if yyExca[xi+0] == -1 && yyExca[xi+1] == yystate {
+			if yyExca[xi+0] == -1 && int(yyExca[xi+1]) == yystate {
 				break
 			}
 			xi += 2
 		}
 		for xi += 2; ; xi += 2 {
-			yyn = yyExca[xi+0]
+			yyn = int(yyExca[xi+0])
 			if yyn < 0 || yyn == yytoken {
 				break
 			}
 		}
-		yyn = yyExca[xi+1]
+		yyn = int(yyExca[xi+1])
 		if yyn < 0 {
 			goto ret0
 		}
@@ -416,10 +455,10 @@ yydefault:
 
 			/* find a state where "error" is a legal shift action */
 			for yyp >= 0 {
-				yyn = yyPact[yyS[yyp].yys] + yyErrCode
+				yyn = int(yyPact[yyS[yyp].yys]) + yyErrCode
 				if yyn >= 0 && yyn < yyLast {
-					yystate = yyAct[yyn] /* simulate a shift of "error" */
-					if yyChk[yystate] == yyErrCode {
+					yystate = int(yyAct[yyn]) /* simulate a shift of "error" */
+					if int(yyChk[yystate]) == yyErrCode {
 						goto yystack
 					}
 				}
@@ -455,7 +494,7 @@ yydefault:
 	yypt := yyp
 	_ = yypt // guard against "declared and not used"
 
-	yyp -= yyR2[yyn]
+	yyp -= int(yyR2[yyn])
 	// yyp is now the index of $0. Perform the default action. Iff the
 	// reduced production is ε, $1 is possibly out of range.
 	if yyp+1 >= len(yyS) {
@@ -466,16 +505,16 @@ yydefault:
 	yyVAL = yyS[yyp+1]
 
 	/* consult goto table to find next state */
-	yyn = yyR1[yyn]
-	yyg := yyPgo[yyn]
+	yyn = int(yyR1[yyn])
+	yyg := int(yyPgo[yyn])
 	yyj := yyg + yyS[yyp].yys + 1
 
 	if yyj >= yyLast {
-		yystate = yyAct[yyg]
+		yystate = int(yyAct[yyg])
 	} else {
-		yystate = yyAct[yyj]
-		if yyChk[yystate] != -yyn {
-			yystate = yyAct[yyg]
+		yystate = int(yyAct[yyj])
+		if int(yyChk[yystate]) != -yyn {
+			yystate = int(yyAct[yyg])
 		}
 	}
 	// dummy call; replaced with literal code
@@ -483,93 +522,145 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:36
+//line query_string.y:49
 		{
 			logDebugGrammar("INPUT")
+			yylex.(*lexerWrapper).query = yylex.(*lexerWrapper).booleanQuery(yyDollar[1].cs)
 		}
 	case 2:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line query_string.y:41
+//line query_string.y:55
 		{
 			logDebugGrammar("SEARCH PARTS")
+			yyVAL.cs = append(yyDollar[1].cs, yyDollar[2].c)
 		}
 	case 3:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:45
+//line query_string.y:60
 		{
 			logDebugGrammar("SEARCH PART")
+			yyVAL.cs = []*queryClause{yyDollar[1].c}
 		}
 	case 4:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:66
+		{
+			yyVAL.c = disjunctionClause(yyDollar[1].cs)
+		}
+	case 5:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line query_string.y:71
+		{
+			logDebugGrammar("OR")
+			yyVAL.cs = append(yyDollar[1].cs, conjunctionClause(yyDollar[3].cs))
+		}
+	case 6:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:76
+		{
+			yyVAL.cs = []*queryClause{conjunctionClause(yyDollar[1].cs)}
+		}
+	case 7:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line query_string.y:50
+//line query_string.y:81
+		{
+			logDebugGrammar("AND")
+			yyVAL.cs = append(yyDollar[1].cs, yyDollar[3].c)
+		}
+	case 8:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:86
+		{
+			yyVAL.cs = []*queryClause{yyDollar[1].c}
+		}
+	case 9:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line query_string.y:91
+		{
+			logDebugGrammar("NOT")
+			yyVAL.c = &queryClause{occur: queryMustNot, query: yyDollar[2].c.clauseQuery()}
+		}
+	case 10:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line query_string.y:96
 		{
 			query := yyDollar[2].q
 			query.SetBoost(yyDollar[3].f)
-			switch yyDollar[1].n {
-			case queryShould:
-				yylex.(*lexerWrapper).query.AddShould(query)
-			case queryMust:
-				yylex.(*lexerWrapper).query.AddMust(query)
-			case queryMustNot:
-				yylex.(*lexerWrapper).query.AddMustNot(query)
-			}
+			yyVAL.c = &queryClause{occur: yyDollar[1].n, query: query}
 		}
-	case 5:
+	case 11:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line query_string.y:65
+//line query_string.y:104
 		{
 			yyVAL.n = queryShould
 		}
-	case 6:
+	case 12:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:69
+//line query_string.y:108
 		{
 			yyVAL.n = yyDollar[1].n
 		}
-	case 7:
+	case 13:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:75
+//line query_string.y:114
 		{
 			logDebugGrammar("PLUS")
 			yyVAL.n = queryMust
 		}
-	case 8:
+	case 14:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:80
+//line query_string.y:119
 		{
 			logDebugGrammar("MINUS")
 			yyVAL.n = queryMustNot
 		}
-	case 9:
+	case 15:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line query_string.y:125
+		{
+			logDebugGrammar("GROUP")
+			yyVAL.q = yylex.(*lexerWrapper).groupQuery(yyDollar[2].cs)
+		}
+	case 16:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line query_string.y:130
+		{
+			field := yyDollar[1].s
+			logDebugGrammar("FIELD - %s GROUP", field)
+			q := yylex.(*lexerWrapper).groupQuery(yyDollar[4].cs)
+			setDefaultField(q, field)
+			yyVAL.q = q
+		}
+	case 17:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:86
+//line query_string.y:138
 		{
 			str := yyDollar[1].s
 			logDebugGrammar("STRING - %s", str)
 			q := NewMatchQuery(str)
 			yyVAL.q = q
 		}
-	case 10:
+	case 18:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:93
+//line query_string.y:145
 		{
 			str := yyDollar[1].s
 			logDebugGrammar("REGEXP - %s", str)
 			q := NewRegexpQuery(str)
 			yyVAL.q = q
 		}
-	case 11:
+	case 19:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:100
+//line query_string.y:152
 		{
 			str := yyDollar[1].s
 			logDebugGrammar("WILDCARD - %s", str)
 			q := NewWildcardQuery(str)
 			yyVAL.q = q
 		}
-	case 12:
+	case 20:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line query_string.y:107
+//line query_string.y:159
 		{
 			str := yyDollar[1].s
 			logDebugGrammar("FUZZY STRING - %s", str)
@@ -577,9 +668,9 @@ yydefault:
 			q.SetFuzziness(1)
 			yyVAL.q = q
 		}
-	case 13:
+	case 21:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line query_string.y:115
+//line query_string.y:167
 		{
 			field := yyDollar[1].s
 			str := yyDollar[3].s
@@ -589,9 +680,9 @@ yydefault:
 			q.SetField(field)
 			yyVAL.q = q
 		}
-	case 14:
+	case 22:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line query_string.y:125
+//line query_string.y:177
 		{
 			str := yyDollar[1].s
 			fuzziness, _ := strconv.ParseFloat(yyDollar[2].s, 64)
@@ -600,9 +691,9 @@ yydefault:
 			q.SetFuzziness(int(fuzziness))
 			yyVAL.q = q
 		}
-	case 15:
+	case 23:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line query_string.y:134
+//line query_string.y:186
 		{
 			field := yyDollar[1].s
 			str := yyDollar[3].s
@@ -613,9 +704,9 @@ yydefault:
 			q.SetField(field)
 			yyVAL.q = q
 		}
-	case 16:
+	case 24:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line query_string.y:145
+//line query_string.y:197
 		{
 			field := yyDollar[1].s
 			str := yyDollar[3].s
@@ -624,9 +715,9 @@ yydefault:
 			q.SetField(field)
 			yyVAL.q = q
 		}
-	case 17:
+	case 25:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line query_string.y:154
+//line query_string.y:206
 		{
 			field := yyDollar[1].s
 			str := yyDollar[3].s
@@ -635,27 +726,38 @@ yydefault:
 			q.SetField(field)
 			yyVAL.q = q
 		}
-	case 18:
+	case 26:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:163
+//line query_string.y:215
 		{
 			str := yyDollar[1].s
 			logDebugGrammar("STRING - %s", str)
 			q := NewMatchQuery(str)
 			yyVAL.q = q
 		}
-	case 19:
+	case 27:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:170
+//line query_string.y:222
 		{
 			phrase := yyDollar[1].s
 			logDebugGrammar("PHRASE - %s", phrase)
 			q := NewMatchPhraseQuery(phrase)
 			yyVAL.q = q
 		}
-	case 20:
+	case 28:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line query_string.y:229
+		{
+			phrase := yyDollar[1].s
+			slop, _ := strconv.ParseFloat(yyDollar[2].s, 64)
+			logDebugGrammar("PHRASE - %s SLOP - %f", phrase, slop)
+			q := NewMatchPhraseQuery(phrase)
+			q.SetSlop(int(slop))
+			yyVAL.q = q
+		}
+	case 29:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line query_string.y:177
+//line query_string.y:238
 		{
 			field := yyDollar[1].s
 			str := yyDollar[3].s
@@ -663,9 +765,9 @@ yydefault:
 			q := NewMatchQuery(str).SetField(field)
 			yyVAL.q = q
 		}
-	case 21:
+	case 30:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line query_string.y:185
+//line query_string.y:246
 		{
 			field := yyDollar[1].s
 			str := yyDollar[3].s
@@ -673,9 +775,9 @@ yydefault:
 			q := NewMatchQuery(str).SetField(field)
 			yyVAL.q = q
 		}
-	case 22:
+	case 31:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line query_string.y:193
+//line query_string.y:254
 		{
 			field := yyDollar[1].s
 			phrase := yyDollar[3].s
@@ -683,9 +785,22 @@ yydefault:
 			q := NewMatchPhraseQuery(phrase).SetField(field)
 			yyVAL.q = q
 		}
-	case 23:
+	case 32:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line query_string.y:201
+//line query_string.y:262
+		{
+			field := yyDollar[1].s
+			phrase := yyDollar[3].s
+			slop, _ := strconv.ParseFloat(yyDollar[4].s, 64)
+			logDebugGrammar("FIELD - %s PHRASE - %s SLOP - %f", field, phrase, slop)
+			q := NewMatchPhraseQuery(phrase)
+			q.SetSlop(int(slop))
+			q.SetField(field)
+			yyVAL.q = q
+		}
+	case 33:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line query_string.y:273
 		{
 			field := yyDollar[1].s
 			min, _ := strconv.ParseFloat(yyDollar[4].s, 64)
@@ -694,9 +809,9 @@ yydefault:
 			q := NewNumericRangeInclusiveQuery(&min, nil, &minInclusive, nil).SetField(field)
 			yyVAL.q = q
 		}
-	case 24:
+	case 34:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line query_string.y:210
+//line query_string.y:282
 		{
 			field := yyDollar[1].s
 			min, _ := strconv.ParseFloat(yyDollar[5].s, 64)
@@ -705,9 +820,9 @@ yydefault:
 			q := NewNumericRangeInclusiveQuery(&min, nil, &minInclusive, nil).SetField(field)
 			yyVAL.q = q
 		}
-	case 25:
+	case 35:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line query_string.y:219
+//line query_string.y:291
 		{
 			field := yyDollar[1].s
 			max, _ := strconv.ParseFloat(yyDollar[4].s, 64)
@@ -716,9 +831,9 @@ yydefault:
 			q := NewNumericRangeInclusiveQuery(nil, &max, nil, &maxInclusive).SetField(field)
 			yyVAL.q = q
 		}
-	case 26:
+	case 36:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line query_string.y:228
+//line query_string.y:300
 		{
 			field := yyDollar[1].s
 			max, _ := strconv.ParseFloat(yyDollar[5].s, 64)
@@ -727,9 +842,9 @@ yydefault:
 			q := NewNumericRangeInclusiveQuery(nil, &max, nil, &maxInclusive).SetField(field)
 			yyVAL.q = q
 		}
-	case 27:
+	case 37:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line query_string.y:237
+//line query_string.y:309
 		{
 			field := yyDollar[1].s
 			minInclusive := false
@@ -739,9 +854,9 @@ yydefault:
 			q := NewDateRangeInclusiveQuery(&phrase, nil, &minInclusive, nil).SetField(field)
 			yyVAL.q = q
 		}
-	case 28:
+	case 38:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line query_string.y:247
+//line query_string.y:319
 		{
 			field := yyDollar[1].s
 			minInclusive := true
@@ -751,9 +866,9 @@ yydefault:
 			q := NewDateRangeInclusiveQuery(&phrase, nil, &minInclusive, nil).SetField(field)
 			yyVAL.q = q
 		}
-	case 29:
+	case 39:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line query_string.y:257
+//line query_string.y:329
 		{
 			field := yyDollar[1].s
 			maxInclusive := false
@@ -763,9 +878,9 @@ yydefault:
 			q := NewDateRangeInclusiveQuery(nil, &phrase, nil, &maxInclusive).SetField(field)
 			yyVAL.q = q
 		}
-	case 30:
+	case 40:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line query_string.y:267
+//line query_string.y:339
 		{
 			field := yyDollar[1].s
 			maxInclusive := true
@@ -775,23 +890,85 @@ yydefault:
 			q := NewDateRangeInclusiveQuery(nil, &phrase, nil, &maxInclusive).SetField(field)
 			yyVAL.q = q
 		}
-	case 31:
+	case 41:
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line query_string.y:349
+		{
+			field := yyDollar[1].s
+			logDebugGrammar("FIELD - %s RANGE", field)
+			q, err := newRangeQuery(yyDollar[4].rb, yyDollar[6].rb, yyDollar[3].b, yyDollar[7].b)
+			if err != nil {
+				yylex.(*lexerWrapper).Error(err.Error())
+				q = NewMatchNoneQuery()
+			}
+			q.SetField(field)
+			yyVAL.q = q
+		}
+	case 42:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:362
+		{
+			yyVAL.b = true
+		}
+	case 43:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:366
+		{
+			yyVAL.b = false
+		}
+	case 44:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:371
+		{
+			yyVAL.b = true
+		}
+	case 45:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:375
+		{
+			yyVAL.b = false
+		}
+	case 46:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:380
+		{
+			yyVAL.rb = &rangeBound{kind: rangeBoundOpen}
+		}
+	case 47:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:384
+		{
+			yyVAL.rb = &rangeBound{kind: rangeBoundNumber, value: yyDollar[1].s}
+		}
+	case 48:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:388
+		{
+			yyVAL.rb = newQuotedRangeBound(yyDollar[1].s)
+		}
+	case 49:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line query_string.y:392
+		{
+			yyVAL.rb = &rangeBound{kind: rangeBoundTerm, value: yyDollar[1].s}
+		}
+	case 50:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line query_string.y:278
+//line query_string.y:397
 		{
 			boost, _ := strconv.ParseFloat(yyDollar[2].s, 64)
 			yyVAL.f = boost
 			logDebugGrammar("BOOST %f", boost)
 		}
-	case 32:
+	case 51:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line query_string.y:285
+//line query_string.y:404
 		{
 			yyVAL.f = 1.0
 		}
-	case 33:
+	case 52:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line query_string.y:289
+//line query_string.y:408
 		{
 
 		}
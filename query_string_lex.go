@@ -0,0 +1,313 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"strings"
+)
+
+func logDebugTokens(format string, v ...interface{}) {
+	if debugLexer {
+		logger.Printf(format, v...)
+	}
+}
+
+// runes which can neither start a term nor continue it
+const (
+	termStartExcluded    = "\t\n\f\r :^+*?><=~-"
+	termExcluded         = "\t\n\f\r :^~*?"
+	wildcardTermExcluded = "\t\n\f\r :^~"
+)
+
+// queryStringLex splits a query string into tokens. The
+// longest token wins, ties going to the first of phrases,
+// regular expressions, operators, fuzziness, numbers, terms
+// and wildcard terms. Runes no token can start with are
+// skipped.
+//
+// Parentheses and range brackets also depend on the context:
+// an opening parenthesis always starts a group, a closing one
+// ends a term when it closes an open group, and the brackets
+// following a colon start a range, ended by the first closing
+// bracket.
+type queryStringLex struct {
+	input   []rune
+	pos     int
+	prev    int
+	groups  int
+	inRange bool
+}
+
+func newQueryStringLex(query string) *queryStringLex {
+	return &queryStringLex{
+		input: []rune(query),
+	}
+}
+
+func (l *queryStringLex) Lex(lval *yySymType) int {
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		if r == ' ' || r == '\t' || r == '\n' {
+			l.pos++
+			continue
+		}
+		tok, n := l.token(lval)
+		if n == 0 {
+			logDebugTokens("SKIPPED - %c", r)
+			l.pos++
+			continue
+		}
+		l.pos += n
+		l.prev = tok
+		return tok
+	}
+	return 0
+}
+
+// Error does nothing, the errors being collected
+// by the lexerWrapper.
+func (l *queryStringLex) Error(s string) {
+}
+
+// token returns the token at the current position and
+// its length, 0 if there is none.
+func (l *queryStringLex) token(lval *yySymType) (int, int) {
+	r := l.input[l.pos]
+	switch {
+	case r == '(':
+		logDebugTokens("LPAREN")
+		l.groups++
+		return tLPAREN, 1
+	case r == ')':
+		logDebugTokens("RPAREN")
+		if l.groups > 0 {
+			l.groups--
+		}
+		return tRPAREN, 1
+	case l.prev == tCOLON && (r == '[' || r == '{'):
+		logDebugTokens("RANGE START - %c", r)
+		l.inRange = true
+		if r == '[' {
+			return tLBRACKET, 1
+		}
+		return tLBRACE, 1
+	case l.inRange && (r == ']' || r == '}'):
+		logDebugTokens("RANGE END - %c", r)
+		l.inRange = false
+		if r == ']' {
+			return tRBRACKET, 1
+		}
+		return tRBRACE, 1
+	case l.inRange && r == '*' && l.boundaryAt(l.pos+1):
+		logDebugTokens("STAR")
+		return tSTAR, 1
+	}
+
+	phraseLen := l.quoted('"')
+	regexpLen := l.quoted('/')
+	operator, operatorLen := l.operator()
+	tildeNumberLen := 0
+	tildeLen := 0
+	if r == '~' {
+		tildeLen = 1
+		if n := l.integer(l.pos + 1); n > 0 {
+			tildeNumberLen = 1 + n
+		}
+	}
+	numberLen := l.number()
+	termLen := l.term(termExcluded)
+	wildcardTermLen := l.term(wildcardTermExcluded)
+
+	lengths := []int{phraseLen, regexpLen, operatorLen, tildeNumberLen, tildeLen, numberLen, termLen, wildcardTermLen}
+	longest := 0
+	for i, n := range lengths {
+		if n > lengths[longest] {
+			longest = i
+		}
+	}
+	n := lengths[longest]
+	if n == 0 {
+		return 0, 0
+	}
+	text := string(l.input[l.pos : l.pos+n])
+
+	switch longest {
+	case 0:
+		lval.s = text[1 : len(text)-1]
+		logDebugTokens("PHRASE - %s", lval.s)
+		return tPHRASE, n
+	case 1:
+		lval.s = text[1 : len(text)-1]
+		logDebugTokens("REGEXP - %s", lval.s)
+		return tREGEXP, n
+	case 2:
+		logDebugTokens("OPERATOR - %s", text)
+		return operator, n
+	case 3:
+		lval.s = text[1:]
+		logDebugTokens("TILDENUMBER - %s", lval.s)
+		return tTILDENUMBER, n
+	case 4:
+		logDebugTokens("TILDE")
+		return tTILDE, n
+	case 5:
+		lval.s = text
+		logDebugTokens("NUMBER - %s", lval.s)
+		return tNUMBER, n
+	case 6:
+		switch {
+		case text == "AND" || text == "&&":
+			logDebugTokens("AND")
+			return tAND, n
+		case text == "OR" || text == "||":
+			logDebugTokens("OR")
+			return tOR, n
+		case text == "NOT":
+			logDebugTokens("NOT")
+			return tNOT, n
+		case text == "TO" && l.inRange:
+			logDebugTokens("TO")
+			return tTO, n
+		}
+		lval.s = text
+		logDebugTokens("STRING - %s", lval.s)
+		return tSTRING, n
+	}
+	lval.s = text
+	logDebugTokens("WILD - %s", lval.s)
+	return tWILD, n
+}
+
+// boundaryAt returns whether a token can end before pos.
+func (l *queryStringLex) boundaryAt(pos int) bool {
+	if pos >= len(l.input) {
+		return true
+	}
+	r := l.input[pos]
+	return r == ' ' || r == '\t' || r == '\n' || r == ']' || r == '}'
+}
+
+// quoted returns the length of the text delimited by delim at the
+// current position, within which delim can be escaped by a backslash.
+func (l *queryStringLex) quoted(delim rune) int {
+	if l.input[l.pos] != delim {
+		return 0
+	}
+	rv := 0
+	// a backslash is either a rune of its own or escapes delim,
+	// so both are followed until no longer possible
+	plain, escaping := true, false
+	for i := l.pos + 1; i < len(l.input) && (plain || escaping); i++ {
+		r := l.input[i]
+		nextPlain, nextEscaping := false, false
+		if plain {
+			if r == delim {
+				rv = i + 1 - l.pos
+			} else {
+				nextPlain = true
+				nextEscaping = r == '\\'
+			}
+		}
+		if escaping && r == delim {
+			nextPlain = true
+		}
+		plain, escaping = nextPlain, nextEscaping
+	}
+	return rv
+}
+
+func (l *queryStringLex) operator() (int, int) {
+	switch l.input[l.pos] {
+	case '+':
+		return tPLUS, 1
+	case '-':
+		return tMINUS, 1
+	case ':':
+		return tCOLON, 1
+	case '^':
+		return tBOOST, 1
+	case '>':
+		return tGREATER, 1
+	case '<':
+		return tLESS, 1
+	case '=':
+		return tEQUAL, 1
+	}
+	return 0, 0
+}
+
+// integer returns the length of the integer at pos,
+// either 0 or without leading zeros.
+func (l *queryStringLex) integer(pos int) int {
+	if pos >= len(l.input) || !isDigit(l.input[pos]) {
+		return 0
+	}
+	if l.input[pos] == '0' {
+		return 1
+	}
+	rv := 1
+	for pos+rv < len(l.input) && isDigit(l.input[pos+rv]) {
+		rv++
+	}
+	return rv
+}
+
+// number returns the length of the optionally negative,
+// optionally decimal number at the current position.
+func (l *queryStringLex) number() int {
+	pos := l.pos
+	if l.input[pos] == '-' {
+		pos++
+	}
+	n := l.integer(pos)
+	if n == 0 {
+		return 0
+	}
+	pos += n
+	if pos+1 < len(l.input) && l.input[pos] == '.' && isDigit(l.input[pos+1]) {
+		pos += 2
+		for pos < len(l.input) && isDigit(l.input[pos]) {
+			pos++
+		}
+	}
+	return pos - l.pos
+}
+
+// term returns the length of the term at the current position,
+// made of runes not in excluded.
+func (l *queryStringLex) term(excluded string) int {
+	if strings.ContainsRune(termStartExcluded, l.input[l.pos]) {
+		return 0
+	}
+	parens := 0
+	i := l.pos
+	for ; i < len(l.input); i++ {
+		r := l.input[i]
+		if i > l.pos && strings.ContainsRune(excluded, r) {
+			break
+		}
+		if l.inRange && (r == ']' || r == '}') {
+			break
+		}
+		if r == '(' {
+			parens++
+		} else if r == ')' {
+			if parens == 0 && l.groups > 0 {
+				break
+			}
+			parens--
+		}
+	}
+	return i - l.pos
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -7,11 +7,6 @@
 //  either express or implied. See the License for the specific language governing permissions
 //  and limitations under the License.
 
-//go:generate nex query_string.nex
-//go:generate sed -i "" -e s/Lexer/lexer/g query_string.nn.go
-//go:generate sed -i "" -e s/Newlexer/newLexer/g query_string.nn.go
-//go:generate sed -i "" -e s/debuglexer/debugLexer/g query_string.nn.go
-//go:generate go fmt query_string.nn.go
 //go:generate go tool yacc -o query_string.y.go query_string.y
 //go:generate sed -i "" -e 1d query_string.y.go
 
@@ -19,6 +14,7 @@ package bleve
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -26,7 +22,14 @@ var debugParser bool
 var debugLexer bool
 
 func parseQuerySyntax(query string) (rq Query, err error) {
-	lex := newLexerWrapper(newLexer(strings.NewReader(query)))
+	return parseQuerySyntaxWithOperator(query, QueryStringOperatorOr)
+}
+
+// parseQuerySyntaxWithOperator parses query, combining the
+// clauses without explicit operators with defaultOperator.
+func parseQuerySyntaxWithOperator(query string, defaultOperator string) (rq Query, err error) {
+	lex := newLexerWrapper(newQueryStringLex(query))
+	lex.defaultOperator = defaultOperator
 	doParse(lex)
 
 	if len(lex.errs) > 0 {
@@ -53,22 +56,216 @@ const (
 	queryMustNot
 )
 
+// A queryClause is a query along with the prefix it was
+// written with, queryShould when there was none.
+type queryClause struct {
+	occur int
+	query Query
+}
+
+// clauseQuery returns the query of c, matching the documents
+// not matched by it when c is negated.
+func (c *queryClause) clauseQuery() Query {
+	if c.occur == queryMustNot {
+		return NewBooleanQuery(nil, nil, []Query{c.query})
+	}
+	return c.query
+}
+
+// conjunctionClause combines the operands of AND.
+func conjunctionClause(clauses []*queryClause) *queryClause {
+	if len(clauses) == 1 {
+		return clauses[0]
+	}
+	var must, mustNot []Query
+	for _, c := range clauses {
+		if c.occur == queryMustNot {
+			mustNot = append(mustNot, c.query)
+		} else {
+			must = append(must, c.query)
+		}
+	}
+	return &queryClause{occur: queryShould, query: NewBooleanQuery(must, nil, mustNot)}
+}
+
+// disjunctionClause combines the operands of OR.
+func disjunctionClause(clauses []*queryClause) *queryClause {
+	if len(clauses) == 1 {
+		return clauses[0]
+	}
+	disjuncts := make([]Query, len(clauses))
+	for i, c := range clauses {
+		disjuncts[i] = c.clauseQuery()
+	}
+	return &queryClause{occur: queryShould, query: NewDisjunctionQuery(disjuncts)}
+}
+
+// setDefaultField sets field on the queries nested in q
+// which do not search a field of their own.
+func setDefaultField(q Query, field string) {
+	switch q := q.(type) {
+	case *booleanQuery:
+		for _, child := range []Query{q.Must, q.Should, q.MustNot} {
+			if child != nil {
+				setDefaultField(child, field)
+			}
+		}
+	case *conjunctionQuery:
+		for _, child := range q.Conjuncts {
+			setDefaultField(child, field)
+		}
+	case *disjunctionQuery:
+		for _, child := range q.Disjuncts {
+			setDefaultField(child, field)
+		}
+	default:
+		if q.Field() == "" {
+			q.SetField(field)
+		}
+	}
+}
+
+// The kinds of bound of a range.
+const (
+	rangeBoundOpen = iota
+	rangeBoundNumber
+	rangeBoundDate
+	rangeBoundTerm
+)
+
+// A rangeBound is a bound of a range, open when written *.
+type rangeBound struct {
+	kind  int
+	value string
+}
+
+// newQuotedRangeBound returns the bound of a quoted value, a
+// date when parsed by Config.QueryDateTimeParser, and a term
+// otherwise.
+func newQuotedRangeBound(value string) *rangeBound {
+	dateTimeParser, err := Config.Cache.DateTimeParserNamed(Config.QueryDateTimeParser)
+	if err == nil && dateTimeParser != nil {
+		_, err = dateTimeParser.ParseDateTime(value)
+		if err == nil {
+			return &rangeBound{kind: rangeBoundDate, value: value}
+		}
+	}
+	return &rangeBound{kind: rangeBoundTerm, value: value}
+}
+
+// newRangeQuery builds the query of a range, a numeric range
+// when its bounds are numbers, a date range when they are
+// dates, and a term range otherwise.
+func newRangeQuery(min, max *rangeBound, minInclusive, maxInclusive bool) (Query, error) {
+	kind := rangeBoundOpen
+	for _, bound := range []*rangeBound{min, max} {
+		switch {
+		case bound.kind == rangeBoundOpen:
+		case kind == rangeBoundOpen || kind == bound.kind:
+			kind = bound.kind
+		case kind == rangeBoundDate || bound.kind == rangeBoundDate:
+			return nil, fmt.Errorf("range cannot mix a date with another bound")
+		default:
+			// numbers and terms are compared as terms
+			kind = rangeBoundTerm
+		}
+	}
+
+	var minInclusivePtr, maxInclusivePtr *bool
+	if min.kind != rangeBoundOpen {
+		minInclusivePtr = &minInclusive
+	}
+	if max.kind != rangeBoundOpen {
+		maxInclusivePtr = &maxInclusive
+	}
+
+	switch kind {
+	case rangeBoundNumber:
+		var minNum, maxNum *float64
+		if min.kind != rangeBoundOpen {
+			f, err := strconv.ParseFloat(min.value, 64)
+			if err != nil {
+				return nil, err
+			}
+			minNum = &f
+		}
+		if max.kind != rangeBoundOpen {
+			f, err := strconv.ParseFloat(max.value, 64)
+			if err != nil {
+				return nil, err
+			}
+			maxNum = &f
+		}
+		return NewNumericRangeInclusiveQuery(minNum, maxNum, minInclusivePtr, maxInclusivePtr), nil
+	case rangeBoundDate:
+		var start, end *string
+		if min.kind != rangeBoundOpen {
+			start = &min.value
+		}
+		if max.kind != rangeBoundOpen {
+			end = &max.value
+		}
+		return NewDateRangeInclusiveQuery(start, end, minInclusivePtr, maxInclusivePtr), nil
+	case rangeBoundTerm:
+		var minTerm, maxTerm *string
+		if min.kind != rangeBoundOpen {
+			minTerm = &min.value
+		}
+		if max.kind != rangeBoundOpen {
+			maxTerm = &max.value
+		}
+		return NewTermRangeInclusiveQuery(minTerm, maxTerm, minInclusivePtr, maxInclusivePtr), nil
+	}
+	return nil, fmt.Errorf("range must have at least one bound")
+}
+
 type lexerWrapper struct {
-	nex   yyLexer
-	errs  []string
-	query *booleanQuery
+	lex             yyLexer
+	errs            []string
+	query           *booleanQuery
+	defaultOperator string
 }
 
-func newLexerWrapper(nex yyLexer) *lexerWrapper {
+func newLexerWrapper(lex yyLexer) *lexerWrapper {
 	return &lexerWrapper{
-		nex:   nex,
+		lex:   lex,
 		errs:  []string{},
 		query: NewBooleanQuery(nil, nil, nil),
 	}
 }
 
+// booleanQuery combines clauses according to their prefix,
+// those without being combined with the default operator.
+func (this *lexerWrapper) booleanQuery(clauses []*queryClause) *booleanQuery {
+	rv := NewBooleanQuery(nil, nil, nil)
+	for _, c := range clauses {
+		switch c.occur {
+		case queryShould:
+			if this.defaultOperator == QueryStringOperatorAnd {
+				rv.AddMust(c.query)
+			} else {
+				rv.AddShould(c.query)
+			}
+		case queryMust:
+			rv.AddMust(c.query)
+		case queryMustNot:
+			rv.AddMustNot(c.query)
+		}
+	}
+	return rv
+}
+
+// groupQuery combines the clauses of a group, a single
+// clause without prefix being returned as is.
+func (this *lexerWrapper) groupQuery(clauses []*queryClause) Query {
+	if len(clauses) == 1 && clauses[0].occur == queryShould {
+		return clauses[0].query
+	}
+	return this.booleanQuery(clauses)
+}
+
 func (this *lexerWrapper) Lex(lval *yySymType) int {
-	return this.nex.Lex(lval)
+	return this.lex.Lex(lval)
 }
 
 func (this *lexerWrapper) Error(s string) {
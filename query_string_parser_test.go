@@ -19,6 +19,15 @@ func TestQuerySyntaxParserValid(t *testing.T) {
 	theTruth := true
 	theFalsehood := false
 	theDate := "2006-01-02T15:04:05Z07:00"
+	ten := 10.0
+	twenty := 20.0
+	tenTerm := "10"
+	startDate := "2016-01-01"
+	endDate := "2016-07-01"
+	apple := "apple"
+	banana := "banana"
+	quotedTerm := "a b"
+	c := "c"
 	tests := []struct {
 		input   string
 		result  Query
@@ -139,6 +148,26 @@ func TestQuerySyntaxParserValid(t *testing.T) {
 				},
 				nil),
 		},
+		{
+			input:   `"test phrase 1"~2`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewMatchPhraseQuery("test phrase 1").SetSlop(2),
+				},
+				nil),
+		},
+		{
+			input:   `field3:"test phrase 2"~3`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewMatchPhraseQuery("test phrase 2").SetSlop(3).SetField("field3"),
+				},
+				nil),
+		},
 		{
 			input:   `+field4:"test phrase 1"`,
 			mapping: NewIndexMapping(),
@@ -365,6 +394,241 @@ func TestQuerySyntaxParserValid(t *testing.T) {
 				},
 				nil),
 		},
+		{
+			input:   `title:(go OR golang) AND NOT status:draft`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewBooleanQuery(
+						[]Query{
+							NewDisjunctionQuery([]Query{
+								NewMatchQuery("go").SetField("title"),
+								NewMatchQuery("golang").SetField("title"),
+							}),
+						},
+						nil,
+						[]Query{
+							NewMatchQuery("draft").SetField("status"),
+						}),
+				},
+				nil),
+		},
+		// AND binds tighter than OR
+		{
+			input:   `a OR b AND c`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewDisjunctionQuery([]Query{
+						NewMatchQuery("a"),
+						NewBooleanQuery(
+							[]Query{
+								NewMatchQuery("b"),
+								NewMatchQuery("c"),
+							},
+							nil,
+							nil),
+					}),
+				},
+				nil),
+		},
+		{
+			input:   `a || NOT b && c`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewDisjunctionQuery([]Query{
+						NewMatchQuery("a"),
+						NewBooleanQuery(
+							[]Query{
+								NewMatchQuery("c"),
+							},
+							nil,
+							[]Query{
+								NewMatchQuery("b"),
+							}),
+					}),
+				},
+				nil),
+		},
+		{
+			input:   `NOT a`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				nil,
+				[]Query{
+					NewMatchQuery("a"),
+				}),
+		},
+		{
+			input:   `a OR NOT b`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewDisjunctionQuery([]Query{
+						NewMatchQuery("a"),
+						NewBooleanQuery(nil, nil, []Query{NewMatchQuery("b")}),
+					}),
+				},
+				nil),
+		},
+		{
+			input:   `+(a b) -c`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				[]Query{
+					NewBooleanQuery(
+						nil,
+						[]Query{
+							NewMatchQuery("a"),
+							NewMatchQuery("b"),
+						},
+						nil),
+				},
+				nil,
+				[]Query{
+					NewMatchQuery("c"),
+				}),
+		},
+		{
+			input:   `((a))^2`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewMatchQuery("a").SetBoost(2),
+				},
+				nil),
+		},
+		// fields within a group override the field of the group
+		{
+			input:   `title:(a body:"b c" (d OR e))`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewBooleanQuery(
+						nil,
+						[]Query{
+							NewMatchQuery("a").SetField("title"),
+							NewMatchPhraseQuery("b c").SetField("body"),
+							NewDisjunctionQuery([]Query{
+								NewMatchQuery("d").SetField("title"),
+								NewMatchQuery("e").SetField("title"),
+							}),
+						},
+						nil),
+				},
+				nil),
+		},
+		// parentheses are allowed inside a term, phrase or regexp
+		{
+			input:   `(f(x) "(a" /b(c)/)`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewBooleanQuery(
+						nil,
+						[]Query{
+							NewMatchQuery("f(x)"),
+							NewMatchPhraseQuery("(a"),
+							NewRegexpQuery("b(c)"),
+						},
+						nil),
+				},
+				nil),
+		},
+		{
+			input:   `price:[10 TO 20}`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewNumericRangeInclusiveQuery(&ten, &twenty, &theTruth, &theFalsehood).SetField("price"),
+				},
+				nil),
+		},
+		{
+			input:   `price:{10 TO *]`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewNumericRangeInclusiveQuery(&ten, nil, &theFalsehood, nil).SetField("price"),
+				},
+				nil),
+		},
+		{
+			input:   `created:["2016-01-01" TO "2016-07-01"}`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewDateRangeInclusiveQuery(&startDate, &endDate, &theTruth, &theFalsehood).SetField("created"),
+				},
+				nil),
+		},
+		{
+			input:   `created:[* TO "2016-07-01"]`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewDateRangeInclusiveQuery(nil, &endDate, nil, &theTruth).SetField("created"),
+				},
+				nil),
+		},
+		{
+			input:   `name:[apple TO banana]`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewTermRangeInclusiveQuery(&apple, &banana, &theTruth, &theTruth).SetField("name"),
+				},
+				nil),
+		},
+		// numbers and terms are compared as terms
+		{
+			input:   `+(name:{10 TO banana}) -name:{* TO apple}`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				[]Query{
+					NewTermRangeInclusiveQuery(&tenTerm, &banana, &theFalsehood, &theFalsehood).SetField("name"),
+				},
+				nil,
+				[]Query{
+					NewTermRangeInclusiveQuery(nil, &apple, nil, &theFalsehood).SetField("name"),
+				}),
+		},
+		// quoted bounds which are not dates are terms
+		{
+			input:   `name:["a b" TO c]`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewTermRangeInclusiveQuery(&quotedTerm, &c, &theTruth, &theTruth).SetField("name"),
+				},
+				nil),
+		},
+		// brackets are allowed inside a term outside of a range
+		{
+			input:   `name:a[1]`,
+			mapping: NewIndexMapping(),
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewMatchQuery("a[1]").SetField("name"),
+				},
+				nil),
+		},
 	}
 
 	// turn on lexer debugging
@@ -399,6 +663,18 @@ func TestQuerySyntaxParserInvalid(t *testing.T) {
 		{"field:~text"},
 		{"field:^text"},
 		{"field::text"},
+		{"(a"},
+		{"(a))"},
+		{"a AND"},
+		{"OR a"},
+		{"NOT"},
+		{"field:("},
+		{"()"},
+		{"field:[* TO *]"},
+		{`field:["2016-01-01" TO b]`},
+		{"field:[1 TO]"},
+		{"field:[1 2]"},
+		{"field:[1 TO 2"},
 	}
 
 	// turn on lexer debugging
@@ -412,3 +688,71 @@ func TestQuerySyntaxParserInvalid(t *testing.T) {
 		}
 	}
 }
+
+func TestQuerySyntaxParserDefaultOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		operator string
+		result   Query
+	}{
+		{
+			input:    "a b",
+			operator: QueryStringOperatorAnd,
+			result: NewBooleanQuery(
+				[]Query{
+					NewMatchQuery("a"),
+					NewMatchQuery("b"),
+				},
+				nil,
+				nil),
+		},
+		{
+			input:    "a OR b -c (d e)",
+			operator: QueryStringOperatorAnd,
+			result: NewBooleanQuery(
+				[]Query{
+					NewDisjunctionQuery([]Query{
+						NewMatchQuery("a"),
+						NewMatchQuery("b"),
+					}),
+					NewBooleanQuery(
+						[]Query{
+							NewMatchQuery("d"),
+							NewMatchQuery("e"),
+						},
+						nil,
+						nil),
+				},
+				nil,
+				[]Query{
+					NewMatchQuery("c"),
+				}),
+		},
+		{
+			input:    "a b",
+			operator: QueryStringOperatorOr,
+			result: NewBooleanQuery(
+				nil,
+				[]Query{
+					NewMatchQuery("a"),
+					NewMatchQuery("b"),
+				},
+				nil),
+		},
+	}
+
+	for _, test := range tests {
+		q, err := parseQuerySyntaxWithOperator(test.input, test.operator)
+		if err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(q, test.result) {
+			t.Errorf("Expected %#v, got %#v: for %s with %s", test.result, q, test.input, test.operator)
+		}
+	}
+
+	err := NewQueryStringQuery("a b").SetDefaultOperator("xor").Validate()
+	if err == nil {
+		t.Errorf("expected error for unknown default operator")
+	}
+}
@@ -53,9 +53,18 @@ func (q *termQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool)
 	if q.FieldVal == "" {
 		field = m.DefaultField
 	}
-	return searchers.NewTermSearcher(i, q.Term, field, q.BoostVal, explain)
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewTermSearcherWithSimilarity(i, q.Term, field, q.BoostVal, similarity, explain)
 }
 
 func (q *termQuery) Validate() error {
 	return nil
 }
+
+func (q *termQuery) MarshalJSON() ([]byte, error) {
+	type _termQuery termQuery
+	return marshalQuery("term", (*_termQuery)(q))
+}
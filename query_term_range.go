@@ -0,0 +1,91 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searchers"
+)
+
+type termRangeQuery struct {
+	Min          *string `json:"min,omitempty"`
+	Max          *string `json:"max,omitempty"`
+	InclusiveMin *bool   `json:"inclusive_min,omitempty"`
+	InclusiveMax *bool   `json:"inclusive_max,omitempty"`
+	FieldVal     string  `json:"field,omitempty"`
+	BoostVal     float64 `json:"boost,omitempty"`
+}
+
+// NewTermRangeQuery creates a new Query for ranges
+// of terms, compared lexicographically, typically in
+// fields indexed with the keyword analyzer.
+// Either, but not both endpoints can be nil.
+// The minimum value is inclusive.
+// The maximum value is exclusive.
+func NewTermRangeQuery(min, max *string) *termRangeQuery {
+	return NewTermRangeInclusiveQuery(min, max, nil, nil)
+}
+
+// NewTermRangeInclusiveQuery creates a new Query for ranges
+// of terms, compared lexicographically.
+// Either, but not both endpoints can be nil.
+// Control endpoint inclusion with inclusiveMin, inclusiveMax.
+func NewTermRangeInclusiveQuery(min, max *string, minInclusive, maxInclusive *bool) *termRangeQuery {
+	return &termRangeQuery{
+		Min:          min,
+		Max:          max,
+		InclusiveMin: minInclusive,
+		InclusiveMax: maxInclusive,
+		BoostVal:     1.0,
+	}
+}
+
+func (q *termRangeQuery) Boost() float64 {
+	return q.BoostVal
+}
+
+func (q *termRangeQuery) SetBoost(b float64) Query {
+	q.BoostVal = b
+	return q
+}
+
+func (q *termRangeQuery) Field() string {
+	return q.FieldVal
+}
+
+func (q *termRangeQuery) SetField(f string) Query {
+	q.FieldVal = f
+	return q
+}
+
+func (q *termRangeQuery) Searcher(i index.IndexReader, m *IndexMapping, explain bool) (search.Searcher, error) {
+	field := q.FieldVal
+	if q.FieldVal == "" {
+		field = m.DefaultField
+	}
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewTermRangeSearcherWithSimilarity(i, q.Min, q.Max, q.InclusiveMin, q.InclusiveMax, field, q.BoostVal, similarity, explain)
+}
+
+func (q *termRangeQuery) Validate() error {
+	if q.Min == nil && q.Max == nil {
+		return ErrorTermRangeQueryNoBounds
+	}
+	return nil
+}
+
+func (q *termRangeQuery) MarshalJSON() ([]byte, error) {
+	type _termRangeQuery termRangeQuery
+	return marshalQuery("term_range", (*_termRangeQuery)(q))
+}
@@ -10,6 +10,8 @@
 package bleve
 
 import (
+	"encoding/json"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -19,6 +21,9 @@ var minNum = 5.1
 var maxNum = 7.1
 var startDate = "2011-01-01"
 var endDate = "2012-01-01"
+var minTerm = "apple"
+var maxTerm = "banana"
+var inclusiveTrue = true
 
 func TestParseQuery(t *testing.T) {
 	tests := []struct {
@@ -34,6 +39,14 @@ func TestParseQuery(t *testing.T) {
 			input:  []byte(`{"match":"beer","field":"desc"}`),
 			output: NewMatchQuery("beer").SetField("desc"),
 		},
+		{
+			input:  []byte(`{"match":"red leather sofa","field":"desc","operator":"and"}`),
+			output: NewMatchQuery("red leather sofa").SetOperator(MatchQueryOperatorAnd).SetField("desc"),
+		},
+		{
+			input:  []byte(`{"match":"red leather sofa","field":"desc","minimum_should_match":"3<75%"}`),
+			output: NewMatchQuery("red leather sofa").SetMinimumShouldMatch("3<75%").SetField("desc"),
+		},
 		{
 			input:  []byte(`{"match_phrase":"light beer","field":"desc"}`),
 			output: NewMatchPhraseQuery("light beer").SetField("desc"),
@@ -46,6 +59,23 @@ func TestParseQuery(t *testing.T) {
 				[]Query{NewMatchQuery("devon").SetField("desc")},
 				1.0),
 		},
+		{
+			input: []byte(`{"must":{"conjuncts": [{"match":"beer","field":"desc"}]},"should":{"disjuncts": [{"match":"water","field":"desc"},{"match":"light","field":"desc"}],"minimum_should_match":"-1"}}`),
+			output: NewBooleanQuery(
+				[]Query{NewMatchQuery("beer").SetField("desc")},
+				[]Query{NewMatchQuery("water").SetField("desc"), NewMatchQuery("light").SetField("desc")},
+				nil).SetMinimumShouldMatch("-1"),
+		},
+		{
+			input: []byte(`{"must":{"conjuncts": [{"match":"beer","field":"desc"}]},"should":{"disjuncts": [{"match":"water","field":"desc"},{"match":"light","field":"desc"}],"min":0,"minimum_should_match":"-1"}}`),
+			output: func() Query {
+				q := NewBooleanQuery([]Query{NewMatchQuery("beer").SetField("desc")}, nil, nil)
+				q.SetMinimumShouldMatch("-1")
+				q.AddShould(NewMatchQuery("water").SetField("desc"))
+				q.AddShould(NewMatchQuery("light").SetField("desc"))
+				return q
+			}(),
+		},
 		{
 			input:  []byte(`{"terms":["watered","down"],"field":"desc"}`),
 			output: NewPhraseQuery([]string{"watered", "down"}, "desc"),
@@ -54,10 +84,26 @@ func TestParseQuery(t *testing.T) {
 			input:  []byte(`{"query":"+beer \"light beer\" -devon"}`),
 			output: NewQueryStringQuery(`+beer "light beer" -devon`),
 		},
+		{
+			input:  []byte(`{"query":"beer (light OR lager)","default_operator":"and"}`),
+			output: NewQueryStringQuery(`beer (light OR lager)`).SetDefaultOperator(QueryStringOperatorAnd),
+		},
+		{
+			input:  []byte(`{"query":"beer light lager","minimum_should_match":"2"}`),
+			output: NewQueryStringQuery(`beer light lager`).SetMinimumShouldMatch("2"),
+		},
 		{
 			input:  []byte(`{"min":5.1,"max":7.1,"field":"desc"}`),
 			output: NewNumericRangeQuery(&minNum, &maxNum).SetField("desc"),
 		},
+		{
+			input:  []byte(`{"min":"apple","max":"banana","inclusive_max":true,"field":"name"}`),
+			output: NewTermRangeInclusiveQuery(&minTerm, &maxTerm, nil, &inclusiveTrue).SetField("name"),
+		},
+		{
+			input:  []byte(`{"max":"banana","field":"name","boost":2}`),
+			output: NewTermRangeQuery(nil, &maxTerm).SetField("name").SetBoost(2),
+		},
 		{
 			input:  []byte(`{"start":"` + startDate + `","end":"` + endDate + `","field":"desc"}`),
 			output: NewDateRangeQuery(&startDate, &endDate).SetField("desc"),
@@ -78,11 +124,132 @@ func TestParseQuery(t *testing.T) {
 			input:  []byte(`{"ids":["a","b","c"]}`),
 			output: NewDocIDQuery([]string{"a", "b", "c"}),
 		},
+		{
+			input:  []byte(`{"top_left":{"lat":49,"lon":2},"bottom_right":[13,43],"field":"loc"}`),
+			output: NewGeoBoundingBoxQuery(2, 49, 13, 43).SetField("loc"),
+		},
+		{
+			input:  []byte(`{"location":"48.85,2.35","distance":"100km","field":"loc"}`),
+			output: NewGeoDistanceQuery(2.35, 48.85, "100km").SetField("loc"),
+		},
+		{
+			input: []byte(`{"query":{"match_all":{}},"functions":[{"field_value_factor":{"field":"popularity","modifier":"log1p"}},{"random_score":{"seed":7},"weight":2}],"boost_mode":"sum"}`),
+			output: NewFunctionScoreQuery(NewMatchAllQuery()).
+				AddFunction(NewFieldValueFactorFunction("popularity", 0, "log1p")).
+				AddFunction(NewRandomScoreFunction(7).SetWeight(2)).
+				SetBoostMode("sum"),
+		},
+		{
+			input:  []byte(`{"match_phrase":"light beer","field":"desc","slop":2}`),
+			output: NewMatchPhraseQuery("light beer").SetSlop(2).SetField("desc"),
+		},
+		{
+			input:  []byte(`{"span_near":[{"span_term":"light","field":"desc"},{"span_term":"beer","field":"desc"}],"slop":2,"in_order":true}`),
+			output: NewSpanNearQuery([]SpanQuery{NewSpanTermQuery("light"), NewSpanTermQuery("beer")}, 2, true).SetField("desc"),
+		},
+		{
+			input: []byte(`{"span_not":{"span_or":[{"span_term":"light","field":"desc"},{"span_term":"dark","field":"desc"}]},"exclude":{"span_term":"beer","field":"desc"}}`),
+			output: NewSpanNotQuery(
+				NewSpanOrQuery([]SpanQuery{NewSpanTermQuery("light"), NewSpanTermQuery("dark")}),
+				NewSpanTermQuery("beer")).SetField("desc"),
+		},
+		{
+			input:  []byte(`{"span_first":{"span_term":"beer","field":"desc"},"end":3}`),
+			output: NewSpanFirstQuery(NewSpanTermQuery("beer"), 3).SetField("desc"),
+		},
+		{
+			input: []byte(`{"like_id":"a","fields":["desc"],"min_term_freq":1,"min_doc_freq":2,"max_query_terms":10}`),
+			output: &moreLikeThisQuery{
+				LikeID:        "a",
+				Fields:        []string{"desc"},
+				MinTermFreq:   1,
+				MinDocFreq:    2,
+				MaxQueryTerms: 10,
+				BoostVal:      1,
+			},
+		},
+		{
+			input:  []byte(`{"like":"light beer","field":"desc"}`),
+			output: NewMoreLikeThisTextQuery("light beer").SetField("desc"),
+		},
+		{
+			input:  []byte(`{"type":"more_like_this","like_id":"a","min_term_freq":0,"boost":2}`),
+			output: NewMoreLikeThisQuery("a").SetMinTermFreq(0).SetBoost(2),
+		},
+		{
+			input:  []byte(`{"like":"light beer","field":"desc","min_term_freq":2,"min_doc_freq":5,"max_query_terms":25}`),
+			output: NewMoreLikeThisTextQuery("light beer").SetField("desc"),
+		},
+		{
+			input:  []byte(`{"span_near":[{"term":"beer","field":"desc"}],"slop":2}`),
+			output: nil,
+			err: &QueryPathError{
+				Path: "span_near[0]",
+				Err:  fmt.Errorf("span queries can only contain span queries, got *bleve.termQuery"),
+			},
+		},
 		{
 			input:  []byte(`{"madeitup":"queryhere"}`),
 			output: nil,
 			err:    ErrorUnknownQueryType,
 		},
+		{
+			input:  []byte(`{"type":"match","match":"beer","field":"desc"}`),
+			output: NewMatchQuery("beer").SetField("desc"),
+		},
+		{
+			input:  []byte(`{"type":"bool_field","bool":true,"field":"on_draft"}`),
+			output: NewBoolFieldQuery(true).SetField("on_draft"),
+		},
+		{
+			input:  []byte(`{"type":"phrase","terms":["light","beer"],"field":"desc","boost":2}`),
+			output: NewPhraseQuery([]string{"light", "beer"}, "desc").SetBoost(2),
+		},
+		{
+			input:  []byte(`{"type":"boolean","must":{"type":"conjunction","conjuncts":[{"type":"term","term":"beer"}]}}`),
+			output: NewBooleanQuery([]Query{NewTermQuery("beer")}, nil, nil),
+		},
+		{
+			input:  []byte(`{"type":"term","match":"beer"}`),
+			output: nil,
+			err: &QueryPathError{
+				Path: "match",
+				Err:  fmt.Errorf("unknown key for query type 'term'"),
+			},
+		},
+		{
+			input:  []byte(`{"type":"boolean","must":{"type":"conjunction","conjuncts":[{"type":"term","term":"beer"},{"type":"term","term":"light","feild":"desc"}]}}`),
+			output: nil,
+			err: &QueryPathError{
+				Path: "must.conjuncts[1].feild",
+				Err:  fmt.Errorf("unknown key for query type 'term'"),
+			},
+		},
+		{
+			input:  []byte(`{"type":"match_all","match_all":{},"boost":2}`),
+			output: NewMatchAllQuery().SetBoost(2),
+		},
+		{
+			input:  []byte(`{"type":"madeitup","match":"beer"}`),
+			output: nil,
+			err:    fmt.Errorf("unknown query type 'madeitup'"),
+		},
+		{
+			input:  []byte(`{"must":{"conjuncts":[{"match":"beer"},{"type":"disjunction","disjuncts":[{"madeitup":"queryhere"}]}]}}`),
+			output: nil,
+			err: &QueryPathError{
+				Path: "must.conjuncts[1].disjuncts[0]",
+				Err:  ErrorUnknownQueryType,
+			},
+		},
+		{
+			input:  []byte(`{"should":{"match":"beer"}}`),
+			output: nil,
+			err: &QueryPathError{
+				Path: "should",
+				Err:  fmt.Errorf("should clause must be disjunction"),
+			},
+		},
 	}
 
 	for i, test := range tests {
@@ -101,6 +268,79 @@ func TestParseQuery(t *testing.T) {
 	}
 }
 
+func TestQueryJSONRoundTrip(t *testing.T) {
+	queries := []Query{
+		NewBoolFieldQuery(true).SetField("on_draft"),
+		NewBooleanQueryMinShould(
+			[]Query{NewMatchQuery("beer").SetField("desc")},
+			[]Query{NewMatchPhraseQuery("light beer").SetSlop(1).SetField("desc"), NewTermQuery("ale")},
+			[]Query{NewPrefixQuery("dark")},
+			1),
+		NewConjunctionQuery([]Query{NewTermQuery("beer"), NewWildcardQuery("li*t")}),
+		NewDisjunctionQueryMin([]Query{NewRegexpQuery("be.*"), NewFuzzyQuery("bere").SetFuzziness(2)}, 1),
+		NewDisjunctionQuery([]Query{NewTermQuery("beer"), NewTermQuery("ale")}).SetMinimumShouldMatch("-1"),
+		NewDateRangeInclusiveQuery(&startDate, &endDate, &inclusiveTrue, nil).SetField("updated"),
+		NewDocIDQuery([]string{"a", "b"}),
+		NewFunctionScoreQuery(NewMatchQuery("beer")).
+			AddFunction(NewFieldValueFactorFunction("abv", 1.5, "log1p")).
+			AddFunction(NewRandomScoreFunction(7).SetWeight(2)).
+			SetBoostMode("sum"),
+		NewGeoBoundingBoxQuery(-122.5, 37.8, -122.3, 37.7).SetField("geo"),
+		NewGeoDistanceQuery(-122.4, 37.7, "10km").SetField("geo"),
+		NewMatchQuery("beer").SetField("desc").SetBoost(2),
+		NewMatchQuery("red leather sofa").SetOperator(MatchQueryOperatorAnd),
+		NewMatchQuery("red leather sofa").SetMinimumShouldMatch("2<75%"),
+		NewMatchAllQuery(),
+		NewMatchNoneQuery(),
+		NewMoreLikeThisTextQuery("light beer").SetField("desc"),
+		NewMoreLikeThisQuery("a").SetMinDocFreq(0).SetField("desc"),
+		NewNumericRangeInclusiveQuery(&minNum, &maxNum, &inclusiveTrue, nil).SetField("abv"),
+		NewPhraseQuery([]string{"light", "beer"}, "desc").SetBoost(3),
+		NewQueryStringQuery("+beer light").SetDefaultOperator(QueryStringOperatorAnd),
+		NewQueryStringQuery("beer light lager").SetMinimumShouldMatch("2"),
+		NewSpanFirstQuery(NewSpanTermQuery("light"), 2).SetField("desc"),
+		NewSpanNearQuery([]SpanQuery{
+			NewSpanTermQuery("light"),
+			NewSpanOrQuery([]SpanQuery{NewSpanTermQuery("beer"), NewSpanTermQuery("ale")}),
+		}, 2, true).SetField("desc"),
+		NewSpanNotQuery(NewSpanTermQuery("beer"), NewSpanTermQuery("light")).SetField("desc"),
+		NewTermRangeInclusiveQuery(&minTerm, &maxTerm, nil, &inclusiveTrue).SetField("name"),
+	}
+
+	for _, query := range queries {
+		data, err := json.Marshal(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := ParseQuery(data)
+		if err != nil {
+			t.Fatalf("error %v parsing %s", err, data)
+		}
+		if !reflect.DeepEqual(query, actual) {
+			t.Errorf("expected: %#v, got: %#v, from %s", query, actual, data)
+		}
+	}
+}
+
+func TestQueryTypes(t *testing.T) {
+	for name, newQuery := range queryTypes {
+		data, err := json.Marshal(newQuery())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tmp struct {
+			Type string `json:"type"`
+		}
+		err = json.Unmarshal(data, &tmp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tmp.Type != name {
+			t.Errorf("expected query type %s, got %s", name, tmp.Type)
+		}
+	}
+}
+
 func TestSetGetField(t *testing.T) {
 	tests := []struct {
 		query Query
@@ -165,6 +405,14 @@ func TestQueryValidate(t *testing.T) {
 			query: NewNumericRangeQuery(nil, nil).SetField("desc"),
 			err:   ErrorNumericQueryNoBounds,
 		},
+		{
+			query: NewTermRangeQuery(&minTerm, &maxTerm).SetField("name"),
+			err:   nil,
+		},
+		{
+			query: NewTermRangeQuery(nil, nil).SetField("name"),
+			err:   ErrorTermRangeQueryNoBounds,
+		},
 		{
 			query: NewDateRangeQuery(&startDate, &endDate).SetField("desc"),
 			err:   nil,
@@ -236,10 +484,73 @@ func TestQueryValidate(t *testing.T) {
 				2.0),
 			err: ErrorDisjunctionFewerThanMinClauses,
 		},
+		{
+			query: NewDisjunctionQueryMin(
+				[]Query{NewMatchQuery("beer"), NewMatchQuery("water")},
+				3.0).SetMinimumShouldMatch("50%"),
+			err: nil,
+		},
+		{
+			query: NewBooleanQuery([]Query{NewMatchQuery("beer")}, nil, nil).SetMinimumShouldMatch("1"),
+			err:   nil,
+		},
+		{
+			query: NewBooleanQuery(nil, nil, nil).SetMinimumShouldMatch("1"),
+			err:   ErrorBooleanQueryNeedsMustOrShouldOrNotMust,
+		},
+		{
+			query: NewDisjunctionQuery([]Query{NewMatchQuery("beer")}).SetMinimumShouldMatch("most"),
+			err:   fmt.Errorf("invalid minimum should match 'most'"),
+		},
+		{
+			query: NewMatchQuery("red leather sofa").SetOperator("xor"),
+			err:   fmt.Errorf("unknown match query operator 'xor'"),
+		},
+		{
+			query: NewMatchQuery("red leather sofa").SetMinimumShouldMatch("3<"),
+			err:   fmt.Errorf("invalid minimum should match '3<'"),
+		},
+		{
+			query: NewQueryStringQuery("beer light").SetMinimumShouldMatch("3<75% 5"),
+			err:   fmt.Errorf("invalid minimum should match '%s'", "3<75% 5"),
+		},
 		{
 			query: NewDocIDQuery(nil).SetBoost(25),
 			err:   nil,
 		},
+		{
+			query: NewSpanNearQuery([]SpanQuery{NewSpanTermQuery("light"), NewSpanTermQuery("beer")}, 2, true).SetField("desc"),
+			err:   nil,
+		},
+		{
+			query: NewSpanNearQuery(nil, 2, true),
+			err:   ErrorSpanQueryNoClauses,
+		},
+		{
+			query: NewSpanOrQuery([]SpanQuery{
+				NewSpanTermQuery("light").SetField("desc").(SpanQuery),
+				NewSpanTermQuery("beer").SetField("name").(SpanQuery),
+			}),
+			err: ErrorSpanQueryFieldMismatch,
+		},
+		{
+			query: NewFunctionScoreQuery(NewMatchQuery("beer").SetField("desc")).
+				AddFunction(NewDateTimeDecayFunction("gauss", "published", "2016-01-01T00:00:00Z", "10d", "12h")).
+				AddFunction(NewWeightFunction(3)),
+			err: nil,
+		},
+		{
+			query: NewMoreLikeThisQuery("a"),
+			err:   nil,
+		},
+		{
+			query: NewMoreLikeThisTextQuery("light beer"),
+			err:   nil,
+		},
+		{
+			query: NewMoreLikeThisQuery(""),
+			err:   ErrorMoreLikeThisQueryNoLike,
+		},
 	}
 
 	for _, test := range tests {
@@ -259,9 +570,12 @@ func TestDumpQuery(t *testing.T) {
 	}
 	s = strings.TrimSpace(s)
 	wanted := strings.TrimSpace(`{
+  "type": "boolean",
   "must": {
+    "type": "conjunction",
     "conjuncts": [
       {
+        "type": "match",
         "match": "water",
         "boost": 1,
         "prefix_length": 0,
@@ -271,8 +585,10 @@ func TestDumpQuery(t *testing.T) {
     "boost": 1
   },
   "should": {
+    "type": "disjunction",
     "disjuncts": [
       {
+        "type": "match",
         "match": "beer",
         "boost": 1,
         "prefix_length": 0,
@@ -283,8 +599,10 @@ func TestDumpQuery(t *testing.T) {
     "min": 0
   },
   "must_not": {
+    "type": "disjunction",
     "disjuncts": [
       {
+        "type": "match",
         "match": "light",
         "boost": 1,
         "prefix_length": 0,
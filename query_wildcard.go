@@ -87,7 +87,11 @@ func (q *wildcardQuery) Searcher(i index.IndexReader, m *IndexMapping, explain b
 		}
 	}
 
-	return searchers.NewRegexpSearcher(i, q.compiled, field, q.BoostVal, explain)
+	similarity, err := m.similarityForPath(field)
+	if err != nil {
+		return nil, err
+	}
+	return searchers.NewRegexpSearcherWithSimilarity(i, q.compiled, field, q.BoostVal, similarity, explain)
 }
 
 func (q *wildcardQuery) Validate() error {
@@ -100,3 +104,8 @@ func (q *wildcardQuery) convertToRegexp() (*regexp.Regexp, error) {
 	regexpString := "^" + wildcardRegexpReplacer.Replace(q.Wildcard) + "$"
 	return regexp.Compile(regexpString)
 }
+
+func (q *wildcardQuery) MarshalJSON() ([]byte, error) {
+	type _wildcardQuery wildcardQuery
+	return marshalQuery("wildcard", (*_wildcardQuery)(q))
+}
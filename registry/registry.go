@@ -14,6 +14,7 @@ import (
 
 	"github.com/blevesearch/bleve/analysis"
 	"github.com/blevesearch/bleve/search/highlight"
+	"github.com/blevesearch/bleve/search/scorers"
 )
 
 var stores = make(KVStoreRegistry, 0)
@@ -26,10 +27,14 @@ var fragmentFormatters = make(FragmentFormatterRegistry, 0)
 var fragmenters = make(FragmenterRegistry, 0)
 var highlighters = make(HighlighterRegistry, 0)
 
+// scoring
+var similarities = make(SimilarityRegistry, 0)
+
 // analysis
 var charFilters = make(CharFilterRegistry, 0)
 var tokenizers = make(TokenizerRegistry, 0)
 var tokenMaps = make(TokenMapRegistry, 0)
+var synonymMaps = make(SynonymMapRegistry, 0)
 var tokenFilters = make(TokenFilterRegistry, 0)
 var analyzers = make(AnalyzerRegistry, 0)
 var dateTimeParsers = make(DateTimeParserRegistry, 0)
@@ -38,12 +43,14 @@ type Cache struct {
 	CharFilters        *CharFilterCache
 	Tokenizers         *TokenizerCache
 	TokenMaps          *TokenMapCache
+	SynonymMaps        *SynonymMapCache
 	TokenFilters       *TokenFilterCache
 	Analyzers          *AnalyzerCache
 	DateTimeParsers    *DateTimeParserCache
 	FragmentFormatters *FragmentFormatterCache
 	Fragmenters        *FragmenterCache
 	Highlighters       *HighlighterCache
+	Similarities       *SimilarityCache
 }
 
 func NewCache() *Cache {
@@ -51,12 +58,14 @@ func NewCache() *Cache {
 		CharFilters:        NewCharFilterCache(),
 		Tokenizers:         NewTokenizerCache(),
 		TokenMaps:          NewTokenMapCache(),
+		SynonymMaps:        NewSynonymMapCache(),
 		TokenFilters:       NewTokenFilterCache(),
 		Analyzers:          NewAnalyzerCache(),
 		DateTimeParsers:    NewDateTimeParserCache(),
 		FragmentFormatters: NewFragmentFormatterCache(),
 		Fragmenters:        NewFragmenterCache(),
 		Highlighters:       NewHighlighterCache(),
+		Similarities:       NewSimilarityCache(),
 	}
 }
 
@@ -108,6 +117,18 @@ func (c *Cache) DefineTokenMap(name string, config map[string]interface{}) (anal
 	return c.TokenMaps.DefineTokenMap(name, typ, config, c)
 }
 
+func (c *Cache) SynonymMapNamed(name string) (*analysis.SynonymMap, error) {
+	return c.SynonymMaps.SynonymMapNamed(name, c)
+}
+
+func (c *Cache) DefineSynonymMap(name string, config map[string]interface{}) (*analysis.SynonymMap, error) {
+	typ, err := typeFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return c.SynonymMaps.DefineSynonymMap(name, typ, config, c)
+}
+
 func (c *Cache) TokenFilterNamed(name string) (analysis.TokenFilter, error) {
 	return c.TokenFilters.TokenFilterNamed(name, c)
 }
@@ -179,3 +200,15 @@ func (c *Cache) DefineHighlighter(name string, config map[string]interface{}) (h
 	}
 	return c.Highlighters.DefineHighlighter(name, typ, config, c)
 }
+
+func (c *Cache) SimilarityNamed(name string) (scorers.Similarity, error) {
+	return c.Similarities.SimilarityNamed(name, c)
+}
+
+func (c *Cache) DefineSimilarity(name string, config map[string]interface{}) (scorers.Similarity, error) {
+	typ, err := typeFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return c.Similarities.DefineSimilarity(name, typ, config, c)
+}
@@ -12,10 +12,13 @@ package bleve
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/blevesearch/bleve/analysis"
 	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/facets"
+	"github.com/blevesearch/bleve/search/highlight"
 )
 
 type numericRange struct {
@@ -78,6 +81,28 @@ type FacetRequest struct {
 	Field          string           `json:"field"`
 	NumericRanges  []*numericRange  `json:"numeric_ranges,omitempty"`
 	DateTimeRanges []*dateTimeRange `json:"date_ranges,omitempty"`
+
+	// DateTimeInterval, when set, makes the facet a date histogram:
+	// buckets of this interval ("1h", "1d", "1w", "month", "year"...) are
+	// generated from the values of the field, in the TimeZone location
+	// (UTC when empty), and returned in chronological order.
+	DateTimeInterval string `json:"date_interval,omitempty"`
+	TimeZone         string `json:"time_zone,omitempty"`
+
+	// NumericInterval, when set, makes the facet a numeric histogram of
+	// buckets of this width, returned in increasing order.
+	NumericInterval float64 `json:"numeric_interval,omitempty"`
+
+	// MinDocCount is the minimum number of values of the buckets returned
+	// by histograms. When it is 0, the empty buckets between the first
+	// and the last values are returned too, and the search fails if
+	// there would be more than facets.MaxHistogramBuckets buckets.
+	// Histograms do not use Size.
+	MinDocCount int `json:"min_doc_count,omitempty"`
+
+	// partial is set on the requests of the indexes of an alias, the
+	// histograms being fixed up once their results are merged
+	partial bool
 }
 
 func (fr *FacetRequest) Validate() error {
@@ -85,6 +110,30 @@ func (fr *FacetRequest) Validate() error {
 		return fmt.Errorf("facet can only conain numeric ranges or date ranges, not both")
 	}
 
+	if fr.DateTimeInterval != "" || fr.NumericInterval != 0 {
+		if fr.DateTimeInterval != "" && fr.NumericInterval != 0 {
+			return fmt.Errorf("facet can only be a date histogram or a numeric histogram, not both")
+		}
+		if len(fr.NumericRanges) > 0 || len(fr.DateTimeRanges) > 0 {
+			return fmt.Errorf("histogram facet cannot contain ranges")
+		}
+		if fr.NumericInterval < 0 {
+			return fmt.Errorf("numeric interval must be positive")
+		}
+		if fr.MinDocCount < 0 {
+			return fmt.Errorf("min doc count cannot be negative")
+		}
+	}
+	if fr.TimeZone != "" && fr.DateTimeInterval == "" {
+		return fmt.Errorf("time zone requires a date interval")
+	}
+	if fr.DateTimeInterval != "" {
+		_, _, err := fr.dateHistogram()
+		if err != nil {
+			return err
+		}
+	}
+
 	nrNames := map[string]interface{}{}
 	for _, nr := range fr.NumericRanges {
 		if _, ok := nrNames[nr.Name]; ok {
@@ -114,6 +163,62 @@ func NewFacetRequest(field string, size int) *FacetRequest {
 	}
 }
 
+// NewDateHistogramFacetRequest creates a facet grouping the date values
+// of the specified field in buckets of the specified interval, like
+// "1h", "1d", "1w", "month" or "year".
+func NewDateHistogramFacetRequest(field string, interval string) *FacetRequest {
+	return &FacetRequest{
+		Field:            field,
+		DateTimeInterval: interval,
+	}
+}
+
+// NewNumericHistogramFacetRequest creates a facet grouping the numeric
+// values of the specified field in buckets of the specified width.
+func NewNumericHistogramFacetRequest(field string, interval float64) *FacetRequest {
+	return &FacetRequest{
+		Field:           field,
+		NumericInterval: interval,
+	}
+}
+
+func (fr *FacetRequest) isHistogram() bool {
+	return fr.DateTimeInterval != "" || fr.NumericInterval > 0
+}
+
+// dateHistogram returns the parsed interval and time zone of a date
+// histogram facet.
+func (fr *FacetRequest) dateHistogram() (facets.DateTimeInterval, *time.Location, error) {
+	interval, err := facets.ParseDateTimeInterval(fr.DateTimeInterval)
+	if err != nil {
+		return interval, nil, err
+	}
+	location := time.UTC
+	if fr.TimeZone != "" {
+		location, err = time.LoadLocation(fr.TimeZone)
+		if err != nil {
+			return interval, nil, fmt.Errorf("invalid time zone '%s': %v", fr.TimeZone, err)
+		}
+	}
+	return interval, location, nil
+}
+
+// fixupHistogram reorders the buckets of a histogram facet and adds
+// the empty ones once the results from all the indexes are merged.
+func (fr *FacetRequest) fixupHistogram(facetResult *search.FacetResult) error {
+	if facetResult == nil {
+		return nil
+	}
+	if fr.NumericInterval > 0 {
+		return facets.FixupNumericHistogram(facetResult, fr.NumericInterval, fr.MinDocCount)
+	}
+	interval, location, err := fr.dateHistogram()
+	if err != nil {
+		return err
+	}
+	return facets.FixupDateHistogram(facetResult, interval, location, fr.MinDocCount)
+}
+
 // AddDateTimeRange adds a bucket to a field
 // containing date values.  Documents with a
 // date value falling into this range are tabulated
@@ -150,11 +255,172 @@ func (fr FacetsRequest) Validate() error {
 	return nil
 }
 
+// An AggregationRequest describes an aggregation computed over the
+// documents matching a search. Type is one of:
+//   - "terms": buckets documents by the terms of Field, keeping the Size
+//     most frequent ones (10 when Size is not set)
+//   - "numeric_range" and "date_range": buckets documents by ranges of the
+//     values of Field
+//   - "min", "max", "sum", "avg", "stats": metrics over the values of a
+//     numeric Field
+//   - "cardinality": counts the distinct values of Field
+//
+// Bucket aggregations can nest Aggregations, computed over the
+// documents of each bucket.
+type AggregationRequest struct {
+	Type           string              `json:"type"`
+	Field          string              `json:"field"`
+	Size           int                 `json:"size,omitempty"`
+	NumericRanges  []*numericRange     `json:"numeric_ranges,omitempty"`
+	DateTimeRanges []*dateTimeRange    `json:"date_ranges,omitempty"`
+	Aggregations   AggregationsRequest `json:"aggregations,omitempty"`
+}
+
+// NewAggregationRequest creates an aggregation of the given type on
+// the specified field.
+func NewAggregationRequest(typ, field string) *AggregationRequest {
+	return &AggregationRequest{
+		Type:  typ,
+		Field: field,
+	}
+}
+
+// AddNumericRange adds a bucket to a numeric_range aggregation.
+// Documents with a value falling into this range are part of the
+// bucket.
+func (ar *AggregationRequest) AddNumericRange(name string, min, max *float64) {
+	ar.NumericRanges = append(ar.NumericRanges, &numericRange{Name: name, Min: min, Max: max})
+}
+
+// AddDateTimeRange adds a bucket to a date_range aggregation.
+// Documents with a date falling into this range are part of the
+// bucket.
+func (ar *AggregationRequest) AddDateTimeRange(name string, start, end time.Time) {
+	ar.DateTimeRanges = append(ar.DateTimeRanges, &dateTimeRange{Name: name, Start: start, End: end})
+}
+
+// AddAggregation adds a sub-aggregation computed over the documents
+// of each bucket of this aggregation.
+func (ar *AggregationRequest) AddAggregation(name string, sub *AggregationRequest) {
+	if ar.Aggregations == nil {
+		ar.Aggregations = make(AggregationsRequest, 1)
+	}
+	ar.Aggregations[name] = sub
+}
+
+func (ar *AggregationRequest) Validate() error {
+	if ar.Field == "" {
+		return fmt.Errorf("aggregation must specify a field")
+	}
+	bucket := false
+	switch ar.Type {
+	case "terms":
+		bucket = true
+	case "numeric_range":
+		if len(ar.NumericRanges) == 0 {
+			return fmt.Errorf("numeric_range aggregation must have numeric ranges")
+		}
+		bucket = true
+	case "date_range":
+		if len(ar.DateTimeRanges) == 0 {
+			return fmt.Errorf("date_range aggregation must have date ranges")
+		}
+		bucket = true
+	case "min", "max", "sum", "avg", "stats", "cardinality":
+	default:
+		return fmt.Errorf("unknown aggregation type '%s'", ar.Type)
+	}
+	if ar.Type != "numeric_range" && len(ar.NumericRanges) > 0 {
+		return fmt.Errorf("only numeric_range aggregations can have numeric ranges")
+	}
+	if ar.Type != "date_range" && len(ar.DateTimeRanges) > 0 {
+		return fmt.Errorf("only date_range aggregations can have date ranges")
+	}
+	if !bucket && len(ar.Aggregations) > 0 {
+		return fmt.Errorf("%s aggregation cannot have sub-aggregations", ar.Type)
+	}
+
+	names := map[string]struct{}{}
+	for _, nr := range ar.NumericRanges {
+		if _, ok := names[nr.Name]; ok {
+			return fmt.Errorf("numeric ranges contains duplicate name '%s'", nr.Name)
+		}
+		names[nr.Name] = struct{}{}
+	}
+	for _, dr := range ar.DateTimeRanges {
+		if _, ok := names[dr.Name]; ok {
+			return fmt.Errorf("date ranges contains duplicate name '%s'", dr.Name)
+		}
+		names[dr.Name] = struct{}{}
+	}
+
+	return ar.Aggregations.Validate()
+}
+
+func (ar *AggregationRequest) size() int {
+	if ar.Size <= 0 {
+		return 10
+	}
+	return ar.Size
+}
+
+// AggregationsRequest groups together named AggregationRequest
+// objects.
+type AggregationsRequest map[string]*AggregationRequest
+
+func (ar AggregationsRequest) Validate() error {
+	for _, v := range ar {
+		err := v.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fixup trims the terms buckets of merged results to their requested
+// size, at every nesting level.
+func (ar AggregationsRequest) fixup(results search.AggregationResults) {
+	for name, req := range ar {
+		result, ok := results[name]
+		if !ok {
+			continue
+		}
+		result.Fixup(req.size())
+		if len(req.Aggregations) > 0 {
+			for _, bucket := range result.Buckets {
+				req.Aggregations.fixup(bucket.Aggregations)
+			}
+		}
+	}
+}
+
 // HighlightRequest describes how field matches
 // should be highlighted.
+// FragmentCount is the maximum number of fragments
+// returned per field, defaulting to 1.
+// FragmentSize overrides the fragment size of the
+// highlighter style.
+// PreTag and PostTag, when set, replace the markup
+// placed around matched terms by the style, the style
+// markup being kept for the one not set.
+// Order is "score" (the default) to return the best
+// fragments first, or "document" to return them in the
+// order they appear in the field.
+// NoMatchSize controls the snippet returned for a
+// requested field without matches: 0 returns the
+// beginning of the field using the fragment size, a
+// positive value returns that many characters and a
+// negative value returns nothing.
 type HighlightRequest struct {
-	Style  *string  `json:"style"`
-	Fields []string `json:"fields"`
+	Style         *string  `json:"style"`
+	Fields        []string `json:"fields"`
+	FragmentCount int      `json:"fragment_count,omitempty"`
+	FragmentSize  int      `json:"fragment_size,omitempty"`
+	PreTag        string   `json:"pre_tag,omitempty"`
+	PostTag       string   `json:"post_tag,omitempty"`
+	Order         string   `json:"order,omitempty"`
+	NoMatchSize   int      `json:"no_match_size,omitempty"`
 }
 
 // NewHighlight creates a default
@@ -178,6 +444,32 @@ func (h *HighlightRequest) AddField(field string) {
 	h.Fields = append(h.Fields, field)
 }
 
+func (h *HighlightRequest) Validate() error {
+	if h.FragmentCount < 0 {
+		return fmt.Errorf("highlight fragment count must not be negative")
+	}
+	if h.FragmentSize < 0 {
+		return fmt.Errorf("highlight fragment size must not be negative")
+	}
+	switch h.Order {
+	case "", highlight.OrderScore, highlight.OrderDocument:
+	default:
+		return fmt.Errorf("unknown highlight order '%s'", h.Order)
+	}
+	return nil
+}
+
+func (h *HighlightRequest) fragmentOptions() *highlight.FragmentOptions {
+	return &highlight.FragmentOptions{
+		Count:       h.FragmentCount,
+		Size:        h.FragmentSize,
+		Before:      h.PreTag,
+		After:       h.PostTag,
+		Order:       h.Order,
+		NoMatchSize: h.NoMatchSize,
+	}
+}
+
 // A SearchRequest describes all the parameters
 // needed to search the index.
 // Query is required.
@@ -189,18 +481,27 @@ func (h *HighlightRequest) AddField(field string) {
 // should be retrieved for result documents, provided they
 // were stored while indexing.
 // Facets describe the set of facets to be computed.
+// Aggregations describe the set of aggregations to be computed.
 // Explain triggers inclusion of additional search
 // result score explanations.
+// Sort describes the desired order for the results to be returned.
+// SearchAfter supports deep paging: it holds the Sort values of the
+// last hit of the previous page, and only hits sorting strictly
+// after it are returned. It cannot be combined with From, and the
+// sort order should end with _id so that no two hits are tied.
 //
 // A special field named "*" can be used to return all fields.
 type SearchRequest struct {
-	Query     Query             `json:"query"`
-	Size      int               `json:"size"`
-	From      int               `json:"from"`
-	Highlight *HighlightRequest `json:"highlight"`
-	Fields    []string          `json:"fields"`
-	Facets    FacetsRequest     `json:"facets"`
-	Explain   bool              `json:"explain"`
+	Query        Query               `json:"query"`
+	Size         int                 `json:"size"`
+	From         int                 `json:"from"`
+	Highlight    *HighlightRequest   `json:"highlight"`
+	Fields       []string            `json:"fields"`
+	Facets       FacetsRequest       `json:"facets"`
+	Aggregations AggregationsRequest `json:"aggregations,omitempty"`
+	Explain      bool                `json:"explain"`
+	Sort         search.SortOrder    `json:"sort"`
+	SearchAfter  []string            `json:"search_after,omitempty"`
 }
 
 func (sr *SearchRequest) Validate() error {
@@ -209,7 +510,38 @@ func (sr *SearchRequest) Validate() error {
 		return err
 	}
 
-	return sr.Facets.Validate()
+	if sr.Highlight != nil {
+		err = sr.Highlight.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if sr.SearchAfter != nil {
+		if sr.From != 0 {
+			return fmt.Errorf("cannot use search after with from != 0")
+		}
+		_, err = sr.sortOrder().DocumentMatchAfter(sr.SearchAfter)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = sr.Facets.Validate()
+	if err != nil {
+		return err
+	}
+
+	return sr.Aggregations.Validate()
+}
+
+// sortOrder returns the requested sort order, or the default
+// one ordering by descending score.
+func (sr *SearchRequest) sortOrder() search.SortOrder {
+	if len(sr.Sort) == 0 {
+		return search.SortOrder{&search.SortScore{Desc: true}}
+	}
+	return sr.Sort
 }
 
 // AddFacet adds a FacetRequest to this SearchRequest
@@ -220,17 +552,49 @@ func (r *SearchRequest) AddFacet(facetName string, f *FacetRequest) {
 	r.Facets[facetName] = f
 }
 
+// AddAggregation adds an AggregationRequest to this SearchRequest
+func (r *SearchRequest) AddAggregation(aggregationName string, a *AggregationRequest) {
+	if r.Aggregations == nil {
+		r.Aggregations = make(AggregationsRequest, 1)
+	}
+	r.Aggregations[aggregationName] = a
+}
+
+// SortBy changes the request to use the requested sort order
+// this form uses the simplified syntax with an array of strings
+// each string can either be a field name
+// or the magic value _id and _score which refer to the doc id and search score
+// any of these values can optionally be prefixed with - to reverse the order
+func (r *SearchRequest) SortBy(order []string) {
+	so := search.ParseSortOrderStrings(order)
+	r.Sort = so
+}
+
+// SortByCustom changes the request to use the requested sort order
+func (r *SearchRequest) SortByCustom(order search.SortOrder) {
+	r.Sort = order
+}
+
+// SetSearchAfter sets the request to skip over hits with a sort
+// value less than the provided sort after key
+func (r *SearchRequest) SetSearchAfter(after []string) {
+	r.SearchAfter = after
+}
+
 // UnmarshalJSON deserializes a JSON representation of
 // a SearchRequest
 func (r *SearchRequest) UnmarshalJSON(input []byte) error {
 	var temp struct {
-		Q         json.RawMessage   `json:"query"`
-		Size      *int              `json:"size"`
-		From      int               `json:"from"`
-		Highlight *HighlightRequest `json:"highlight"`
-		Fields    []string          `json:"fields"`
-		Facets    FacetsRequest     `json:"facets"`
-		Explain   bool              `json:"explain"`
+		Q            json.RawMessage     `json:"query"`
+		Size         *int                `json:"size"`
+		From         int                 `json:"from"`
+		Highlight    *HighlightRequest   `json:"highlight"`
+		Fields       []string            `json:"fields"`
+		Facets       FacetsRequest       `json:"facets"`
+		Aggregations AggregationsRequest `json:"aggregations"`
+		Explain      bool                `json:"explain"`
+		Sort         []json.RawMessage   `json:"sort"`
+		SearchAfter  []string            `json:"search_after"`
 	}
 
 	err := json.Unmarshal(input, &temp)
@@ -248,7 +612,17 @@ func (r *SearchRequest) UnmarshalJSON(input []byte) error {
 	r.Highlight = temp.Highlight
 	r.Fields = temp.Fields
 	r.Facets = temp.Facets
-	r.Query, err = ParseQuery(temp.Q)
+	r.Aggregations = temp.Aggregations
+	if temp.Sort == nil {
+		r.Sort = search.SortOrder{&search.SortScore{Desc: true}}
+	} else {
+		r.Sort, err = search.ParseSortOrderJSON(temp.Sort)
+		if err != nil {
+			return err
+		}
+	}
+	r.SearchAfter = temp.SearchAfter
+	r.Query, err = parseQueryAt("query", temp.Q)
 	if err != nil {
 		return err
 	}
@@ -274,12 +648,14 @@ func NewSearchRequest(q Query) *SearchRequest {
 // NewSearchRequestOptions creates a new SearchRequest
 // for the Query, with the requested size, from
 // and explanation search parameters.
+// By default results are ordered by score, descending.
 func NewSearchRequestOptions(q Query, size, from int, explain bool) *SearchRequest {
 	return &SearchRequest{
 		Query:   q,
 		Size:    size,
 		From:    from,
 		Explain: explain,
+		Sort:    search.SortOrder{&search.SortScore{Desc: true}},
 	}
 }
 
@@ -342,6 +718,8 @@ type SearchResult struct {
 	MaxScore float64                        `json:"max_score"`
 	Took     time.Duration                  `json:"took"`
 	Facets   search.FacetResults            `json:"facets"`
+
+	Aggregations search.AggregationResults `json:"aggregations,omitempty"`
 }
 
 func (sr *SearchResult) String() string {
@@ -368,18 +746,33 @@ func (sr *SearchResult) String() string {
 			rv = fmt.Sprintf("%d matches, took %s\n", sr.Total, sr.Took)
 		}
 	} else {
-		rv = "No matches"
+		rv = "No matches\n"
 	}
 	if len(sr.Facets) > 0 {
 		rv += fmt.Sprintf("Facets:\n")
-		for fn, f := range sr.Facets {
+		facetNames := make([]string, 0, len(sr.Facets))
+		for fn := range sr.Facets {
+			facetNames = append(facetNames, fn)
+		}
+		sort.Strings(facetNames)
+		for _, fn := range facetNames {
+			f := sr.Facets[fn]
 			rv += fmt.Sprintf("%s(%d)\n", fn, f.Total)
 			for _, t := range f.Terms {
 				rv += fmt.Sprintf("\t%s(%d)\n", t.Term, t.Count)
 			}
+			for _, nr := range f.NumericRanges {
+				rv += fmt.Sprintf("\t%s(%d)\n", nr.Name, nr.Count)
+			}
+			for _, dr := range f.DateRanges {
+				rv += fmt.Sprintf("\t%s(%d)\n", dr.Name, dr.Count)
+			}
 			if f.Other != 0 {
 				rv += fmt.Sprintf("\tOther(%d)\n", f.Other)
 			}
+			if f.Missing != 0 {
+				rv += fmt.Sprintf("\tMissing(%d)\n", f.Missing)
+			}
 		}
 	}
 	return rv
@@ -394,4 +787,9 @@ func (sr *SearchResult) Merge(other *SearchResult) {
 		sr.MaxScore = other.MaxScore
 	}
 	sr.Facets.Merge(other.Facets)
+	if sr.Aggregations == nil {
+		sr.Aggregations = other.Aggregations
+	} else {
+		sr.Aggregations.Merge(other.Aggregations)
+	}
 }
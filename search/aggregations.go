@@ -0,0 +1,243 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package search
+
+import (
+	"math"
+	"sort"
+
+	"github.com/blevesearch/bleve/index"
+)
+
+// AggregationBuilder computes one aggregation over the documents
+// matching a search.
+type AggregationBuilder interface {
+	// Fields returns the fields whose terms the aggregation, and its
+	// sub-aggregations, are built from.
+	Fields() []string
+	Update(index.FieldTerms)
+	Result() *AggregationResult
+}
+
+// AggregationsBuilder feeds the documents matching a search to a set
+// of named aggregations, loading the terms of the needed fields once
+// per document.
+type AggregationsBuilder struct {
+	indexReader  index.IndexReader
+	aggregations map[string]AggregationBuilder
+	fields       []string
+}
+
+func NewAggregationsBuilder(indexReader index.IndexReader) *AggregationsBuilder {
+	return &AggregationsBuilder{
+		indexReader:  indexReader,
+		aggregations: make(map[string]AggregationBuilder),
+	}
+}
+
+func (ab *AggregationsBuilder) Add(name string, aggregationBuilder AggregationBuilder) {
+	ab.aggregations[name] = aggregationBuilder
+	ab.fields = append(ab.fields, aggregationBuilder.Fields()...)
+}
+
+func (ab *AggregationsBuilder) Update(docMatch *DocumentMatch) error {
+	fieldTerms, err := ab.indexReader.DocumentFieldTermsForFields(docMatch.ID, ab.fields)
+	if err != nil {
+		return err
+	}
+	for _, aggregationBuilder := range ab.aggregations {
+		aggregationBuilder.Update(fieldTerms)
+	}
+	return nil
+}
+
+func (ab *AggregationsBuilder) Results() AggregationResults {
+	rv := make(AggregationResults, len(ab.aggregations))
+	for name, aggregationBuilder := range ab.aggregations {
+		rv[name] = aggregationBuilder.Result()
+	}
+	return rv
+}
+
+// AggregationStats summarizes the numeric values seen by a metric
+// aggregation. It holds everything needed to merge results.
+type AggregationStats struct {
+	Count int     `json:"count"`
+	Sum   float64 `json:"sum"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Avg   float64 `json:"avg"`
+}
+
+// Add records one value.
+func (as *AggregationStats) Add(value float64) {
+	if as.Count == 0 || value < as.Min {
+		as.Min = value
+	}
+	if as.Count == 0 || value > as.Max {
+		as.Max = value
+	}
+	as.Count++
+	as.Sum += value
+	as.Avg = as.Sum / float64(as.Count)
+}
+
+func (as *AggregationStats) Merge(other *AggregationStats) {
+	if other.Count == 0 {
+		return
+	}
+	if as.Count == 0 {
+		*as = *other
+		return
+	}
+	as.Min = math.Min(as.Min, other.Min)
+	as.Max = math.Max(as.Max, other.Max)
+	as.Count += other.Count
+	as.Sum += other.Sum
+	as.Avg = as.Sum / float64(as.Count)
+}
+
+// AggregationBucket is one group of documents of a bucket aggregation,
+// along with the results of the sub-aggregations computed over them.
+type AggregationBucket struct {
+	Key          string             `json:"key"`
+	Count        int                `json:"count"`
+	Min          *float64           `json:"min,omitempty"`
+	Max          *float64           `json:"max,omitempty"`
+	Start        *string            `json:"start,omitempty"`
+	End          *string            `json:"end,omitempty"`
+	Aggregations AggregationResults `json:"aggregations,omitempty"`
+}
+
+type AggregationBuckets []*AggregationBucket
+
+func (ab AggregationBuckets) Len() int      { return len(ab) }
+func (ab AggregationBuckets) Swap(i, j int) { ab[i], ab[j] = ab[j], ab[i] }
+func (ab AggregationBuckets) Less(i, j int) bool {
+	if ab[i].Count == ab[j].Count {
+		return ab[i].Key < ab[j].Key
+	}
+	return ab[i].Count > ab[j].Count
+}
+
+// AggregationResult is the outcome of an aggregation. Metric
+// aggregations fill Value and Stats, bucket aggregations fill Buckets.
+type AggregationResult struct {
+	Field   string             `json:"field"`
+	Type    string             `json:"type"`
+	Value   *float64           `json:"value,omitempty"`
+	Stats   *AggregationStats  `json:"stats,omitempty"`
+	Buckets AggregationBuckets `json:"buckets,omitempty"`
+	Missing int                `json:"missing"`
+	Other   int                `json:"other,omitempty"`
+
+	// Distinct holds the distinct values counted by a cardinality
+	// aggregation, so that results from several indexes merge exactly.
+	Distinct map[string]struct{} `json:"-"`
+}
+
+// UpdateValue recomputes Value from the stats or the distinct values
+// according to the type of the aggregation.
+func (ar *AggregationResult) UpdateValue() {
+	var value float64
+	switch ar.Type {
+	case "cardinality":
+		if ar.Distinct == nil {
+			return
+		}
+		value = float64(len(ar.Distinct))
+	case "min", "max", "sum", "avg":
+		if ar.Stats == nil {
+			return
+		}
+		switch ar.Type {
+		case "min":
+			value = ar.Stats.Min
+		case "max":
+			value = ar.Stats.Max
+		case "sum":
+			value = ar.Stats.Sum
+		case "avg":
+			value = ar.Stats.Avg
+		}
+	default:
+		return
+	}
+	ar.Value = &value
+}
+
+func (ar *AggregationResult) Merge(other *AggregationResult) {
+	ar.Missing += other.Missing
+	ar.Other += other.Other
+	if ar.Stats != nil && other.Stats != nil {
+		ar.Stats.Merge(other.Stats)
+	}
+	if ar.Type == "cardinality" {
+		if ar.Distinct != nil && other.Distinct != nil {
+			for value := range other.Distinct {
+				ar.Distinct[value] = struct{}{}
+			}
+		} else if ar.Value != nil && other.Value != nil {
+			// the distinct values were lost, the best we can do is an upper bound
+			ar.Distinct = nil
+			value := *ar.Value + *other.Value
+			ar.Value = &value
+			return
+		}
+	}
+	for _, otherBucket := range other.Buckets {
+		merged := false
+		for _, bucket := range ar.Buckets {
+			if bucket.Key == otherBucket.Key {
+				bucket.Count += otherBucket.Count
+				if bucket.Aggregations == nil {
+					bucket.Aggregations = otherBucket.Aggregations
+				} else {
+					bucket.Aggregations.Merge(otherBucket.Aggregations)
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			ar.Buckets = append(ar.Buckets, otherBucket)
+		}
+	}
+	ar.UpdateValue()
+}
+
+// Fixup orders the buckets of a terms aggregation by decreasing count
+// and keeps only the first size of them, the others are counted in
+// Other.
+func (ar *AggregationResult) Fixup(size int) {
+	if ar.Type != "terms" {
+		return
+	}
+	sort.Sort(ar.Buckets)
+	if len(ar.Buckets) > size {
+		for _, bucket := range ar.Buckets[size:] {
+			ar.Other += bucket.Count
+		}
+		ar.Buckets = ar.Buckets[:size]
+	}
+}
+
+type AggregationResults map[string]*AggregationResult
+
+func (ar AggregationResults) Merge(other AggregationResults) {
+	for name, oAggregationResult := range other {
+		aggregationResult, ok := ar[name]
+		if ok {
+			aggregationResult.Merge(oAggregationResult)
+		} else {
+			ar[name] = oAggregationResult
+		}
+	}
+}
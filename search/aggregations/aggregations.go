@@ -0,0 +1,86 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package aggregations provides the metric and bucket aggregations
+// which can be computed over the documents matching a search. Bucket
+// aggregations group documents and compute sub-aggregations over each
+// group.
+package aggregations
+
+import (
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/numeric_util"
+	"github.com/blevesearch/bleve/search"
+)
+
+// SubAggregations builds a fresh set of named sub-aggregations, it is
+// called once for every bucket created by a bucket aggregation.
+type SubAggregations func() map[string]search.AggregationBuilder
+
+// bucket accumulates the documents of one bucket
+type bucket struct {
+	count int
+	subs  map[string]search.AggregationBuilder
+}
+
+func newBucket(newSubs SubAggregations) *bucket {
+	rv := &bucket{}
+	if newSubs != nil {
+		rv.subs = newSubs()
+	}
+	return rv
+}
+
+func (b *bucket) update(ft index.FieldTerms) {
+	b.count++
+	for _, sub := range b.subs {
+		sub.Update(ft)
+	}
+}
+
+func (b *bucket) result(key string) *search.AggregationBucket {
+	rv := &search.AggregationBucket{
+		Key:   key,
+		Count: b.count,
+	}
+	if len(b.subs) > 0 {
+		rv.Aggregations = make(search.AggregationResults, len(b.subs))
+		for name, sub := range b.subs {
+			rv.Aggregations[name] = sub.Result()
+		}
+	}
+	return rv
+}
+
+// subFields returns the fields needed by the sub-aggregations
+func subFields(newSubs SubAggregations) []string {
+	var rv []string
+	if newSubs != nil {
+		for _, sub := range newSubs() {
+			rv = append(rv, sub.Fields()...)
+		}
+	}
+	return rv
+}
+
+// numericValues decodes the full precision numeric terms
+func numericValues(terms []string) []int64 {
+	var rv []int64
+	for _, term := range terms {
+		prefixCoded := numeric_util.PrefixCoded(term)
+		shift, err := prefixCoded.Shift()
+		if err == nil && shift == 0 {
+			i64, err := prefixCoded.Int64()
+			if err == nil {
+				rv = append(rv, i64)
+			}
+		}
+	}
+	return rv
+}
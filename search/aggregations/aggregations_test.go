@@ -0,0 +1,162 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package aggregations
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve/index"
+	nu "github.com/blevesearch/bleve/numeric_util"
+	"github.com/blevesearch/bleve/search"
+)
+
+// numericTerms returns the terms indexed for a number, at all precisions
+func numericTerms(f float64) []string {
+	var rv []string
+	i64 := nu.Float64ToInt64(f)
+	for shift := uint(0); shift < 64; shift += 4 {
+		rv = append(rv, string(nu.MustNewPrefixCodedInt64(i64, shift)))
+	}
+	return rv
+}
+
+var testDocs = []index.FieldTerms{
+	{"type": {"beer"}, "abv": numericTerms(5.0)},
+	{"type": {"beer"}, "abv": numericTerms(7.5)},
+	{"type": {"wine"}, "abv": numericTerms(13.0)},
+	{"type": {"beer", "cider"}, "abv": numericTerms(4.5)},
+	{"type": {"water"}},
+}
+
+func TestTermsAggregationWithSubAggregations(t *testing.T) {
+	newSubs := func() map[string]search.AggregationBuilder {
+		return map[string]search.AggregationBuilder{
+			"avg_abv": NewMetricAggregationBuilder("avg", "abv"),
+		}
+	}
+	ab := NewTermsAggregationBuilder("type", 2, newSubs)
+	if !reflect.DeepEqual(ab.Fields(), []string{"type", "abv"}) {
+		t.Errorf("expected fields type and abv, got %v", ab.Fields())
+	}
+	for _, doc := range testDocs {
+		ab.Update(doc)
+	}
+	res := ab.Result()
+	if res.Type != "terms" || res.Missing != 0 || res.Other != 2 {
+		t.Errorf("unexpected result %#v", res)
+	}
+	if len(res.Buckets) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(res.Buckets))
+	}
+	beer := res.Buckets[0]
+	if beer.Key != "beer" || beer.Count != 3 {
+		t.Errorf("expected 3 beers, got %d %s", beer.Count, beer.Key)
+	}
+	avg := beer.Aggregations["avg_abv"]
+	if avg.Value == nil || *avg.Value != 17.0/3 {
+		t.Errorf("expected beer average abv of %f, got %v", 17.0/3, avg.Value)
+	}
+	// ties are broken by key
+	if res.Buckets[1].Key != "cider" || res.Buckets[1].Count != 1 {
+		t.Errorf("expected second bucket to be cider, got %s", res.Buckets[1].Key)
+	}
+}
+
+func TestMetricAggregations(t *testing.T) {
+	tests := []struct {
+		typ   string
+		value *float64
+	}{
+		{"min", floatPtr(4.5)},
+		{"max", floatPtr(13.0)},
+		{"sum", floatPtr(30.0)},
+		{"avg", floatPtr(7.5)},
+		{"stats", nil},
+	}
+	for _, test := range tests {
+		ab := NewMetricAggregationBuilder(test.typ, "abv")
+		for _, doc := range testDocs {
+			ab.Update(doc)
+		}
+		res := ab.Result()
+		if !reflect.DeepEqual(res.Value, test.value) {
+			t.Errorf("%s: expected value %v, got %v", test.typ, test.value, res.Value)
+		}
+		expectedStats := &search.AggregationStats{Count: 4, Sum: 30, Min: 4.5, Max: 13, Avg: 7.5}
+		if !reflect.DeepEqual(res.Stats, expectedStats) {
+			t.Errorf("%s: expected stats %#v, got %#v", test.typ, expectedStats, res.Stats)
+		}
+		if res.Missing != 1 {
+			t.Errorf("%s: expected 1 missing, got %d", test.typ, res.Missing)
+		}
+	}
+}
+
+func TestCardinalityAggregation(t *testing.T) {
+	ab := NewCardinalityAggregationBuilder("type")
+	for _, doc := range testDocs {
+		ab.Update(doc)
+	}
+	res := ab.Result()
+	if res.Value == nil || *res.Value != 4 {
+		t.Errorf("expected 4 distinct types, got %v", res.Value)
+	}
+
+	// numeric fields only count their full precision values
+	ab = NewCardinalityAggregationBuilder("abv")
+	for _, doc := range append(testDocs, index.FieldTerms{"abv": numericTerms(5.0)}) {
+		ab.Update(doc)
+	}
+	res = ab.Result()
+	if res.Value == nil || *res.Value != 4 {
+		t.Errorf("expected 4 distinct abv, got %v", res.Value)
+	}
+}
+
+func TestNumericRangeAggregation(t *testing.T) {
+	newSubs := func() map[string]search.AggregationBuilder {
+		return map[string]search.AggregationBuilder{
+			"types": NewCardinalityAggregationBuilder("type"),
+		}
+	}
+	ab := NewNumericRangeAggregationBuilder("abv", newSubs)
+	ab.AddRange("strong", floatPtr(7.5), nil)
+	ab.AddRange("light", nil, floatPtr(7.5))
+	ab.AddRange("none", floatPtr(50), nil)
+	for _, doc := range testDocs {
+		ab.Update(doc)
+	}
+	res := ab.Result()
+	var keys []string
+	var counts []int
+	var types []float64
+	for _, bucket := range res.Buckets {
+		keys = append(keys, bucket.Key)
+		counts = append(counts, bucket.Count)
+		types = append(types, *bucket.Aggregations["types"].Value)
+	}
+	if !reflect.DeepEqual(keys, []string{"strong", "light", "none"}) {
+		t.Errorf("expected buckets in request order, got %v", keys)
+	}
+	if !reflect.DeepEqual(counts, []int{2, 2, 0}) {
+		t.Errorf("unexpected bucket counts %v", counts)
+	}
+	if !reflect.DeepEqual(types, []float64{2, 2, 0}) {
+		t.Errorf("unexpected bucket types %v", types)
+	}
+	if res.Missing != 1 {
+		t.Errorf("expected 1 missing, got %d", res.Missing)
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
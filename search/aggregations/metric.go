@@ -0,0 +1,119 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package aggregations
+
+import (
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/numeric_util"
+	"github.com/blevesearch/bleve/search"
+)
+
+// MetricAggregationBuilder computes the min, max, sum, avg or stats of
+// the values of a numeric field.
+type MetricAggregationBuilder struct {
+	typ     string
+	field   string
+	stats   search.AggregationStats
+	missing int
+}
+
+// NewMetricAggregationBuilder returns a builder for one of the "min",
+// "max", "sum", "avg" or "stats" aggregation types.
+func NewMetricAggregationBuilder(typ, field string) *MetricAggregationBuilder {
+	return &MetricAggregationBuilder{
+		typ:   typ,
+		field: field,
+	}
+}
+
+func (ab *MetricAggregationBuilder) Fields() []string {
+	return []string{ab.field}
+}
+
+func (ab *MetricAggregationBuilder) Update(ft index.FieldTerms) {
+	values := numericValues(ft[ab.field])
+	if len(values) == 0 {
+		ab.missing++
+		return
+	}
+	for _, i64 := range values {
+		ab.stats.Add(numeric_util.Int64ToFloat64(i64))
+	}
+}
+
+func (ab *MetricAggregationBuilder) Result() *search.AggregationResult {
+	stats := ab.stats
+	rv := &search.AggregationResult{
+		Field:   ab.field,
+		Type:    ab.typ,
+		Stats:   &stats,
+		Missing: ab.missing,
+	}
+	rv.UpdateValue()
+	return rv
+}
+
+// CardinalityAggregationBuilder counts the distinct values of a field.
+type CardinalityAggregationBuilder struct {
+	field    string
+	distinct map[string]struct{}
+	missing  int
+}
+
+func NewCardinalityAggregationBuilder(field string) *CardinalityAggregationBuilder {
+	return &CardinalityAggregationBuilder{
+		field:    field,
+		distinct: make(map[string]struct{}),
+	}
+}
+
+func (ab *CardinalityAggregationBuilder) Fields() []string {
+	return []string{ab.field}
+}
+
+func (ab *CardinalityAggregationBuilder) Update(ft index.FieldTerms) {
+	terms := ft[ab.field]
+	if len(terms) == 0 {
+		ab.missing++
+		return
+	}
+	// numeric values are indexed at several precisions, only count
+	// the full precision ones
+	allNumeric := true
+	for _, term := range terms {
+		if valid, _ := numeric_util.ValidPrefixCodedTerm(term); !valid {
+			allNumeric = false
+			break
+		}
+	}
+	for _, term := range terms {
+		if allNumeric {
+			if _, shift := numeric_util.ValidPrefixCodedTerm(term); shift != 0 {
+				continue
+			}
+		}
+		ab.distinct[term] = struct{}{}
+	}
+}
+
+func (ab *CardinalityAggregationBuilder) Result() *search.AggregationResult {
+	distinct := make(map[string]struct{}, len(ab.distinct))
+	for term := range ab.distinct {
+		distinct[term] = struct{}{}
+	}
+	rv := &search.AggregationResult{
+		Field:    ab.field,
+		Type:     "cardinality",
+		Missing:  ab.missing,
+		Distinct: distinct,
+	}
+	rv.UpdateValue()
+	return rv
+}
@@ -0,0 +1,164 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package aggregations
+
+import (
+	"time"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/numeric_util"
+	"github.com/blevesearch/bleve/search"
+)
+
+type numericRange struct {
+	name   string
+	min    *float64
+	max    *float64
+	bucket *bucket
+}
+
+// NumericRangeAggregationBuilder groups documents by ranges of the
+// values of a numeric field. Ranges include their minimum and exclude
+// their maximum, buckets are returned in the order ranges were added.
+type NumericRangeAggregationBuilder struct {
+	field   string
+	newSubs SubAggregations
+	ranges  []*numericRange
+	missing int
+}
+
+func NewNumericRangeAggregationBuilder(field string, newSubs SubAggregations) *NumericRangeAggregationBuilder {
+	return &NumericRangeAggregationBuilder{
+		field:   field,
+		newSubs: newSubs,
+	}
+}
+
+func (ab *NumericRangeAggregationBuilder) AddRange(name string, min, max *float64) {
+	ab.ranges = append(ab.ranges, &numericRange{
+		name:   name,
+		min:    min,
+		max:    max,
+		bucket: newBucket(ab.newSubs),
+	})
+}
+
+func (ab *NumericRangeAggregationBuilder) Fields() []string {
+	return append([]string{ab.field}, subFields(ab.newSubs)...)
+}
+
+func (ab *NumericRangeAggregationBuilder) Update(ft index.FieldTerms) {
+	values := numericValues(ft[ab.field])
+	if len(values) == 0 {
+		ab.missing++
+		return
+	}
+	for _, r := range ab.ranges {
+		for _, i64 := range values {
+			f64 := numeric_util.Int64ToFloat64(i64)
+			if (r.min == nil || f64 >= *r.min) && (r.max == nil || f64 < *r.max) {
+				r.bucket.update(ft)
+				break
+			}
+		}
+	}
+}
+
+func (ab *NumericRangeAggregationBuilder) Result() *search.AggregationResult {
+	rv := &search.AggregationResult{
+		Field:   ab.field,
+		Type:    "numeric_range",
+		Missing: ab.missing,
+		Buckets: make(search.AggregationBuckets, 0, len(ab.ranges)),
+	}
+	for _, r := range ab.ranges {
+		b := r.bucket.result(r.name)
+		b.Min = r.min
+		b.Max = r.max
+		rv.Buckets = append(rv.Buckets, b)
+	}
+	return rv
+}
+
+type dateTimeRange struct {
+	name   string
+	start  time.Time
+	end    time.Time
+	bucket *bucket
+}
+
+// DateTimeRangeAggregationBuilder groups documents by ranges of the
+// values of a date field. Ranges include their start and exclude their
+// end, buckets are returned in the order ranges were added.
+type DateTimeRangeAggregationBuilder struct {
+	field   string
+	newSubs SubAggregations
+	ranges  []*dateTimeRange
+	missing int
+}
+
+func NewDateTimeRangeAggregationBuilder(field string, newSubs SubAggregations) *DateTimeRangeAggregationBuilder {
+	return &DateTimeRangeAggregationBuilder{
+		field:   field,
+		newSubs: newSubs,
+	}
+}
+
+func (ab *DateTimeRangeAggregationBuilder) AddRange(name string, start, end time.Time) {
+	ab.ranges = append(ab.ranges, &dateTimeRange{
+		name:   name,
+		start:  start,
+		end:    end,
+		bucket: newBucket(ab.newSubs),
+	})
+}
+
+func (ab *DateTimeRangeAggregationBuilder) Fields() []string {
+	return append([]string{ab.field}, subFields(ab.newSubs)...)
+}
+
+func (ab *DateTimeRangeAggregationBuilder) Update(ft index.FieldTerms) {
+	values := numericValues(ft[ab.field])
+	if len(values) == 0 {
+		ab.missing++
+		return
+	}
+	for _, r := range ab.ranges {
+		for _, i64 := range values {
+			t := time.Unix(0, i64)
+			if (r.start.IsZero() || !t.Before(r.start)) && (r.end.IsZero() || t.Before(r.end)) {
+				r.bucket.update(ft)
+				break
+			}
+		}
+	}
+}
+
+func (ab *DateTimeRangeAggregationBuilder) Result() *search.AggregationResult {
+	rv := &search.AggregationResult{
+		Field:   ab.field,
+		Type:    "date_range",
+		Missing: ab.missing,
+		Buckets: make(search.AggregationBuckets, 0, len(ab.ranges)),
+	}
+	for _, r := range ab.ranges {
+		b := r.bucket.result(r.name)
+		if !r.start.IsZero() {
+			start := r.start.Format(time.RFC3339Nano)
+			b.Start = &start
+		}
+		if !r.end.IsZero() {
+			end := r.end.Format(time.RFC3339Nano)
+			b.End = &end
+		}
+		rv.Buckets = append(rv.Buckets, b)
+	}
+	return rv
+}
@@ -0,0 +1,73 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package aggregations
+
+import (
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+// TermsAggregationBuilder groups documents by the terms of a field,
+// keeping the size most frequent ones.
+type TermsAggregationBuilder struct {
+	size    int
+	field   string
+	newSubs SubAggregations
+	buckets map[string]*bucket
+	missing int
+}
+
+func NewTermsAggregationBuilder(field string, size int, newSubs SubAggregations) *TermsAggregationBuilder {
+	return &TermsAggregationBuilder{
+		size:    size,
+		field:   field,
+		newSubs: newSubs,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (ab *TermsAggregationBuilder) Fields() []string {
+	return append([]string{ab.field}, subFields(ab.newSubs)...)
+}
+
+func (ab *TermsAggregationBuilder) Update(ft index.FieldTerms) {
+	terms := ft[ab.field]
+	if len(terms) == 0 {
+		ab.missing++
+		return
+	}
+	seen := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		b, ok := ab.buckets[term]
+		if !ok {
+			b = newBucket(ab.newSubs)
+			ab.buckets[term] = b
+		}
+		b.update(ft)
+	}
+}
+
+func (ab *TermsAggregationBuilder) Result() *search.AggregationResult {
+	rv := &search.AggregationResult{
+		Field:   ab.field,
+		Type:    "terms",
+		Missing: ab.missing,
+		Buckets: make(search.AggregationBuckets, 0, len(ab.buckets)),
+	}
+	for term, b := range ab.buckets {
+		rv.Buckets = append(rv.Buckets, b.result(term))
+	}
+	rv.Fixup(ab.size)
+	return rv
+}
@@ -0,0 +1,76 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAggregationResultsMerge(t *testing.T) {
+	avg1 := &AggregationResult{Field: "abv", Type: "avg", Stats: &AggregationStats{Count: 2, Sum: 10, Min: 4, Max: 6, Avg: 5}}
+	avg1.UpdateValue()
+	avg2 := &AggregationResult{Field: "abv", Type: "avg", Stats: &AggregationStats{Count: 1, Sum: 11, Min: 11, Max: 11, Avg: 11}}
+	avg2.UpdateValue()
+
+	ar1 := AggregationResults{
+		"types": &AggregationResult{
+			Field: "type",
+			Type:  "terms",
+			Buckets: AggregationBuckets{
+				{Key: "beer", Count: 2, Aggregations: AggregationResults{"abv": avg1}},
+			},
+		},
+		"brewers": &AggregationResult{
+			Field:    "brewer",
+			Type:     "cardinality",
+			Distinct: map[string]struct{}{"a": {}, "b": {}},
+		},
+	}
+	ar1["brewers"].UpdateValue()
+	ar2 := AggregationResults{
+		"types": &AggregationResult{
+			Field: "type",
+			Type:  "terms",
+			Buckets: AggregationBuckets{
+				{Key: "wine", Count: 4},
+				{Key: "beer", Count: 1, Aggregations: AggregationResults{"abv": avg2}},
+			},
+		},
+		"brewers": &AggregationResult{
+			Field:    "brewer",
+			Type:     "cardinality",
+			Distinct: map[string]struct{}{"b": {}, "c": {}},
+		},
+		"other": &AggregationResult{Field: "x", Type: "sum"},
+	}
+	ar2["brewers"].UpdateValue()
+
+	ar1.Merge(ar2)
+	ar1["types"].Fixup(1)
+
+	types := ar1["types"]
+	if len(types.Buckets) != 1 || types.Buckets[0].Key != "wine" || types.Other != 3 {
+		t.Errorf("expected only wine bucket and 3 others, got %#v", types)
+	}
+	if _, ok := ar1["other"]; !ok {
+		t.Errorf("expected aggregation only in second results to be merged")
+	}
+	if *ar1["brewers"].Value != 3 {
+		t.Errorf("expected 3 distinct brewers, got %f", *ar1["brewers"].Value)
+	}
+	expectedStats := &AggregationStats{Count: 3, Sum: 21, Min: 4, Max: 11, Avg: 7}
+	if !reflect.DeepEqual(avg1.Stats, expectedStats) {
+		t.Errorf("expected merged stats %#v, got %#v", expectedStats, avg1.Stats)
+	}
+	if *avg1.Value != 7 {
+		t.Errorf("expected merged average of 7, got %f", *avg1.Value)
+	}
+}
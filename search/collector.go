@@ -23,4 +23,6 @@ type Collector interface {
 	Took() time.Duration
 	SetFacetsBuilder(facetsBuilder *FacetsBuilder)
 	FacetResults() FacetResults
+	SetAggregationsBuilder(aggregationsBuilder *AggregationsBuilder)
+	AggregationResults() AggregationResults
 }
@@ -0,0 +1,210 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"container/list"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+// TopNCollector collects the top N hits according to a SortOrder,
+// skipping the first ones.
+type TopNCollector struct {
+	size          int
+	skip          int
+	sort          search.SortOrder
+	neededFields  []string
+	indexReader   index.IndexReader
+	searchAfter   *search.DocumentMatch
+	results       *list.List
+	took          time.Duration
+	maxScore      float64
+	total         uint64
+	facetsBuilder *search.FacetsBuilder
+
+	aggregationsBuilder *search.AggregationsBuilder
+}
+
+// NewTopNCollector builds a collector returning size hits ordered
+// by sort after skipping the first skip ones. The index reader is
+// used to load field terms when sort depends on field values.
+func NewTopNCollector(size int, skip int, sort search.SortOrder, indexReader index.IndexReader) *TopNCollector {
+	return &TopNCollector{
+		size:         size,
+		skip:         skip,
+		sort:         sort,
+		neededFields: sort.RequiresFields(),
+		indexReader:  indexReader,
+		results:      list.New(),
+	}
+}
+
+// NewTopNCollectorAfter builds a collector returning size hits
+// ordered by sort which all sort strictly after the searchAfter
+// cursor, as built by SortOrder.DocumentMatchAfter. Only size hits
+// are ever kept in memory, whatever the depth of the page.
+func NewTopNCollectorAfter(size int, sort search.SortOrder, searchAfter *search.DocumentMatch, indexReader index.IndexReader) *TopNCollector {
+	rv := NewTopNCollector(size, 0, sort, indexReader)
+	rv.searchAfter = searchAfter
+	return rv
+}
+
+func (tnc *TopNCollector) Total() uint64 {
+	return tnc.total
+}
+
+func (tnc *TopNCollector) MaxScore() float64 {
+	return tnc.maxScore
+}
+
+func (tnc *TopNCollector) Took() time.Duration {
+	return tnc.took
+}
+
+func (tnc *TopNCollector) Collect(ctx context.Context, searcher search.Searcher) error {
+	startTime := time.Now()
+	var err error
+	var next *search.DocumentMatch
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		next, err = searcher.Next()
+	}
+	for err == nil && next != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			err = tnc.collectSingle(next)
+			if err != nil {
+				break
+			}
+			if tnc.facetsBuilder != nil {
+				err = tnc.facetsBuilder.Update(next)
+				if err != nil {
+					break
+				}
+			}
+			if tnc.aggregationsBuilder != nil {
+				err = tnc.aggregationsBuilder.Update(next)
+				if err != nil {
+					break
+				}
+			}
+			next, err = searcher.Next()
+		}
+	}
+	// compute search duration
+	tnc.took = time.Since(startTime)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (tnc *TopNCollector) collectSingle(dm *search.DocumentMatch) error {
+	// increment total hits
+	tnc.total++
+
+	// update max score
+	if dm.Score > tnc.maxScore {
+		tnc.maxScore = dm.Score
+	}
+
+	var fieldTerms index.FieldTerms
+	if len(tnc.neededFields) > 0 {
+		var err error
+		fieldTerms, err = tnc.indexReader.DocumentFieldTermsForFields(dm.ID, tnc.neededFields)
+		if err != nil {
+			return err
+		}
+	}
+	dm.Sort = tnc.sort.Value(dm, fieldTerms)
+
+	// hits at or before the cursor were returned by previous pages
+	if tnc.searchAfter != nil && tnc.sort.Compare(dm, tnc.searchAfter) <= 0 {
+		return nil
+	}
+
+	// the list is kept worst first, a hit not better than the worst
+	// one of a full list can be dropped right away
+	if tnc.results.Len() >= tnc.size+tnc.skip {
+		if tnc.results.Len() == 0 ||
+			tnc.sort.Compare(dm, tnc.results.Front().Value.(*search.DocumentMatch)) >= 0 {
+			return nil
+		}
+	}
+
+	inserted := false
+	for e := tnc.results.Front(); e != nil; e = e.Next() {
+		curr := e.Value.(*search.DocumentMatch)
+		if tnc.sort.Compare(dm, curr) >= 0 {
+			tnc.results.InsertBefore(dm, e)
+			inserted = true
+			break
+		}
+	}
+	if !inserted {
+		tnc.results.PushBack(dm)
+	}
+
+	// if we just made the list too long
+	if tnc.results.Len() > tnc.size+tnc.skip {
+		// remove the head
+		tnc.results.Remove(tnc.results.Front())
+	}
+	return nil
+}
+
+func (tnc *TopNCollector) Results() search.DocumentMatchCollection {
+	if tnc.results.Len()-tnc.skip > 0 {
+		rv := make(search.DocumentMatchCollection, tnc.results.Len()-tnc.skip)
+		i := 0
+		skipped := 0
+		for e := tnc.results.Back(); e != nil; e = e.Prev() {
+			if skipped < tnc.skip {
+				skipped++
+				continue
+			}
+			rv[i] = e.Value.(*search.DocumentMatch)
+			i++
+		}
+		return rv
+	}
+	return search.DocumentMatchCollection{}
+}
+
+func (tnc *TopNCollector) SetFacetsBuilder(facetsBuilder *search.FacetsBuilder) {
+	tnc.facetsBuilder = facetsBuilder
+}
+
+func (tnc *TopNCollector) FacetResults() search.FacetResults {
+	if tnc.facetsBuilder != nil {
+		return tnc.facetsBuilder.Results()
+	}
+	return search.FacetResults{}
+}
+
+func (tnc *TopNCollector) SetAggregationsBuilder(aggregationsBuilder *search.AggregationsBuilder) {
+	tnc.aggregationsBuilder = aggregationsBuilder
+}
+
+func (tnc *TopNCollector) AggregationResults() search.AggregationResults {
+	if tnc.aggregationsBuilder != nil {
+		return tnc.aggregationsBuilder.Results()
+	}
+	return search.AggregationResults{}
+}
@@ -0,0 +1,140 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/blevesearch/bleve/search"
+)
+
+func TestTopNCollectorScoreDescending(t *testing.T) {
+	searcher := &stubSearcher{
+		matches: search.DocumentMatchCollection{
+			&search.DocumentMatch{ID: "a", Score: 11},
+			&search.DocumentMatch{ID: "b", Score: 9},
+			&search.DocumentMatch{ID: "c", Score: 11},
+			&search.DocumentMatch{ID: "d", Score: 99},
+			&search.DocumentMatch{ID: "e", Score: 11},
+			&search.DocumentMatch{ID: "f", Score: 7},
+		},
+	}
+
+	collector := NewTopNCollector(3, 1, search.SortOrder{&search.SortScore{Desc: true}}, nil)
+	err := collector.Collect(context.Background(), searcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if collector.Total() != 6 {
+		t.Errorf("expected 6 total results, got %d", collector.Total())
+	}
+	if collector.MaxScore() != 99 {
+		t.Errorf("expected max score 99, got %f", collector.MaxScore())
+	}
+
+	// equal scores keep the order they were collected in
+	expectedIDs := []string{"a", "c", "e"}
+	results := collector.Results()
+	if len(results) != len(expectedIDs) {
+		t.Fatalf("expected %d results, got %d", len(expectedIDs), len(results))
+	}
+	for i, result := range results {
+		if result.ID != expectedIDs[i] {
+			t.Errorf("expected result %d to be %s, got %s", i, expectedIDs[i], result.ID)
+		}
+	}
+}
+
+func TestTopNCollectorDocIDAscending(t *testing.T) {
+	searcher := &stubSearcher{
+		matches: search.DocumentMatchCollection{
+			&search.DocumentMatch{ID: "d", Score: 1},
+			&search.DocumentMatch{ID: "b", Score: 2},
+			&search.DocumentMatch{ID: "e", Score: 3},
+			&search.DocumentMatch{ID: "a", Score: 4},
+			&search.DocumentMatch{ID: "c", Score: 5},
+		},
+	}
+
+	collector := NewTopNCollector(2, 0, search.SortOrder{&search.SortDocID{}}, nil)
+	err := collector.Collect(context.Background(), searcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedIDs := []string{"a", "b"}
+	results := collector.Results()
+	if len(results) != len(expectedIDs) {
+		t.Fatalf("expected %d results, got %d", len(expectedIDs), len(results))
+	}
+	for i, result := range results {
+		if result.ID != expectedIDs[i] {
+			t.Errorf("expected result %d to be %s, got %s", i, expectedIDs[i], result.ID)
+		}
+		if len(result.Sort) != 1 || result.Sort[0] != result.ID {
+			t.Errorf("expected sort value %s, got %v", result.ID, result.Sort)
+		}
+	}
+}
+
+func TestTopNCollectorSearchAfter(t *testing.T) {
+	matches := func() search.DocumentMatchCollection {
+		return search.DocumentMatchCollection{
+			&search.DocumentMatch{ID: "a", Score: 2},
+			&search.DocumentMatch{ID: "b", Score: 3},
+			&search.DocumentMatch{ID: "c", Score: 2},
+			&search.DocumentMatch{ID: "d", Score: 1},
+			&search.DocumentMatch{ID: "e", Score: 3},
+		}
+	}
+	sort := search.SortOrder{&search.SortScore{Desc: true}, &search.SortDocID{}}
+
+	var actualIDs []string
+	var after []string
+	for {
+		collector := NewTopNCollector(2, 0, sort, nil)
+		if after != nil {
+			searchAfter, err := sort.DocumentMatchAfter(after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			collector = NewTopNCollectorAfter(2, sort, searchAfter, nil)
+		}
+		err := collector.Collect(context.Background(), &stubSearcher{matches: matches()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if collector.Total() != 5 {
+			t.Errorf("expected total of 5 regardless of the cursor, got %d", collector.Total())
+		}
+		results := collector.Results()
+		if len(results) == 0 {
+			break
+		}
+		for _, result := range results {
+			actualIDs = append(actualIDs, result.ID)
+		}
+		after = results[len(results)-1].Sort
+	}
+
+	expectedIDs := []string{"b", "e", "a", "c", "d"}
+	if len(actualIDs) != len(expectedIDs) {
+		t.Fatalf("expected %v, got %v", expectedIDs, actualIDs)
+	}
+	for i := range expectedIDs {
+		if actualIDs[i] != expectedIDs[i] {
+			t.Errorf("expected %v, got %v", expectedIDs, actualIDs)
+			break
+		}
+	}
+}
@@ -27,6 +27,8 @@ type TopScoreCollector struct {
 	minScore      float64
 	total         uint64
 	facetsBuilder *search.FacetsBuilder
+
+	aggregationsBuilder *search.AggregationsBuilder
 }
 
 func NewTopScorerCollector(k int) *TopScoreCollector {
@@ -79,6 +81,12 @@ func (tksc *TopScoreCollector) Collect(ctx context.Context, searcher search.Sear
 					break
 				}
 			}
+			if tksc.aggregationsBuilder != nil {
+				err = tksc.aggregationsBuilder.Update(next)
+				if err != nil {
+					break
+				}
+			}
 			next, err = searcher.Next()
 		}
 	}
@@ -152,3 +160,14 @@ func (tksc *TopScoreCollector) FacetResults() search.FacetResults {
 	}
 	return search.FacetResults{}
 }
+
+func (tksc *TopScoreCollector) SetAggregationsBuilder(aggregationsBuilder *search.AggregationsBuilder) {
+	tksc.aggregationsBuilder = aggregationsBuilder
+}
+
+func (tksc *TopScoreCollector) AggregationResults() search.AggregationResults {
+	if tksc.aggregationsBuilder != nil {
+		return tksc.aggregationsBuilder.Results()
+	}
+	return search.AggregationResults{}
+}
@@ -0,0 +1,422 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package completion implements the weighted prefix trie backing
+// search-as-you-type completions. Unlike an FST, its nodes are not
+// shared between keys, so that entries are added and removed as the
+// documents of the index are written.
+package completion
+
+import (
+	"container/heap"
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// An Entry is a completion of a document: Inputs are the
+// texts it is suggested for, and Keys their normalized
+// forms which are looked up.
+type Entry struct {
+	ID       string
+	Inputs   []string
+	Keys     []string
+	Weight   int64
+	Payload  json.RawMessage
+	Contexts map[string][]string
+}
+
+// Normalize returns the key of input when no analyzer is
+// configured: input lower cased, with spaces collapsed.
+func Normalize(input string) string {
+	return strings.Join(strings.Fields(strings.ToLower(input)), " ")
+}
+
+// NormalizePrefix works like Normalize, but keeps a trailing
+// space, so that a prefix ending with one only matches keys
+// where the word is complete.
+func NormalizePrefix(prefix string) string {
+	rv := Normalize(prefix)
+	if rv != "" {
+		r, _ := utf8.DecodeLastRuneInString(prefix)
+		if unicode.IsSpace(r) {
+			rv += " "
+		}
+	}
+	return rv
+}
+
+// output refers to an input of an entry.
+type output struct {
+	entry *Entry
+	input int
+}
+
+type transition struct {
+	label  byte
+	target *node
+}
+
+type node struct {
+	// transitions sorted by label
+	transitions []transition
+	outputs     []output
+	// the maximum weight of the entries reachable
+	// from this node
+	max int64
+}
+
+func (n *node) child(label byte) (int, bool) {
+	i := sort.Search(len(n.transitions), func(i int) bool {
+		return n.transitions[i].label >= label
+	})
+	return i, i < len(n.transitions) && n.transitions[i].label == label
+}
+
+// updateMax recomputes the maximum weight of the entries
+// reachable from n, from its outputs and its children.
+func (n *node) updateMax() {
+	n.max = -1 << 63
+	for _, out := range n.outputs {
+		if out.entry.Weight > n.max {
+			n.max = out.entry.Weight
+		}
+	}
+	for _, t := range n.transitions {
+		if t.target.max > n.max {
+			n.max = t.target.max
+		}
+	}
+}
+
+// A Trie is a prefix tree over the key bytes of entries, each
+// node knowing the maximum weight of the entries below it so
+// that the best completions are found without visiting them
+// all. Entries are added and removed by document as the index
+// changes. A Trie is not safe for concurrent modification, but
+// lookups may run concurrently.
+type Trie struct {
+	root *node
+	docs map[string][]*Entry
+	len  int
+}
+
+// New returns an empty Trie.
+func New() *Trie {
+	return &Trie{
+		root: &node{max: -1 << 63},
+		docs: make(map[string][]*Entry),
+	}
+}
+
+// Len returns the number of entries of the Trie.
+func (t *Trie) Len() int {
+	return t.len
+}
+
+// Set replaces the entries of the document id by entries,
+// removing them when there are none. Setting the same
+// entries again leaves the Trie unchanged.
+func (t *Trie) Set(id string, entries []*Entry) {
+	for _, entry := range t.docs[id] {
+		for i, key := range entry.Keys {
+			t.remove(key, output{entry: entry, input: i})
+		}
+	}
+	t.len -= len(t.docs[id])
+	delete(t.docs, id)
+	if len(entries) == 0 {
+		return
+	}
+	for _, entry := range entries {
+		for i, key := range entry.Keys {
+			t.add(key, output{entry: entry, input: i})
+		}
+	}
+	t.len += len(entries)
+	t.docs[id] = entries
+}
+
+func (t *Trie) add(key string, out output) {
+	current := t.root
+	for i := 0; ; i++ {
+		if out.entry.Weight > current.max {
+			current.max = out.entry.Weight
+		}
+		if i == len(key) {
+			break
+		}
+		j, ok := current.child(key[i])
+		if !ok {
+			current.transitions = append(current.transitions, transition{})
+			copy(current.transitions[j+1:], current.transitions[j:])
+			current.transitions[j] = transition{label: key[i], target: &node{max: -1 << 63}}
+		}
+		current = current.transitions[j].target
+	}
+	current.outputs = append(current.outputs, out)
+}
+
+func (t *Trie) remove(key string, out output) {
+	path := make([]*node, 0, len(key)+1)
+	current := t.root
+	for i := 0; i < len(key); i++ {
+		path = append(path, current)
+		j, ok := current.child(key[i])
+		if !ok {
+			return
+		}
+		current = current.transitions[j].target
+	}
+	for j, o := range current.outputs {
+		if o == out {
+			current.outputs = append(current.outputs[:j], current.outputs[j+1:]...)
+			break
+		}
+	}
+	current.updateMax()
+
+	// prune the nodes left without outputs below them,
+	// and lower the maximum weights of their parents
+	for i := len(path) - 1; i >= 0; i-- {
+		parent := path[i]
+		if len(current.outputs) == 0 && len(current.transitions) == 0 {
+			j, _ := parent.child(key[i])
+			parent.transitions = append(parent.transitions[:j], parent.transitions[j+1:]...)
+		}
+		parent.updateMax()
+		current = parent
+	}
+}
+
+// A Completion is an entry matching a lookup, through the
+// input Input, whose key is within Distance edits of the
+// prefix.
+type Completion struct {
+	Entry    *Entry
+	Input    string
+	Distance int
+}
+
+// Lookup returns at most size entries having a key starting
+// with prefix, or with a prefix within fuzziness edits of it.
+// Completions are ordered by increasing distance, then by
+// decreasing weight. Each entry is returned once. If contexts
+// is not empty, entries must have, for each of its names, one
+// of the listed values.
+func (t *Trie) Lookup(prefix string, fuzziness, size int, contexts map[string][]string) []*Completion {
+	rv := make([]*Completion, 0)
+	if size <= 0 || t.len == 0 {
+		return rv
+	}
+
+	q := &lookupQueue{}
+	for _, start := range t.starts(prefix, fuzziness) {
+		heap.Push(q, &lookupItem{node: start.node, key: start.key, distance: start.distance, weight: start.node.max})
+	}
+
+	expanded := make(map[*node]bool)
+	returned := make(map[*Entry]bool)
+	for q.Len() > 0 && len(rv) < size {
+		item := heap.Pop(q).(*lookupItem)
+		if item.output != nil {
+			entry := item.output.entry
+			if returned[entry] {
+				continue
+			}
+			returned[entry] = true
+			rv = append(rv, &Completion{
+				Entry:    entry,
+				Input:    entry.Inputs[item.output.input],
+				Distance: item.distance,
+			})
+			continue
+		}
+
+		// the first expansion of a node is the one at the
+		// smallest distance
+		if expanded[item.node] {
+			continue
+		}
+		expanded[item.node] = true
+		for i := range item.node.outputs {
+			out := item.node.outputs[i]
+			if returned[out.entry] || !matchContexts(out.entry, contexts) {
+				continue
+			}
+			heap.Push(q, &lookupItem{output: &out, key: item.key, distance: item.distance, weight: out.entry.Weight})
+		}
+		for _, tr := range item.node.transitions {
+			heap.Push(q, &lookupItem{node: tr.target, key: item.key + string(tr.label), distance: item.distance, weight: tr.target.max})
+		}
+	}
+	return rv
+}
+
+type start struct {
+	node     *node
+	key      string
+	distance int
+}
+
+// starts returns the nodes whose key is within fuzziness
+// edits of prefix, counted in characters.
+func (t *Trie) starts(prefix string, fuzziness int) []start {
+	if fuzziness <= 0 {
+		current := t.root
+		for i := 0; i < len(prefix); i++ {
+			j, ok := current.child(prefix[i])
+			if !ok {
+				return nil
+			}
+			current = current.transitions[j].target
+		}
+		return []start{{node: current, key: prefix}}
+	}
+
+	runes := []rune(prefix)
+	row := make([]int, len(runes)+1)
+	for i := range row {
+		row[i] = i
+	}
+	rv := make([]start, 0)
+	fuzzyStarts(t.root, nil, runes, row, fuzziness, &rv)
+	return rv
+}
+
+// fuzzyStarts walks the nodes below the node reached by
+// key, row holding the edit distances between the runes of
+// key and the prefixes of runes.
+func fuzzyStarts(current *node, key []byte, runes []rune, row []int, fuzziness int, rv *[]start) {
+	// distances only change once a rune is complete
+	if len(key) == 0 || utf8.FullRune(key[lastRuneStart(key):]) {
+		if row[len(runes)] <= fuzziness {
+			*rv = append(*rv, start{node: current, key: string(key), distance: row[len(runes)]})
+		}
+		min := row[0]
+		for _, d := range row {
+			if d < min {
+				min = d
+			}
+		}
+		if min > fuzziness {
+			return
+		}
+	}
+
+	for _, t := range current.transitions {
+		next := append(key[:len(key):len(key)], t.label)
+		nextRow := row
+		begin := lastRuneStart(next)
+		if utf8.FullRune(next[begin:]) {
+			r, _ := utf8.DecodeRune(next[begin:])
+			nextRow = make([]int, len(row))
+			nextRow[0] = row[0] + 1
+			for i := 1; i < len(row); i++ {
+				cost := 1
+				if runes[i-1] == r {
+					cost = 0
+				}
+				nextRow[i] = minInt(row[i]+1, nextRow[i-1]+1, row[i-1]+cost)
+			}
+		}
+		fuzzyStarts(t.target, next, runes, nextRow, fuzziness, rv)
+	}
+}
+
+// lastRuneStart returns the offset of the last, possibly
+// incomplete, rune of b.
+func lastRuneStart(b []byte) int {
+	i := len(b) - 1
+	for i > 0 && !utf8.RuneStart(b[i]) {
+		i--
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+func minInt(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func matchContexts(entry *Entry, contexts map[string][]string) bool {
+OUTER:
+	for name, values := range contexts {
+		for _, value := range values {
+			for _, ev := range entry.Contexts[name] {
+				if ev == value {
+					continue OUTER
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// lookupItem is a node or an output to visit, ordered by
+// distance, then weight, then key.
+type lookupItem struct {
+	node     *node
+	output   *output
+	key      string
+	distance int
+	weight   int64
+}
+
+type lookupQueue []*lookupItem
+
+func (q lookupQueue) Len() int { return len(q) }
+
+func (q lookupQueue) Less(i, j int) bool {
+	if q[i].distance != q[j].distance {
+		return q[i].distance < q[j].distance
+	}
+	if q[i].weight != q[j].weight {
+		return q[i].weight > q[j].weight
+	}
+	if q[i].key != q[j].key {
+		return q[i].key < q[j].key
+	}
+	// outputs of a node come before the nodes below it
+	if (q[i].output != nil) != (q[j].output != nil) {
+		return q[i].output != nil
+	}
+	if q[i].output != nil {
+		if q[i].output.entry.ID != q[j].output.entry.ID {
+			return q[i].output.entry.ID < q[j].output.entry.ID
+		}
+		return q[i].output.input < q[j].output.input
+	}
+	return false
+}
+
+func (q lookupQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *lookupQueue) Push(x interface{}) {
+	*q = append(*q, x.(*lookupItem))
+}
+
+func (q *lookupQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[0 : n-1]
+	return item
+}
@@ -0,0 +1,227 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package completion
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func testEntries() []*Entry {
+	entry := func(id string, weight int64, inputs ...string) *Entry {
+		rv := &Entry{ID: id, Weight: weight, Inputs: inputs}
+		for _, input := range inputs {
+			rv.Keys = append(rv.Keys, Normalize(input))
+		}
+		return rv
+	}
+	rv := []*Entry{
+		entry("nirvana", 10, "Nirvana", "Kurt Cobain"),
+		entry("nine", 30, "Nine Inch Nails"),
+		entry("nick", 20, "Nick Cave"),
+		entry("nico", 5, "Nico"),
+		entry("muse", 40, "Muse"),
+		entry("mötley", 15, "Mötley Crüe"),
+		entry("nina", 25, "Nina Simone"),
+	}
+	rv[2].Payload = json.RawMessage(`{"genre":"rock"}`)
+	rv[2].Contexts = map[string][]string{"country": {"au"}}
+	rv[6].Contexts = map[string][]string{"country": {"us"}}
+	return rv
+}
+
+func lookupInputs(completions []*Completion) []string {
+	rv := []string{}
+	for _, c := range completions {
+		rv = append(rv, c.Input)
+	}
+	return rv
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input  string
+		key    string
+		prefix string
+	}{
+		{input: "", key: "", prefix: ""},
+		{input: "Nine  Inch\tNails", key: "nine inch nails", prefix: "nine inch nails"},
+		{input: "Nine ", key: "nine", prefix: "nine "},
+		{input: "  ", key: "", prefix: ""},
+	}
+	for _, test := range tests {
+		key := Normalize(test.input)
+		if key != test.key {
+			t.Errorf("expected key '%s' for '%s', got '%s'", test.key, test.input, key)
+		}
+		prefix := NormalizePrefix(test.input)
+		if prefix != test.prefix {
+			t.Errorf("expected prefix '%s' for '%s', got '%s'", test.prefix, test.input, prefix)
+		}
+	}
+}
+
+func testTrie(entries []*Entry) *Trie {
+	rv := New()
+	for _, entry := range entries {
+		rv.Set(entry.ID, []*Entry{entry})
+	}
+	return rv
+}
+
+func TestTrieLookup(t *testing.T) {
+	trie := testTrie(testEntries())
+	if trie.Len() != 7 {
+		t.Errorf("expected 7 entries, got %d", trie.Len())
+	}
+
+	tests := []struct {
+		prefix    string
+		fuzziness int
+		size      int
+		contexts  map[string][]string
+		inputs    []string
+	}{
+		{
+			prefix: "ni",
+			size:   10,
+			inputs: []string{"Nine Inch Nails", "Nina Simone", "Nick Cave", "Nirvana", "Nico"},
+		},
+		{
+			prefix: "ni",
+			size:   2,
+			inputs: []string{"Nine Inch Nails", "Nina Simone"},
+		},
+		{
+			prefix: "nic",
+			size:   10,
+			inputs: []string{"Nick Cave", "Nico"},
+		},
+		{
+			prefix: "kurt",
+			size:   10,
+			inputs: []string{"Kurt Cobain"},
+		},
+		{
+			prefix: "nine ",
+			size:   10,
+			inputs: []string{"Nine Inch Nails"},
+		},
+		{
+			prefix: "x",
+			size:   10,
+			inputs: []string{},
+		},
+		{
+			// every entry matches the empty prefix
+			prefix: "",
+			size:   3,
+			inputs: []string{"Muse", "Nine Inch Nails", "Nina Simone"},
+		},
+		{
+			prefix:   "ni",
+			size:     10,
+			contexts: map[string][]string{"country": {"au", "nz"}},
+			inputs:   []string{"Nick Cave"},
+		},
+		{
+			prefix:   "ni",
+			size:     10,
+			contexts: map[string][]string{"country": {"fr"}},
+			inputs:   []string{},
+		},
+		{
+			// exact matches come first
+			prefix:    "nick",
+			fuzziness: 1,
+			size:      10,
+			inputs:    []string{"Nick Cave", "Nico"},
+		},
+		{
+			prefix:    "mut",
+			fuzziness: 1,
+			size:      10,
+			inputs:    []string{"Muse", "Mötley Crüe"},
+		},
+		{
+			// fuzziness counts characters, not bytes
+			prefix:    "motley",
+			fuzziness: 1,
+			size:      10,
+			inputs:    []string{"Mötley Crüe"},
+		},
+		{
+			prefix:    "nivrana",
+			fuzziness: 2,
+			size:      10,
+			inputs:    []string{"Nirvana"},
+		},
+	}
+
+	for _, test := range tests {
+		completions := trie.Lookup(test.prefix, test.fuzziness, test.size, test.contexts)
+		inputs := lookupInputs(completions)
+		if !reflect.DeepEqual(inputs, test.inputs) {
+			t.Errorf("expected %q for '%s' with fuzziness %d and contexts %v, got %q", test.inputs, test.prefix, test.fuzziness, test.contexts, inputs)
+		}
+	}
+
+	completions := trie.Lookup("nick", 0, 1, nil)
+	if string(completions[0].Entry.Payload) != `{"genre":"rock"}` || completions[0].Entry.ID != "nick" {
+		t.Errorf("unexpected completion %+v", completions[0].Entry)
+	}
+	completions = trie.Lookup("nick", 1, 10, nil)
+	if completions[0].Distance != 0 || completions[1].Distance != 1 {
+		t.Errorf("expected distances 0 and 1, got %d and %d", completions[0].Distance, completions[1].Distance)
+	}
+}
+
+func TestTrieSet(t *testing.T) {
+	entries := testEntries()
+	trie := testTrie(entries)
+
+	// replacing the entries of a document drops its previous keys
+	trie.Set("nine", []*Entry{{ID: "nine", Inputs: []string{"NIN"}, Keys: []string{"nin"}, Weight: 1}})
+	inputs := lookupInputs(trie.Lookup("ni", 0, 10, nil))
+	expected := []string{"Nina Simone", "Nick Cave", "Nirvana", "Nico", "NIN"}
+	if !reflect.DeepEqual(inputs, expected) {
+		t.Errorf("expected %q, got %q", expected, inputs)
+	}
+
+	// the weights of removed entries no longer rank the nodes
+	// above them
+	trie.Set("nina", nil)
+	trie.Set("nina", nil)
+	inputs = lookupInputs(trie.Lookup("ni", 0, 2, nil))
+	expected = []string{"Nick Cave", "Nirvana"}
+	if !reflect.DeepEqual(inputs, expected) {
+		t.Errorf("expected %q, got %q", expected, inputs)
+	}
+	if trie.Len() != 6 {
+		t.Errorf("expected 6 entries, got %d", trie.Len())
+	}
+
+	// setting the same entries again changes nothing
+	trie.Set("nick", []*Entry{entries[2]})
+	if trie.Len() != 6 {
+		t.Errorf("expected 6 entries, got %d", trie.Len())
+	}
+
+	for _, id := range []string{"nirvana", "nine", "nick", "nico", "muse", "mötley"} {
+		trie.Set(id, nil)
+	}
+	if trie.Len() != 0 || len(trie.root.transitions) != 0 {
+		t.Errorf("expected an empty trie, got %d entries and %d transitions", trie.Len(), len(trie.root.transitions))
+	}
+	if len(trie.Lookup("", 0, 10, nil)) != 0 {
+		t.Errorf("expected no completions")
+	}
+}
@@ -30,6 +30,11 @@ type DateTimeFacetBuilder struct {
 	total      int
 	missing    int
 	ranges     map[string]*dateTimeRange
+
+	// histogram buckets are generated from the values instead of being
+	// added upfront
+	interval *DateTimeInterval
+	location *time.Location
 }
 
 func NewDateTimeFacetBuilder(field string, size int) *DateTimeFacetBuilder {
@@ -49,6 +54,19 @@ func (fb *DateTimeFacetBuilder) AddRange(name string, start, end time.Time) {
 	fb.ranges[name] = &r
 }
 
+// SetHistogram makes the builder group the values in buckets of the
+// given interval, computed in the given time zone, instead of the ranges
+// added with AddRange. The empty buckets and the minimum document count
+// are left to FixupDateHistogram.
+func (fb *DateTimeFacetBuilder) SetHistogram(interval DateTimeInterval, location *time.Location) {
+	fb.interval = &interval
+	fb.location = location
+}
+
+func (fb *DateTimeFacetBuilder) Field() string {
+	return fb.field
+}
+
 func (fb *DateTimeFacetBuilder) Update(ft index.FieldTerms) {
 	terms, ok := ft[fb.field]
 	if ok {
@@ -61,6 +79,11 @@ func (fb *DateTimeFacetBuilder) Update(ft index.FieldTerms) {
 				if err == nil {
 					t := time.Unix(0, i64)
 
+					if fb.interval != nil {
+						fb.updateHistogram(t)
+						continue
+					}
+
 					// look at each of the ranges for a match
 					for rangeName, r := range fb.ranges {
 
@@ -83,6 +106,19 @@ func (fb *DateTimeFacetBuilder) Update(ft index.FieldTerms) {
 	}
 }
 
+func (fb *DateTimeFacetBuilder) updateHistogram(t time.Time) {
+	start := fb.interval.Start(t.In(fb.location))
+	name := start.Format(time.RFC3339Nano)
+	if _, exists := fb.ranges[name]; !exists {
+		fb.ranges[name] = &dateTimeRange{
+			start: start,
+			end:   fb.interval.Next(start),
+		}
+	}
+	fb.termsCount[name]++
+	fb.total++
+}
+
 func (fb *DateTimeFacetBuilder) Result() *search.FacetResult {
 	rv := search.FacetResult{
 		Field:   fb.field,
@@ -109,6 +145,15 @@ func (fb *DateTimeFacetBuilder) Result() *search.FacetResult {
 		rv.DateRanges = append(rv.DateRanges, tf)
 	}
 
+	if fb.interval != nil {
+		// histograms keep all their buckets, in chronological order,
+		// the empty ones being added and the ones below the minimum
+		// document count dropped by FixupDateHistogram once the
+		// results of all the indexes are merged
+		sortDateHistogram(&rv, fb.location)
+		return &rv
+	}
+
 	sort.Sort(rv.DateRanges)
 
 	// we now have the list of the top N facets
@@ -10,7 +10,9 @@
 package facets
 
 import (
+	"math"
 	"sort"
+	"strconv"
 
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/numeric_util"
@@ -29,6 +31,10 @@ type NumericFacetBuilder struct {
 	total      int
 	missing    int
 	ranges     map[string]*numericRange
+
+	// histogram buckets are generated from the values instead of being
+	// added upfront
+	interval float64
 }
 
 func NewNumericFacetBuilder(field string, size int) *NumericFacetBuilder {
@@ -48,6 +54,18 @@ func (fb *NumericFacetBuilder) AddRange(name string, min, max *float64) {
 	fb.ranges[name] = &r
 }
 
+// SetHistogram makes the builder group the values in buckets of the
+// given width, aligned on multiples of it, instead of the ranges added
+// with AddRange. The empty buckets and the minimum document count are
+// left to FixupNumericHistogram.
+func (fb *NumericFacetBuilder) SetHistogram(interval float64) {
+	fb.interval = interval
+}
+
+func (fb *NumericFacetBuilder) Field() string {
+	return fb.field
+}
+
 func (fb *NumericFacetBuilder) Update(ft index.FieldTerms) {
 	terms, ok := ft[fb.field]
 	if ok {
@@ -60,6 +78,11 @@ func (fb *NumericFacetBuilder) Update(ft index.FieldTerms) {
 				if err == nil {
 					f64 := numeric_util.Int64ToFloat64(i64)
 
+					if fb.interval > 0 {
+						fb.updateHistogram(f64)
+						continue
+					}
+
 					// look at each of the ranges for a match
 					for rangeName, r := range fb.ranges {
 
@@ -82,6 +105,21 @@ func (fb *NumericFacetBuilder) Update(ft index.FieldTerms) {
 	}
 }
 
+func (fb *NumericFacetBuilder) updateHistogram(f64 float64) {
+	key := math.Floor(f64 / fb.interval)
+	min := key * fb.interval
+	name := strconv.FormatFloat(min, 'f', -1, 64)
+	if _, exists := fb.ranges[name]; !exists {
+		max := (key + 1) * fb.interval
+		fb.ranges[name] = &numericRange{
+			min: &min,
+			max: &max,
+		}
+	}
+	fb.termsCount[name]++
+	fb.total++
+}
+
 func (fb *NumericFacetBuilder) Result() *search.FacetResult {
 	rv := search.FacetResult{
 		Field:   fb.field,
@@ -103,6 +141,15 @@ func (fb *NumericFacetBuilder) Result() *search.FacetResult {
 		rv.NumericRanges = append(rv.NumericRanges, tf)
 	}
 
+	if fb.interval > 0 {
+		// histograms keep all their buckets, in increasing order, the
+		// empty ones being added and the ones below the minimum
+		// document count dropped by FixupNumericHistogram once the
+		// results of all the indexes are merged
+		sortNumericHistogram(&rv)
+		return &rv
+	}
+
 	sort.Sort(rv.NumericRanges)
 
 	// we now have the list of the top N facets
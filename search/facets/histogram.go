@@ -0,0 +1,298 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package facets
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/blevesearch/bleve/search"
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// MaxHistogramBuckets is the maximum number of buckets of a histogram
+// facet once the empty buckets are added.
+var MaxHistogramBuckets = 10000
+
+func errTooManyHistogramBuckets() error {
+	return fmt.Errorf("histogram exceeds %d buckets, use a wider interval or a min doc count", MaxHistogramBuckets)
+}
+
+// A DateTimeInterval is the width of the buckets of a date histogram.
+// Intervals of seconds, minutes and hours split each day, intervals of
+// days, weeks, months and years follow the calendar of the time zone
+// the histogram is built in.
+type DateTimeInterval struct {
+	seconds int64
+	days    int64
+	months  int64
+}
+
+var namedDateTimeIntervals = map[string]string{
+	"second":  "1s",
+	"minute":  "1m",
+	"hour":    "1h",
+	"day":     "1d",
+	"week":    "1w",
+	"month":   "1M",
+	"quarter": "1q",
+	"year":    "1y",
+}
+
+// ParseDateTimeInterval parses an interval made of a positive count
+// followed by one of the units s, m, h, d, w, M (month), q (quarter) or
+// y, like "1h" or "3M", or one of the names second, minute, hour, day,
+// week, month, quarter and year.
+func ParseDateTimeInterval(s string) (DateTimeInterval, error) {
+	if named, ok := namedDateTimeIntervals[s]; ok {
+		s = named
+	}
+	if len(s) < 2 {
+		return DateTimeInterval{}, fmt.Errorf("invalid date interval '%s'", s)
+	}
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || n <= 0 {
+		return DateTimeInterval{}, fmt.Errorf("invalid date interval '%s'", s)
+	}
+	var rv DateTimeInterval
+	switch s[len(s)-1] {
+	case 's':
+		rv.seconds = n
+	case 'm':
+		rv.seconds = n * 60
+	case 'h':
+		rv.seconds = n * 60 * 60
+	case 'd':
+		rv.days = n
+	case 'w':
+		rv.days = n * 7
+	case 'M':
+		rv.months = n
+	case 'q':
+		rv.months = n * 3
+	case 'y':
+		rv.months = n * 12
+	default:
+		return DateTimeInterval{}, fmt.Errorf("invalid date interval '%s'", s)
+	}
+	if rv.seconds > secondsPerDay {
+		return DateTimeInterval{}, fmt.Errorf("invalid date interval '%s', use days for intervals longer than a day", s)
+	}
+	return rv, nil
+}
+
+// Start returns the start of the bucket containing t, in the time zone
+// of t.
+func (i DateTimeInterval) Start(t time.Time) time.Time {
+	year, month, day := t.Date()
+	loc := t.Location()
+	switch {
+	case i.seconds > 0:
+		hour, min, sec := t.Clock()
+		secs := int64(hour*60*60 + min*60 + sec)
+		secs -= secs % i.seconds
+		return time.Date(year, month, day, 0, 0, int(secs), 0, loc)
+	case i.days > 0:
+		// count days from the monday before the unix epoch, so that weeks
+		// start on mondays
+		days := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()/secondsPerDay + 3
+		days = floorDiv(days, i.days)*i.days - 3
+		d := time.Unix(days*secondsPerDay, 0).UTC()
+		return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
+	default:
+		months := floorDiv(int64(year)*12+int64(month)-1, i.months) * i.months
+		return time.Date(int(floorDiv(months, 12)), time.Month(months-floorDiv(months, 12)*12+1), 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// Next returns the start of the bucket following the one starting at
+// start.
+func (i DateTimeInterval) Next(start time.Time) time.Time {
+	year, month, day := start.Date()
+	switch {
+	case i.seconds > 0:
+		return i.Start(start.Add(time.Duration(i.seconds) * time.Second))
+	case i.days > 0:
+		return time.Date(year, month, day+int(i.days), 0, 0, 0, 0, start.Location())
+	default:
+		return time.Date(year, month+time.Month(i.months), 1, 0, 0, 0, 0, start.Location())
+	}
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+type dateHistogramBucket struct {
+	start time.Time
+	facet *search.DateRangeFacet
+}
+
+type dateHistogramBuckets []*dateHistogramBucket
+
+func (b dateHistogramBuckets) Len() int           { return len(b) }
+func (b dateHistogramBuckets) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b dateHistogramBuckets) Less(i, j int) bool { return b[i].start.Before(b[j].start) }
+
+func newDateHistogramFacet(start, end time.Time, count int) *search.DateRangeFacet {
+	startString := start.Format(time.RFC3339Nano)
+	endString := end.Format(time.RFC3339Nano)
+	return &search.DateRangeFacet{
+		Name:  startString,
+		Start: &startString,
+		End:   &endString,
+		Count: count,
+	}
+}
+
+// FixupDateHistogram orders the buckets of a date histogram facet
+// chronologically. When minDocCount is 0, the empty buckets between the
+// first and the last ones are added, failing if there would be more
+// than MaxHistogramBuckets, otherwise the buckets with fewer than
+// minDocCount documents are dropped. It is used once the results of all
+// the indexes searched are merged.
+func FixupDateHistogram(fr *search.FacetResult, interval DateTimeInterval, location *time.Location, minDocCount int) error {
+	buckets := sortDateHistogram(fr, location)
+
+	rv := make(search.DateRangeFacets, 0, len(buckets))
+	if minDocCount == 0 && len(buckets) > 0 {
+		last := buckets[len(buckets)-1].start
+		for start := buckets[0].start; !start.After(last); {
+			if len(rv) >= MaxHistogramBuckets {
+				return errTooManyHistogramBuckets()
+			}
+			next := interval.Next(start)
+			if len(buckets) > 0 && buckets[0].start.Equal(start) {
+				rv = append(rv, buckets[0].facet)
+				buckets = buckets[1:]
+			} else {
+				rv = append(rv, newDateHistogramFacet(start, next, 0))
+			}
+			// keep any bucket which does not fall on the boundaries
+			for len(buckets) > 0 && buckets[0].start.Before(next) {
+				rv = append(rv, buckets[0].facet)
+				buckets = buckets[1:]
+			}
+			start = next
+		}
+	} else {
+		for _, bucket := range buckets {
+			if bucket.facet.Count >= minDocCount {
+				rv = append(rv, bucket.facet)
+			}
+		}
+	}
+	fr.DateRanges = rv
+	return nil
+}
+
+// sortDateHistogram orders the buckets of a date histogram facet
+// chronologically, dropping those without start.
+func sortDateHistogram(fr *search.FacetResult, location *time.Location) dateHistogramBuckets {
+	buckets := make(dateHistogramBuckets, 0, len(fr.DateRanges))
+	for _, dr := range fr.DateRanges {
+		if dr.Start == nil {
+			continue
+		}
+		start, err := time.Parse(time.RFC3339Nano, *dr.Start)
+		if err != nil {
+			continue
+		}
+		buckets = append(buckets, &dateHistogramBucket{start: start.In(location), facet: dr})
+	}
+	sort.Sort(buckets)
+	fr.DateRanges = make(search.DateRangeFacets, len(buckets))
+	for i, bucket := range buckets {
+		fr.DateRanges[i] = bucket.facet
+	}
+	return buckets
+}
+
+type numericHistogramBuckets []*search.NumericRangeFacet
+
+func (b numericHistogramBuckets) Len() int           { return len(b) }
+func (b numericHistogramBuckets) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b numericHistogramBuckets) Less(i, j int) bool { return *b[i].Min < *b[j].Min }
+
+// numericHistogramBucketKey recovers the key of a bucket from its lower
+// bound, which may carry rounding errors.
+func numericHistogramBucketKey(nr *search.NumericRangeFacet, interval float64) int64 {
+	return int64(math.Floor(*nr.Min/interval + 0.5))
+}
+
+func newNumericHistogramFacet(key int64, interval float64, count int) *search.NumericRangeFacet {
+	min := float64(key) * interval
+	max := float64(key+1) * interval
+	return &search.NumericRangeFacet{
+		Name:  strconv.FormatFloat(min, 'f', -1, 64),
+		Min:   &min,
+		Max:   &max,
+		Count: count,
+	}
+}
+
+// FixupNumericHistogram orders the buckets of a numeric histogram facet
+// by increasing values. When minDocCount is 0, the empty buckets between
+// the first and the last ones are added, failing if there would be more
+// than MaxHistogramBuckets, otherwise the buckets with fewer than
+// minDocCount documents are dropped.
+func FixupNumericHistogram(fr *search.FacetResult, interval float64, minDocCount int) error {
+	buckets := sortNumericHistogram(fr)
+
+	rv := make(search.NumericRangeFacets, 0, len(buckets))
+	if minDocCount == 0 && len(buckets) > 0 {
+		first := numericHistogramBucketKey(buckets[0], interval)
+		last := numericHistogramBucketKey(buckets[len(buckets)-1], interval)
+		if float64(last)-float64(first) >= float64(MaxHistogramBuckets) {
+			return errTooManyHistogramBuckets()
+		}
+		for key := first; key <= last; key++ {
+			found := false
+			for len(buckets) > 0 && numericHistogramBucketKey(buckets[0], interval) <= key {
+				rv = append(rv, buckets[0])
+				buckets = buckets[1:]
+				found = true
+			}
+			if !found {
+				rv = append(rv, newNumericHistogramFacet(key, interval, 0))
+			}
+		}
+	} else {
+		for _, bucket := range buckets {
+			if bucket.Count >= minDocCount {
+				rv = append(rv, bucket)
+			}
+		}
+	}
+	fr.NumericRanges = rv
+	return nil
+}
+
+// sortNumericHistogram orders the buckets of a numeric histogram facet
+// by increasing values, dropping those without lower bound.
+func sortNumericHistogram(fr *search.FacetResult) numericHistogramBuckets {
+	buckets := make(numericHistogramBuckets, 0, len(fr.NumericRanges))
+	for _, nr := range fr.NumericRanges {
+		if nr.Min != nil {
+			buckets = append(buckets, nr)
+		}
+	}
+	sort.Sort(buckets)
+	fr.NumericRanges = search.NumericRangeFacets(buckets)
+	return buckets
+}
@@ -0,0 +1,286 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package facets
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/numeric_util"
+	"github.com/blevesearch/bleve/search"
+)
+
+func TestDateTimeInterval(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		interval string
+		t        time.Time
+		start    time.Time
+		next     time.Time
+	}{
+		{
+			interval: "1h",
+			t:        time.Date(2016, 3, 4, 10, 35, 12, 5, time.UTC),
+			start:    time.Date(2016, 3, 4, 10, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 3, 4, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			interval: "15m",
+			t:        time.Date(2016, 3, 4, 10, 35, 12, 5, time.UTC),
+			start:    time.Date(2016, 3, 4, 10, 30, 0, 0, time.UTC),
+			next:     time.Date(2016, 3, 4, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			interval: "7h",
+			t:        time.Date(2016, 3, 4, 22, 0, 0, 0, time.UTC),
+			start:    time.Date(2016, 3, 4, 21, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 3, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			interval: "day",
+			t:        time.Date(2016, 3, 4, 23, 59, 59, 0, paris),
+			start:    time.Date(2016, 3, 4, 0, 0, 0, 0, paris),
+			next:     time.Date(2016, 3, 5, 0, 0, 0, 0, paris),
+		},
+		{
+			// the day of the switch to summer time lasts 23 hours
+			interval: "1d",
+			t:        time.Date(2016, 3, 27, 12, 0, 0, 0, paris),
+			start:    time.Date(2016, 3, 27, 0, 0, 0, 0, paris),
+			next:     time.Date(2016, 3, 28, 0, 0, 0, 0, paris),
+		},
+		{
+			// 2016-03-04 is a friday
+			interval: "1w",
+			t:        time.Date(2016, 3, 4, 10, 0, 0, 0, time.UTC),
+			start:    time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 3, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			interval: "1w",
+			t:        time.Date(1960, 1, 1, 10, 0, 0, 0, time.UTC),
+			start:    time.Date(1959, 12, 28, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(1960, 1, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			interval: "month",
+			t:        time.Date(2016, 1, 31, 10, 0, 0, 0, paris),
+			start:    time.Date(2016, 1, 1, 0, 0, 0, 0, paris),
+			next:     time.Date(2016, 2, 1, 0, 0, 0, 0, paris),
+		},
+		{
+			interval: "quarter",
+			t:        time.Date(2016, 6, 30, 10, 0, 0, 0, time.UTC),
+			start:    time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			interval: "year",
+			t:        time.Date(2016, 6, 30, 10, 0, 0, 0, time.UTC),
+			start:    time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		interval, err := ParseDateTimeInterval(test.interval)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := interval.Start(test.t)
+		if !start.Equal(test.start) {
+			t.Errorf("expected %s bucket of %v to start at %v, got %v", test.interval, test.t, test.start, start)
+		}
+		next := interval.Next(start)
+		if !next.Equal(test.next) {
+			t.Errorf("expected %s bucket after %v to start at %v, got %v", test.interval, start, test.next, next)
+		}
+	}
+
+	for _, invalid := range []string{"", "h", "0d", "-1d", "1x", "25h", "fortnight"} {
+		_, err := ParseDateTimeInterval(invalid)
+		if err == nil {
+			t.Errorf("expected error parsing interval '%s'", invalid)
+		}
+	}
+}
+
+func dateTerm(t time.Time) string {
+	return string(numeric_util.MustNewPrefixCodedInt64(t.UnixNano(), 0))
+}
+
+func TestDateTimeFacetBuilderHistogram(t *testing.T) {
+	interval, err := ParseDateTimeInterval("1d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fb := NewDateTimeFacetBuilder("date", 0)
+	fb.SetHistogram(interval, time.UTC)
+	fb.Update(index.FieldTerms{"date": []string{dateTerm(time.Date(2016, 1, 3, 10, 0, 0, 0, time.UTC))}})
+	fb.Update(index.FieldTerms{"date": []string{dateTerm(time.Date(2016, 1, 1, 10, 0, 0, 0, time.UTC))}})
+	fb.Update(index.FieldTerms{"date": []string{dateTerm(time.Date(2016, 1, 1, 23, 0, 0, 0, time.UTC))}})
+	fb.Update(index.FieldTerms{"other": []string{"x"}})
+
+	res := fb.Result()
+	err = FixupDateHistogram(res, interval, time.UTC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 3 || res.Missing != 1 || res.Other != 0 {
+		t.Errorf("unexpected totals %d/%d/%d", res.Total, res.Missing, res.Other)
+	}
+	var names []string
+	var counts []int
+	for _, dr := range res.DateRanges {
+		names = append(names, dr.Name)
+		counts = append(counts, dr.Count)
+	}
+	expectedNames := []string{"2016-01-01T00:00:00Z", "2016-01-02T00:00:00Z", "2016-01-03T00:00:00Z"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("expected buckets %v, got %v", expectedNames, names)
+	}
+	if !reflect.DeepEqual(counts, []int{2, 0, 1}) {
+		t.Errorf("expected counts [2 0 1], got %v", counts)
+	}
+	if *res.DateRanges[1].End != "2016-01-03T00:00:00Z" {
+		t.Errorf("unexpected end %s", *res.DateRanges[1].End)
+	}
+
+	// buckets are computed in the requested time zone
+	tz := time.FixedZone("UTC-5", -5*60*60)
+	fb = NewDateTimeFacetBuilder("date", 0)
+	fb.SetHistogram(interval, tz)
+	fb.Update(index.FieldTerms{"date": []string{dateTerm(time.Date(2016, 1, 3, 10, 0, 0, 0, time.UTC))}})
+	fb.Update(index.FieldTerms{"date": []string{dateTerm(time.Date(2016, 1, 1, 10, 0, 0, 0, time.UTC))}})
+	fb.Update(index.FieldTerms{"date": []string{dateTerm(time.Date(2016, 1, 1, 23, 0, 0, 0, time.UTC))}})
+	res = fb.Result()
+	// buckets below the minimum document count are only dropped by
+	// the fixup, once the results of all the indexes are merged
+	if len(res.DateRanges) != 2 {
+		t.Errorf("expected 2 buckets before the fixup, got %v", res.DateRanges)
+	}
+	err = FixupDateHistogram(res, interval, tz, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.DateRanges) != 1 || res.DateRanges[0].Name != "2016-01-01T00:00:00-05:00" || res.DateRanges[0].Count != 2 {
+		t.Errorf("unexpected buckets %v", res.DateRanges)
+	}
+}
+
+func TestNumericFacetBuilderHistogram(t *testing.T) {
+	fb := NewNumericFacetBuilder("num", 0)
+	fb.SetHistogram(10)
+	for _, f := range []float64{-3, 1, 9.5, 35} {
+		fb.Update(index.FieldTerms{"num": []string{string(numeric_util.MustNewPrefixCodedInt64(numeric_util.Float64ToInt64(f), 0))}})
+	}
+
+	res := fb.Result()
+	if len(res.NumericRanges) != 3 {
+		t.Errorf("expected the empty buckets to be left to the fixup, got %v", res.NumericRanges)
+	}
+	err := FixupNumericHistogram(res, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	var counts []int
+	for _, nr := range res.NumericRanges {
+		names = append(names, nr.Name)
+		counts = append(counts, nr.Count)
+	}
+	expectedNames := []string{"-10", "0", "10", "20", "30"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("expected buckets %v, got %v", expectedNames, names)
+	}
+	if !reflect.DeepEqual(counts, []int{1, 2, 0, 0, 1}) {
+		t.Errorf("expected counts [1 2 0 0 1], got %v", counts)
+	}
+	if *res.NumericRanges[2].Min != 10 || *res.NumericRanges[2].Max != 20 {
+		t.Errorf("unexpected bounds of empty bucket %v-%v", *res.NumericRanges[2].Min, *res.NumericRanges[2].Max)
+	}
+
+	// merging results of several indexes
+	other := NewNumericFacetBuilder("num", 0)
+	other.SetHistogram(10)
+	other.Update(index.FieldTerms{"num": []string{string(numeric_util.MustNewPrefixCodedInt64(numeric_util.Float64ToInt64(55), 0))}})
+	other.Update(index.FieldTerms{"num": []string{string(numeric_util.MustNewPrefixCodedInt64(numeric_util.Float64ToInt64(5), 0))}})
+	res.Merge(other.Result())
+	err = FixupNumericHistogram(res, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names = nil
+	counts = nil
+	for _, nr := range res.NumericRanges {
+		names = append(names, nr.Name)
+		counts = append(counts, nr.Count)
+	}
+	expectedNames = []string{"-10", "0", "30", "50"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("expected merged buckets %v, got %v", expectedNames, names)
+	}
+	if !reflect.DeepEqual(counts, []int{1, 3, 1, 1}) {
+		t.Errorf("expected merged counts [1 3 1 1], got %v", counts)
+	}
+}
+
+func TestHistogramMaxBuckets(t *testing.T) {
+	defer func(max int) {
+		MaxHistogramBuckets = max
+	}(MaxHistogramBuckets)
+	MaxHistogramBuckets = 5
+
+	numericHistogram := func(values ...float64) *search.FacetResult {
+		fb := NewNumericFacetBuilder("num", 0)
+		fb.SetHistogram(1)
+		for _, f := range values {
+			fb.Update(index.FieldTerms{"num": []string{string(numeric_util.MustNewPrefixCodedInt64(numeric_util.Float64ToInt64(f), 0))}})
+		}
+		return fb.Result()
+	}
+	err := FixupNumericHistogram(numericHistogram(0, 4), 1, 0)
+	if err != nil {
+		t.Errorf("expected 5 buckets to be allowed, got %v", err)
+	}
+	err = FixupNumericHistogram(numericHistogram(0, 5), 1, 0)
+	if err == nil {
+		t.Errorf("expected error for 6 buckets")
+	}
+	err = FixupNumericHistogram(numericHistogram(0, 1e15), 1, 0)
+	if err == nil {
+		t.Errorf("expected error for 1e15 buckets")
+	}
+	// without empty buckets the limit does not apply
+	err = FixupNumericHistogram(numericHistogram(0, 1e15), 1, 1)
+	if err != nil {
+		t.Errorf("expected no error with a min doc count, got %v", err)
+	}
+
+	interval, err := ParseDateTimeInterval("1s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fb := NewDateTimeFacetBuilder("date", 0)
+	fb.SetHistogram(interval, time.UTC)
+	fb.Update(index.FieldTerms{"date": []string{dateTerm(time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC))}})
+	fb.Update(index.FieldTerms{"date": []string{dateTerm(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC))}})
+	err = FixupDateHistogram(fb.Result(), interval, time.UTC, 0)
+	if err == nil {
+		t.Errorf("expected error for a date histogram of 46 years of seconds")
+	}
+}
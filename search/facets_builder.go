@@ -16,6 +16,8 @@ import (
 )
 
 type FacetBuilder interface {
+	// Field returns the field whose terms the facet is built from.
+	Field() string
 	Update(index.FieldTerms)
 	Result() *FacetResult
 }
@@ -23,6 +25,7 @@ type FacetBuilder interface {
 type FacetsBuilder struct {
 	indexReader index.IndexReader
 	facets      map[string]FacetBuilder
+	fields      []string
 }
 
 func NewFacetsBuilder(indexReader index.IndexReader) *FacetsBuilder {
@@ -34,10 +37,11 @@ func NewFacetsBuilder(indexReader index.IndexReader) *FacetsBuilder {
 
 func (fb *FacetsBuilder) Add(name string, facetBuilder FacetBuilder) {
 	fb.facets[name] = facetBuilder
+	fb.fields = append(fb.fields, facetBuilder.Field())
 }
 
 func (fb *FacetsBuilder) Update(docMatch *DocumentMatch) error {
-	fieldTerms, err := fb.indexReader.DocumentFieldTerms(docMatch.ID)
+	fieldTerms, err := fb.indexReader.DocumentFieldTermsForFields(docMatch.ID, fb.fields)
 	if err != nil {
 		return err
 	}
@@ -86,7 +90,7 @@ type NumericRangeFacets []*NumericRangeFacet
 
 func (nrf NumericRangeFacets) Add(numericRangeFacet *NumericRangeFacet) NumericRangeFacets {
 	for _, existingNr := range nrf {
-		if numericRangeFacet.Min == existingNr.Min && numericRangeFacet.Max == existingNr.Max {
+		if sameFloat64(numericRangeFacet.Min, existingNr.Min) && sameFloat64(numericRangeFacet.Max, existingNr.Max) {
 			existingNr.Count += numericRangeFacet.Count
 			return nrf
 		}
@@ -96,6 +100,13 @@ func (nrf NumericRangeFacets) Add(numericRangeFacet *NumericRangeFacet) NumericR
 	return nrf
 }
 
+func sameFloat64(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 func (nrf NumericRangeFacets) Len() int      { return len(nrf) }
 func (nrf NumericRangeFacets) Swap(i, j int) { nrf[i], nrf[j] = nrf[j], nrf[i] }
 func (nrf NumericRangeFacets) Less(i, j int) bool {
@@ -29,6 +29,19 @@ func NewFragmentFormatter(color string) *FragmentFormatter {
 }
 
 func (a *FragmentFormatter) Format(f *highlight.Fragment, orderedTermLocations highlight.TermLocations) string {
+	return a.FormatWithTags(f, orderedTermLocations, a.color, Reset)
+}
+
+// FormatWithTags works like Format, surrounding terms with
+// the before and after tags, an empty tag keeping the
+// configured one.
+func (a *FragmentFormatter) FormatWithTags(f *highlight.Fragment, orderedTermLocations highlight.TermLocations, before, after string) string {
+	if before == "" {
+		before = a.color
+	}
+	if after == "" {
+		after = Reset
+	}
 	rv := ""
 	curr := f.Start
 	for _, termLocation := range orderedTermLocations {
@@ -48,11 +61,11 @@ func (a *FragmentFormatter) Format(f *highlight.Fragment, orderedTermLocations h
 		// add the stuff before this location
 		rv += string(f.Orig[curr:termLocation.Start])
 		// add the color
-		rv += a.color
+		rv += before
 		// add the term itself
 		rv += string(f.Orig[termLocation.Start:termLocation.End])
 		// reset the color
-		rv += Reset
+		rv += after
 		// update current
 		curr = termLocation.End
 	}
@@ -32,6 +32,19 @@ func NewFragmentFormatter(before, after string) *FragmentFormatter {
 }
 
 func (a *FragmentFormatter) Format(f *highlight.Fragment, orderedTermLocations highlight.TermLocations) string {
+	return a.FormatWithTags(f, orderedTermLocations, a.before, a.after)
+}
+
+// FormatWithTags works like Format, surrounding terms with
+// the before and after tags, an empty tag keeping the
+// configured one.
+func (a *FragmentFormatter) FormatWithTags(f *highlight.Fragment, orderedTermLocations highlight.TermLocations, before, after string) string {
+	if before == "" {
+		before = a.before
+	}
+	if after == "" {
+		after = a.after
+	}
 	rv := ""
 	curr := f.Start
 	for _, termLocation := range orderedTermLocations {
@@ -51,11 +64,11 @@ func (a *FragmentFormatter) Format(f *highlight.Fragment, orderedTermLocations h
 		// add the stuff before this location
 		rv += string(f.Orig[curr:termLocation.Start])
 		// add the color
-		rv += a.before
+		rv += before
 		// add the term itself
 		rv += string(f.Orig[termLocation.Start:termLocation.End])
 		// reset the color
-		rv += a.after
+		rv += after
 		// update current
 		curr = termLocation.End
 	}
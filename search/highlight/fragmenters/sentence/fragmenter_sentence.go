@@ -0,0 +1,350 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package sentence provides a fragmenter producing fragments
+// which start and end at sentence boundaries whenever the
+// sentences fit within the fragment size, and at word
+// boundaries otherwise.
+package sentence
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blevesearch/segment"
+
+	"github.com/blevesearch/bleve/registry"
+	"github.com/blevesearch/bleve/search/highlight"
+)
+
+const Name = "sentence"
+
+const defaultFragmentSize = 200
+
+type Fragmenter struct {
+	fragmentSize int
+}
+
+func NewFragmenter(fragmentSize int) *Fragmenter {
+	return &Fragmenter{
+		fragmentSize: fragmentSize,
+	}
+}
+
+func (s *Fragmenter) Fragment(orig []byte, ot highlight.TermLocations) []*highlight.Fragment {
+	return s.FragmentSized(orig, ot, s.fragmentSize)
+}
+
+// FragmentSized works like Fragment, producing fragments of
+// at most fragmentSize characters.
+func (s *Fragmenter) FragmentSized(orig []byte, ot highlight.TermLocations, fragmentSize int) []*highlight.Fragment {
+	rv := make([]*highlight.Fragment, 0)
+
+	words := wordSpans(orig)
+	sentences := sentenceSpans(orig)
+	if len(sentences) == 0 {
+		return rv
+	}
+
+	if len(ot) == 0 {
+		// if there were no terms to highlight
+		// produce a single fragment from the beginning
+		start, end := s.fragment(orig, words, sentences, span{start: 0, end: 0}, 0, fragmentSize)
+		return append(rv, &highlight.Fragment{Orig: orig, Start: start, End: end})
+	}
+
+	maxbegin := 0
+	var last *highlight.Fragment
+	for _, termLocation := range ot {
+		term := span{start: termLocation.Start, end: termLocation.End}
+		if term.start < 0 || term.end > len(orig) || term.start > term.end {
+			continue
+		}
+		// terms within the previous fragment are already covered
+		if last != nil && term.start >= last.Start && term.end <= last.End {
+			continue
+		}
+		start, end := s.fragment(orig, words, sentences, term, maxbegin, fragmentSize)
+		last = &highlight.Fragment{Orig: orig, Start: start, End: end}
+		rv = append(rv, last)
+		// the next fragment should not back up to include this one
+		maxbegin = end
+	}
+
+	return rv
+}
+
+// fragment returns the offsets of the fragment holding the
+// sentences around term, or the words around it if its
+// sentence does not fit within size characters.
+func (s *Fragmenter) fragment(orig []byte, words, sentences []span, term span, maxbegin, size int) (int, int) {
+	first, last := covering(sentences, term)
+	if first >= 0 && runeCount(orig, sentences[first].start, sentences[last].end) <= size {
+		first, last = expand(orig, sentences, first, last, maxbegin, size)
+		return sentences[first].start, sentences[last].end
+	}
+
+	// the sentence is too long, cut it at word boundaries
+	lo, hi := term.start, term.end
+	if first >= 0 {
+		lo, hi = sentences[first].start, sentences[last].end
+	}
+	within := make([]span, 0)
+	for _, word := range words {
+		if word.start >= lo && word.end <= hi {
+			within = append(within, word)
+		}
+	}
+	first, last = covering(within, term)
+	if first < 0 {
+		return term.start, term.end
+	}
+	first, last = expand(orig, within, first, last, maxbegin, size)
+	return within[first].start, within[last].end
+}
+
+// span is a range of byte offsets.
+type span struct {
+	start int
+	end   int
+}
+
+// covering returns the indexes of the first and last
+// spans overlapping term, or of the span starting it if
+// term is empty. It returns -1 if there are none.
+func covering(spans []span, term span) (int, int) {
+	first, last := -1, -1
+	for i, sp := range spans {
+		overlaps := sp.start < term.end && term.start < sp.end
+		if term.start == term.end {
+			overlaps = term.start >= sp.start && term.start < sp.end
+		}
+		if overlaps {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 && term.start == term.end {
+		// an empty term before the first span
+		for i, sp := range spans {
+			if sp.start >= term.start {
+				return i, i
+			}
+		}
+	}
+	return first, last
+}
+
+// expand grows the range of spans from first to last,
+// alternately adding the following and preceding span, as
+// long as the range fits within size characters. Spans
+// starting before maxbegin are not added.
+func expand(orig []byte, spans []span, first, last, maxbegin, size int) (int, int) {
+	for {
+		grown := false
+		if last+1 < len(spans) && runeCount(orig, spans[first].start, spans[last+1].end) <= size {
+			last++
+			grown = true
+		}
+		if first > 0 && spans[first-1].start >= maxbegin && runeCount(orig, spans[first-1].start, spans[last].end) <= size {
+			first--
+			grown = true
+		}
+		if !grown {
+			return first, last
+		}
+	}
+}
+
+func runeCount(orig []byte, start, end int) int {
+	return utf8.RuneCount(orig[start:end])
+}
+
+// wordSpan is a segment produced by the word segmenter,
+// word is false for spaces and punctuation.
+type wordSpan struct {
+	span
+	word bool
+}
+
+// wordSpans returns the spans of the words of orig,
+// ignoring spaces but keeping punctuation.
+func wordSpans(orig []byte) []span {
+	rv := make([]span, 0)
+	for _, ws := range segments(orig) {
+		if !isSpace(orig[ws.start:ws.end]) {
+			rv = append(rv, ws.span)
+		}
+	}
+	return rv
+}
+
+func segments(orig []byte) []wordSpan {
+	rv := make([]wordSpan, 0)
+	segmenter := segment.NewWordSegmenterDirect(orig)
+	start := 0
+	for segmenter.Segment() {
+		end := start + len(segmenter.Bytes())
+		rv = append(rv, wordSpan{
+			span: span{start: start, end: end},
+			word: segmenter.Type() != segment.None,
+		})
+		start = end
+	}
+	return rv
+}
+
+// sentenceSpans returns the spans of the sentences of orig,
+// without surrounding spaces. A sentence ends after terminal
+// punctuation and any closing punctuation following it. When
+// that punctuation is a full stop, the sentence ends only if a
+// space follows and the next word does not start with a lower
+// case letter. A sentence also ends at line and paragraph
+// separators.
+func sentenceSpans(orig []byte) []span {
+	rv := make([]span, 0)
+	segs := segments(orig)
+	start := 0
+	for i := 0; i < len(segs); i++ {
+		seg := orig[segs[i].start:segs[i].end]
+		if isSeparator(seg) {
+			rv = appendSentence(rv, orig, start, segs[i].end)
+			start = segs[i].end
+			continue
+		}
+		if segs[i].word || !isTerminal(seg) {
+			continue
+		}
+
+		// consume further terminal and closing punctuation
+		fullStop := isFullStop(seg)
+		j := i + 1
+		for ; j < len(segs); j++ {
+			next := orig[segs[j].start:segs[j].end]
+			if segs[j].word || !(isTerminal(next) || isClosing(next)) {
+				break
+			}
+			if isTerminal(next) && !isFullStop(next) {
+				fullStop = false
+			}
+		}
+		i = j - 1
+		if fullStop {
+			// a full stop not followed by a space is part of
+			// an abbreviation or a number
+			if j < len(segs) && !isSpace(orig[segs[j].start:segs[j].end]) && !isSeparator(orig[segs[j].start:segs[j].end]) {
+				continue
+			}
+			k := j
+			for k < len(segs) && isSpace(orig[segs[k].start:segs[k].end]) {
+				k++
+			}
+			if k < len(segs) {
+				r, _ := utf8.DecodeRune(orig[segs[k].start:segs[k].end])
+				if unicode.IsLower(r) {
+					continue
+				}
+			}
+		}
+		rv = appendSentence(rv, orig, start, segs[j-1].end)
+		start = segs[j-1].end
+	}
+	return appendSentence(rv, orig, start, len(orig))
+}
+
+// appendSentence appends the span from start to end, trimmed
+// of spaces, unless it is empty.
+func appendSentence(sentences []span, orig []byte, start, end int) []span {
+	for start < end {
+		r, size := utf8.DecodeRune(orig[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		start += size
+	}
+	for end > start {
+		r, size := utf8.DecodeLastRune(orig[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end -= size
+	}
+	if start == end {
+		return sentences
+	}
+	return append(sentences, span{start: start, end: end})
+}
+
+func isSpace(b []byte) bool {
+	for _, r := range string(b) {
+		if !unicode.IsSpace(r) || isSeparatorRune(r) {
+			return false
+		}
+	}
+	return len(b) > 0
+}
+
+func isSeparator(b []byte) bool {
+	for _, r := range string(b) {
+		if isSeparatorRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSeparatorRune(r rune) bool {
+	switch r {
+	case '\n', '\r', '\u0085', '\u2028', '\u2029':
+		return true
+	}
+	return false
+}
+
+func isTerminal(b []byte) bool {
+	for _, r := range string(b) {
+		if !unicode.Is(unicode.STerm, r) {
+			return false
+		}
+	}
+	return len(b) > 0
+}
+
+func isFullStop(b []byte) bool {
+	for _, r := range string(b) {
+		if r != '.' {
+			return false
+		}
+	}
+	return len(b) > 0
+}
+
+func isClosing(b []byte) bool {
+	for _, r := range string(b) {
+		if !unicode.In(r, unicode.Pe, unicode.Pf, unicode.Quotation_Mark) {
+			return false
+		}
+	}
+	return len(b) > 0
+}
+
+func Constructor(config map[string]interface{}, cache *registry.Cache) (highlight.Fragmenter, error) {
+	size := defaultFragmentSize
+	sizeVal, ok := config["size"].(float64)
+	if ok {
+		size = int(sizeVal)
+	}
+	return NewFragmenter(size), nil
+}
+
+func init() {
+	registry.RegisterFragmenter(Name, Constructor)
+}
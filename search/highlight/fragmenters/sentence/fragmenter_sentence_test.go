@@ -0,0 +1,136 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package sentence
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve/search/highlight"
+)
+
+func TestSentenceSpans(t *testing.T) {
+	tests := []struct {
+		input     string
+		sentences []string
+	}{
+		{
+			input:     "",
+			sentences: []string{},
+		},
+		{
+			input:     "no terminal punctuation",
+			sentences: []string{"no terminal punctuation"},
+		},
+		{
+			input:     "The fox jumped. The dog slept!  Did it? Yes.",
+			sentences: []string{"The fox jumped.", "The dog slept!", "Did it?", "Yes."},
+		},
+		{
+			input:     `He said "stop." Then he left...  It was 3.14 p.m. on a Monday.`,
+			sentences: []string{`He said "stop."`, "Then he left...", "It was 3.14 p.m. on a Monday."},
+		},
+		{
+			input:     "Is it (really?) true?Maybe",
+			sentences: []string{"Is it (really?)", "true?", "Maybe"},
+		},
+		{
+			input:     "a heading\nfollowed by text",
+			sentences: []string{"a heading", "followed by text"},
+		},
+		{
+			input:     "日本語の文です。二つ目の文！",
+			sentences: []string{"日本語の文です。", "二つ目の文！"},
+		},
+	}
+
+	for _, test := range tests {
+		orig := []byte(test.input)
+		sentences := []string{}
+		for _, sp := range sentenceSpans(orig) {
+			sentences = append(sentences, string(orig[sp.start:sp.end]))
+		}
+		if !reflect.DeepEqual(sentences, test.sentences) {
+			t.Errorf("expected sentences %q for %q, got %q", test.sentences, test.input, sentences)
+		}
+	}
+}
+
+func TestSentenceFragmenter(t *testing.T) {
+	orig := []byte("Bleve is a search library. It is written in Go. The quick brown fox jumps over the lazy dog near the river bank. Foxes are quick.")
+	location := func(term string, from int) *highlight.TermLocation {
+		start := from + bytes.Index(orig[from:], []byte(term))
+		return &highlight.TermLocation{Term: term, Start: start, End: start + len(term)}
+	}
+	fox := location("fox", 0)
+	quick := location("quick", fox.End)
+
+	tests := []struct {
+		size      int
+		ot        highlight.TermLocations
+		fragments []string
+	}{
+		{
+			// the sentence of the term and its neighbours
+			size:      100,
+			ot:        highlight.TermLocations{fox},
+			fragments: []string{"The quick brown fox jumps over the lazy dog near the river bank. Foxes are quick."},
+		},
+		{
+			size:      200,
+			ot:        highlight.TermLocations{fox},
+			fragments: []string{string(orig)},
+		},
+		{
+			// a sentence too long is cut at word boundaries
+			size:      20,
+			ot:        highlight.TermLocations{fox},
+			fragments: []string{"brown fox jumps over"},
+		},
+		{
+			// the second fragment does not back up over the first
+			size:      70,
+			ot:        highlight.TermLocations{fox, quick},
+			fragments: []string{"The quick brown fox jumps over the lazy dog near the river bank.", "Foxes are quick."},
+		},
+		{
+			// terms within the previous fragment are skipped
+			size:      200,
+			ot:        highlight.TermLocations{fox, quick},
+			fragments: []string{string(orig)},
+		},
+		{
+			// without terms, the fragment starts at the beginning
+			size:      50,
+			ot:        nil,
+			fragments: []string{"Bleve is a search library. It is written in Go."},
+		},
+		{
+			size:      10,
+			ot:        nil,
+			fragments: []string{"Bleve is a"},
+		},
+	}
+
+	for _, test := range tests {
+		fragmenter := NewFragmenter(test.size)
+		fragments := []string{}
+		for _, fragment := range fragmenter.Fragment(orig, test.ot) {
+			if !bytes.Equal(fragment.Orig, orig) {
+				t.Errorf("expected fragment of the original text")
+			}
+			fragments = append(fragments, string(orig[fragment.Start:fragment.End]))
+		}
+		if !reflect.DeepEqual(fragments, test.fragments) {
+			t.Errorf("expected fragments %q with size %d, got %q", test.fragments, test.size, fragments)
+		}
+	}
+}
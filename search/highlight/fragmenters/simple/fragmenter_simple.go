@@ -31,6 +31,12 @@ func NewFragmenter(fragmentSize int) *Fragmenter {
 }
 
 func (s *Fragmenter) Fragment(orig []byte, ot highlight.TermLocations) []*highlight.Fragment {
+	return s.FragmentSized(orig, ot, s.fragmentSize)
+}
+
+// FragmentSized works like Fragment, producing fragments of
+// fragmentSize characters.
+func (s *Fragmenter) FragmentSized(orig []byte, ot highlight.TermLocations, fragmentSize int) []*highlight.Fragment {
 	rv := make([]*highlight.Fragment, 0)
 
 	maxbegin := 0
@@ -41,7 +47,7 @@ OUTER:
 		start := termLocation.Start
 		end := start
 		used := 0
-		for end < len(orig) && used < s.fragmentSize {
+		for end < len(orig) && used < fragmentSize {
 			r, size := utf8.DecodeRune(orig[end:])
 			if r == utf8.RuneError {
 				continue OUTER // bail
@@ -53,7 +59,7 @@ OUTER:
 		// if we still have more characters available to us
 		// push back towards beginning
 		// without cross maxbegin
-		for start > 0 && used < s.fragmentSize {
+		for start > 0 && used < fragmentSize {
 			r, size := utf8.DecodeLastRune(orig[0:start])
 			if r == utf8.RuneError {
 				continue OUTER // bail
@@ -113,12 +119,12 @@ OUTER:
 	if len(ot) == 0 {
 		// if there were no terms to highlight
 		// produce a single fragment from the beginning
-		start := 0
-		end := start + s.fragmentSize
-		if end > len(orig) {
-			end = len(orig)
+		end := 0
+		for used := 0; end < len(orig) && used < fragmentSize; used++ {
+			_, runeSize := utf8.DecodeRune(orig[end:])
+			end += runeSize
 		}
-		rv = append(rv, &highlight.Fragment{Orig: orig, Start: start, End: end})
+		rv = append(rv, &highlight.Fragment{Orig: orig, Start: 0, End: end})
 	}
 
 	return rv
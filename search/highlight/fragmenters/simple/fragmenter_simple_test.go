@@ -293,3 +293,43 @@ func TestSimpleFragmenterWithSize(t *testing.T) {
 		}
 	}
 }
+
+func TestSimpleFragmenterSized(t *testing.T) {
+	orig := []byte("this is a test")
+	ot := highlight.TermLocations{
+		&highlight.TermLocation{
+			Term:  "test",
+			Pos:   4,
+			Start: 10,
+			End:   14,
+		},
+	}
+
+	fragmenter := NewFragmenter(200)
+	fragments := fragmenter.FragmentSized(orig, ot, 6)
+	expected := []*highlight.Fragment{
+		{
+			Orig:  orig,
+			Start: 8,
+			End:   14,
+		},
+	}
+	if !reflect.DeepEqual(fragments, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fragments)
+	}
+
+	// without terms, the fragment starts at the beginning
+	// and counts characters rather than bytes
+	orig = []byte("ünïcödé text")
+	fragments = fragmenter.FragmentSized(orig, nil, 7)
+	expected = []*highlight.Fragment{
+		{
+			Orig:  orig,
+			Start: 0,
+			End:   len("ünïcödé"),
+		},
+	}
+	if !reflect.DeepEqual(fragments, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fragments)
+	}
+}
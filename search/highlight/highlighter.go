@@ -36,10 +36,27 @@ type Fragmenter interface {
 	Fragment([]byte, TermLocations) []*Fragment
 }
 
+// SizedFragmenter is implemented by fragmenters able to
+// produce fragments of about size characters, rather than
+// of their configured size.
+type SizedFragmenter interface {
+	Fragmenter
+	FragmentSized(orig []byte, ot TermLocations, size int) []*Fragment
+}
+
 type FragmentFormatter interface {
 	Format(f *Fragment, orderedTermLocations TermLocations) string
 }
 
+// TagFragmentFormatter is implemented by fragment formatters
+// able to surround terms with the before and after tags, rather
+// than with their configured ones. An empty tag keeps the
+// configured one.
+type TagFragmentFormatter interface {
+	FragmentFormatter
+	FormatWithTags(f *Fragment, orderedTermLocations TermLocations, before, after string) string
+}
+
 type FragmentScorer interface {
 	Score(f *Fragment) float64
 }
@@ -57,3 +74,41 @@ type Highlighter interface {
 	BestFragmentInField(*search.DocumentMatch, *document.Document, string) string
 	BestFragmentsInField(*search.DocumentMatch, *document.Document, string, int) []string
 }
+
+// OptionsHighlighter is implemented by highlighters able to
+// customize the fragments they return with FragmentOptions.
+type OptionsHighlighter interface {
+	Highlighter
+	BestFragmentsInFieldWithOptions(*search.DocumentMatch, *document.Document, string, *FragmentOptions) []string
+}
+
+// Fragment orders of FragmentOptions.
+const (
+	// best scoring fragments first
+	OrderScore = "score"
+	// fragments in the order they appear in the document
+	OrderDocument = "document"
+)
+
+// FragmentOptions customize the fragments returned by
+// BestFragmentsInFieldWithOptions. Zero values keep the
+// defaults of the highlighter.
+type FragmentOptions struct {
+	// Count is the maximum number of fragments, 1 by default.
+	Count int
+	// Size is the size of the fragments in characters, used
+	// if the fragmenter is a SizedFragmenter.
+	Size int
+	// Before and After surround the highlighted terms, if the
+	// fragment formatter is a TagFragmentFormatter. When only
+	// one is set, the other is the one of the formatter.
+	Before string
+	After  string
+	// Order is OrderScore or OrderDocument.
+	Order string
+	// NoMatchSize is the size in characters of the fragment
+	// taken from the start of a field without matches. It is
+	// the fragment size by default, negative values return no
+	// fragment for such fields.
+	NoMatchSize int
+}
@@ -12,6 +12,7 @@ package simple
 import (
 	"container/heap"
 	"fmt"
+	"sort"
 
 	"github.com/blevesearch/bleve/document"
 	"github.com/blevesearch/bleve/registry"
@@ -69,10 +70,26 @@ func (s *Highlighter) BestFragmentInField(dm *search.DocumentMatch, doc *documen
 }
 
 func (s *Highlighter) BestFragmentsInField(dm *search.DocumentMatch, doc *document.Document, field string, num int) []string {
+	return s.BestFragmentsInFieldWithOptions(dm, doc, field, &highlight.FragmentOptions{Count: num})
+}
+
+func (s *Highlighter) BestFragmentsInFieldWithOptions(dm *search.DocumentMatch, doc *document.Document, field string, options *highlight.FragmentOptions) []string {
 	tlm := dm.Locations[field]
+	if len(tlm) == 0 && options.NoMatchSize < 0 {
+		return nil
+	}
 	orderedTermLocations := highlight.OrderTermLocations(tlm)
 	scorer := NewFragmentScorer(tlm)
 
+	num := options.Count
+	if num <= 0 {
+		num = 1
+	}
+	size := options.Size
+	if len(tlm) == 0 && options.NoMatchSize > 0 {
+		size = options.NoMatchSize
+	}
+
 	// score the fragments and put them into a priority queue ordered by score
 	fq := make(FragmentQueue, 0)
 	heap.Init(&fq)
@@ -88,7 +105,12 @@ func (s *Highlighter) BestFragmentsInField(dm *search.DocumentMatch, doc *docume
 				}
 
 				fieldData := f.Value()
-				fragments := s.fragmenter.Fragment(fieldData, termLocationsSameArrayPosition)
+				var fragments []*highlight.Fragment
+				if sizedFragmenter, ok := s.fragmenter.(highlight.SizedFragmenter); ok && size > 0 {
+					fragments = sizedFragmenter.FragmentSized(fieldData, termLocationsSameArrayPosition, size)
+				} else {
+					fragments = s.fragmenter.Fragment(fieldData, termLocationsSameArrayPosition)
+				}
 				for _, fragment := range fragments {
 					fragment.ArrayPositions = f.ArrayPositions()
 					scorer.Score(fragment)
@@ -126,16 +148,25 @@ func (s *Highlighter) BestFragmentsInField(dm *search.DocumentMatch, doc *docume
 			candidate = heap.Pop(&fq)
 		}
 	}
+	if options.Order == highlight.OrderDocument {
+		sort.Sort(fragmentsInDocumentOrder(bestFragments))
+	}
 
 	// now that we have the best fragments, we can format them
 	orderedTermLocations.MergeOverlapping()
+	tagFormatter, useTags := s.formatter.(highlight.TagFragmentFormatter)
+	useTags = useTags && (options.Before != "" || options.After != "")
 	formattedFragments := make([]string, len(bestFragments))
 	for i, fragment := range bestFragments {
 		formattedFragments[i] = ""
 		if fragment.Start != 0 {
 			formattedFragments[i] += s.sep
 		}
-		formattedFragments[i] += s.formatter.Format(fragment, orderedTermLocations)
+		if useTags {
+			formattedFragments[i] += tagFormatter.FormatWithTags(fragment, orderedTermLocations, options.Before, options.After)
+		} else {
+			formattedFragments[i] += s.formatter.Format(fragment, orderedTermLocations)
+		}
 		if fragment.End != len(fragment.Orig) {
 			formattedFragments[i] += s.sep
 		}
@@ -151,6 +182,25 @@ func (s *Highlighter) BestFragmentsInField(dm *search.DocumentMatch, doc *docume
 	return formattedFragments
 }
 
+// fragmentsInDocumentOrder sorts fragments by array
+// positions, then by start offset.
+type fragmentsInDocumentOrder []*highlight.Fragment
+
+func (f fragmentsInDocumentOrder) Len() int      { return len(f) }
+func (f fragmentsInDocumentOrder) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f fragmentsInDocumentOrder) Less(i, j int) bool {
+	ai, aj := f[i].ArrayPositions, f[j].ArrayPositions
+	for k := 0; k < len(ai) && k < len(aj); k++ {
+		if ai[k] != aj[k] {
+			return ai[k] < aj[k]
+		}
+	}
+	if len(ai) != len(aj) {
+		return len(ai) < len(aj)
+	}
+	return f[i].Start < f[j].Start
+}
+
 // FragmentQueue implements heap.Interface and holds Items.
 type FragmentQueue []*highlight.Fragment
 
@@ -15,7 +15,9 @@ import (
 
 	"github.com/blevesearch/bleve/document"
 	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/highlight"
 	"github.com/blevesearch/bleve/search/highlight/fragment_formatters/ansi"
+	"github.com/blevesearch/bleve/search/highlight/fragment_formatters/html"
 	sfrag "github.com/blevesearch/bleve/search/highlight/fragmenters/simple"
 )
 
@@ -162,3 +164,112 @@ Etiam vel augue vel nisl commodo suscipit et ac nisl. Quisque eros diam, porttit
 	}
 
 }
+
+func TestSimpleHighlighterOptions(t *testing.T) {
+	fragmenter := sfrag.NewFragmenter(100)
+	formatter := html.NewFragmentFormatter("<b>", "</b>")
+	highlighter := NewHighlighter(fragmenter, formatter, "|")
+
+	docMatch := search.DocumentMatch{
+		ID:    "a",
+		Score: 1.0,
+		Locations: search.FieldTermLocationMap{
+			"desc": search.TermLocationMap{
+				"fox": []*search.Location{
+					{
+						Pos:   1,
+						Start: 0,
+						End:   3,
+					},
+					{
+						Pos:   8,
+						Start: 35,
+						End:   38,
+					},
+				},
+				"quick": []*search.Location{
+					{
+						Pos:   7,
+						Start: 29,
+						End:   34,
+					},
+				},
+			},
+		},
+	}
+	doc := document.NewDocument("a").
+		AddField(document.NewTextField("desc", []uint64{}, []byte("fox aaaa bbbb cccc dddd eeee quick fox gggg"))).
+		AddField(document.NewTextField("title", []uint64{}, []byte("a title without matches")))
+
+	tests := []struct {
+		field    string
+		options  *highlight.FragmentOptions
+		expected []string
+	}{
+		{
+			field:    "desc",
+			options:  &highlight.FragmentOptions{},
+			expected: []string{"<b>fox</b> aaaa bbbb cccc dddd eeee <b>quick</b> <b>fox</b> gggg"},
+		},
+		{
+			field:   "desc",
+			options: &highlight.FragmentOptions{Count: 3, Size: 10},
+			expected: []string{
+				"|<b>quick</b> <b>fox</b> |",
+				"<b>fox</b> aaaa b|",
+			},
+		},
+		{
+			field:   "desc",
+			options: &highlight.FragmentOptions{Count: 3, Size: 10, Order: highlight.OrderDocument},
+			expected: []string{
+				"<b>fox</b> aaaa b|",
+				"|<b>quick</b> <b>fox</b> |",
+			},
+		},
+		{
+			field:   "desc",
+			options: &highlight.FragmentOptions{Count: 1, Size: 10, Before: "[", After: "]"},
+			expected: []string{
+				"|[quick] [fox] |",
+			},
+		},
+		{
+			field:   "desc",
+			options: &highlight.FragmentOptions{Count: 1, Size: 10, Before: "<em>"},
+			expected: []string{
+				"|<em>quick</b> <em>fox</b> |",
+			},
+		},
+		{
+			field:   "desc",
+			options: &highlight.FragmentOptions{Count: 1, Size: 10, After: "</em>"},
+			expected: []string{
+				"|<b>quick</em> <b>fox</em> |",
+			},
+		},
+		{
+			field:    "title",
+			options:  &highlight.FragmentOptions{},
+			expected: []string{"a title without matches"},
+		},
+		{
+			field:    "title",
+			options:  &highlight.FragmentOptions{NoMatchSize: 7},
+			expected: []string{"a title|"},
+		},
+		{
+			field:    "title",
+			options:  &highlight.FragmentOptions{NoMatchSize: -1},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		docMatch.Fragments = nil
+		fragments := highlighter.BestFragmentsInFieldWithOptions(&docMatch, doc, test.field, test.options)
+		if !reflect.DeepEqual(fragments, test.expected) {
+			t.Errorf("expected %q for %s with %+v, got %q", test.expected, test.field, test.options, fragments)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package scorers
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+const DefaultBM25K1 = 1.2
+const DefaultBM25B = 0.75
+
+// BM25Similarity scores documents with Okapi BM25. K1 controls how fast
+// the score saturates as the term frequency grows, B how much the length
+// of the field, relative to its average length, lowers the score.
+type BM25Similarity struct {
+	K1 float64
+	B  float64
+}
+
+func NewBM25Similarity(k1, b float64) (*BM25Similarity, error) {
+	if k1 < 0 {
+		return nil, fmt.Errorf("bm25 k1 cannot be negative")
+	}
+	if b < 0 || b > 1 {
+		return nil, fmt.Errorf("bm25 b must be between 0 and 1")
+	}
+	return &BM25Similarity{
+		K1: k1,
+		B:  b,
+	}, nil
+}
+
+func (s *BM25Similarity) TermScorer(indexReader index.IndexReader, term, field string, boost float64, docTerm uint64, explain bool) (TermScorer, error) {
+	fieldStats, err := indexReader.FieldStats(field)
+	if err != nil {
+		return nil, err
+	}
+	return NewBM25TermScorer(term, field, boost, indexReader.DocCount(), docTerm, fieldStats.AverageLength(), s.K1, s.B, explain), nil
+}
+
+// UsesFieldLengths reports whether the lengths of fields lower the score,
+// that is whether B is not 0.
+func (s *BM25Similarity) UsesFieldLengths() bool {
+	return s.B > 0
+}
+
+// BM25TermScorer scores the documents matching a term with BM25. Like
+// in Lucene, query normalization does not apply to it.
+type BM25TermScorer struct {
+	queryTerm      string
+	queryField     string
+	queryBoost     float64
+	docTerm        uint64
+	docTotal       uint64
+	avgFieldLength float64
+	k1             float64
+	b              float64
+	idf            float64
+	explain        bool
+	idfExplanation *search.Explanation
+}
+
+// NewBM25TermScorer returns a scorer for a term found in docTerm of the
+// docTotal documents, in a field of average length avgFieldLength. When
+// it is unknown, 0, the lengths of fields are not taken into account.
+func NewBM25TermScorer(queryTerm string, queryField string, queryBoost float64, docTotal, docTerm uint64, avgFieldLength, k1, b float64, explain bool) *BM25TermScorer {
+	rv := BM25TermScorer{
+		queryTerm:      queryTerm,
+		queryField:     queryField,
+		queryBoost:     queryBoost,
+		docTerm:        docTerm,
+		docTotal:       docTotal,
+		avgFieldLength: avgFieldLength,
+		k1:             k1,
+		b:              b,
+		idf:            math.Log(1.0 + (float64(docTotal)-float64(docTerm)+0.5)/(float64(docTerm)+0.5)),
+		explain:        explain,
+	}
+
+	if explain {
+		rv.idfExplanation = &search.Explanation{
+			Value:   rv.idf,
+			Message: fmt.Sprintf("idf(docFreq=%d, maxDocs=%d)", docTerm, docTotal),
+		}
+	}
+
+	return &rv
+}
+
+func (s *BM25TermScorer) Weight() float64 {
+	sum := s.queryBoost * s.idf
+	return sum * sum
+}
+
+func (s *BM25TermScorer) SetQueryNorm(qnorm float64) {
+}
+
+func (s *BM25TermScorer) Score(termMatch *index.TermFieldDoc) *search.DocumentMatch {
+	freq := float64(termMatch.Freq)
+
+	// recover the length of the field from its norm
+	fieldLength := s.avgFieldLength
+	if termMatch.Norm > 0 {
+		fieldLength = 1.0 / (termMatch.Norm * termMatch.Norm)
+	}
+	lengthRatio := 1.0
+	if s.avgFieldLength > 0 {
+		lengthRatio = fieldLength / s.avgFieldLength
+	}
+	tfNorm := freq * (s.k1 + 1) / (freq + s.k1*(1-s.b+s.b*lengthRatio))
+	score := s.queryBoost * s.idf * tfNorm
+
+	rv := search.DocumentMatch{
+		ID:    termMatch.ID,
+		Score: score,
+	}
+
+	if s.explain {
+		childrenExplanations := make([]*search.Explanation, 3)
+		childrenExplanations[0] = &search.Explanation{
+			Value:   s.queryBoost,
+			Message: "boost",
+		}
+		childrenExplanations[1] = s.idfExplanation
+		childrenExplanations[2] = &search.Explanation{
+			Value: tfNorm,
+			Message: fmt.Sprintf("tfNorm(termFreq(%s:%s)=%d, k1=%f, b=%f, fieldLength=%f, avgFieldLength=%f)",
+				s.queryField, s.queryTerm, termMatch.Freq, s.k1, s.b, fieldLength, s.avgFieldLength),
+		}
+		rv.Expl = &search.Explanation{
+			Value:    score,
+			Message:  fmt.Sprintf("weight(%s:%s^%f in %s), product of:", s.queryField, s.queryTerm, s.queryBoost, termMatch.ID),
+			Children: childrenExplanations,
+		}
+	}
+
+	if termMatch.Vectors != nil && len(termMatch.Vectors) > 0 {
+		rv.Locations = termLocations(s.queryTerm, termMatch.Vectors)
+	}
+
+	return &rv
+}
@@ -0,0 +1,76 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package scorers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/blevesearch/bleve/index"
+)
+
+func TestBM25TermScorer(t *testing.T) {
+	var docTotal uint64 = 100
+	var docTerm uint64 = 9
+	k1 := DefaultBM25K1
+	b := DefaultBM25B
+	avgFieldLength := 4.0
+	scorer := NewBM25TermScorer("beer", "desc", 2.0, docTotal, docTerm, avgFieldLength, k1, b, true)
+	idf := math.Log(1.0 + (100.0-9.0+0.5)/(9.0+0.5))
+
+	tests := []struct {
+		freq        uint64
+		fieldLength float64
+	}{
+		{freq: 1, fieldLength: 4},
+		{freq: 1, fieldLength: 16},
+		{freq: 3, fieldLength: 1},
+	}
+
+	for _, test := range tests {
+		termMatch := &index.TermFieldDoc{
+			ID:   "one",
+			Freq: test.freq,
+			Norm: 1.0 / math.Sqrt(test.fieldLength),
+		}
+		freq := float64(test.freq)
+		tfNorm := freq * (k1 + 1) / (freq + k1*(1-b+b*test.fieldLength/avgFieldLength))
+		expected := 2.0 * idf * tfNorm
+
+		actual := scorer.Score(termMatch)
+		if math.Abs(actual.Score-expected) > 1e-9 {
+			t.Errorf("expected score %f for freq %d and length %f, got %f", expected, test.freq, test.fieldLength, actual.Score)
+		}
+		if actual.Expl == nil || math.Abs(actual.Expl.Value-actual.Score) > 1e-9 || len(actual.Expl.Children) != 3 {
+			t.Errorf("unexpected explanation %v", actual.Expl)
+		}
+	}
+
+	// the query norm does not change the scores
+	termMatch := &index.TermFieldDoc{ID: "one", Freq: 1, Norm: 0.5}
+	before := scorer.Score(termMatch).Score
+	scorer.SetQueryNorm(0.1)
+	after := scorer.Score(termMatch).Score
+	if before != after {
+		t.Errorf("expected query norm to be ignored, got %f then %f", before, after)
+	}
+
+	// shorter fields score higher
+	short := scorer.Score(&index.TermFieldDoc{ID: "short", Freq: 1, Norm: 1.0})
+	long := scorer.Score(&index.TermFieldDoc{ID: "long", Freq: 1, Norm: 0.25})
+	if short.Score <= long.Score {
+		t.Errorf("expected short field score %f to be higher than long field score %f", short.Score, long.Score)
+	}
+
+	_, err := NewBM25Similarity(1.2, 1.5)
+	if err == nil {
+		t.Errorf("expected error for b greater than 1")
+	}
+}
@@ -137,40 +137,44 @@ func (s *TermQueryScorer) Score(termMatch *index.TermFieldDoc) *search.DocumentM
 	}
 
 	if termMatch.Vectors != nil && len(termMatch.Vectors) > 0 {
+		rv.Locations = termLocations(s.queryTerm, termMatch.Vectors)
+	}
 
-		rv.Locations = make(search.FieldTermLocationMap)
-		for _, v := range termMatch.Vectors {
-			tlm := rv.Locations[v.Field]
-			if tlm == nil {
-				tlm = make(search.TermLocationMap)
-			}
+	return &rv
+}
 
-			loc := search.Location{
-				Pos:   float64(v.Pos),
-				Start: float64(v.Start),
-				End:   float64(v.End),
-			}
+// termLocations builds the locations of a matched term from its vectors.
+func termLocations(term string, vectors []*index.TermFieldVector) search.FieldTermLocationMap {
+	rv := make(search.FieldTermLocationMap)
+	for _, v := range vectors {
+		tlm := rv[v.Field]
+		if tlm == nil {
+			tlm = make(search.TermLocationMap)
+		}
 
-			if len(v.ArrayPositions) > 0 {
-				loc.ArrayPositions = make([]float64, len(v.ArrayPositions))
-				for i, ap := range v.ArrayPositions {
-					loc.ArrayPositions[i] = float64(ap)
-				}
-			}
+		loc := search.Location{
+			Pos:   float64(v.Pos),
+			Start: float64(v.Start),
+			End:   float64(v.End),
+		}
 
-			locations := tlm[s.queryTerm]
-			if locations == nil {
-				locations = make(search.Locations, 1)
-				locations[0] = &loc
-			} else {
-				locations = append(locations, &loc)
+		if len(v.ArrayPositions) > 0 {
+			loc.ArrayPositions = make([]float64, len(v.ArrayPositions))
+			for i, ap := range v.ArrayPositions {
+				loc.ArrayPositions[i] = float64(ap)
 			}
-			tlm[s.queryTerm] = locations
+		}
 
-			rv.Locations[v.Field] = tlm
+		locations := tlm[term]
+		if locations == nil {
+			locations = make(search.Locations, 1)
+			locations[0] = &loc
+		} else {
+			locations = append(locations, &loc)
 		}
+		tlm[term] = locations
 
+		rv[v.Field] = tlm
 	}
-
-	return &rv
+	return rv
 }
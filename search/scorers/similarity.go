@@ -0,0 +1,51 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package scorers
+
+import (
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+// A TermScorer scores the documents matching a term of a query.
+type TermScorer interface {
+	Weight() float64
+	SetQueryNorm(qnorm float64)
+	Score(termMatch *index.TermFieldDoc) *search.DocumentMatch
+}
+
+// A Similarity decides how the frequency of a term in a document, the
+// length of the field and the rarity of the term in the index make the
+// document relevant.
+type Similarity interface {
+	// TermScorer returns the scorer of the docTerm documents matching term
+	// in field.
+	TermScorer(indexReader index.IndexReader, term, field string, boost float64, docTerm uint64, explain bool) (TermScorer, error)
+}
+
+// TFIDFSimilarity scores documents with the classic TF-IDF of
+// TermQueryScorer. It is the default similarity.
+type TFIDFSimilarity struct{}
+
+func NewTFIDFSimilarity() *TFIDFSimilarity {
+	return &TFIDFSimilarity{}
+}
+
+func (s *TFIDFSimilarity) TermScorer(indexReader index.IndexReader, term, field string, boost float64, docTerm uint64, explain bool) (TermScorer, error) {
+	return NewTermQueryScorer(term, field, boost, indexReader.DocCount(), docTerm, explain), nil
+}
+
+// A FieldLengthsSimilarity is a Similarity reading the field statistics of
+// the index, which are only maintained for the fields indexed with the
+// document.FieldLengths option.
+type FieldLengthsSimilarity interface {
+	Similarity
+	UsesFieldLengths() bool
+}
@@ -57,10 +57,12 @@ type DocumentMatch struct {
 	Expl      *Explanation         `json:"explanation,omitempty"`
 	Locations FieldTermLocationMap `json:"locations,omitempty"`
 	Fragments FieldFragmentMap     `json:"fragments,omitempty"`
+	Sort      []string             `json:"sort,omitempty"`
 
 	// Fields contains the values for document fields listed in
 	// SearchRequest.Fields. Text fields are returned as strings, numeric
-	// fields as float64s and date fields as time.RFC3339 formatted strings.
+	// fields as float64s, date fields as time.RFC3339 formatted strings and
+	// geo points as [lon, lat] float64 slices.
 	Fields map[string]interface{} `json:"fields,omitempty"`
 }
 
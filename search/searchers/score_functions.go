@@ -0,0 +1,275 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/blevesearch/bleve/numeric_util"
+	"github.com/blevesearch/bleve/search"
+)
+
+// A ScoreFunction computes a value for each document matched by a
+// FunctionScoreSearcher, which is combined with the score of the match.
+type ScoreFunction interface {
+	// Field returns the field whose terms the function needs, or "".
+	Field() string
+
+	// Score returns the value of the function for a document given the
+	// terms of its field. The function does not apply to the document when
+	// ok is false.
+	Score(d *search.DocumentMatch, terms []string) (value float64, ok bool, expl *search.Explanation)
+}
+
+// numericValues decodes the full precision values of numeric terms, or
+// the nanoseconds since the epoch of date terms.
+func numericValues(terms []string, dateTime bool) []float64 {
+	var rv []float64
+	for _, term := range terms {
+		prefixCoded := numeric_util.PrefixCoded(term)
+		shift, err := prefixCoded.Shift()
+		if err == nil && shift == 0 {
+			i64, err := prefixCoded.Int64()
+			if err == nil && dateTime {
+				rv = append(rv, float64(i64))
+			} else if err == nil {
+				rv = append(rv, numeric_util.Int64ToFloat64(i64))
+			}
+		}
+	}
+	return rv
+}
+
+// WeightFunction scales another function, or is a constant when it has no
+// function.
+type WeightFunction struct {
+	function ScoreFunction
+	weight   float64
+}
+
+func NewWeightFunction(function ScoreFunction, weight float64) *WeightFunction {
+	return &WeightFunction{
+		function: function,
+		weight:   weight,
+	}
+}
+
+func (f *WeightFunction) Field() string {
+	if f.function == nil {
+		return ""
+	}
+	return f.function.Field()
+}
+
+func (f *WeightFunction) Score(d *search.DocumentMatch, terms []string) (float64, bool, *search.Explanation) {
+	if f.function == nil {
+		return f.weight, true, &search.Explanation{
+			Value:   f.weight,
+			Message: "weight",
+		}
+	}
+	value, ok, expl := f.function.Score(d, terms)
+	if !ok {
+		return 0, false, nil
+	}
+	rv := value * f.weight
+	return rv, true, &search.Explanation{
+		Value:   rv,
+		Message: fmt.Sprintf("product of weight %f and:", f.weight),
+		Children: []*search.Explanation{
+			expl,
+		},
+	}
+}
+
+var fieldValueModifiers = map[string]func(float64) float64{
+	"none":       func(v float64) float64 { return v },
+	"log":        math.Log10,
+	"log1p":      func(v float64) float64 { return math.Log10(v + 1) },
+	"log2p":      func(v float64) float64 { return math.Log10(v + 2) },
+	"ln":         math.Log,
+	"ln1p":       math.Log1p,
+	"ln2p":       func(v float64) float64 { return math.Log(v + 2) },
+	"square":     func(v float64) float64 { return v * v },
+	"sqrt":       math.Sqrt,
+	"reciprocal": func(v float64) float64 { return 1 / v },
+}
+
+// FieldValueFactorFunction computes modifier(factor * value) from the
+// smallest numeric value of a field. The modifier is one of none, log,
+// log1p, log2p, ln, ln1p, ln2p, square, sqrt and reciprocal.
+type FieldValueFactorFunction struct {
+	field        string
+	factor       float64
+	modifierName string
+	modifier     func(float64) float64
+	missing      *float64
+}
+
+// NewFieldValueFactorFunction returns the function, documents without a
+// value use missing, or are left out when it is nil.
+func NewFieldValueFactorFunction(field string, factor float64, modifier string, missing *float64) (*FieldValueFactorFunction, error) {
+	if modifier == "" {
+		modifier = "none"
+	}
+	modifierFunc, ok := fieldValueModifiers[modifier]
+	if !ok {
+		return nil, fmt.Errorf("unknown field value factor modifier '%s'", modifier)
+	}
+	return &FieldValueFactorFunction{
+		field:        field,
+		factor:       factor,
+		modifierName: modifier,
+		modifier:     modifierFunc,
+		missing:      missing,
+	}, nil
+}
+
+func (f *FieldValueFactorFunction) Field() string {
+	return f.field
+}
+
+func (f *FieldValueFactorFunction) Score(d *search.DocumentMatch, terms []string) (float64, bool, *search.Explanation) {
+	values := numericValues(terms, false)
+	var value float64
+	if len(values) > 0 {
+		value = values[0]
+		for _, v := range values[1:] {
+			value = math.Min(value, v)
+		}
+	} else if f.missing != nil {
+		value = *f.missing
+	} else {
+		return 0, false, nil
+	}
+	rv := f.modifier(f.factor * value)
+	if math.IsNaN(rv) || math.IsInf(rv, 0) {
+		rv = 0
+	}
+	return rv, true, &search.Explanation{
+		Value:   rv,
+		Message: fmt.Sprintf("field value function: %s(doc['%s'].value=%f * factor=%f)", f.modifierName, f.field, value, f.factor),
+	}
+}
+
+// DecayFunction scores documents by the distance of the closest value of
+// a numeric or date field to an origin: 1 up to offset, then decaying to
+// decay at offset+scale, following a gauss, linear or exp curve.
+type DecayFunction struct {
+	function string
+	field    string
+	dateTime bool
+	origin   float64
+	scale    float64
+	offset   float64
+	decay    float64
+}
+
+func NewDecayFunction(function, field string, origin, scale, offset, decay float64) (*DecayFunction, error) {
+	switch function {
+	case "gauss", "linear", "exp":
+	default:
+		return nil, fmt.Errorf("unknown decay function '%s'", function)
+	}
+	if scale <= 0 {
+		return nil, fmt.Errorf("decay function scale must be positive")
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("decay function offset cannot be negative")
+	}
+	if decay <= 0 || decay >= 1 {
+		return nil, fmt.Errorf("decay must be between 0 and 1 exclusive")
+	}
+	return &DecayFunction{
+		function: function,
+		field:    field,
+		origin:   origin,
+		scale:    scale,
+		offset:   offset,
+		decay:    decay,
+	}, nil
+}
+
+// NewDateTimeDecayFunction returns a decay function over a date field,
+// distances are computed in nanoseconds.
+func NewDateTimeDecayFunction(function, field string, origin time.Time, scale, offset time.Duration, decay float64) (*DecayFunction, error) {
+	rv, err := NewDecayFunction(function, field, float64(origin.UnixNano()), float64(scale), float64(offset), decay)
+	if err != nil {
+		return nil, err
+	}
+	rv.dateTime = true
+	return rv, nil
+}
+
+func (f *DecayFunction) Field() string {
+	return f.field
+}
+
+func (f *DecayFunction) Score(d *search.DocumentMatch, terms []string) (float64, bool, *search.Explanation) {
+	values := numericValues(terms, f.dateTime)
+	if len(values) == 0 {
+		return 0, false, nil
+	}
+	distance := math.Inf(1)
+	for _, v := range values {
+		distance = math.Min(distance, math.Abs(v-f.origin))
+	}
+	x := math.Max(0, distance-f.offset)
+
+	var rv float64
+	switch f.function {
+	case "gauss":
+		sigmaSquared := -f.scale * f.scale / (2 * math.Log(f.decay))
+		rv = math.Exp(-x * x / (2 * sigmaSquared))
+	case "exp":
+		lambda := math.Log(f.decay) / f.scale
+		rv = math.Exp(lambda * x)
+	case "linear":
+		s := f.scale / (1 - f.decay)
+		rv = math.Max(0, (s-x)/s)
+	}
+	return rv, true, &search.Explanation{
+		Value:   rv,
+		Message: fmt.Sprintf("%s decay function: distance(doc['%s'].value, origin=%f)=%f, scale=%f, offset=%f, decay=%f", f.function, f.field, f.origin, distance, f.scale, f.offset, f.decay),
+	}
+}
+
+// RandomScoreFunction gives each document a random value in [0, 1),
+// which is the same for all the searches using the same seed.
+type RandomScoreFunction struct {
+	seed int64
+}
+
+func NewRandomScoreFunction(seed int64) *RandomScoreFunction {
+	return &RandomScoreFunction{
+		seed: seed,
+	}
+}
+
+func (f *RandomScoreFunction) Field() string {
+	return ""
+}
+
+func (f *RandomScoreFunction) Score(d *search.DocumentMatch, terms []string) (float64, bool, *search.Explanation) {
+	var seed [8]byte
+	binary.BigEndian.PutUint64(seed[:], uint64(f.seed))
+	h := fnv.New64a()
+	_, _ = h.Write(seed[:])
+	_, _ = h.Write([]byte(d.ID))
+	rv := float64(h.Sum64()>>11) / (1 << 53)
+	return rv, true, &search.Explanation{
+		Value:   rv,
+		Message: fmt.Sprintf("random score function (seed: %d)", f.seed),
+	}
+}
@@ -0,0 +1,86 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"github.com/blevesearch/bleve/search"
+)
+
+// FilterFunc decides whether a document match produced by the child
+// searcher of a FilteringSearcher is kept.
+type FilterFunc func(d *search.DocumentMatch) (bool, error)
+
+// FilteringSearcher wraps any other searcher, but checks any Next/Advance
+// call against the supplied FilterFunc
+type FilteringSearcher struct {
+	child  search.Searcher
+	accept FilterFunc
+}
+
+func NewFilteringSearcher(s search.Searcher, filter FilterFunc) *FilteringSearcher {
+	return &FilteringSearcher{
+		child:  s,
+		accept: filter,
+	}
+}
+
+func (f *FilteringSearcher) Next() (*search.DocumentMatch, error) {
+	next, err := f.child.Next()
+	for next != nil && err == nil {
+		var accepted bool
+		accepted, err = f.accept(next)
+		if err != nil {
+			return nil, err
+		}
+		if accepted {
+			return next, nil
+		}
+		next, err = f.child.Next()
+	}
+	return nil, err
+}
+
+func (f *FilteringSearcher) Advance(ID string) (*search.DocumentMatch, error) {
+	adv, err := f.child.Advance(ID)
+	if err != nil {
+		return nil, err
+	}
+	if adv == nil {
+		return nil, nil
+	}
+	accepted, err := f.accept(adv)
+	if err != nil {
+		return nil, err
+	}
+	if accepted {
+		return adv, nil
+	}
+	return f.Next()
+}
+
+func (f *FilteringSearcher) Close() error {
+	return f.child.Close()
+}
+
+func (f *FilteringSearcher) Weight() float64 {
+	return f.child.Weight()
+}
+
+func (f *FilteringSearcher) SetQueryNorm(n float64) {
+	f.child.SetQueryNorm(n)
+}
+
+func (f *FilteringSearcher) Count() uint64 {
+	return f.child.Count()
+}
+
+func (f *FilteringSearcher) Min() int {
+	return f.child.Min()
+}
@@ -0,0 +1,214 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+// FunctionScoreSearcher wraps any other searcher and changes the score of
+// its matches with the values of score functions.
+//
+// The scoreMode combines the values of the functions applying to a
+// document: multiply, sum, avg, first, max or min. The boostMode then
+// combines the result with the score of the match: multiply, replace,
+// sum, avg, max or min. Documents no function applies to get a function
+// value of 1. The outcome is finally multiplied by boost.
+type FunctionScoreSearcher struct {
+	indexReader index.IndexReader
+	child       search.Searcher
+	functions   []ScoreFunction
+	fields      []string
+	scoreMode   string
+	boostMode   string
+	boost       float64
+	explain     bool
+}
+
+// ValidateFunctionScoreModes checks the score and boost modes of a
+// FunctionScoreSearcher, the empty string standing for multiply.
+func ValidateFunctionScoreModes(scoreMode, boostMode string) error {
+	switch scoreMode {
+	case "", "multiply", "sum", "avg", "first", "max", "min":
+	default:
+		return fmt.Errorf("unknown score mode '%s'", scoreMode)
+	}
+	switch boostMode {
+	case "", "multiply", "replace", "sum", "avg", "max", "min":
+	default:
+		return fmt.Errorf("unknown boost mode '%s'", boostMode)
+	}
+	return nil
+}
+
+func NewFunctionScoreSearcher(indexReader index.IndexReader, child search.Searcher, functions []ScoreFunction, scoreMode, boostMode string, boost float64, explain bool) (*FunctionScoreSearcher, error) {
+	err := ValidateFunctionScoreModes(scoreMode, boostMode)
+	if err != nil {
+		return nil, err
+	}
+	if scoreMode == "" {
+		scoreMode = "multiply"
+	}
+	if boostMode == "" {
+		boostMode = "multiply"
+	}
+
+	var fields []string
+	for _, function := range functions {
+		if function.Field() != "" {
+			fields = append(fields, function.Field())
+		}
+	}
+
+	return &FunctionScoreSearcher{
+		indexReader: indexReader,
+		child:       child,
+		functions:   functions,
+		fields:      fields,
+		scoreMode:   scoreMode,
+		boostMode:   boostMode,
+		boost:       boost,
+		explain:     explain,
+	}, nil
+}
+
+func (s *FunctionScoreSearcher) rescore(dm *search.DocumentMatch) error {
+	var fieldTerms index.FieldTerms
+	if len(s.fields) > 0 {
+		var err error
+		fieldTerms, err = s.indexReader.DocumentFieldTermsForFields(dm.ID, s.fields)
+		if err != nil {
+			return err
+		}
+	}
+
+	var values []float64
+	var functionsExpl []*search.Explanation
+	for _, function := range s.functions {
+		var terms []string
+		if function.Field() != "" {
+			terms = fieldTerms[function.Field()]
+		}
+		value, ok, expl := function.Score(dm, terms)
+		if !ok {
+			continue
+		}
+		values = append(values, value)
+		if s.explain {
+			functionsExpl = append(functionsExpl, expl)
+		}
+		if s.scoreMode == "first" {
+			break
+		}
+	}
+
+	functionScore := 1.0
+	if len(values) > 0 {
+		functionScore = values[0]
+		for _, value := range values[1:] {
+			switch s.scoreMode {
+			case "multiply":
+				functionScore *= value
+			case "sum", "avg":
+				functionScore += value
+			case "max":
+				functionScore = math.Max(functionScore, value)
+			case "min":
+				functionScore = math.Min(functionScore, value)
+			}
+		}
+		if s.scoreMode == "avg" {
+			functionScore /= float64(len(values))
+		}
+	}
+
+	var score float64
+	switch s.boostMode {
+	case "multiply":
+		score = dm.Score * functionScore
+	case "replace":
+		score = functionScore
+	case "sum":
+		score = dm.Score + functionScore
+	case "avg":
+		score = (dm.Score + functionScore) / 2
+	case "max":
+		score = math.Max(dm.Score, functionScore)
+	case "min":
+		score = math.Min(dm.Score, functionScore)
+	}
+	score *= s.boost
+
+	if s.explain {
+		functionExpl := &search.Explanation{
+			Value:    functionScore,
+			Message:  fmt.Sprintf("functions, score mode %s of:", s.scoreMode),
+			Children: functionsExpl,
+		}
+		if len(functionsExpl) == 0 {
+			functionExpl.Message = "no function applies"
+		}
+		dm.Expl = &search.Explanation{
+			Value:    score,
+			Message:  fmt.Sprintf("function score^%f, boost mode %s of:", s.boost, s.boostMode),
+			Children: []*search.Explanation{dm.Expl, functionExpl},
+		}
+	}
+	dm.Score = score
+	return nil
+}
+
+func (s *FunctionScoreSearcher) Next() (*search.DocumentMatch, error) {
+	next, err := s.child.Next()
+	if err != nil || next == nil {
+		return next, err
+	}
+	err = s.rescore(next)
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+func (s *FunctionScoreSearcher) Advance(ID string) (*search.DocumentMatch, error) {
+	adv, err := s.child.Advance(ID)
+	if err != nil || adv == nil {
+		return adv, err
+	}
+	err = s.rescore(adv)
+	if err != nil {
+		return nil, err
+	}
+	return adv, nil
+}
+
+func (s *FunctionScoreSearcher) Close() error {
+	return s.child.Close()
+}
+
+func (s *FunctionScoreSearcher) Weight() float64 {
+	return s.child.Weight()
+}
+
+func (s *FunctionScoreSearcher) SetQueryNorm(n float64) {
+	s.child.SetQueryNorm(n)
+}
+
+func (s *FunctionScoreSearcher) Count() uint64 {
+	return s.child.Count()
+}
+
+func (s *FunctionScoreSearcher) Min() int {
+	return s.child.Min()
+}
@@ -0,0 +1,195 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/store/gtreap"
+	"github.com/blevesearch/bleve/index/upside_down"
+	"github.com/blevesearch/bleve/numeric_util"
+	"github.com/blevesearch/bleve/search"
+)
+
+func setupFunctionScore(t *testing.T) index.Index {
+	analysisQueue := index.NewAnalysisQueue(1)
+	i, err := upside_down.NewUpsideDownCouch(gtreap.Name, nil, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = i.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, doc := range []struct {
+		id         string
+		popularity float64
+		days       int
+	}{
+		{"a", 9, 0},
+		{"b", 99, 10},
+		{"c", 0, 20},
+	} {
+		d := document.NewDocument(doc.id)
+		d.AddField(document.NewNumericField("popularity", []uint64{}, doc.popularity))
+		dateField, err := document.NewDateTimeField("published", []uint64{}, published.AddDate(0, 0, doc.days))
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.AddField(dateField)
+		err = i.Update(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := document.NewDocument("d")
+	d.AddField(document.NewTextField("name", []uint64{}, []byte("no values")))
+	err = i.Update(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return i
+}
+
+func functionScores(t *testing.T, indexReader index.IndexReader, functions []ScoreFunction, scoreMode, boostMode string, explain bool) map[string]*search.DocumentMatch {
+	matchAll, err := NewMatchAllSearcher(indexReader, 1.0, explain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	searcher, err := NewFunctionScoreSearcher(indexReader, matchAll, functions, scoreMode, boostMode, 2.0, explain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := searcher.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	rv := make(map[string]*search.DocumentMatch)
+	next, err := searcher.Next()
+	for err == nil && next != nil {
+		rv[next.ID] = next
+		next, err = searcher.Next()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rv
+}
+
+func TestFunctionScoreSearcher(t *testing.T) {
+	i := setupFunctionScore(t)
+	indexReader, err := i.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := indexReader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	fieldValue, err := NewFieldValueFactorFunction("popularity", 1, "log1p", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	origin := time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)
+	decay, err := NewDateTimeDecayFunction("exp", "published", origin, 10*24*time.Hour, 0, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the match all score is 1, the boost 2
+	scores := functionScores(t, indexReader, []ScoreFunction{fieldValue, NewWeightFunction(decay, 3)}, "sum", "multiply", true)
+	expected := map[string]float64{
+		"a": 2 * (1 + 3),
+		"b": 2 * (2 + 3*0.5),
+		"c": 2 * (0 + 3*0.25),
+		"d": 2,
+	}
+	for id, score := range expected {
+		dm := scores[id]
+		if dm == nil {
+			t.Fatalf("missing match %s", id)
+		}
+		if math.Abs(dm.Score-score) > 1e-9 {
+			t.Errorf("expected score %f for %s, got %f", score, id, dm.Score)
+		}
+		if dm.Expl == nil || dm.Expl.Value != dm.Score || len(dm.Expl.Children) != 2 {
+			t.Errorf("unexpected explanation for %s: %v", id, dm.Expl)
+		}
+	}
+	if len(scores["b"].Expl.Children[1].Children) != 2 {
+		t.Errorf("expected 2 function explanations, got %v", scores["b"].Expl.Children[1])
+	}
+
+	// random scores are stable for a seed
+	random := NewRandomScoreFunction(42)
+	first := functionScores(t, indexReader, []ScoreFunction{random}, "", "replace", false)
+	second := functionScores(t, indexReader, []ScoreFunction{random}, "", "replace", false)
+	for id, dm := range first {
+		if dm.Score < 0 || dm.Score >= 2 || dm.Score != second[id].Score {
+			t.Errorf("unexpected random scores %f and %f for %s", dm.Score, second[id].Score, id)
+		}
+	}
+
+	_, err = NewFunctionScoreSearcher(indexReader, nil, nil, "median", "", 1, false)
+	if err == nil {
+		t.Errorf("expected error for unknown score mode")
+	}
+}
+
+func TestDecayFunctions(t *testing.T) {
+	for _, function := range []string{"gauss", "linear", "exp"} {
+		f, err := NewDecayFunction(function, "f", 10, 5, 1, 0.3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 1 up to the offset, the decay at offset+scale on both sides
+		for _, test := range []struct {
+			value    float64
+			expected float64
+		}{
+			{10, 1},
+			{11, 1},
+			{16, 0.3},
+			{4, 0.3},
+		} {
+			term := numeric_util.MustNewPrefixCodedInt64(numeric_util.Float64ToInt64(test.value), 0)
+			actual, ok, _ := f.Score(nil, []string{string(term)})
+			if !ok {
+				t.Fatalf("expected %s decay to apply", function)
+			}
+			if math.Abs(actual-test.expected) > 1e-9 {
+				t.Errorf("expected %s decay of %f to be %f, got %f", function, test.value, test.expected, actual)
+			}
+		}
+		_, ok, _ := f.Score(nil, nil)
+		if ok {
+			t.Errorf("expected %s decay not to apply without values", function)
+		}
+	}
+
+	_, err := NewDecayFunction("cubic", "f", 0, 1, 0, 0.5)
+	if err == nil {
+		t.Errorf("expected error for unknown decay function")
+	}
+	_, err = NewDecayFunction("gauss", "f", 0, 1, 0, 1)
+	if err == nil {
+		t.Errorf("expected error for decay of 1")
+	}
+}
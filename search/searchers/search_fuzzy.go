@@ -12,6 +12,7 @@ package searchers
 import (
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/scorers"
 )
 
 type FuzzySearcher struct {
@@ -25,6 +26,12 @@ type FuzzySearcher struct {
 }
 
 func NewFuzzySearcher(indexReader index.IndexReader, term string, prefix, fuzziness int, field string, boost float64, explain bool) (*FuzzySearcher, error) {
+	return NewFuzzySearcherWithSimilarity(indexReader, term, prefix, fuzziness, field, boost, nil, explain)
+}
+
+// NewFuzzySearcherWithSimilarity works like NewFuzzySearcher, scoring the
+// terms it matches with similarity, or with TF-IDF when it is nil.
+func NewFuzzySearcherWithSimilarity(indexReader index.IndexReader, term string, prefix, fuzziness int, field string, boost float64, similarity scorers.Similarity, explain bool) (*FuzzySearcher, error) {
 	prefixTerm := ""
 	for i, r := range term {
 		if i < prefix {
@@ -41,7 +48,7 @@ func NewFuzzySearcher(indexReader index.IndexReader, term string, prefix, fuzzin
 	qsearchers := make([]search.Searcher, 0, len(candidateTerms))
 
 	for _, cterm := range candidateTerms {
-		qsearcher, err := NewTermSearcher(indexReader, cterm, field, boost, explain)
+		qsearcher, err := NewTermSearcherWithSimilarity(indexReader, cterm, field, boost, similarity, explain)
 		if err != nil {
 			return nil, err
 		}
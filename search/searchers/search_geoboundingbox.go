@@ -0,0 +1,206 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/geo"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/numeric_util"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/scorers"
+)
+
+var geoMaxShift = document.GeoPrecisionStep * 4
+var geoDetailLevel = ((geo.GeoBits << 1) - geoMaxShift) / 2
+
+type GeoBoundingBoxSearcher struct {
+	indexReader index.IndexReader
+	field       string
+	searcher    search.Searcher
+}
+
+// NewGeoBoundingBoxSearcher builds a searcher matching the documents
+// with a geo point in field within the box. When minLon is greater
+// than maxLon the box is taken to cross the date line.
+func NewGeoBoundingBoxSearcher(indexReader index.IndexReader, minLon, minLat, maxLon, maxLat float64, field string, boost float64, explain bool) (*GeoBoundingBoxSearcher, error) {
+	return NewGeoBoundingBoxSearcherWithSimilarity(indexReader, minLon, minLat, maxLon, maxLat, field, boost, nil, explain)
+}
+
+// NewGeoBoundingBoxSearcherWithSimilarity works like NewGeoBoundingBoxSearcher, scoring the
+// terms it matches with similarity, or with TF-IDF when it is nil.
+func NewGeoBoundingBoxSearcherWithSimilarity(indexReader index.IndexReader, minLon, minLat, maxLon, maxLat float64, field string, boost float64, similarity scorers.Similarity, explain bool) (*GeoBoundingBoxSearcher, error) {
+	var onBoundary, notOnBoundary [][]byte
+	if minLon > maxLon {
+		// split the box at the date line
+		onBoundary, notOnBoundary = ComputeGeoRange(0, (geo.GeoBits<<1)-1, minLon, minLat, 180, maxLat)
+		onBoundaryWest, notOnBoundaryWest := ComputeGeoRange(0, (geo.GeoBits<<1)-1, -180, minLat, maxLon, maxLat)
+		onBoundary = append(onBoundary, onBoundaryWest...)
+		notOnBoundary = append(notOnBoundary, notOnBoundaryWest...)
+	} else {
+		onBoundary, notOnBoundary = ComputeGeoRange(0, (geo.GeoBits<<1)-1, minLon, minLat, maxLon, maxLat)
+	}
+	if tooManyClauses(len(onBoundary) + len(notOnBoundary)) {
+		return nil, tooManyClausesErr()
+	}
+
+	qsearchers := make([]search.Searcher, 0, 2)
+
+	// terms on the boundary of the box may match points outside of it
+	if len(onBoundary) > 0 {
+		boundarySearcher, err := newTermsDisjunctionSearcher(indexReader, onBoundary, field, boost, similarity, explain)
+		if err != nil {
+			return nil, err
+		}
+		qsearchers = append(qsearchers, NewFilteringSearcher(boundarySearcher,
+			buildGeoPointFilter(indexReader, field, func(lon, lat float64) bool {
+				if minLon > maxLon {
+					return geo.BoundingBoxContains(lon, lat, minLon, minLat, 180, maxLat) ||
+						geo.BoundingBoxContains(lon, lat, -180, minLat, maxLon, maxLat)
+				}
+				return geo.BoundingBoxContains(lon, lat, minLon, minLat, maxLon, maxLat)
+			})))
+	}
+	if len(notOnBoundary) > 0 {
+		insideSearcher, err := newTermsDisjunctionSearcher(indexReader, notOnBoundary, field, boost, similarity, explain)
+		if err != nil {
+			return nil, err
+		}
+		qsearchers = append(qsearchers, insideSearcher)
+	}
+
+	searcher, err := NewDisjunctionSearcher(indexReader, qsearchers, 0, explain)
+	if err != nil {
+		for _, qsearcher := range qsearchers {
+			_ = qsearcher.Close()
+		}
+		return nil, err
+	}
+	return &GeoBoundingBoxSearcher{
+		indexReader: indexReader,
+		field:       field,
+		searcher:    searcher,
+	}, nil
+}
+
+func (s *GeoBoundingBoxSearcher) Count() uint64 {
+	return s.searcher.Count()
+}
+
+func (s *GeoBoundingBoxSearcher) Weight() float64 {
+	return s.searcher.Weight()
+}
+
+func (s *GeoBoundingBoxSearcher) SetQueryNorm(qnorm float64) {
+	s.searcher.SetQueryNorm(qnorm)
+}
+
+func (s *GeoBoundingBoxSearcher) Next() (*search.DocumentMatch, error) {
+	return s.searcher.Next()
+}
+
+func (s *GeoBoundingBoxSearcher) Advance(ID string) (*search.DocumentMatch, error) {
+	return s.searcher.Advance(ID)
+}
+
+func (s *GeoBoundingBoxSearcher) Close() error {
+	return s.searcher.Close()
+}
+
+func (s *GeoBoundingBoxSearcher) Min() int {
+	return 0
+}
+
+func newTermsDisjunctionSearcher(indexReader index.IndexReader, terms [][]byte, field string, boost float64, similarity scorers.Similarity, explain bool) (search.Searcher, error) {
+	qsearchers := make([]search.Searcher, len(terms))
+	for i, term := range terms {
+		var err error
+		qsearchers[i], err = NewTermSearcherWithSimilarity(indexReader, string(term), field, boost, similarity, explain)
+		if err != nil {
+			for _, qsearcher := range qsearchers[:i] {
+				_ = qsearcher.Close()
+			}
+			return nil, err
+		}
+	}
+	return NewDisjunctionSearcher(indexReader, qsearchers, 0, explain)
+}
+
+// buildGeoPointFilter builds a FilterFunc accepting the documents
+// having at least one geo point in field satisfying accept
+func buildGeoPointFilter(indexReader index.IndexReader, field string, accept func(lon, lat float64) bool) FilterFunc {
+	fields := []string{field}
+	return func(d *search.DocumentMatch) (bool, error) {
+		fieldTerms, err := indexReader.DocumentFieldTermsForFields(d.ID, fields)
+		if err != nil {
+			return false, err
+		}
+		for _, term := range fieldTerms[field] {
+			prefixCoded := numeric_util.PrefixCoded(term)
+			shift, err := prefixCoded.Shift()
+			if err == nil && shift == 0 {
+				i64, err := prefixCoded.Int64()
+				if err == nil {
+					lon := geo.MortonUnhashLon(uint64(i64))
+					lat := geo.MortonUnhashLat(uint64(i64))
+					if accept(lon, lat) {
+						return true, nil
+					}
+				}
+			}
+		}
+		return false, nil
+	}
+}
+
+// ComputeGeoRange recursively splits the morton hash space, starting
+// at term with shift bits of precision left, into the prefix coded
+// terms covering the box. Terms for cells entirely within the box are
+// returned in notOnBoundary, those only intersecting it in onBoundary,
+// their matches have to be checked against the box.
+func ComputeGeoRange(term uint64, shift uint, sminLon, sminLat, smaxLon, smaxLat float64) (onBoundary [][]byte, notOnBoundary [][]byte) {
+	split := term | uint64(0x1)<<shift
+	var upperMax uint64
+	if shift < 63 {
+		upperMax = term | ((uint64(1) << (shift + 1)) - 1)
+	} else {
+		upperMax = 0xffffffffffffffff
+	}
+	lowerMax := split - 1
+	onBoundary, notOnBoundary = relateAndRecurse(term, lowerMax, shift, sminLon, sminLat, smaxLon, smaxLat)
+	plusOnBoundary, plusNotOnBoundary := relateAndRecurse(split, upperMax, shift, sminLon, sminLat, smaxLon, smaxLat)
+	onBoundary = append(onBoundary, plusOnBoundary...)
+	notOnBoundary = append(notOnBoundary, plusNotOnBoundary...)
+	return
+}
+
+func relateAndRecurse(start, end uint64, res uint, sminLon, sminLat, smaxLon, smaxLat float64) (onBoundary [][]byte, notOnBoundary [][]byte) {
+	minLon := geo.MortonUnhashLon(start)
+	minLat := geo.MortonUnhashLat(start)
+	maxLon := geo.MortonUnhashLon(end)
+	maxLat := geo.MortonUnhashLat(end)
+
+	level := ((geo.GeoBits << 1) - res) >> 1
+
+	within := res%document.GeoPrecisionStep == 0 &&
+		geo.RectWithin(minLon, minLat, maxLon, maxLat, sminLon, sminLat, smaxLon, smaxLat)
+	if within || (level == geoDetailLevel &&
+		geo.RectIntersects(minLon, minLat, maxLon, maxLat, sminLon, sminLat, smaxLon, smaxLat)) {
+		term := []byte(numeric_util.MustNewPrefixCodedInt64(int64(start), res))
+		if !within {
+			return [][]byte{term}, nil
+		}
+		return nil, [][]byte{term}
+	} else if level < geoDetailLevel &&
+		geo.RectIntersects(minLon, minLat, maxLon, maxLat, sminLon, sminLat, smaxLon, smaxLat) {
+		return ComputeGeoRange(start, res-1, sminLon, sminLat, smaxLon, smaxLat)
+	}
+	return nil, nil
+}
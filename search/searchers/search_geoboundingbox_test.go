@@ -0,0 +1,180 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/geo"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/store/gtreap"
+	"github.com/blevesearch/bleve/index/upside_down"
+	"github.com/blevesearch/bleve/search"
+)
+
+// a grid of points, one every 5 degrees
+func setupGeo(t *testing.T) (index.Index, map[string][]float64) {
+	analysisQueue := index.NewAnalysisQueue(1)
+	i, err := upside_down.NewUpsideDownCouch(gtreap.Name, nil, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = i.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := make(map[string][]float64)
+	n := 0
+	for lon := -180.0; lon <= 180.0; lon += 5 {
+		for lat := -90.0; lat <= 90.0; lat += 5 {
+			id := strconv.Itoa(n)
+			n++
+			points[id] = []float64{lon, lat}
+			doc := document.NewDocument(id)
+			doc.AddField(document.NewGeoPointField("loc", []uint64{}, lon, lat))
+			err = i.Update(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return i, points
+}
+
+func collectIDs(t *testing.T, s search.Searcher) []string {
+	var rv []string
+	next, err := s.Next()
+	for err == nil && next != nil {
+		rv = append(rv, next.ID)
+		next, err = s.Next()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+func TestGeoBoundingBox(t *testing.T) {
+	i, points := setupGeo(t)
+	defer func() {
+		err := i.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	indexReader, err := i.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := indexReader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tests := []struct {
+		minLon, minLat, maxLon, maxLat float64
+	}{
+		{-12.5, -12.5, 12.5, 12.5},
+		{2, 43, 13, 49},
+		{-180, -90, 180, 90},
+		{0.5, 0.5, 1.5, 1.5},
+		// crosses the date line
+		{170, -20, -170, 20},
+	}
+
+	for _, test := range tests {
+		var expected []string
+		for id, p := range points {
+			inside := false
+			if test.minLon > test.maxLon {
+				inside = geo.BoundingBoxContains(p[0], p[1], test.minLon, test.minLat, 180, test.maxLat) ||
+					geo.BoundingBoxContains(p[0], p[1], -180, test.minLat, test.maxLon, test.maxLat)
+			} else {
+				inside = geo.BoundingBoxContains(p[0], p[1], test.minLon, test.minLat, test.maxLon, test.maxLat)
+			}
+			if inside {
+				expected = append(expected, id)
+			}
+		}
+		sort.Strings(expected)
+
+		searcher, err := NewGeoBoundingBoxSearcher(indexReader, test.minLon, test.minLat, test.maxLon, test.maxLat, "loc", 1.0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := collectIDs(t, searcher)
+		err = searcher.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("box %v: expected %v, got %v", test, expected, got)
+		}
+	}
+}
+
+func TestGeoPointDistance(t *testing.T) {
+	i, points := setupGeo(t)
+	defer func() {
+		err := i.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	indexReader, err := i.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := indexReader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tests := []struct {
+		lon, lat, dist float64
+	}{
+		{0, 0, 1000000},
+		{2.35, 48.85, 600000},
+		{179, 0, 1500000},
+		{1, 1, 1000},
+	}
+
+	for _, test := range tests {
+		var expected []string
+		for id, p := range points {
+			if geo.Haversin(test.lon, test.lat, p[0], p[1]) <= test.dist {
+				expected = append(expected, id)
+			}
+		}
+		sort.Strings(expected)
+
+		searcher, err := NewGeoPointDistanceSearcher(indexReader, test.lon, test.lat, test.dist, "loc", 1.0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := collectIDs(t, searcher)
+		err = searcher.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("distance %v: expected %v, got %v", test, expected, got)
+		}
+	}
+}
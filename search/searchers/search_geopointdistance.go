@@ -0,0 +1,42 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"github.com/blevesearch/bleve/geo"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search/scorers"
+)
+
+// NewGeoPointDistanceSearcher builds a searcher matching the documents
+// with a geo point in field at most dist meters away from the center.
+// Candidates are found with the bounding box of the circle, then
+// checked against the actual distance.
+func NewGeoPointDistanceSearcher(indexReader index.IndexReader, centerLon, centerLat, dist float64, field string, boost float64, explain bool) (*FilteringSearcher, error) {
+	return NewGeoPointDistanceSearcherWithSimilarity(indexReader, centerLon, centerLat, dist, field, boost, nil, explain)
+}
+
+// NewGeoPointDistanceSearcherWithSimilarity works like NewGeoPointDistanceSearcher, scoring the
+// terms it matches with similarity, or with TF-IDF when it is nil.
+func NewGeoPointDistanceSearcherWithSimilarity(indexReader index.IndexReader, centerLon, centerLat, dist float64, field string, boost float64, similarity scorers.Similarity, explain bool) (*FilteringSearcher, error) {
+	topLeftLon, topLeftLat, bottomRightLon, bottomRightLat, err := geo.RectFromPointDistance(centerLon, centerLat, dist)
+	if err != nil {
+		return nil, err
+	}
+
+	boxSearcher, err := NewGeoBoundingBoxSearcherWithSimilarity(indexReader, topLeftLon, bottomRightLat, bottomRightLon, topLeftLat, field, boost, similarity, explain)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFilteringSearcher(boxSearcher, buildGeoPointFilter(indexReader, field, func(lon, lat float64) bool {
+		return geo.Haversin(centerLon, centerLat, lon, lat) <= dist
+	})), nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/numeric_util"
 	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/scorers"
 )
 
 type NumericRangeSearcher struct {
@@ -28,6 +29,12 @@ type NumericRangeSearcher struct {
 }
 
 func NewNumericRangeSearcher(indexReader index.IndexReader, min *float64, max *float64, inclusiveMin, inclusiveMax *bool, field string, boost float64, explain bool) (*NumericRangeSearcher, error) {
+	return NewNumericRangeSearcherWithSimilarity(indexReader, min, max, inclusiveMin, inclusiveMax, field, boost, nil, explain)
+}
+
+// NewNumericRangeSearcherWithSimilarity works like NewNumericRangeSearcher, scoring the
+// terms it matches with similarity, or with TF-IDF when it is nil.
+func NewNumericRangeSearcherWithSimilarity(indexReader index.IndexReader, min *float64, max *float64, inclusiveMin, inclusiveMax *bool, field string, boost float64, similarity scorers.Similarity, explain bool) (*NumericRangeSearcher, error) {
 	// account for unbounded edges
 	if min == nil {
 		negInf := math.Inf(-1)
@@ -64,7 +71,7 @@ func NewNumericRangeSearcher(indexReader index.IndexReader, min *float64, max *f
 	qsearchers := make([]search.Searcher, len(terms))
 	for i, term := range terms {
 		var err error
-		qsearchers[i], err = NewTermSearcher(indexReader, string(term), field, boost, explain)
+		qsearchers[i], err = NewTermSearcherWithSimilarity(indexReader, string(term), field, boost, similarity, explain)
 		if err != nil {
 			return nil, err
 		}
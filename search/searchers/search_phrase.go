@@ -134,7 +134,13 @@ func (s *PhraseSearcher) Next() (*search.DocumentMatch, error) {
 					}
 					// if we got here all the terms matched
 					freq++
-					search.MergeTermLocationMaps(rvtlm, crvtlm)
+					// keep the locations of every occurrence
+					// of the phrase, not just the last one
+					for term, locations := range crvtlm {
+						for _, location := range locations {
+							rvtlm.AddLocation(term, location)
+						}
+					}
 					rvftlm[field] = rvtlm
 				}
 			}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/scorers"
 )
 
 type RegexpSearcher struct {
@@ -25,6 +26,12 @@ type RegexpSearcher struct {
 }
 
 func NewRegexpSearcher(indexReader index.IndexReader, pattern *regexp.Regexp, field string, boost float64, explain bool) (*RegexpSearcher, error) {
+	return NewRegexpSearcherWithSimilarity(indexReader, pattern, field, boost, nil, explain)
+}
+
+// NewRegexpSearcherWithSimilarity works like NewRegexpSearcher, scoring the
+// terms it matches with similarity, or with TF-IDF when it is nil.
+func NewRegexpSearcherWithSimilarity(indexReader index.IndexReader, pattern *regexp.Regexp, field string, boost float64, similarity scorers.Similarity, explain bool) (*RegexpSearcher, error) {
 
 	prefixTerm, complete := pattern.LiteralPrefix()
 	var candidateTerms []string
@@ -43,7 +50,7 @@ func NewRegexpSearcher(indexReader index.IndexReader, pattern *regexp.Regexp, fi
 	qsearchers := make([]search.Searcher, 0, len(candidateTerms))
 
 	for _, cterm := range candidateTerms {
-		qsearcher, err := NewTermSearcher(indexReader, cterm, field, boost, explain)
+		qsearcher, err := NewTermSearcherWithSimilarity(indexReader, cterm, field, boost, similarity, explain)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,145 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"math"
+	"sort"
+
+	"github.com/blevesearch/bleve/search"
+)
+
+// A Span is a range of term positions [Start, End) matched in a single
+// array element of a field. Terms holds the locations of the terms
+// making up the span.
+type Span struct {
+	Field          string
+	ArrayPositions []float64
+	Start          int
+	End            int
+	Terms          search.TermLocationMap
+}
+
+// Len returns the number of positions covered by the span.
+func (s *Span) Len() int {
+	return s.End - s.Start
+}
+
+// SameArrayElement returns true if both spans are in the same array
+// element of the same field.
+func (s *Span) SameArrayElement(other *Span) bool {
+	if s.Field != other.Field || len(s.ArrayPositions) != len(other.ArrayPositions) {
+		return false
+	}
+	for i, elem := range s.ArrayPositions {
+		if other.ArrayPositions[i] != elem {
+			return false
+		}
+	}
+	return true
+}
+
+// Overlaps returns true if both spans share at least one position.
+func (s *Span) Overlaps(other *Span) bool {
+	return s.SameArrayElement(other) && s.Start < other.End && other.Start < s.End
+}
+
+type spanList []*Span
+
+func (l spanList) Len() int      { return len(l) }
+func (l spanList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l spanList) Less(i, j int) bool {
+	if l[i].Start != l[j].Start {
+		return l[i].Start < l[j].Start
+	}
+	return l[i].End < l[j].End
+}
+
+// A SpanMatch is a document matched by a SpanSearcher along with the
+// spans matched in it, ordered by start position.
+type SpanMatch struct {
+	Match *search.DocumentMatch
+	Spans []*Span
+}
+
+// A SpanSearcher is a Searcher which also reports the spans matched in
+// each document, so that span searchers can be composed into more
+// complex positional queries. Documents without any span are never
+// returned.
+type SpanSearcher interface {
+	search.Searcher
+	NextSpans() (*SpanMatch, error)
+	AdvanceSpans(ID string) (*SpanMatch, error)
+}
+
+// spanDocumentMatch returns the document match for a span match, with
+// its locations restricted to the terms making up its spans.
+func spanDocumentMatch(sm *SpanMatch, err error) (*search.DocumentMatch, error) {
+	if err != nil || sm == nil {
+		return nil, err
+	}
+	locations := make(search.FieldTermLocationMap)
+	for _, span := range sm.Spans {
+		tlm, ok := locations[span.Field]
+		if !ok {
+			tlm = make(search.TermLocationMap)
+			locations[span.Field] = tlm
+		}
+		for term, locs := range span.Terms {
+			for _, loc := range locs {
+				if !containsLocation(tlm[term], loc) {
+					tlm.AddLocation(term, loc)
+				}
+			}
+		}
+	}
+	sm.Match.Locations = locations
+	return sm.Match, nil
+}
+
+func containsLocation(locs search.Locations, loc *search.Location) bool {
+	for _, l := range locs {
+		if l == loc || (l.Pos == loc.Pos && l.Start == loc.Start && l.SameArrayElement(loc)) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSpanTerms returns the union of the term locations of spans.
+func mergeSpanTerms(spans []*Span) search.TermLocationMap {
+	rv := make(search.TermLocationMap)
+	for _, span := range spans {
+		for term, locs := range span.Terms {
+			for _, loc := range locs {
+				rv.AddLocation(term, loc)
+			}
+		}
+	}
+	return rv
+}
+
+func sortSpans(spans []*Span) {
+	sort.Sort(spanList(spans))
+}
+
+// spanQueryNorm computes the query norm of a set of span searchers and
+// propagates it to them, the same way the ConjunctionSearcher does.
+func spanQueryNorm(searchers []SpanSearcher) float64 {
+	sumOfSquaredWeights := 0.0
+	for _, searcher := range searchers {
+		sumOfSquaredWeights += searcher.Weight()
+	}
+	queryNorm := 1.0 / math.Sqrt(sumOfSquaredWeights)
+	for _, searcher := range searchers {
+		searcher.SetQueryNorm(queryNorm)
+	}
+	return queryNorm
+}
@@ -0,0 +1,102 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"fmt"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+// SpanFirstSearcher matches the spans of its clause lying entirely
+// within the first end positions of their array element.
+type SpanFirstSearcher struct {
+	indexReader index.IndexReader
+	searcher    SpanSearcher
+	end         int
+}
+
+func NewSpanFirstSearcher(indexReader index.IndexReader, clause SpanSearcher, end int, explain bool) (*SpanFirstSearcher, error) {
+	if end < 1 {
+		return nil, fmt.Errorf("span first end must be positive, got %d", end)
+	}
+	return &SpanFirstSearcher{
+		indexReader: indexReader,
+		searcher:    clause,
+		end:         end,
+	}, nil
+}
+
+func (s *SpanFirstSearcher) Count() uint64 {
+	return s.searcher.Count()
+}
+
+func (s *SpanFirstSearcher) Weight() float64 {
+	return s.searcher.Weight()
+}
+
+func (s *SpanFirstSearcher) SetQueryNorm(qnorm float64) {
+	s.searcher.SetQueryNorm(qnorm)
+}
+
+func (s *SpanFirstSearcher) NextSpans() (*SpanMatch, error) {
+	for {
+		match, err := s.searcher.NextSpans()
+		if err != nil || match == nil {
+			return nil, err
+		}
+		if match = s.filter(match); match != nil {
+			return match, nil
+		}
+	}
+}
+
+func (s *SpanFirstSearcher) AdvanceSpans(ID string) (*SpanMatch, error) {
+	match, err := s.searcher.AdvanceSpans(ID)
+	if err != nil || match == nil {
+		return nil, err
+	}
+	if match = s.filter(match); match != nil {
+		return match, nil
+	}
+	return s.NextSpans()
+}
+
+func (s *SpanFirstSearcher) filter(match *SpanMatch) *SpanMatch {
+	spans := make([]*Span, 0, len(match.Spans))
+	for _, span := range match.Spans {
+		// positions start at 1
+		if span.End-1 <= s.end {
+			spans = append(spans, span)
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	match.Spans = spans
+	return match
+}
+
+func (s *SpanFirstSearcher) Next() (*search.DocumentMatch, error) {
+	return spanDocumentMatch(s.NextSpans())
+}
+
+func (s *SpanFirstSearcher) Advance(ID string) (*search.DocumentMatch, error) {
+	return spanDocumentMatch(s.AdvanceSpans(ID))
+}
+
+func (s *SpanFirstSearcher) Close() error {
+	return s.searcher.Close()
+}
+
+func (s *SpanFirstSearcher) Min() int {
+	return 0
+}
@@ -0,0 +1,381 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+// SpanNearSearcher matches spans made of one non-overlapping span of
+// each clause, in the same array element, separated by at most slop
+// unmatched positions in total. When inOrder is set, the clause spans
+// must also appear in the order of the clauses.
+//
+// Matches are scored as the sum of the clause scores, scaled by
+// 1/(1+s) where s is the smallest slop of the matched spans.
+type SpanNearSearcher struct {
+	initialized bool
+	indexReader index.IndexReader
+	searchers   []SpanSearcher
+	slop        int
+	inOrder     bool
+	explain     bool
+	queryNorm   float64
+	currs       []*SpanMatch
+}
+
+func NewSpanNearSearcher(indexReader index.IndexReader, clauses []SpanSearcher, slop int, inOrder bool, explain bool) (*SpanNearSearcher, error) {
+	if slop < 0 {
+		return nil, fmt.Errorf("span near slop must not be negative, got %d", slop)
+	}
+	rv := SpanNearSearcher{
+		indexReader: indexReader,
+		searchers:   clauses,
+		slop:        slop,
+		inOrder:     inOrder,
+		explain:     explain,
+		currs:       make([]*SpanMatch, len(clauses)),
+	}
+	rv.queryNorm = spanQueryNorm(clauses)
+	return &rv, nil
+}
+
+func (s *SpanNearSearcher) Count() uint64 {
+	// for now return a worst case
+	var sum uint64
+	for _, searcher := range s.searchers {
+		sum += searcher.Count()
+	}
+	return sum
+}
+
+func (s *SpanNearSearcher) Weight() float64 {
+	var rv float64
+	for _, searcher := range s.searchers {
+		rv += searcher.Weight()
+	}
+	return rv
+}
+
+func (s *SpanNearSearcher) SetQueryNorm(qnorm float64) {
+	for _, searcher := range s.searchers {
+		searcher.SetQueryNorm(qnorm)
+	}
+}
+
+func (s *SpanNearSearcher) NextSpans() (*SpanMatch, error) {
+	if len(s.searchers) == 0 {
+		return nil, nil
+	}
+	var err error
+	if !s.initialized {
+		for i, searcher := range s.searchers {
+			s.currs[i], err = searcher.NextSpans()
+			if err != nil {
+				return nil, err
+			}
+		}
+		s.initialized = true
+	}
+	for {
+		// find the furthest document any clause is positioned on
+		maxID := ""
+		for _, curr := range s.currs {
+			if curr == nil {
+				return nil, nil
+			}
+			if curr.Match.ID > maxID {
+				maxID = curr.Match.ID
+			}
+		}
+		// bring the other clauses up to it
+		allMatch := true
+		for i, curr := range s.currs {
+			if curr.Match.ID < maxID {
+				allMatch = false
+				s.currs[i], err = s.searchers[i].AdvanceSpans(maxID)
+				if err != nil {
+					return nil, err
+				}
+				if s.currs[i] == nil {
+					return nil, nil
+				}
+			}
+		}
+		if !allMatch {
+			continue
+		}
+
+		matches := make([]*SpanMatch, len(s.currs))
+		copy(matches, s.currs)
+		for i, searcher := range s.searchers {
+			s.currs[i], err = searcher.NextSpans()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		spans, minSlop := s.nearSpans(matches)
+		if len(spans) > 0 {
+			return s.spanMatch(matches, spans, minSlop), nil
+		}
+	}
+}
+
+func (s *SpanNearSearcher) AdvanceSpans(ID string) (*SpanMatch, error) {
+	var err error
+	for i, searcher := range s.searchers {
+		if !s.initialized || (s.currs[i] != nil && s.currs[i].Match.ID < ID) {
+			s.currs[i], err = searcher.AdvanceSpans(ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	s.initialized = true
+	return s.NextSpans()
+}
+
+// nearSpans returns the spans made of one span of each match satisfying
+// the slop and ordering constraints, and the smallest slop among them.
+// As in a merge, the spans of each match are only walked forward, in a
+// single pass for each array element, so that not every combination of
+// spans is found: in order, each span of the first match is combined
+// with the first following span of each further match, and otherwise
+// the current spans of all the matches are combined before moving the
+// one starting first.
+func (s *SpanNearSearcher) nearSpans(matches []*SpanMatch) ([]*Span, int) {
+	var rv []*Span
+	minSlop := -1
+	for _, elements := range spanArrayElements(matches) {
+		seen := make(map[[2]int]bool)
+		emit := func(chosen []*Span) {
+			start, end, slop := spanSlop(chosen)
+			if slop > s.slop {
+				return
+			}
+			if minSlop < 0 || slop < minSlop {
+				minSlop = slop
+			}
+			key := [2]int{start, end}
+			if seen[key] {
+				return
+			}
+			seen[key] = true
+			rv = append(rv, &Span{
+				Field:          chosen[0].Field,
+				ArrayPositions: chosen[0].ArrayPositions,
+				Start:          start,
+				End:            end,
+				Terms:          mergeSpanTerms(chosen),
+			})
+		}
+		if s.inOrder {
+			orderedNearSpans(elements, emit)
+		} else {
+			unorderedNearSpans(elements, emit)
+		}
+	}
+
+	sortSpans(rv)
+	return rv, minSlop
+}
+
+// spanArrayElements groups the spans of each match by array element,
+// keeping only the array elements found in all the matches. The spans
+// of each group are ordered by start and end positions.
+func spanArrayElements(matches []*SpanMatch) [][][]*Span {
+	var keys []string
+	groups := make(map[string][][]*Span)
+	for i, match := range matches {
+		for _, span := range match.Spans {
+			key := fmt.Sprintf("%s%v", span.Field, span.ArrayPositions)
+			group, ok := groups[key]
+			if !ok {
+				if i > 0 {
+					continue
+				}
+				group = make([][]*Span, len(matches))
+				groups[key] = group
+				keys = append(keys, key)
+			}
+			group[i] = append(group[i], span)
+		}
+	}
+	rv := make([][][]*Span, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		complete := true
+		for _, spans := range group {
+			if len(spans) == 0 {
+				complete = false
+				break
+			}
+			sortSpans(spans)
+		}
+		if complete {
+			rv = append(rv, group)
+		}
+	}
+	return rv
+}
+
+// orderedNearSpans combines each span of the first list with the first
+// span of each further list starting after the end of the span chosen
+// in the previous list.
+func orderedNearSpans(lists [][]*Span, emit func([]*Span)) {
+	positions := make([]int, len(lists))
+	chosen := make([]*Span, len(lists))
+	for _, first := range lists[0] {
+		chosen[0] = first
+		for i := 1; i < len(lists); i++ {
+			for positions[i] < len(lists[i]) && lists[i][positions[i]].Start < chosen[i-1].End {
+				positions[i]++
+			}
+			if positions[i] == len(lists[i]) {
+				return
+			}
+			chosen[i] = lists[i][positions[i]]
+		}
+		emit(chosen)
+	}
+}
+
+// unorderedNearSpans combines the current spans of all the lists when
+// they do not overlap, then moves forward the list whose current span
+// starts first. When two current spans overlap, the one starting last
+// is moved forward instead, or the other one if it is the last of its
+// list. It stops once a list to move forward is exhausted.
+func unorderedNearSpans(lists [][]*Span, emit func([]*Span)) {
+	positions := make([]int, len(lists))
+	chosen := make([]*Span, len(lists))
+	order := make([]int, len(lists))
+	for {
+		for i, list := range lists {
+			chosen[i] = list[positions[i]]
+			order[i] = i
+		}
+		sort.Sort(&spanOrder{spans: chosen, order: order})
+		next := order[0]
+		overlap := false
+		for i := 1; i < len(order) && !overlap; i++ {
+			if chosen[order[i]].Start < chosen[order[i-1]].End {
+				overlap = true
+				next = order[i]
+				if positions[next] == len(lists[next])-1 {
+					next = order[i-1]
+				}
+			}
+		}
+		if !overlap {
+			emit(chosen)
+		}
+		positions[next]++
+		if positions[next] == len(lists[next]) {
+			return
+		}
+	}
+}
+
+// spanOrder sorts the indexes of spans by start and end positions of
+// the spans, then by index.
+type spanOrder struct {
+	spans []*Span
+	order []int
+}
+
+func (o *spanOrder) Len() int      { return len(o.order) }
+func (o *spanOrder) Swap(i, j int) { o.order[i], o.order[j] = o.order[j], o.order[i] }
+func (o *spanOrder) Less(i, j int) bool {
+	a, b := o.order[i], o.order[j]
+	if spanList(o.spans).Less(a, b) {
+		return true
+	}
+	if spanList(o.spans).Less(b, a) {
+		return false
+	}
+	return a < b
+}
+
+// spanSlop returns the extent of spans and the number of positions
+// within it not covered by any of them.
+func spanSlop(spans []*Span) (start, end, slop int) {
+	start, end = spans[0].Start, spans[0].End
+	covered := 0
+	for _, span := range spans {
+		if span.Start < start {
+			start = span.Start
+		}
+		if span.End > end {
+			end = span.End
+		}
+		covered += span.Len()
+	}
+	return start, end, end - start - covered
+}
+
+func (s *SpanNearSearcher) spanMatch(matches []*SpanMatch, spans []*Span, minSlop int) *SpanMatch {
+	sum := 0.0
+	var childrenExplanations []*search.Explanation
+	if s.explain {
+		childrenExplanations = make([]*search.Explanation, len(matches))
+	}
+	for i, match := range matches {
+		sum += match.Match.Score
+		if s.explain {
+			childrenExplanations[i] = match.Match.Expl
+		}
+	}
+	proximity := 1.0 / float64(1+minSlop)
+	rv := &search.DocumentMatch{
+		ID:    matches[0].Match.ID,
+		Score: sum * proximity,
+	}
+	if s.explain {
+		rv.Expl = &search.Explanation{
+			Value:   rv.Score,
+			Message: "product of:",
+			Children: []*search.Explanation{
+				{Value: sum, Message: "sum of:", Children: childrenExplanations},
+				{Value: proximity, Message: fmt.Sprintf("proximity(slop=%d)", minSlop)},
+			},
+		}
+	}
+	return &SpanMatch{
+		Match: rv,
+		Spans: spans,
+	}
+}
+
+func (s *SpanNearSearcher) Next() (*search.DocumentMatch, error) {
+	return spanDocumentMatch(s.NextSpans())
+}
+
+func (s *SpanNearSearcher) Advance(ID string) (*search.DocumentMatch, error) {
+	return spanDocumentMatch(s.AdvanceSpans(ID))
+}
+
+func (s *SpanNearSearcher) Close() error {
+	for _, searcher := range s.searchers {
+		err := searcher.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SpanNearSearcher) Min() int {
+	return 0
+}
@@ -0,0 +1,121 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+// SpanNotSearcher matches the spans of include which do not overlap any
+// span of exclude. Only include contributes to the score.
+type SpanNotSearcher struct {
+	initialized bool
+	indexReader index.IndexReader
+	include     SpanSearcher
+	exclude     SpanSearcher
+	excludeCurr *SpanMatch
+}
+
+func NewSpanNotSearcher(indexReader index.IndexReader, include SpanSearcher, exclude SpanSearcher, explain bool) (*SpanNotSearcher, error) {
+	return &SpanNotSearcher{
+		indexReader: indexReader,
+		include:     include,
+		exclude:     exclude,
+	}, nil
+}
+
+func (s *SpanNotSearcher) Count() uint64 {
+	return s.include.Count()
+}
+
+func (s *SpanNotSearcher) Weight() float64 {
+	return s.include.Weight()
+}
+
+func (s *SpanNotSearcher) SetQueryNorm(qnorm float64) {
+	s.include.SetQueryNorm(qnorm)
+}
+
+func (s *SpanNotSearcher) NextSpans() (*SpanMatch, error) {
+	for {
+		match, err := s.include.NextSpans()
+		if err != nil || match == nil {
+			return nil, err
+		}
+		match, err = s.filter(match)
+		if err != nil || match != nil {
+			return match, err
+		}
+	}
+}
+
+func (s *SpanNotSearcher) AdvanceSpans(ID string) (*SpanMatch, error) {
+	match, err := s.include.AdvanceSpans(ID)
+	if err != nil || match == nil {
+		return nil, err
+	}
+	match, err = s.filter(match)
+	if err != nil || match != nil {
+		return match, err
+	}
+	return s.NextSpans()
+}
+
+// filter drops the spans of match overlapping an excluded span, and
+// returns nil if none remain.
+func (s *SpanNotSearcher) filter(match *SpanMatch) (*SpanMatch, error) {
+	var err error
+	if !s.initialized || (s.excludeCurr != nil && s.excludeCurr.Match.ID < match.Match.ID) {
+		s.excludeCurr, err = s.exclude.AdvanceSpans(match.Match.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.initialized = true
+	}
+	if s.excludeCurr == nil || s.excludeCurr.Match.ID != match.Match.ID {
+		return match, nil
+	}
+	spans := make([]*Span, 0, len(match.Spans))
+SPANS:
+	for _, span := range match.Spans {
+		for _, excluded := range s.excludeCurr.Spans {
+			if span.Overlaps(excluded) {
+				continue SPANS
+			}
+		}
+		spans = append(spans, span)
+	}
+	if len(spans) == 0 {
+		return nil, nil
+	}
+	match.Spans = spans
+	return match, nil
+}
+
+func (s *SpanNotSearcher) Next() (*search.DocumentMatch, error) {
+	return spanDocumentMatch(s.NextSpans())
+}
+
+func (s *SpanNotSearcher) Advance(ID string) (*search.DocumentMatch, error) {
+	return spanDocumentMatch(s.AdvanceSpans(ID))
+}
+
+func (s *SpanNotSearcher) Close() error {
+	err := s.include.Close()
+	if err != nil {
+		return err
+	}
+	return s.exclude.Close()
+}
+
+func (s *SpanNotSearcher) Min() int {
+	return 0
+}
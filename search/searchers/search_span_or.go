@@ -0,0 +1,151 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+// SpanOrSearcher matches the spans of any of its clauses. Matches are
+// scored as the sum of the scores of the matching clauses.
+type SpanOrSearcher struct {
+	initialized bool
+	indexReader index.IndexReader
+	searchers   []SpanSearcher
+	explain     bool
+	queryNorm   float64
+	currs       []*SpanMatch
+}
+
+func NewSpanOrSearcher(indexReader index.IndexReader, clauses []SpanSearcher, explain bool) (*SpanOrSearcher, error) {
+	rv := SpanOrSearcher{
+		indexReader: indexReader,
+		searchers:   clauses,
+		explain:     explain,
+		currs:       make([]*SpanMatch, len(clauses)),
+	}
+	rv.queryNorm = spanQueryNorm(clauses)
+	return &rv, nil
+}
+
+func (s *SpanOrSearcher) Count() uint64 {
+	var sum uint64
+	for _, searcher := range s.searchers {
+		sum += searcher.Count()
+	}
+	return sum
+}
+
+func (s *SpanOrSearcher) Weight() float64 {
+	var rv float64
+	for _, searcher := range s.searchers {
+		rv += searcher.Weight()
+	}
+	return rv
+}
+
+func (s *SpanOrSearcher) SetQueryNorm(qnorm float64) {
+	for _, searcher := range s.searchers {
+		searcher.SetQueryNorm(qnorm)
+	}
+}
+
+func (s *SpanOrSearcher) NextSpans() (*SpanMatch, error) {
+	var err error
+	if !s.initialized {
+		for i, searcher := range s.searchers {
+			s.currs[i], err = searcher.NextSpans()
+			if err != nil {
+				return nil, err
+			}
+		}
+		s.initialized = true
+	}
+
+	// find the nearest document any clause is positioned on
+	var minID string
+	found := false
+	for _, curr := range s.currs {
+		if curr != nil && (!found || curr.Match.ID < minID) {
+			minID = curr.Match.ID
+			found = true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	rv := &SpanMatch{
+		Match: &search.DocumentMatch{
+			ID: minID,
+		},
+	}
+	var childrenExplanations []*search.Explanation
+	for i, curr := range s.currs {
+		if curr == nil || curr.Match.ID != minID {
+			continue
+		}
+		rv.Match.Score += curr.Match.Score
+		if s.explain {
+			childrenExplanations = append(childrenExplanations, curr.Match.Expl)
+		}
+		rv.Spans = append(rv.Spans, curr.Spans...)
+		s.currs[i], err = s.searchers[i].NextSpans()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if s.explain {
+		rv.Match.Expl = &search.Explanation{
+			Value:    rv.Match.Score,
+			Message:  "sum of:",
+			Children: childrenExplanations,
+		}
+	}
+	sortSpans(rv.Spans)
+	return rv, nil
+}
+
+func (s *SpanOrSearcher) AdvanceSpans(ID string) (*SpanMatch, error) {
+	var err error
+	for i, searcher := range s.searchers {
+		if !s.initialized || (s.currs[i] != nil && s.currs[i].Match.ID < ID) {
+			s.currs[i], err = searcher.AdvanceSpans(ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	s.initialized = true
+	return s.NextSpans()
+}
+
+func (s *SpanOrSearcher) Next() (*search.DocumentMatch, error) {
+	return spanDocumentMatch(s.NextSpans())
+}
+
+func (s *SpanOrSearcher) Advance(ID string) (*search.DocumentMatch, error) {
+	return spanDocumentMatch(s.AdvanceSpans(ID))
+}
+
+func (s *SpanOrSearcher) Close() error {
+	for _, searcher := range s.searchers {
+		err := searcher.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SpanOrSearcher) Min() int {
+	return 0
+}
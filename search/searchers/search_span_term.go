@@ -0,0 +1,110 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/scorers"
+)
+
+// SpanTermSearcher matches a one position span for every occurrence of
+// a term. Occurrences are read from the term vectors, so documents
+// indexed without them never match.
+type SpanTermSearcher struct {
+	searcher *TermSearcher
+}
+
+func NewSpanTermSearcher(indexReader index.IndexReader, term string, field string, boost float64, similarity scorers.Similarity, explain bool) (*SpanTermSearcher, error) {
+	searcher, err := NewTermSearcherWithSimilarity(indexReader, term, field, boost, similarity, explain)
+	if err != nil {
+		return nil, err
+	}
+	return &SpanTermSearcher{
+		searcher: searcher,
+	}, nil
+}
+
+func (s *SpanTermSearcher) Count() uint64 {
+	return s.searcher.Count()
+}
+
+func (s *SpanTermSearcher) Weight() float64 {
+	return s.searcher.Weight()
+}
+
+func (s *SpanTermSearcher) SetQueryNorm(qnorm float64) {
+	s.searcher.SetQueryNorm(qnorm)
+}
+
+func (s *SpanTermSearcher) NextSpans() (*SpanMatch, error) {
+	for {
+		match, err := s.searcher.Next()
+		if err != nil || match == nil {
+			return nil, err
+		}
+		if rv := s.spanMatch(match); rv != nil {
+			return rv, nil
+		}
+	}
+}
+
+func (s *SpanTermSearcher) AdvanceSpans(ID string) (*SpanMatch, error) {
+	match, err := s.searcher.Advance(ID)
+	if err != nil || match == nil {
+		return nil, err
+	}
+	if rv := s.spanMatch(match); rv != nil {
+		return rv, nil
+	}
+	return s.NextSpans()
+}
+
+func (s *SpanTermSearcher) spanMatch(match *search.DocumentMatch) *SpanMatch {
+	var spans []*Span
+	for field, tlm := range match.Locations {
+		for _, loc := range tlm[s.searcher.term] {
+			start := int(loc.Pos)
+			spans = append(spans, &Span{
+				Field:          field,
+				ArrayPositions: loc.ArrayPositions,
+				Start:          start,
+				End:            start + 1,
+				Terms: search.TermLocationMap{
+					s.searcher.term: search.Locations{loc},
+				},
+			})
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	sortSpans(spans)
+	return &SpanMatch{
+		Match: match,
+		Spans: spans,
+	}
+}
+
+func (s *SpanTermSearcher) Next() (*search.DocumentMatch, error) {
+	return spanDocumentMatch(s.NextSpans())
+}
+
+func (s *SpanTermSearcher) Advance(ID string) (*search.DocumentMatch, error) {
+	return spanDocumentMatch(s.AdvanceSpans(ID))
+}
+
+func (s *SpanTermSearcher) Close() error {
+	return s.searcher.Close()
+}
+
+func (s *SpanTermSearcher) Min() int {
+	return 0
+}
@@ -0,0 +1,271 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpanSearchers(t *testing.T) {
+
+	twoDocIndexReader, err := twoDocIndex.Reader()
+	if err != nil {
+		t.Error(err)
+	}
+	defer func() {
+		err := twoDocIndexReader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	term := func(term string) SpanSearcher {
+		rv, err := NewSpanTermSearcher(twoDocIndexReader, term, "desc", 1.0, nil, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rv
+	}
+	near := func(slop int, inOrder bool, clauses ...SpanSearcher) SpanSearcher {
+		rv, err := NewSpanNearSearcher(twoDocIndexReader, clauses, slop, inOrder, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rv
+	}
+	or := func(clauses ...SpanSearcher) SpanSearcher {
+		rv, err := NewSpanOrSearcher(twoDocIndexReader, clauses, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rv
+	}
+	not := func(include, exclude SpanSearcher) SpanSearcher {
+		rv, err := NewSpanNotSearcher(twoDocIndexReader, include, exclude, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rv
+	}
+	first := func(clause SpanSearcher, end int) SpanSearcher {
+		rv, err := NewSpanFirstSearcher(twoDocIndexReader, clause, end, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rv
+	}
+
+	tests := []struct {
+		searcher SpanSearcher
+		ids      []string
+		// spans of the first match, as start/end pairs
+		spans [][2]int
+	}{
+		{
+			searcher: term("couch"),
+			ids:      []string{"2"},
+			spans:    [][2]int{{3, 4}},
+		},
+		{
+			searcher: near(1, true, term("angst"), term("couch")),
+			ids:      []string{"2"},
+			spans:    [][2]int{{1, 4}},
+		},
+		{
+			searcher: near(0, true, term("angst"), term("couch")),
+			ids:      nil,
+		},
+		{
+			searcher: near(1, true, term("couch"), term("angst")),
+			ids:      nil,
+		},
+		{
+			searcher: near(1, false, term("couch"), term("angst")),
+			ids:      []string{"2"},
+			spans:    [][2]int{{1, 4}},
+		},
+		{
+			searcher: near(0, true, term("beer"), term("beer")),
+			ids:      []string{"1", "4"},
+			spans:    [][2]int{{1, 3}, {2, 4}, {3, 5}},
+		},
+		{
+			searcher: near(0, true, near(0, true, term("angst"), term("beer")), term("couch")),
+			ids:      []string{"2"},
+			spans:    [][2]int{{1, 4}},
+		},
+		{
+			searcher: or(term("angst"), term("apple")),
+			ids:      []string{"2", "3"},
+			spans:    [][2]int{{1, 2}},
+		},
+		{
+			searcher: or(term("couch"), term("angst")),
+			ids:      []string{"2"},
+			spans:    [][2]int{{1, 2}, {3, 4}},
+		},
+		{
+			searcher: not(term("beer"), near(0, true, term("beer"), term("couch"))),
+			ids:      []string{"1", "3", "4"},
+		},
+		{
+			searcher: not(near(0, true, term("beer"), term("beer")), term("angst")),
+			ids:      []string{"1", "4"},
+		},
+		{
+			searcher: first(term("beer"), 1),
+			ids:      []string{"1", "4"},
+			spans:    [][2]int{{1, 2}},
+		},
+		{
+			searcher: first(term("beer"), 2),
+			ids:      []string{"1", "2", "3", "4"},
+			spans:    [][2]int{{1, 2}, {2, 3}},
+		},
+		{
+			searcher: first(near(0, true, term("beer"), term("couch")), 2),
+			ids:      nil,
+		},
+	}
+
+	for testIndex, test := range tests {
+		var ids []string
+		next, err := test.searcher.NextSpans()
+		for err == nil && next != nil {
+			if len(ids) == 0 && test.spans != nil {
+				spans := make([][2]int, len(next.Spans))
+				for i, span := range next.Spans {
+					spans[i] = [2]int{span.Start, span.End}
+				}
+				if !reflect.DeepEqual(spans, test.spans) {
+					t.Errorf("expected spans %v, got %v for test %d", test.spans, spans, testIndex)
+				}
+			}
+			ids = append(ids, next.Match.ID)
+			next, err = test.searcher.NextSpans()
+		}
+		if err != nil {
+			t.Fatalf("error iterating searcher: %v for test %d", err, testIndex)
+		}
+		if !reflect.DeepEqual(ids, test.ids) {
+			t.Errorf("expected ids %v, got %v for test %d", test.ids, ids, testIndex)
+		}
+		err = test.searcher.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSpanNearSearcherAdvance(t *testing.T) {
+
+	twoDocIndexReader, err := twoDocIndex.Reader()
+	if err != nil {
+		t.Error(err)
+	}
+	defer func() {
+		err := twoDocIndexReader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	clauses := make([]SpanSearcher, 2)
+	for i := range clauses {
+		clauses[i], err = NewSpanTermSearcher(twoDocIndexReader, "beer", "desc", 1.0, nil, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	searcher, err := NewSpanNearSearcher(twoDocIndexReader, clauses, 0, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := searcher.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	match, err := searcher.Advance("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match == nil || match.ID != "4" {
+		t.Fatalf("expected to advance to doc 4, got %v", match)
+	}
+	if match.Score <= 0 {
+		t.Errorf("expected positive score, got %f", match.Score)
+	}
+	if len(match.Locations["desc"]["beer"]) != 65 {
+		t.Errorf("expected 65 beer locations, got %d", len(match.Locations["desc"]["beer"]))
+	}
+	if match.Expl == nil {
+		t.Errorf("expected explanation")
+	}
+}
+
+func TestSpanNearSearcherRepeatedTerms(t *testing.T) {
+
+	twoDocIndexReader, err := twoDocIndex.Reader()
+	if err != nil {
+		t.Error(err)
+	}
+	defer func() {
+		err := twoDocIndexReader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// document 4 holds 65 consecutive beers, combining the spans of
+	// each clause would not end
+	for _, inOrder := range []bool{true, false} {
+		clauses := make([]SpanSearcher, 8)
+		for i := range clauses {
+			clauses[i], err = NewSpanTermSearcher(twoDocIndexReader, "beer", "desc", 1.0, nil, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		searcher, err := NewSpanNearSearcher(twoDocIndexReader, clauses, 0, inOrder, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ids []string
+		next, err := searcher.NextSpans()
+		for err == nil && next != nil {
+			ids = append(ids, next.Match.ID)
+			if len(next.Spans) != 58 {
+				t.Errorf("expected 58 spans in order %t, got %d", inOrder, len(next.Spans))
+			}
+			for i, span := range next.Spans {
+				if span.Start != i+1 || span.End != i+9 {
+					t.Errorf("expected span %d-%d in order %t, got %d-%d", i+1, i+9, inOrder, span.Start, span.End)
+					break
+				}
+			}
+			next, err = searcher.NextSpans()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ids, []string{"4"}) {
+			t.Errorf("expected ids [4] in order %t, got %v", inOrder, ids)
+		}
+		err = searcher.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -21,15 +21,28 @@ type TermSearcher struct {
 	field       string
 	explain     bool
 	reader      index.TermFieldReader
-	scorer      *scorers.TermQueryScorer
+	scorer      scorers.TermScorer
 }
 
 func NewTermSearcher(indexReader index.IndexReader, term string, field string, boost float64, explain bool) (*TermSearcher, error) {
+	return NewTermSearcherWithSimilarity(indexReader, term, field, boost, nil, explain)
+}
+
+// NewTermSearcherWithSimilarity returns a TermSearcher scoring documents
+// with similarity, or with TF-IDF when it is nil.
+func NewTermSearcherWithSimilarity(indexReader index.IndexReader, term string, field string, boost float64, similarity scorers.Similarity, explain bool) (*TermSearcher, error) {
+	if similarity == nil {
+		similarity = scorers.NewTFIDFSimilarity()
+	}
 	reader, err := indexReader.TermFieldReader([]byte(term), field)
 	if err != nil {
 		return nil, err
 	}
-	scorer := scorers.NewTermQueryScorer(term, field, boost, indexReader.DocCount(), reader.Count(), explain)
+	scorer, err := similarity.TermScorer(indexReader, term, field, boost, reader.Count(), explain)
+	if err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
 	return &TermSearcher{
 		indexReader: indexReader,
 		term:        term,
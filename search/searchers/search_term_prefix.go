@@ -12,6 +12,7 @@ package searchers
 import (
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/scorers"
 )
 
 type TermPrefixSearcher struct {
@@ -23,6 +24,12 @@ type TermPrefixSearcher struct {
 }
 
 func NewTermPrefixSearcher(indexReader index.IndexReader, prefix string, field string, boost float64, explain bool) (*TermPrefixSearcher, error) {
+	return NewTermPrefixSearcherWithSimilarity(indexReader, prefix, field, boost, nil, explain)
+}
+
+// NewTermPrefixSearcherWithSimilarity works like NewTermPrefixSearcher, scoring the
+// terms it matches with similarity, or with TF-IDF when it is nil.
+func NewTermPrefixSearcherWithSimilarity(indexReader index.IndexReader, prefix string, field string, boost float64, similarity scorers.Similarity, explain bool) (*TermPrefixSearcher, error) {
 	// find the terms with this prefix
 	fieldDict, err := indexReader.FieldDictPrefix(field, []byte(prefix))
 
@@ -31,7 +38,7 @@ func NewTermPrefixSearcher(indexReader index.IndexReader, prefix string, field s
 	tfd, err := fieldDict.Next()
 	for err == nil && tfd != nil {
 		var qsearcher *TermSearcher
-		qsearcher, err = NewTermSearcher(indexReader, string(tfd.Term), field, 1.0, explain)
+		qsearcher, err = NewTermSearcherWithSimilarity(indexReader, string(tfd.Term), field, 1.0, similarity, explain)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,134 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/scorers"
+)
+
+type TermRangeSearcher struct {
+	indexReader index.IndexReader
+	min         *string
+	max         *string
+	field       string
+	explain     bool
+	searcher    *DisjunctionSearcher
+}
+
+// NewTermRangeSearcher builds a searcher matching the documents
+// with a term of field lexicographically between min and max.
+// An unbounded edge is nil. The minimum is inclusive and the
+// maximum exclusive unless inclusiveMin and inclusiveMax say
+// otherwise.
+func NewTermRangeSearcher(indexReader index.IndexReader, min *string, max *string, inclusiveMin, inclusiveMax *bool, field string, boost float64, explain bool) (*TermRangeSearcher, error) {
+	return NewTermRangeSearcherWithSimilarity(indexReader, min, max, inclusiveMin, inclusiveMax, field, boost, nil, explain)
+}
+
+// NewTermRangeSearcherWithSimilarity works like NewTermRangeSearcher, scoring the
+// terms it matches with similarity, or with TF-IDF when it is nil.
+func NewTermRangeSearcherWithSimilarity(indexReader index.IndexReader, min *string, max *string, inclusiveMin, inclusiveMax *bool, field string, boost float64, similarity scorers.Similarity, explain bool) (*TermRangeSearcher, error) {
+	if inclusiveMin == nil {
+		defaultInclusiveMin := true
+		inclusiveMin = &defaultInclusiveMin
+	}
+	if inclusiveMax == nil {
+		defaultInclusiveMax := false
+		inclusiveMax = &defaultInclusiveMax
+	}
+
+	var minTerm, maxTerm []byte
+	if min != nil {
+		minTerm = []byte(*min)
+	}
+	if max != nil {
+		maxTerm = []byte(*max)
+	}
+	fieldDict, err := indexReader.FieldDictRange(field, minTerm, maxTerm)
+	if err != nil {
+		return nil, err
+	}
+
+	// enumerate all the terms in the range, the dictionary
+	// range also holding the terms prefixed by the maximum
+	var terms []string
+	tfd, err := fieldDict.Next()
+	for err == nil && tfd != nil {
+		term := tfd.Term
+		if max != nil && (term > *max || (term == *max && !*inclusiveMax)) {
+			break
+		}
+		if min == nil || term != *min || *inclusiveMin {
+			terms = append(terms, term)
+		}
+		tfd, err = fieldDict.Next()
+	}
+	cerr := fieldDict.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tooManyClauses(len(terms)) {
+		return nil, tooManyClausesErr()
+	}
+
+	qsearchers := make([]search.Searcher, len(terms))
+	for i, term := range terms {
+		qsearchers[i], err = NewTermSearcherWithSimilarity(indexReader, term, field, boost, similarity, explain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// build disjunction searcher of these terms
+	searcher, err := NewDisjunctionSearcher(indexReader, qsearchers, 0, explain)
+	if err != nil {
+		return nil, err
+	}
+	return &TermRangeSearcher{
+		indexReader: indexReader,
+		min:         min,
+		max:         max,
+		field:       field,
+		explain:     explain,
+		searcher:    searcher,
+	}, nil
+}
+
+func (s *TermRangeSearcher) Count() uint64 {
+	return s.searcher.Count()
+}
+
+func (s *TermRangeSearcher) Weight() float64 {
+	return s.searcher.Weight()
+}
+
+func (s *TermRangeSearcher) SetQueryNorm(qnorm float64) {
+	s.searcher.SetQueryNorm(qnorm)
+}
+
+func (s *TermRangeSearcher) Next() (*search.DocumentMatch, error) {
+	return s.searcher.Next()
+}
+
+func (s *TermRangeSearcher) Advance(ID string) (*search.DocumentMatch, error) {
+	return s.searcher.Advance(ID)
+}
+
+func (s *TermRangeSearcher) Close() error {
+	return s.searcher.Close()
+}
+
+func (s *TermRangeSearcher) Min() int {
+	return 0
+}
@@ -0,0 +1,76 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package searchers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTermRangeSearch(t *testing.T) {
+
+	twoDocIndexReader, err := twoDocIndex.Reader()
+	if err != nil {
+		t.Error(err)
+	}
+	defer func() {
+		err := twoDocIndexReader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	dustin := "dustin"
+	marty := "marty"
+	m := "m"
+	inclusive := true
+	exclusive := false
+
+	tests := []struct {
+		min          *string
+		max          *string
+		inclusiveMin *bool
+		inclusiveMax *bool
+		ids          []string
+	}{
+		// marty, steve, dustin, ravi and bobert
+		{min: &dustin, max: &marty, ids: []string{"3"}},
+		{min: &dustin, max: &marty, inclusiveMax: &inclusive, ids: []string{"1", "3"}},
+		{min: &dustin, max: &marty, inclusiveMin: &exclusive, inclusiveMax: &inclusive, ids: []string{"1"}},
+		// terms prefixed by the maximum are above it
+		{min: &dustin, max: &m, inclusiveMax: &inclusive, ids: []string{"3"}},
+		{min: &marty, ids: []string{"1", "2", "4"}},
+		{max: &dustin, ids: []string{"5"}},
+		{max: &m, inclusiveMax: &exclusive, ids: []string{"3", "5"}},
+	}
+
+	for testIndex, test := range tests {
+		searcher, err := NewTermRangeSearcher(twoDocIndexReader, test.min, test.max, test.inclusiveMin, test.inclusiveMax, "name", 1.0, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []string{}
+		next, err := searcher.Next()
+		for err == nil && next != nil {
+			ids = append(ids, next.ID)
+			next, err = searcher.Next()
+		}
+		if err != nil {
+			t.Fatalf("error iterating searcher: %v for test %d", err, testIndex)
+		}
+		if !reflect.DeepEqual(ids, test.ids) {
+			t.Errorf("expected ids %v, got %v for test %d", test.ids, ids, testIndex)
+		}
+		err = searcher.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// package bm25 registers the Okapi BM25 similarity.
+//
+// Its constructor takes the following optional arguments:
+//
+// "k1" (float64): the term frequency saturation, 1.2 by default.
+//
+// "b" (float64): the field length normalization, between 0 and 1, 0.75 by
+// default.
+package bm25
+
+import (
+	"github.com/blevesearch/bleve/registry"
+	"github.com/blevesearch/bleve/search/scorers"
+)
+
+const Name = "bm25"
+
+func Constructor(config map[string]interface{}, cache *registry.Cache) (scorers.Similarity, error) {
+	k1 := scorers.DefaultBM25K1
+	if k1Val, ok := config["k1"].(float64); ok {
+		k1 = k1Val
+	}
+	b := scorers.DefaultBM25B
+	if bVal, ok := config["b"].(float64); ok {
+		b = bVal
+	}
+	return scorers.NewBM25Similarity(k1, b)
+}
+
+func init() {
+	registry.RegisterSimilarity(Name, Constructor)
+}
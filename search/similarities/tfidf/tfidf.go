@@ -0,0 +1,27 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// package tfidf registers the classic TF-IDF similarity, which bleve
+// uses by default.
+package tfidf
+
+import (
+	"github.com/blevesearch/bleve/registry"
+	"github.com/blevesearch/bleve/search/scorers"
+)
+
+const Name = "tfidf"
+
+func Constructor(config map[string]interface{}, cache *registry.Cache) (scorers.Similarity, error) {
+	return scorers.NewTFIDFSimilarity(), nil
+}
+
+func init() {
+	registry.RegisterSimilarity(Name, Constructor)
+}
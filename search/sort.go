@@ -0,0 +1,620 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/geo"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/numeric_util"
+)
+
+// HighTerm sorts after any term found in the index, LowTerm before.
+// They are used as the sort value of documents missing a field.
+// HighTerm is valid UTF-8 so that it survives a JSON round-trip.
+var HighTerm = strings.Repeat(string(utf8.MaxRune), 10)
+var LowTerm = string([]byte{0x00})
+
+// SearchSort describes one criterion used to order search results.
+type SearchSort interface {
+	// Value returns the sort key of the document match, fieldTerms
+	// holds the indexed terms of the fields listed by RequiresFields.
+	Value(dm *DocumentMatch, fieldTerms index.FieldTerms) string
+
+	// Descending reports whether larger values come first.
+	Descending() bool
+
+	RequiresScoring() bool
+	RequiresFields() []string
+}
+
+// ParseSearchSortString parses the short string form of a sort
+// criterion: a field name, "_id" or "_score", optionally prefixed
+// by "-" to reverse the order.
+func ParseSearchSortString(input string) SearchSort {
+	descending := false
+	if strings.HasPrefix(input, "-") {
+		descending = true
+		input = input[1:]
+	} else if strings.HasPrefix(input, "+") {
+		input = input[1:]
+	}
+	if input == "_id" {
+		return &SortDocID{
+			Desc: descending,
+		}
+	} else if input == "_score" {
+		return &SortScore{
+			Desc: descending,
+		}
+	}
+	return &SortField{
+		Field: input,
+		Desc:  descending,
+	}
+}
+
+// ParseSearchSortObj parses the object form of a sort criterion,
+// for example {"by": "field", "field": "price", "type": "number"}.
+func ParseSearchSortObj(input map[string]interface{}) (SearchSort, error) {
+	descending, ok := input["desc"].(bool)
+	if _, exists := input["desc"]; exists && !ok {
+		return nil, fmt.Errorf("search sort desc must be a boolean")
+	}
+	by, ok := input["by"].(string)
+	if !ok {
+		return nil, fmt.Errorf("search sort must specify by")
+	}
+	switch by {
+	case "id":
+		return &SortDocID{
+			Desc: descending,
+		}, nil
+	case "score":
+		return &SortScore{
+			Desc: descending,
+		}, nil
+	case "field":
+		field, ok := input["field"].(string)
+		if !ok {
+			return nil, fmt.Errorf("search sort mode field must specify field")
+		}
+		rv := &SortField{
+			Field: field,
+			Desc:  descending,
+		}
+		if typ, ok := input["type"].(string); ok {
+			switch typ {
+			case "auto":
+				rv.Type = SortFieldAuto
+			case "string":
+				rv.Type = SortFieldAsString
+			case "number":
+				rv.Type = SortFieldAsNumber
+			case "date":
+				rv.Type = SortFieldAsDate
+			default:
+				return nil, fmt.Errorf("unknown sort field type: %s", typ)
+			}
+		}
+		if mode, ok := input["mode"].(string); ok {
+			switch mode {
+			case "default":
+				rv.Mode = SortFieldDefault
+			case "min":
+				rv.Mode = SortFieldMin
+			case "max":
+				rv.Mode = SortFieldMax
+			default:
+				return nil, fmt.Errorf("unknown sort field mode: %s", mode)
+			}
+		}
+		if missing, ok := input["missing"].(string); ok {
+			switch missing {
+			case "first":
+				rv.Missing = SortFieldMissingFirst
+			case "last":
+				rv.Missing = SortFieldMissingLast
+			default:
+				return nil, fmt.Errorf("unknown sort field missing: %s", missing)
+			}
+		}
+		return rv, nil
+	case "geo_distance":
+		field, ok := input["field"].(string)
+		if !ok {
+			return nil, fmt.Errorf("search sort mode geo_distance must specify field")
+		}
+		lon, lat, foundLocation := geo.ExtractGeoPoint(input["location"])
+		if !foundLocation {
+			return nil, fmt.Errorf("unable to parse geo_distance location")
+		}
+		rvd := &SortGeoDistance{
+			Field: field,
+			Desc:  descending,
+			Lon:   lon,
+			Lat:   lat,
+		}
+		if unit, ok := input["unit"].(string); ok {
+			var err error
+			rvd.unitMult, err = geo.ParseDistanceUnit(unit)
+			if err != nil {
+				return nil, err
+			}
+			rvd.Unit = unit
+		}
+		return rvd, nil
+	}
+
+	return nil, fmt.Errorf("unknown search sort by: %s", by)
+}
+
+// ParseSortOrderStrings builds a SortOrder from the short string
+// form of each criterion.
+func ParseSortOrderStrings(in []string) SortOrder {
+	rv := make(SortOrder, 0, len(in))
+	for _, i := range in {
+		ss := ParseSearchSortString(i)
+		rv = append(rv, ss)
+	}
+	return rv
+}
+
+// ParseSortOrderJSON builds a SortOrder from a list of criteria
+// in either string or object form.
+func ParseSortOrderJSON(in []json.RawMessage) (SortOrder, error) {
+	rv := make(SortOrder, 0, len(in))
+	for _, i := range in {
+		var sortString string
+		err := json.Unmarshal(i, &sortString)
+		if err == nil {
+			rv = append(rv, ParseSearchSortString(sortString))
+			continue
+		}
+		var sortObj map[string]interface{}
+		err = json.Unmarshal(i, &sortObj)
+		if err != nil {
+			return nil, fmt.Errorf("search sort must be a string or an object")
+		}
+		ss, err := ParseSearchSortObj(sortObj)
+		if err != nil {
+			return nil, err
+		}
+		rv = append(rv, ss)
+	}
+	return rv, nil
+}
+
+// SortOrder is an ordered list of sort criteria, later criteria
+// only break ties of earlier ones.
+type SortOrder []SearchSort
+
+// Value computes the sort keys of the document match.
+func (so SortOrder) Value(dm *DocumentMatch, fieldTerms index.FieldTerms) []string {
+	rv := make([]string, len(so))
+	for i, soi := range so {
+		rv[i] = soi.Value(dm, fieldTerms)
+	}
+	return rv
+}
+
+// Compare returns a negative number if i sorts before j, a positive
+// number if it sorts after j and 0 if they are equivalent. Both
+// document matches must already have their Sort keys computed.
+func (so SortOrder) Compare(i, j *DocumentMatch) int {
+	for x, soi := range so {
+		c := 0
+		if _, ok := soi.(*SortScore); ok {
+			if i.Score < j.Score {
+				c = -1
+			} else if i.Score > j.Score {
+				c = 1
+			}
+		} else {
+			c = strings.Compare(sortValue(i, x), sortValue(j, x))
+		}
+		if c == 0 {
+			continue
+		}
+		if soi.Descending() {
+			c = -c
+		}
+		return c
+	}
+	return 0
+}
+
+func sortValue(dm *DocumentMatch, x int) string {
+	if x < len(dm.Sort) {
+		return dm.Sort[x]
+	}
+	return ""
+}
+
+// DocumentMatchAfter builds a document match positioned at the sort
+// keys of a previously returned hit, as found in DocumentMatch.Sort,
+// so that collected hits can be compared against it.
+func (so SortOrder) DocumentMatchAfter(after []string) (*DocumentMatch, error) {
+	if len(after) != len(so) {
+		return nil, fmt.Errorf("search after must have %d values, one for each sort criterion, got %d", len(so), len(after))
+	}
+	rv := &DocumentMatch{
+		Sort: after,
+	}
+	for i, soi := range so {
+		switch soi.(type) {
+		case *SortScore:
+			score, err := strconv.ParseFloat(after[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("search after value for _score must be a number, got '%s'", after[i])
+			}
+			rv.Score = score
+		case *SortDocID:
+			rv.ID = after[i]
+		}
+	}
+	return rv, nil
+}
+
+// RequiresScoring reports whether any criterion orders by score.
+func (so SortOrder) RequiresScoring() bool {
+	for _, soi := range so {
+		if soi.RequiresScoring() {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresFields returns the fields whose terms must be loaded to
+// compute the sort keys.
+func (so SortOrder) RequiresFields() []string {
+	var rv []string
+	for _, soi := range so {
+		rv = append(rv, soi.RequiresFields()...)
+	}
+	return rv
+}
+
+// SortField describes how to order results by the indexed terms of
+// a field. Text fields should use an analyzer producing a single
+// term, such as keyword, for the ordering to be meaningful.
+type SortField struct {
+	Field   string
+	Desc    bool
+	Type    SortFieldType
+	Mode    SortFieldMode
+	Missing SortFieldMissing
+}
+
+// SortFieldType restricts which terms of a field are considered
+// when sorting.
+type SortFieldType int
+
+const (
+	// SortFieldAuto uses the numeric terms when all the terms of the
+	// field are numeric, all the terms otherwise.
+	SortFieldAuto SortFieldType = iota
+	SortFieldAsString
+	SortFieldAsNumber
+	SortFieldAsDate
+)
+
+// SortFieldMode chooses the term used when a field has several.
+type SortFieldMode int
+
+const (
+	// SortFieldDefault uses the first indexed term.
+	SortFieldDefault SortFieldMode = iota
+	SortFieldMin
+	SortFieldMax
+)
+
+// SortFieldMissing controls where documents without the field go.
+type SortFieldMissing int
+
+const (
+	SortFieldMissingLast SortFieldMissing = iota
+	SortFieldMissingFirst
+)
+
+// Value returns the sort key of the document for this field.
+func (s *SortField) Value(dm *DocumentMatch, fieldTerms index.FieldTerms) string {
+	terms := s.filterTermsByType(fieldTerms[s.Field])
+	if len(terms) == 0 {
+		// documents missing the field go last unless asked otherwise,
+		// regardless of the direction
+		if (s.Missing == SortFieldMissingLast) != s.Desc {
+			return HighTerm
+		}
+		return LowTerm
+	}
+	switch s.Mode {
+	case SortFieldMin:
+		rv := terms[0]
+		for _, term := range terms[1:] {
+			if term < rv {
+				rv = term
+			}
+		}
+		return rv
+	case SortFieldMax:
+		rv := terms[0]
+		for _, term := range terms[1:] {
+			if term > rv {
+				rv = term
+			}
+		}
+		return rv
+	}
+	return terms[0]
+}
+
+func (s *SortField) filterTermsByType(terms []string) []string {
+	switch s.Type {
+	case SortFieldAsString:
+		return terms
+	case SortFieldAsNumber, SortFieldAsDate:
+		return numericTermsShiftZero(terms)
+	}
+	for _, term := range terms {
+		if valid, _ := numeric_util.ValidPrefixCodedTerm(term); !valid {
+			return terms
+		}
+	}
+	return numericTermsShiftZero(terms)
+}
+
+func numericTermsShiftZero(terms []string) []string {
+	var rv []string
+	for _, term := range terms {
+		valid, shift := numeric_util.ValidPrefixCodedTerm(term)
+		if valid && shift == 0 {
+			rv = append(rv, term)
+		}
+	}
+	return rv
+}
+
+// Descending reports whether larger values come first.
+func (s *SortField) Descending() bool {
+	return s.Desc
+}
+
+func (s *SortField) RequiresScoring() bool {
+	return false
+}
+
+func (s *SortField) RequiresFields() []string {
+	return []string{s.Field}
+}
+
+func (s *SortField) MarshalJSON() ([]byte, error) {
+	// see if simple format can be used
+	if s.Missing == SortFieldMissingLast &&
+		s.Mode == SortFieldDefault &&
+		s.Type == SortFieldAuto {
+		if s.Desc {
+			return json.Marshal("-" + s.Field)
+		}
+		return json.Marshal(s.Field)
+	}
+	sfm := map[string]interface{}{
+		"by":    "field",
+		"field": s.Field,
+	}
+	if s.Desc {
+		sfm["desc"] = true
+	}
+	if s.Missing > SortFieldMissingLast {
+		switch s.Missing {
+		case SortFieldMissingFirst:
+			sfm["missing"] = "first"
+		}
+	}
+	if s.Mode > SortFieldDefault {
+		switch s.Mode {
+		case SortFieldMin:
+			sfm["mode"] = "min"
+		case SortFieldMax:
+			sfm["mode"] = "max"
+		}
+	}
+	if s.Type > SortFieldAuto {
+		switch s.Type {
+		case SortFieldAsString:
+			sfm["type"] = "string"
+		case SortFieldAsNumber:
+			sfm["type"] = "number"
+		case SortFieldAsDate:
+			sfm["type"] = "date"
+		}
+	}
+
+	return json.Marshal(sfm)
+}
+
+// SortGeoDistance orders results by the distance between a geo point
+// field and a fixed location. Documents with several points use the
+// closest one, documents without the field go last.
+type SortGeoDistance struct {
+	Field    string
+	Desc     bool
+	Unit     string
+	Lon      float64
+	Lat      float64
+	unitMult float64
+}
+
+// NewSortGeoDistance returns a SortGeoDistance ordering by the distance
+// to the given location, expressed in unit, meters if empty.
+func NewSortGeoDistance(field, unit string, lon, lat float64, desc bool) (*SortGeoDistance, error) {
+	rv := &SortGeoDistance{
+		Field: field,
+		Desc:  desc,
+		Unit:  unit,
+		Lon:   lon,
+		Lat:   lat,
+	}
+	if unit != "" {
+		var err error
+		rv.unitMult, err = geo.ParseDistanceUnit(unit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+// Value returns the sort key of the document, the distance to the
+// location prefix coded so that it orders lexicographically.
+func (s *SortGeoDistance) Value(dm *DocumentMatch, fieldTerms index.FieldTerms) string {
+	found := false
+	var distance float64
+	for _, term := range numericTermsShiftZero(fieldTerms[s.Field]) {
+		i64, err := numeric_util.PrefixCoded(term).Int64()
+		if err != nil {
+			continue
+		}
+		docLon := geo.MortonUnhashLon(uint64(i64))
+		docLat := geo.MortonUnhashLat(uint64(i64))
+		d := geo.Haversin(s.Lon, s.Lat, docLon, docLat)
+		if !found || d < distance {
+			distance = d
+			found = true
+		}
+	}
+	if !found {
+		if s.Desc {
+			return LowTerm
+		}
+		return HighTerm
+	}
+	if s.unitMult != 0 {
+		distance /= s.unitMult
+	}
+	return string(numeric_util.MustNewPrefixCodedInt64(numeric_util.Float64ToInt64(distance), 0))
+}
+
+// Descending reports whether larger values come first.
+func (s *SortGeoDistance) Descending() bool {
+	return s.Desc
+}
+
+func (s *SortGeoDistance) RequiresScoring() bool {
+	return false
+}
+
+func (s *SortGeoDistance) RequiresFields() []string {
+	return []string{s.Field}
+}
+
+func (s *SortGeoDistance) MarshalJSON() ([]byte, error) {
+	sfm := map[string]interface{}{
+		"by":    "geo_distance",
+		"field": s.Field,
+		"location": map[string]interface{}{
+			"lon": s.Lon,
+			"lat": s.Lat,
+		},
+	}
+	if s.Unit != "" {
+		sfm["unit"] = s.Unit
+	}
+	if s.Desc {
+		sfm["desc"] = true
+	}
+	return json.Marshal(sfm)
+}
+
+// SortDocID orders results by document identifier.
+type SortDocID struct {
+	Desc bool
+}
+
+// Value returns the identifier of the document.
+func (s *SortDocID) Value(dm *DocumentMatch, fieldTerms index.FieldTerms) string {
+	return dm.ID
+}
+
+// Descending reports whether larger values come first.
+func (s *SortDocID) Descending() bool {
+	return s.Desc
+}
+
+func (s *SortDocID) RequiresScoring() bool {
+	return false
+}
+
+func (s *SortDocID) RequiresFields() []string {
+	return nil
+}
+
+func (s *SortDocID) MarshalJSON() ([]byte, error) {
+	if s.Desc {
+		return json.Marshal("-_id")
+	}
+	return json.Marshal("_id")
+}
+
+// SortScore orders results by score.
+type SortScore struct {
+	Desc bool
+}
+
+// Value returns the score formatted as a string, scores are still
+// compared numerically straight from the document match.
+func (s *SortScore) Value(dm *DocumentMatch, fieldTerms index.FieldTerms) string {
+	return strconv.FormatFloat(dm.Score, 'g', -1, 64)
+}
+
+// Descending reports whether larger values come first.
+func (s *SortScore) Descending() bool {
+	return s.Desc
+}
+
+func (s *SortScore) RequiresScoring() bool {
+	return true
+}
+
+func (s *SortScore) RequiresFields() []string {
+	return nil
+}
+
+func (s *SortScore) MarshalJSON() ([]byte, error) {
+	if s.Desc {
+		return json.Marshal("-_score")
+	}
+	return json.Marshal("_score")
+}
+
+// DocumentMatchSorter sorts a DocumentMatchCollection according to
+// a SortOrder, keeping the original order of equivalent matches.
+type DocumentMatchSorter struct {
+	Hits DocumentMatchCollection
+	Sort SortOrder
+}
+
+func (d DocumentMatchSorter) Len() int      { return len(d.Hits) }
+func (d DocumentMatchSorter) Swap(i, j int) { d.Hits[i], d.Hits[j] = d.Hits[j], d.Hits[i] }
+func (d DocumentMatchSorter) Less(i, j int) bool {
+	return d.Sort.Compare(d.Hits[i], d.Hits[j]) < 0
+}
+
+// SortDocumentMatches orders hits in place according to so.
+func SortDocumentMatches(hits DocumentMatchCollection, so SortOrder) {
+	sort.Stable(DocumentMatchSorter{Hits: hits, Sort: so})
+}
@@ -0,0 +1,222 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/numeric_util"
+)
+
+func TestParseSortOrderJSON(t *testing.T) {
+	tests := []struct {
+		input  string
+		output SortOrder
+		err    bool
+	}{
+		{
+			input: `["-published", "title", "_score", "-_id"]`,
+			output: SortOrder{
+				&SortField{Field: "published", Desc: true},
+				&SortField{Field: "title"},
+				&SortScore{},
+				&SortDocID{Desc: true},
+			},
+		},
+		{
+			input: `[{"by":"field","field":"price","type":"number","mode":"max","missing":"first","desc":true}]`,
+			output: SortOrder{
+				&SortField{
+					Field:   "price",
+					Desc:    true,
+					Type:    SortFieldAsNumber,
+					Mode:    SortFieldMax,
+					Missing: SortFieldMissingFirst,
+				},
+			},
+		},
+		{
+			input: `[{"by":"score"}]`,
+			output: SortOrder{
+				&SortScore{},
+			},
+		},
+		{
+			input: `[{"by":"geo_distance","field":"loc","location":{"lat":48.85,"lon":2.35},"unit":"km","desc":true}]`,
+			output: SortOrder{
+				&SortGeoDistance{
+					Field:    "loc",
+					Desc:     true,
+					Unit:     "km",
+					Lon:      2.35,
+					Lat:      48.85,
+					unitMult: 1000,
+				},
+			},
+		},
+		{
+			input: `[{"by":"geo_distance","field":"loc","location":[2.35,48.85],"unit":"parsecs"}]`,
+			err:   true,
+		},
+		{
+			input: `[{"by":"field"}]`,
+			err:   true,
+		},
+		{
+			input: `[{"by":"field","field":"price","type":"color"}]`,
+			err:   true,
+		},
+		{
+			input: `[5]`,
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		var raw []json.RawMessage
+		err := json.Unmarshal([]byte(test.input), &raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := ParseSortOrderJSON(raw)
+		if test.err {
+			if err == nil {
+				t.Errorf("expected error parsing %s", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error parsing %s: %v", test.input, err)
+		}
+		if !reflect.DeepEqual(actual, test.output) {
+			t.Errorf("expected %#v, got %#v for %s", test.output, actual, test.input)
+		}
+
+		// marshaling must round-trip
+		marshaled, err := json.Marshal(actual)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = json.Unmarshal(marshaled, &raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roundTripped, err := ParseSortOrderJSON(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(roundTripped, test.output) {
+			t.Errorf("expected %#v after round-trip, got %#v", test.output, roundTripped)
+		}
+	}
+}
+
+func TestSortFieldValue(t *testing.T) {
+	one := string(numeric_util.MustNewPrefixCodedInt64(numeric_util.Float64ToInt64(1.0), 0))
+	oneShifted := string(numeric_util.MustNewPrefixCodedInt64(numeric_util.Float64ToInt64(1.0), 4))
+	five := string(numeric_util.MustNewPrefixCodedInt64(numeric_util.Float64ToInt64(5.0), 0))
+
+	fieldTerms := index.FieldTerms{
+		"name":  []string{"marty", "bob"},
+		"price": []string{one, oneShifted, five},
+	}
+
+	tests := []struct {
+		sort   *SortField
+		expect string
+	}{
+		{
+			sort:   &SortField{Field: "name"},
+			expect: "marty",
+		},
+		{
+			sort:   &SortField{Field: "name", Mode: SortFieldMin},
+			expect: "bob",
+		},
+		{
+			sort:   &SortField{Field: "price", Mode: SortFieldMax},
+			expect: five,
+		},
+		{
+			sort:   &SortField{Field: "price", Mode: SortFieldMin, Type: SortFieldAsNumber},
+			expect: one,
+		},
+		{
+			sort:   &SortField{Field: "name", Type: SortFieldAsNumber},
+			expect: HighTerm,
+		},
+		{
+			sort:   &SortField{Field: "missing"},
+			expect: HighTerm,
+		},
+		{
+			sort:   &SortField{Field: "missing", Desc: true},
+			expect: LowTerm,
+		},
+		{
+			sort:   &SortField{Field: "missing", Missing: SortFieldMissingFirst},
+			expect: LowTerm,
+		},
+		{
+			sort:   &SortField{Field: "missing", Missing: SortFieldMissingFirst, Desc: true},
+			expect: HighTerm,
+		},
+	}
+
+	for _, test := range tests {
+		actual := test.sort.Value(&DocumentMatch{ID: "a"}, fieldTerms)
+		if actual != test.expect {
+			t.Errorf("expected %q, got %q for %#v", test.expect, actual, test.sort)
+		}
+	}
+}
+
+func TestSortOrderCompare(t *testing.T) {
+	so := SortOrder{&SortField{Field: "name", Desc: true}, &SortScore{Desc: true}, &SortDocID{}}
+
+	hits := DocumentMatchCollection{
+		&DocumentMatch{ID: "c", Score: 1.0, Sort: []string{"a", "_score", "c"}},
+		&DocumentMatch{ID: "b", Score: 2.0, Sort: []string{"a", "_score", "b"}},
+		&DocumentMatch{ID: "d", Score: 2.0, Sort: []string{"a", "_score", "d"}},
+		&DocumentMatch{ID: "a", Score: 0.5, Sort: []string{"b", "_score", "a"}},
+	}
+	SortDocumentMatches(hits, so)
+
+	expectedIDs := []string{"a", "b", "d", "c"}
+	for i, hit := range hits {
+		if hit.ID != expectedIDs[i] {
+			t.Errorf("expected hit %d to be %s, got %s", i, expectedIDs[i], hit.ID)
+		}
+	}
+}
+
+func TestSortOrderDocumentMatchAfter(t *testing.T) {
+	so := SortOrder{&SortField{Field: "name"}, &SortScore{Desc: true}, &SortDocID{}}
+
+	dm, err := so.DocumentMatchAfter([]string{"marty", "1.5", "doc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dm.Score != 1.5 || dm.ID != "doc" || dm.Sort[0] != "marty" {
+		t.Errorf("unexpected search after document match %#v", dm)
+	}
+
+	_, err = so.DocumentMatchAfter([]string{"marty", "1.5"})
+	if err == nil {
+		t.Errorf("expected error for too few search after values")
+	}
+	_, err = so.DocumentMatchAfter([]string{"marty", "high", "doc"})
+	if err == nil {
+		t.Errorf("expected error for a non numeric score")
+	}
+}
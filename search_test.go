@@ -11,6 +11,7 @@ package bleve
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -62,6 +63,34 @@ func TestSearchResultString(t *testing.T) {
 			},
 			str: "No matches",
 		},
+		{
+			result: &SearchResult{
+				Request: &SearchRequest{
+					Size: 10,
+				},
+				Total: 0,
+				Hits:  search.DocumentMatchCollection{},
+				Facets: search.FacetResults{
+					"type": &search.FacetResult{
+						Field: "type",
+						Total: 3,
+						Terms: search.TermFacets{
+							&search.TermFacet{Term: "beer", Count: 2},
+						},
+						Other: 1,
+					},
+					"abv": &search.FacetResult{
+						Field: "abv",
+						Total: 4,
+						NumericRanges: search.NumericRangeFacets{
+							&search.NumericRangeFacet{Name: "strong", Count: 3},
+						},
+						Missing: 1,
+					},
+				},
+			},
+			str: "No matches\nFacets:\nabv(4)\n\tstrong(3)\n\tMissing(1)\ntype(3)\n\tbeer(2)\n\tOther(1)\n",
+		},
 	}
 
 	for _, test := range tests {
@@ -178,3 +207,78 @@ func TestUnmarshalingSearchResult(t *testing.T) {
 		t.Errorf("expected 1 error, got %d", len(rv.Status.Errors))
 	}
 }
+
+func TestUnmarshalingSearchRequestSortSearchAfter(t *testing.T) {
+	var sr SearchRequest
+	err := json.Unmarshal([]byte(`{
+		"query": {"match_all": {}},
+		"sort": ["-published", {"by": "field", "field": "title", "missing": "first"}, "_id"],
+		"search_after": ["2016", "title", "doc-10"]
+	}`), &sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSort := search.SortOrder{
+		&search.SortField{Field: "published", Desc: true},
+		&search.SortField{Field: "title", Missing: search.SortFieldMissingFirst},
+		&search.SortDocID{},
+	}
+	if !reflect.DeepEqual(sr.Sort, expectedSort) {
+		t.Errorf("expected sort %#v, got %#v", expectedSort, sr.Sort)
+	}
+	expectedAfter := []string{"2016", "title", "doc-10"}
+	if !reflect.DeepEqual(sr.SearchAfter, expectedAfter) {
+		t.Errorf("expected search after %v, got %v", expectedAfter, sr.SearchAfter)
+	}
+	err = sr.Validate()
+	if err != nil {
+		t.Errorf("expected valid request, got %v", err)
+	}
+
+	sr.SearchAfter = []string{"2016"}
+	if sr.Validate() == nil {
+		t.Errorf("expected error for search after not matching the sort order")
+	}
+}
+
+func TestSearchRequestJSONRoundTrip(t *testing.T) {
+	min := 3.0
+	query := NewBooleanQuery(
+		[]Query{NewMatchQuery("beer").SetField("desc")},
+		[]Query{NewPhraseQuery([]string{"light", "beer"}, "desc"), NewBoolFieldQuery(true).SetField("on_draft")},
+		[]Query{NewNumericRangeQuery(nil, &min).SetField("abv")})
+	sr := NewSearchRequestOptions(query, 20, 10, true)
+	sr.Highlight = NewHighlightWithStyle("html")
+	sr.Fields = []string{"name", "desc"}
+	sr.AddFacet("styles", NewFacetRequest("style", 3))
+	sr.SortBy([]string{"-abv", "_id"})
+	sr.SetSearchAfter([]string{"5", "a"})
+
+	data, err := json.Marshal(sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual SearchRequest
+	err = json.Unmarshal(data, &actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sr, &actual) {
+		t.Errorf("expected: %#v, got: %#v, from %s", sr, &actual, data)
+	}
+}
+
+func TestUnmarshalingSearchRequestInvalidQuery(t *testing.T) {
+	var sr SearchRequest
+	err := json.Unmarshal([]byte(`{
+		"query": {"type": "boolean", "must": {"conjuncts": [{"match": "beer"}, {"type": "span_near", "span_near": [{"type": "span_term"}, {"match": "light"}]}]}}
+	}`), &sr)
+	expected := &QueryPathError{
+		Path: "query.must.conjuncts[1].span_near[1]",
+		Err:  fmt.Errorf("span queries can only contain span queries, got *bleve.matchQuery"),
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+}
@@ -0,0 +1,295 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+const (
+	defaultSuggestSize         = 5
+	defaultSuggestMaxEdits     = 2
+	defaultSuggestPrefixLength = 1
+)
+
+// Suggest modes of a SuggestRequest.
+const (
+	// suggest corrections only for terms not in the index
+	SuggestMissing = "missing"
+	// suggest corrections only for terms not in the index,
+	// or found in fewer documents than their corrections
+	SuggestPopular = "popular"
+	// suggest corrections for every term
+	SuggestAlways = "always"
+)
+
+// A SuggestRequest describes a request for spelling
+// corrections of a text.
+// Text is analyzed with Analyzer, or with the analyzer
+// of Field, and corrections are suggested for each of
+// its terms among the terms indexed in Field, which is
+// the default field of the mapping if empty.
+// Size is the maximum number of corrections per term,
+// 5 by default.
+// MaxEdits is the maximum Levenshtein edit distance
+// between a term and its corrections, 2 by default.
+// PrefixLength is the number of leading characters a
+// term and its corrections must share, 1 by default, so
+// that only the part of the dictionary starting like the
+// term is scanned.
+// MinDocFreq is the minimum number of documents a
+// correction must be found in.
+// Mode is one of SuggestMissing (the default),
+// SuggestPopular and SuggestAlways.
+type SuggestRequest struct {
+	Text         string `json:"text"`
+	Field        string `json:"field,omitempty"`
+	Analyzer     string `json:"analyzer,omitempty"`
+	Size         int    `json:"size,omitempty"`
+	MaxEdits     int    `json:"max_edits,omitempty"`
+	PrefixLength int    `json:"prefix_length,omitempty"`
+	MinDocFreq   uint64 `json:"min_doc_freq,omitempty"`
+	Mode         string `json:"mode,omitempty"`
+}
+
+// NewSuggestRequest creates a SuggestRequest for
+// corrections of text in field.
+func NewSuggestRequest(text, field string) *SuggestRequest {
+	return &SuggestRequest{
+		Text:  text,
+		Field: field,
+	}
+}
+
+func (r *SuggestRequest) Validate() error {
+	if r.Size < 0 {
+		return fmt.Errorf("suggest size must not be negative")
+	}
+	if r.MaxEdits < 0 {
+		return fmt.Errorf("suggest max edits must not be negative")
+	}
+	if r.PrefixLength < 0 {
+		return fmt.Errorf("suggest prefix length must not be negative")
+	}
+	switch r.Mode {
+	case "", SuggestMissing, SuggestPopular, SuggestAlways:
+	default:
+		return fmt.Errorf("unknown suggest mode '%s'", r.Mode)
+	}
+	return nil
+}
+
+// A SuggestOption is a correction of a term, found in
+// DocFreq documents.
+type SuggestOption struct {
+	Term     string `json:"term"`
+	Distance int    `json:"distance"`
+	DocFreq  uint64 `json:"doc_freq"`
+}
+
+// A TermSuggestion holds the corrections of a term of
+// the text, which occupies the bytes from Start to End,
+// nearest first and then most frequent first. DocFreq is
+// the number of documents the term itself is found in.
+type TermSuggestion struct {
+	Term    string           `json:"term"`
+	Start   int              `json:"start"`
+	End     int              `json:"end"`
+	DocFreq uint64           `json:"doc_freq"`
+	Options []*SuggestOption `json:"options"`
+}
+
+// A SuggestResult describes the corrections suggested for
+// the terms of a text. Phrase is the text with each term
+// having corrections replaced by the first one, or empty
+// if no term has corrections.
+type SuggestResult struct {
+	Request *SuggestRequest   `json:"request"`
+	Terms   []*TermSuggestion `json:"terms"`
+	Phrase  string            `json:"phrase,omitempty"`
+}
+
+// termSuggester is implemented by indexes able to return
+// the candidate corrections of suggestTerms, so that those
+// of several indexes can be merged.
+type termSuggester interface {
+	termSuggestions(req *SuggestRequest) ([]*TermSuggestion, error)
+}
+
+// suggestTerms returns the terms of the text of req, each
+// with all the candidate corrections found in the index.
+func suggestTerms(i index.IndexReader, m *IndexMapping, req *SuggestRequest) ([]*TermSuggestion, error) {
+	field := req.Field
+	if field == "" {
+		field = m.DefaultField
+	}
+	analyzerName := req.Analyzer
+	if analyzerName == "" {
+		analyzerName = m.analyzerNameForPath(field)
+	}
+	analyzer := m.analyzerNamed(analyzerName)
+	if analyzer == nil {
+		return nil, fmt.Errorf("no analyzer named '%s' registered", analyzerName)
+	}
+	maxEdits := req.MaxEdits
+	if maxEdits == 0 {
+		maxEdits = defaultSuggestMaxEdits
+	}
+	prefixLength := req.PrefixLength
+	if prefixLength == 0 {
+		prefixLength = defaultSuggestPrefixLength
+	}
+
+	rv := make([]*TermSuggestion, 0)
+	for _, token := range analyzer.Analyze([]byte(req.Text)) {
+		term := string(token.Term)
+		ts := &TermSuggestion{
+			Term:    term,
+			Start:   token.Start,
+			End:     token.End,
+			Options: make([]*SuggestOption, 0),
+		}
+
+		prefix := ""
+		for in, r := range term {
+			if in >= prefixLength {
+				break
+			}
+			prefix += string(r)
+		}
+		var fieldDict index.FieldDict
+		var err error
+		if len(prefix) > 0 {
+			fieldDict, err = i.FieldDictPrefix(field, []byte(prefix))
+		} else {
+			fieldDict, err = i.FieldDict(field)
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry, err := fieldDict.Next()
+		for err == nil && entry != nil {
+			if entry.Term == term {
+				ts.DocFreq = entry.Count
+			} else {
+				distance, exceeded := search.LevenshteinDistanceMax(&term, &entry.Term, maxEdits)
+				if !exceeded && distance <= maxEdits {
+					ts.Options = append(ts.Options, &SuggestOption{
+						Term:     entry.Term,
+						Distance: distance,
+						DocFreq:  entry.Count,
+					})
+				}
+			}
+			entry, err = fieldDict.Next()
+		}
+		if cerr := fieldDict.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, err
+		}
+		rv = append(rv, ts)
+	}
+	return rv, nil
+}
+
+// newSuggestResult selects and ranks the candidate
+// corrections of terms according to req.
+func newSuggestResult(req *SuggestRequest, terms []*TermSuggestion) *SuggestResult {
+	size := req.Size
+	if size == 0 {
+		size = defaultSuggestSize
+	}
+
+	phrase := ""
+	corrected := false
+	last := 0
+	for _, ts := range terms {
+		options := make([]*SuggestOption, 0, len(ts.Options))
+		for _, option := range ts.Options {
+			if option.DocFreq < req.MinDocFreq {
+				continue
+			}
+			if req.Mode == SuggestPopular && option.DocFreq <= ts.DocFreq {
+				continue
+			}
+			options = append(options, option)
+		}
+		if ts.DocFreq > 0 && (req.Mode == "" || req.Mode == SuggestMissing) {
+			options = options[:0]
+		}
+		sort.Sort(suggestOptionsByRank(options))
+		if len(options) > size {
+			options = options[:size]
+		}
+		ts.Options = options
+
+		if len(options) > 0 && ts.Start >= last && ts.End <= len(req.Text) {
+			phrase += req.Text[last:ts.Start] + options[0].Term
+			last = ts.End
+			corrected = true
+		}
+	}
+
+	rv := &SuggestResult{
+		Request: req,
+		Terms:   terms,
+	}
+	if corrected {
+		rv.Phrase = phrase + req.Text[last:]
+	}
+	return rv
+}
+
+// mergeTermSuggestions adds the frequencies and candidate
+// corrections of other, suggested by another index for the
+// same text, to terms.
+func mergeTermSuggestions(terms, other []*TermSuggestion) []*TermSuggestion {
+	if terms == nil {
+		return other
+	}
+	for in, ts := range terms {
+		if in >= len(other) {
+			break
+		}
+		ts.DocFreq += other[in].DocFreq
+		options := make(map[string]*SuggestOption, len(ts.Options))
+		for _, option := range ts.Options {
+			options[option.Term] = option
+		}
+		for _, option := range other[in].Options {
+			if existing, ok := options[option.Term]; ok {
+				existing.DocFreq += option.DocFreq
+			} else {
+				ts.Options = append(ts.Options, option)
+			}
+		}
+	}
+	return terms
+}
+
+type suggestOptionsByRank []*SuggestOption
+
+func (o suggestOptionsByRank) Len() int      { return len(o) }
+func (o suggestOptionsByRank) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+func (o suggestOptionsByRank) Less(i, j int) bool {
+	if o[i].Distance != o[j].Distance {
+		return o[i].Distance < o[j].Distance
+	}
+	if o[i].DocFreq != o[j].DocFreq {
+		return o[i].DocFreq > o[j].DocFreq
+	}
+	return o[i].Term < o[j].Term
+}
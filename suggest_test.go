@@ -0,0 +1,184 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package bleve
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestIndexSuggest(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	index, err := New("testidx", NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := index.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	docs := map[string]string{
+		"a": "the quick brown fox",
+		"b": "the quick brown dog",
+		"c": "a quick box of chocolates",
+		"d": "the brewery makes beer",
+		"e": "boxes of beer",
+	}
+	for id, desc := range docs {
+		err = index.Index(id, map[string]interface{}{"desc": desc})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	options := func(res *SuggestResult) [][]string {
+		rv := make([][]string, len(res.Terms))
+		for i, ts := range res.Terms {
+			rv[i] = []string{}
+			for _, option := range ts.Options {
+				rv[i] = append(rv[i], option.Term)
+			}
+		}
+		return rv
+	}
+
+	tests := []struct {
+		req     *SuggestRequest
+		options [][]string
+		phrase  string
+	}{
+		{
+			req:     NewSuggestRequest("Quikc brwn fxo", "desc"),
+			options: [][]string{{"quick"}, {"brown"}, {"fox"}},
+			phrase:  "quick brown fox",
+		},
+		{
+			// known terms are not corrected, and corrections
+			// share the first character by default
+			req:     NewSuggestRequest("quick bo", "desc"),
+			options: [][]string{{}, {"box"}},
+			phrase:  "quick box",
+		},
+		{
+			req:     &SuggestRequest{Text: "quick bo", Field: "desc", Size: 1, MaxEdits: 1},
+			options: [][]string{{}, {"box"}},
+			phrase:  "quick box",
+		},
+		{
+			req:     &SuggestRequest{Text: "fix", Field: "desc", PrefixLength: 1},
+			options: [][]string{{"fox"}},
+			phrase:  "fox",
+		},
+		{
+			req:     &SuggestRequest{Text: "fix", Field: "desc", MinDocFreq: 2},
+			options: [][]string{{}},
+			phrase:  "",
+		},
+		{
+			// box is found in fewer documents than quick
+			req:     &SuggestRequest{Text: "quack box", Field: "desc", Mode: SuggestPopular},
+			options: [][]string{{"quick"}, {}},
+			phrase:  "quick box",
+		},
+		{
+			req:     &SuggestRequest{Text: "box", Field: "desc", Mode: SuggestAlways},
+			options: [][]string{{"boxes"}},
+			phrase:  "boxes",
+		},
+	}
+
+	for i, test := range tests {
+		res, err := index.Suggest(test.req)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(options(res), test.options) {
+			t.Errorf("test %d: expected options %v, got %v", i, test.options, options(res))
+		}
+		if res.Phrase != test.phrase {
+			t.Errorf("test %d: expected phrase '%s', got '%s'", i, test.phrase, res.Phrase)
+		}
+	}
+
+	_, err = index.Suggest(&SuggestRequest{Text: "fox", Mode: "sometimes"})
+	if err == nil {
+		t.Errorf("expected error for unknown suggest mode")
+	}
+}
+
+func TestIndexAliasSuggest(t *testing.T) {
+	defer func() {
+		for _, path := range []string{"testidx1", "testidx2"} {
+			err := os.RemoveAll(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}()
+
+	docs := []map[string]string{
+		{"a": "the fox", "b": "the box"},
+		{"c": "a fox", "d": "a fax"},
+	}
+	alias := NewIndexAlias()
+	for i, paths := range []string{"testidx1", "testidx2"} {
+		index, err := New(paths, NewIndexMapping())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			err := index.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}()
+		for id, desc := range docs[i] {
+			err = index.Index(id, map[string]interface{}{"desc": desc})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		alias.Add(index)
+	}
+
+	res, err := alias.Suggest(NewSuggestRequest("fix", "desc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*SuggestOption{
+		{Term: "fox", Distance: 1, DocFreq: 2},
+		{Term: "fax", Distance: 1, DocFreq: 1},
+	}
+	if !reflect.DeepEqual(res.Terms[0].Options, expected) {
+		t.Errorf("expected options %v, got %v", expected, res.Terms[0].Options)
+	}
+	if res.Phrase != "fox" {
+		t.Errorf("expected phrase 'fox', got '%s'", res.Phrase)
+	}
+
+	// fox is known to one of the indexes
+	res, err = alias.Suggest(NewSuggestRequest("fox", "desc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Terms[0].DocFreq != 2 || len(res.Terms[0].Options) != 0 {
+		t.Errorf("expected fox in 2 documents without options, got %v", res.Terms[0])
+	}
+}
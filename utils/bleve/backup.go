@@ -0,0 +1,123 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"github.com/blevesearch/bleve"
+)
+
+var backupCmd = newCommand("backup", "<index> <file>",
+	"write a backup of an index to file, or stdout if file is -")
+
+var restoreCmd = newCommand("restore", "<index> <file>",
+	"create an index from a backup read from file, or stdin if file is -")
+
+var restoreStore = restoreCmd.flags.String("store", "", "kv store type, defaults to the one of the backed up index")
+
+func init() {
+	backupCmd.run = runBackup
+	restoreCmd.run = runRestore
+}
+
+func runBackup(cmd *command, args []string) (err error) {
+	if len(args) != 2 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	var w io.Writer = os.Stdout
+	if args[1] != "-" {
+		f, err := os.Create(args[1])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil && cerr != nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+	err = index.Backup(w)
+	if err != nil || args[1] == "-" {
+		return err
+	}
+
+	if p.Text() {
+		p.Textf("backed up index %s to: %s\n", args[0], args[1])
+		return nil
+	}
+	return p.Value(map[string]interface{}{
+		"index":  args[0],
+		"backup": args[1],
+	})
+}
+
+func runRestore(cmd *command, args []string) (err error) {
+	if len(args) != 2 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = bufio.NewReader(os.Stdin)
+	if args[1] != "-" {
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil && cerr != nil {
+				err = cerr
+			}
+		}()
+		r = f
+	}
+
+	var index bleve.Index
+	if *restoreStore != "" {
+		index, err = bleve.RestoreUsing(args[0], r, *restoreStore, nil)
+	} else {
+		index, err = bleve.Restore(args[0], r)
+	}
+	if err != nil {
+		return err
+	}
+	count, err := index.DocCount()
+	if cerr := index.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if p.Text() {
+		p.Textf("restored %d documents to index: %s\n", count, args[0])
+		return nil
+	}
+	return p.Value(map[string]interface{}{
+		"index":     args[0],
+		"documents": count,
+	})
+}
@@ -0,0 +1,84 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blevesearch/bleve/index/upside_down"
+)
+
+var checkCmd = newCommand("check", "<index>",
+	"check the consistency of the rows of an upside_down index\n"+
+		"exits with an error if violations remain after the optional repair")
+
+var (
+	checkRepair       = checkCmd.flags.Bool("repair", false, "repair dictionary counts, field lengths and orphaned rows")
+	checkFieldLengths = checkCmd.flags.String("fieldLengths", "", "comma separated fields to maintain the lengths of from now on, computed by -repair")
+)
+
+func init() {
+	checkCmd.run = runCheck
+}
+
+func runCheck(cmd *command, args []string) (err error) {
+	if len(args) != 1 || (*checkFieldLengths != "" && !*checkRepair) {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	i, _, err := index.Advanced()
+	if err != nil {
+		return err
+	}
+	udc, ok := i.(*upside_down.UpsideDownCouch)
+	if !ok {
+		return fmt.Errorf("only upside_down indexes can be checked")
+	}
+	if *checkFieldLengths != "" {
+		udc.MaintainFieldLengths(strings.Split(*checkFieldLengths, ",")...)
+	}
+	report, err := udc.Check(*checkRepair)
+	if err != nil {
+		return err
+	}
+
+	remaining := 0
+	for _, v := range report.Violations {
+		if !v.Repaired {
+			remaining++
+		}
+	}
+	if p.Text() {
+		for _, v := range report.Violations {
+			p.Textf("%v\n", v)
+		}
+		p.Textf("checked %d rows of %d documents, %d violations, %d repaired\n",
+			report.Rows, report.Documents, len(report.Violations), len(report.Violations)-remaining)
+	} else {
+		err = p.Value(report)
+		if err != nil {
+			return err
+		}
+	}
+	if remaining > 0 {
+		return fmt.Errorf("%d violations found", remaining)
+	}
+	return nil
+}
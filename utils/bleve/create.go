@@ -0,0 +1,71 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/blevesearch/bleve"
+)
+
+var createCmd = newCommand("create", "<index>",
+	"create a new index, using the mapping read from -mapping if specified")
+
+var (
+	createMapping   = createCmd.flags.String("mapping", "", "path of a JSON index mapping")
+	createStore     = createCmd.flags.String("store", bleve.Config.DefaultKVStore, "kv store type")
+	createIndexType = createCmd.flags.String("indexType", bleve.Config.DefaultIndexType, "index type")
+)
+
+func init() {
+	createCmd.run = runCreate
+}
+
+func runCreate(cmd *command, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+
+	mapping := bleve.NewIndexMapping()
+	if *createMapping != "" {
+		mappingBytes, err := ioutil.ReadFile(*createMapping)
+		if err != nil {
+			return err
+		}
+		err = json.Unmarshal(mappingBytes, &mapping)
+		if err != nil {
+			return err
+		}
+	}
+
+	index, err := bleve.NewUsing(args[0], mapping, *createIndexType, *createStore, nil)
+	if err != nil {
+		return err
+	}
+	err = index.Close()
+	if err != nil {
+		return err
+	}
+
+	if p.Text() {
+		p.Textf("created bleve index at: %s\n", args[0])
+		return nil
+	}
+	return p.Value(map[string]interface{}{
+		"index":      args[0],
+		"store":      *createStore,
+		"index_type": *createIndexType,
+	})
+}
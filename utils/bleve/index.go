@@ -0,0 +1,220 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+var indexCmd = newCommand("index", "<index> <path>...",
+	`index every JSON file found under the paths, one document per file, using
+the file name as document id`)
+
+var (
+	indexKeepExt = indexCmd.flags.Bool("keepExt", false, "keep extension in doc id")
+	indexKeepDir = indexCmd.flags.Bool("keepDir", false, "keep dir in doc id")
+)
+
+var bulkCmd = newCommand("bulk", "<index> <file>...",
+	`index newline delimited JSON documents, reading stdin if the file is "-"`)
+
+var (
+	bulkSize    = bulkCmd.flags.Int("size", 1000, "size of a single batch to index")
+	bulkIDField = bulkCmd.flags.String("idField", "", "document field holding the document id, random ids are generated otherwise")
+)
+
+func init() {
+	indexCmd.run = runIndex
+	bulkCmd.run = runBulk
+}
+
+// indexSummary is printed by the indexing commands once they complete.
+type indexSummary struct {
+	Index   string `json:"index"`
+	Indexed int    `json:"indexed"`
+}
+
+func runIndex(cmd *command, args []string) (err error) {
+	if len(args) < 2 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	count := 0
+	for _, arg := range args[1:] {
+		err = filepath.Walk(filepath.Clean(arg), func(path string, finfo os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if finfo.IsDir() {
+				return nil
+			}
+			contents, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			var doc interface{}
+			err = json.Unmarshal(contents, &doc)
+			if err != nil {
+				return fmt.Errorf("error parsing %s: %v", path, err)
+			}
+			docID := path
+			if !*indexKeepDir {
+				docID = filepath.Base(docID)
+			}
+			if !*indexKeepExt {
+				docID = docID[0 : len(docID)-len(filepath.Ext(docID))]
+			}
+			if p.Text() {
+				log.Printf("indexing: %s", docID)
+			}
+			err = index.Index(docID, doc)
+			if err != nil {
+				return fmt.Errorf("error indexing %s: %v", path, err)
+			}
+			count++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return printIndexSummary(p, args[0], count)
+}
+
+func runBulk(cmd *command, args []string) (err error) {
+	if len(args) < 2 {
+		return errUsage
+	}
+	if *bulkSize < 1 {
+		return fmt.Errorf("batch size must be positive, got %d", *bulkSize)
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	count := 0
+	batch := index.NewBatch()
+	for _, path := range args[1:] {
+		var r io.Reader = os.Stdin
+		if path != "-" {
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = file.Close()
+			}()
+			r = file
+		}
+		if p.Text() {
+			log.Printf("indexing: %s", path)
+		}
+
+		lines := bufio.NewReader(r)
+		for {
+			line, rerr := lines.ReadBytes('\n')
+			if rerr != nil && rerr != io.EOF {
+				return rerr
+			}
+			if len(bytes.TrimSpace(line)) > 0 {
+				docID, doc, err := parseBulkDoc(line)
+				if err != nil {
+					return fmt.Errorf("%s: document %d: %v", path, count+1, err)
+				}
+				err = batch.Index(docID, doc)
+				if err != nil {
+					return fmt.Errorf("%s: document %d: %v", path, count+1, err)
+				}
+				count++
+				if batch.Size() >= *bulkSize {
+					if p.Text() {
+						log.Printf("indexing batch (%d docs)...", count)
+					}
+					err = index.Batch(batch)
+					if err != nil {
+						return err
+					}
+					batch = index.NewBatch()
+				}
+			}
+			if rerr == io.EOF {
+				break
+			}
+		}
+	}
+	err = index.Batch(batch)
+	if err != nil {
+		return err
+	}
+	return printIndexSummary(p, args[0], count)
+}
+
+// parseBulkDoc parses a bulk loaded JSON document and returns it with its id,
+// taken from the -idField field or generated randomly.
+func parseBulkDoc(line []byte) (string, map[string]interface{}, error) {
+	var doc map[string]interface{}
+	err := json.Unmarshal(line, &doc)
+	if err != nil {
+		return "", nil, err
+	}
+	if *bulkIDField == "" {
+		return randomString(5), doc, nil
+	}
+	id, ok := doc[*bulkIDField]
+	if !ok {
+		return "", nil, fmt.Errorf("missing id field '%s'", *bulkIDField)
+	}
+	return fmt.Sprintf("%v", id), doc, nil
+}
+
+func printIndexSummary(p *printer, path string, count int) error {
+	if p.Text() {
+		log.Printf("indexed %d documents", count)
+		return nil
+	}
+	return p.Value(indexSummary{
+		Index:   path,
+		Indexed: count,
+	})
+}
+
+var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+func randomString(n int) string {
+	b := make([]rune, n)
+	for i := range b {
+		b[i] = letters[rand.Intn(len(letters))]
+	}
+	return string(b)
+}
@@ -0,0 +1,254 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/upside_down"
+)
+
+var countCmd = newCommand("count", "<index>", "print the number of documents in the index")
+
+var mappingCmd = newCommand("mapping", "<index>", "print the index mapping")
+
+var fieldsCmd = newCommand("fields", "<index>", "list the fields of the index")
+
+var dictionaryCmd = newCommand("dictionary", "<index> <field>",
+	`list the terms of a field with their document counts, optionally
+restricted to a prefix or an inclusive range`)
+
+var (
+	dictionaryPrefix = dictionaryCmd.flags.String("prefix", "", "list only terms starting with this prefix")
+	dictionaryStart  = dictionaryCmd.flags.String("start", "", "list only terms greater than or equal to this one")
+	dictionaryEnd    = dictionaryCmd.flags.String("end", "", "list only terms less than or equal to this one")
+	dictionaryLimit  = dictionaryCmd.flags.Int("limit", 0, "list at most N terms, 0 for no limit")
+)
+
+var dumpCmd = newCommand("dump", "<index>",
+	"print the properties and binary representations of the index rows")
+
+var (
+	dumpDocID  = dumpCmd.flags.String("docID", "", "print only rows related to specified document")
+	dumpFields = dumpCmd.flags.Bool("fields", false, "print only field definition rows")
+)
+
+func init() {
+	countCmd.run = runCount
+	mappingCmd.run = runMapping
+	fieldsCmd.run = runFields
+	dictionaryCmd.run = runDictionary
+	dumpCmd.run = runDump
+}
+
+func runCount(cmd *command, args []string) (err error) {
+	if len(args) != 1 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	count, err := index.DocCount()
+	if err != nil {
+		return err
+	}
+	if p.Text() {
+		p.Textf("doc count: %d\n", count)
+		return nil
+	}
+	return p.Value(map[string]uint64{"count": count})
+}
+
+func runMapping(cmd *command, args []string) (err error) {
+	if len(args) != 1 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	if p.Text() {
+		// the mapping has no better text representation
+		p, _ = newPrinter(p.w, outputJSON)
+	}
+	return p.Value(index.Mapping())
+}
+
+func runFields(cmd *command, args []string) (err error) {
+	if len(args) != 1 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	fields, err := index.Fields()
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		err = p.Item(field)
+		if err != nil {
+			return err
+		}
+	}
+	return p.Close()
+}
+
+type dictionaryEntry struct {
+	Term  string `json:"term"`
+	Count uint64 `json:"count"`
+}
+
+func (e dictionaryEntry) String() string {
+	return fmt.Sprintf("%s - %d", e.Term, e.Count)
+}
+
+func runDictionary(cmd *command, args []string) (err error) {
+	if len(args) != 2 {
+		return errUsage
+	}
+	if *dictionaryPrefix != "" && (*dictionaryStart != "" || *dictionaryEnd != "") {
+		return fmt.Errorf("-prefix cannot be used with -start or -end")
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	i, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	field := args[1]
+	var d index.FieldDict
+	switch {
+	case *dictionaryPrefix != "":
+		d, err = i.FieldDictPrefix(field, []byte(*dictionaryPrefix))
+	case *dictionaryStart != "" || *dictionaryEnd != "":
+		var start, end []byte
+		if *dictionaryStart != "" {
+			start = []byte(*dictionaryStart)
+		}
+		if *dictionaryEnd != "" {
+			end = []byte(*dictionaryEnd)
+		}
+		d, err = i.FieldDictRange(field, start, end)
+	default:
+		d, err = i.FieldDict(field)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cerr := d.Close()
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	n := 0
+	de, err := d.Next()
+	for err == nil && de != nil && (*dictionaryLimit <= 0 || n < *dictionaryLimit) {
+		err = p.Item(dictionaryEntry{Term: de.Term, Count: de.Count})
+		if err != nil {
+			return err
+		}
+		n++
+		de, err = d.Next()
+	}
+	if err != nil {
+		return err
+	}
+	return p.Close()
+}
+
+type dumpRow struct {
+	Row   string `json:"row"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (r dumpRow) String() string {
+	return fmt.Sprintf("%s\nKey:   %s\nValue: %s\n", r.Row, r.Key, r.Value)
+}
+
+func runDump(cmd *command, args []string) (err error) {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if *dumpDocID != "" && *dumpFields {
+		return fmt.Errorf("-docID cannot be used with -fields")
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	var dumpChan chan interface{}
+	switch {
+	case *dumpDocID != "":
+		dumpChan = index.DumpDoc(*dumpDocID)
+	case *dumpFields:
+		dumpChan = index.DumpFields()
+	default:
+		dumpChan = index.DumpAll()
+	}
+
+	// the channel must be drained for the enumeration to release its
+	// resources, so only the first error is kept
+	for rowOrErr := range dumpChan {
+		switch rowOrErr := rowOrErr.(type) {
+		case error:
+			if err == nil {
+				err = fmt.Errorf("error dumping: %v", rowOrErr)
+			}
+		case upside_down.UpsideDownCouchRow:
+			if err == nil {
+				err = p.Item(dumpRow{
+					Row:   fmt.Sprintf("%v", rowOrErr),
+					Key:   hex.EncodeToString(rowOrErr.Key()),
+					Value: hex.EncodeToString(rowOrErr.Value()),
+				})
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return p.Close()
+}
@@ -0,0 +1,169 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// The bleve command creates, populates, inspects and queries bleve indexes.
+//
+// Usage:
+//
+//	bleve <command> [flags] [arguments]
+//
+// Run "bleve help" for the list of commands and "bleve help <command>" for
+// the flags of a command. Every command accepts -output text, json or ndjson.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/blevesearch/bleve"
+	_ "github.com/blevesearch/bleve/config"
+	_ "github.com/blevesearch/bleve/index/store/metrics"
+)
+
+// A command is a bleve subcommand with its own flag set.
+type command struct {
+	name    string
+	args    string
+	summary string
+	flags   *flag.FlagSet
+	output  *string
+	run     func(cmd *command, args []string) error
+}
+
+func newCommand(name, args, summary string) *command {
+	cmd := &command{
+		name:    name,
+		args:    args,
+		summary: summary,
+		flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+	}
+	cmd.output = cmd.flags.String("output", "text", "output format: text, json or ndjson")
+	cmd.flags.Usage = cmd.usage
+	return cmd
+}
+
+func (cmd *command) usage() {
+	fmt.Fprintf(os.Stderr, "usage: bleve %s [flags] %s\n\n%s\n\nflags:\n", cmd.name, cmd.args, cmd.summary)
+	cmd.flags.PrintDefaults()
+}
+
+// printer returns a printer writing to stdout in the format selected by the
+// -output flag.
+func (cmd *command) printer() (*printer, error) {
+	return newPrinter(os.Stdout, *cmd.output)
+}
+
+// errUsage is returned by commands invoked with the wrong arguments.
+var errUsage = errors.New("invalid arguments")
+
+var commands = []*command{
+	createCmd,
+	indexCmd,
+	bulkCmd,
+	countCmd,
+	mappingCmd,
+	fieldsCmd,
+	dictionaryCmd,
+	dumpCmd,
+	queryCmd,
+	searchCmd,
+	shellCmd,
+	backupCmd,
+	restoreCmd,
+	migrateCmd,
+	checkCmd,
+	registryCmd,
+}
+
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: bleve <command> [flags] [arguments]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, firstLine(cmd.summary))
+	}
+	fmt.Fprintf(os.Stderr, "\nrun 'bleve help <command>' for the flags of a command\n")
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bleve: ")
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name, args := os.Args[1], os.Args[2:]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		if len(args) > 0 {
+			if cmd := lookupCommand(args[0]); cmd != nil {
+				cmd.usage()
+				return
+			}
+		}
+		usage()
+		return
+	}
+
+	cmd := lookupCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "bleve: unknown command '%s'\n", name)
+		usage()
+		os.Exit(2)
+	}
+	err := cmd.flags.Parse(args)
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+	err = cmd.run(cmd, cmd.flags.Args())
+	if err == errUsage {
+		cmd.usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", cmd.name, err)
+	}
+}
+
+// openIndex opens the index at path and returns a function closing it,
+// reporting close errors through err.
+func openIndex(path string) (bleve.Index, func(err *error), error) {
+	index, err := bleve.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	closer := func(err *error) {
+		cerr := index.Close()
+		if cerr != nil && *err == nil {
+			*err = fmt.Errorf("error closing index: %v", cerr)
+		}
+	}
+	return index, closer, nil
+}
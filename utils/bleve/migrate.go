@@ -0,0 +1,78 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/blevesearch/bleve"
+)
+
+var migrateCmd = newCommand("migrate", "<index>",
+	"copy an index into a new kv store, which replaces the current one\n"+
+		"without -store, the current store is rewritten, compacting the index")
+
+var (
+	migrateStore  = migrateCmd.flags.String("store", "", "kv store type to migrate to")
+	migrateConfig = migrateCmd.flags.String("config", "", "JSON kv store configuration, used with -store")
+)
+
+func init() {
+	migrateCmd.run = runMigrate
+}
+
+func runMigrate(cmd *command, args []string) (err error) {
+	if len(args) != 1 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+
+	var config map[string]interface{}
+	if *migrateConfig != "" {
+		if *migrateStore == "" {
+			return errUsage
+		}
+		err = json.Unmarshal([]byte(*migrateConfig), &config)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = bleve.Migrate(args[0], *migrateStore, config)
+	if err != nil {
+		return err
+	}
+
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+	count, err := index.DocCount()
+	if err != nil {
+		return err
+	}
+
+	if p.Text() {
+		p.Textf("migrated %d documents of index: %s\n", count, args[0])
+		return nil
+	}
+	rv := map[string]interface{}{
+		"index":     args[0],
+		"documents": count,
+	}
+	if *migrateStore != "" {
+		rv["store"] = *migrateStore
+	}
+	return p.Value(rv)
+}
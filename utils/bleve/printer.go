@@ -0,0 +1,103 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	outputText   = "text"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+)
+
+// A printer writes command results as text, as a single JSON document or as
+// newline delimited JSON. List results are streamed one element per line in
+// ndjson and collected into an array in json.
+type printer struct {
+	w      io.Writer
+	format string
+	items  []interface{}
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case outputText, outputJSON, outputNDJSON:
+	default:
+		return nil, fmt.Errorf("unknown output format '%s', expected text, json or ndjson", format)
+	}
+	return &printer{
+		w:      w,
+		format: format,
+	}, nil
+}
+
+// Text returns true if results must be printed as text, in which case the
+// caller formats them itself.
+func (p *printer) Text() bool {
+	return p.format == outputText
+}
+
+// Textf prints text output.
+func (p *printer) Textf(format string, args ...interface{}) {
+	fmt.Fprintf(p.w, format, args...)
+}
+
+// Value prints a single result.
+func (p *printer) Value(v interface{}) error {
+	switch p.format {
+	case outputJSON:
+		return p.indent(v)
+	case outputNDJSON:
+		return json.NewEncoder(p.w).Encode(v)
+	}
+	_, err := fmt.Fprintln(p.w, v)
+	return err
+}
+
+// Item prints an element of a list result. Close must be called once all
+// elements are printed.
+func (p *printer) Item(v interface{}) error {
+	switch p.format {
+	case outputJSON:
+		p.items = append(p.items, v)
+		return nil
+	case outputNDJSON:
+		return json.NewEncoder(p.w).Encode(v)
+	}
+	_, err := fmt.Fprintln(p.w, v)
+	return err
+}
+
+// Close prints the list result collected for json output, or an empty
+// array if Item was never called.
+func (p *printer) Close() error {
+	if p.format != outputJSON {
+		return nil
+	}
+	if p.items == nil {
+		p.items = []interface{}{}
+	}
+	err := p.indent(p.items)
+	p.items = nil
+	return err
+}
+
+func (p *printer) indent(v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(p.w, "%s\n", b)
+	return err
+}
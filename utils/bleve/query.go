@@ -0,0 +1,260 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+
+	"github.com/blevesearch/bleve"
+)
+
+var queryCmd = newCommand("query", "<index> <query>...",
+	`run a query built from the arguments and print the matching documents`)
+
+var (
+	queryType       = queryCmd.flags.String("type", "query_string", "type of query to execute: query_string, match, match_phrase, term or prefix")
+	queryField      = queryCmd.flags.String("field", "", "the field to query, not applicable to query_string queries")
+	queryLimit      = queryCmd.flags.Int("limit", 10, "limit to first N results")
+	querySkip       = queryCmd.flags.Int("skip", 0, "skip the first N results")
+	queryExplain    = queryCmd.flags.Bool("explain", false, "explain scores")
+	queryHighlight  = queryCmd.flags.Bool("highlight", true, "highlight matches")
+	queryFields     = queryCmd.flags.String("fields", "", "comma separated stored fields to return, * for all")
+	querySort       = queryCmd.flags.String("sort", "", "comma separated sort order, for instance -_score,_id")
+	queryRepeat     = queryCmd.flags.Int("repeat", 1, "repeat query n times")
+	queryCPUProfile = queryCmd.flags.String("cpuprofile", "", "write cpu profile to file")
+	queryFacets     facetsFlag
+)
+
+var searchCmd = newCommand("search", "<index>",
+	`run a JSON search request read from -request, or from stdin, and print the
+result`)
+
+var searchRequestFile = searchCmd.flags.String("request", "-", `path of the JSON search request, "-" for stdin`)
+
+func init() {
+	queryCmd.flags.Var(&queryFacets, "facet", "facet on field, as field or field:size, may be repeated")
+	queryCmd.run = runQuery
+	searchCmd.run = runSearch
+}
+
+// facetsFlag collects repeated -facet flags.
+type facetsFlag []string
+
+func (f *facetsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *facetsFlag) Set(value string) error {
+	_, _, err := parseFacet(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+// parseFacet parses a field[:size] facet definition.
+func parseFacet(value string) (string, int, error) {
+	field, size := value, 10
+	if i := strings.LastIndex(value, ":"); i >= 0 {
+		var err error
+		field = value[:i]
+		size, err = strconv.Atoi(value[i+1:])
+		if err != nil || size < 1 {
+			return "", 0, fmt.Errorf("invalid facet size in '%s'", value)
+		}
+	}
+	if field == "" {
+		return "", 0, fmt.Errorf("missing facet field in '%s'", value)
+	}
+	return field, size, nil
+}
+
+// buildQuery returns a query of type qtype for text on field.
+func buildQuery(qtype, field, text string) (bleve.Query, error) {
+	var query bleve.Query
+	switch qtype {
+	case "query_string":
+		return bleve.NewQueryStringQuery(text), nil
+	case "match":
+		query = bleve.NewMatchQuery(text)
+	case "match_phrase":
+		query = bleve.NewMatchPhraseQuery(text)
+	case "term":
+		query = bleve.NewTermQuery(text)
+	case "prefix":
+		query = bleve.NewPrefixQuery(text)
+	default:
+		return nil, fmt.Errorf("unknown query type '%s'", qtype)
+	}
+	if field != "" {
+		query.SetField(field)
+	}
+	return query, nil
+}
+
+// searchOptions are the search request settings shared by the query command
+// and the shell.
+type searchOptions struct {
+	limit     int
+	skip      int
+	explain   bool
+	highlight bool
+	fields    []string
+	sort      []string
+	facets    []string
+}
+
+func (o *searchOptions) request(query bleve.Query, p *printer) *bleve.SearchRequest {
+	req := bleve.NewSearchRequestOptions(query, o.limit, o.skip, o.explain)
+	if o.highlight {
+		if p.Text() {
+			req.Highlight = bleve.NewHighlightWithStyle("ansi")
+		} else {
+			req.Highlight = bleve.NewHighlightWithStyle("html")
+		}
+	}
+	req.Fields = o.fields
+	if len(o.sort) > 0 {
+		req.SortBy(o.sort)
+	}
+	for _, facet := range o.facets {
+		field, size, _ := parseFacet(facet)
+		req.AddFacet(field, bleve.NewFacetRequest(field, size))
+	}
+	return req
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	rv := strings.Split(s, ",")
+	for i := range rv {
+		rv[i] = strings.TrimSpace(rv[i])
+	}
+	return rv
+}
+
+func runQuery(cmd *command, args []string) (err error) {
+	if len(args) < 2 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	query, err := buildQuery(*queryType, *queryField, strings.Join(args[1:], " "))
+	if err != nil {
+		return err
+	}
+	options := &searchOptions{
+		limit:     *queryLimit,
+		skip:      *querySkip,
+		explain:   *queryExplain,
+		highlight: *queryHighlight,
+		fields:    splitList(*queryFields),
+		sort:      splitList(*querySort),
+		facets:    queryFacets,
+	}
+
+	if *queryCPUProfile != "" {
+		f, err := os.Create(*queryCPUProfile)
+		if err != nil {
+			return err
+		}
+		err = pprof.StartCPUProfile(f)
+		if err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	for i := 0; i < *queryRepeat; i++ {
+		err = search(index, options.request(query, p), p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runSearch(cmd *command, args []string) (err error) {
+	if len(args) != 1 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *searchRequestFile != "-" {
+		f, err := os.Open(*searchRequestFile)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		r = f
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	req, err := parseSearchRequest(data)
+	if err != nil {
+		return err
+	}
+
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	return search(index, req, p)
+}
+
+func parseSearchRequest(data []byte) (*bleve.SearchRequest, error) {
+	var req bleve.SearchRequest
+	err := json.Unmarshal(data, &req)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing search request: %v", err)
+	}
+	return &req, nil
+}
+
+// search executes req and prints its result.
+func search(index bleve.Index, req *bleve.SearchRequest, p *printer) error {
+	res, err := index.Search(req)
+	if err != nil {
+		return err
+	}
+	if p.Text() {
+		p.Textf("%s", res)
+		return nil
+	}
+	return p.Value(res)
+}
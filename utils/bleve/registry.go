@@ -0,0 +1,91 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package main
+
+import (
+	"sort"
+
+	"github.com/blevesearch/bleve/registry"
+)
+
+var registryCmd = newCommand("registry", "",
+	"list the types and named instances of every registered component")
+
+func init() {
+	registryCmd.run = runRegistry
+}
+
+type registryComponent struct {
+	Name      string   `json:"name"`
+	Types     []string `json:"types"`
+	Instances []string `json:"instances"`
+}
+
+var registryComponents = []struct {
+	name string
+	list func() ([]string, []string)
+}{
+	{"Char Filter", registry.CharFilterTypesAndInstances},
+	{"Tokenizer", registry.TokenizerTypesAndInstances},
+	{"Token Map", registry.TokenMapTypesAndInstances},
+	{"Synonym Map", registry.SynonymMapTypesAndInstances},
+	{"Token Filter", registry.TokenFilterTypesAndInstances},
+	{"Analyzer", registry.AnalyzerTypesAndInstances},
+	{"Date Time Parser", registry.DateTimeParserTypesAndInstances},
+	{"KV Store", registry.KVStoreTypesAndInstances},
+	{"ByteArrayConverter", registry.ByteArrayConverterTypesAndInstances},
+	{"Fragment Formatter", registry.FragmentFormatterTypesAndInstances},
+	{"Fragmenter", registry.FragmenterTypesAndInstances},
+	{"Highlighter", registry.HighlighterTypesAndInstances},
+	{"Similarity", registry.SimilarityTypesAndInstances},
+}
+
+func runRegistry(cmd *command, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	if p.Text() {
+		p.Textf("Bleve Registry:\n")
+	}
+	for _, component := range registryComponents {
+		types, instances := component.list()
+		sort.Strings(types)
+		sort.Strings(instances)
+		if p.Text() {
+			printComponent(p, component.name, types, instances)
+			continue
+		}
+		err = p.Item(registryComponent{
+			Name:      component.name,
+			Types:     types,
+			Instances: instances,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return p.Close()
+}
+
+func printComponent(p *printer, label string, types, instances []string) {
+	p.Textf("%s Types:\n", label)
+	for _, name := range types {
+		p.Textf("\t%s\n", name)
+	}
+	p.Textf("\n%s Instances:\n", label)
+	for _, name := range instances {
+		p.Textf("\t%s\n", name)
+	}
+	p.Textf("\n")
+}
@@ -0,0 +1,325 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/blevesearch/bleve"
+)
+
+var shellCmd = newCommand("shell", "<index>",
+	`start an interactive query shell on the index
+
+Each line is run as a query of the current type, or as a JSON search request
+if it starts with '{'. Lines starting with ':' change the shell settings, run
+:help for the list. Previous lines can be run again with !N or !!, and are
+saved to the -history file across sessions.`)
+
+var (
+	shellHistory = shellCmd.flags.String("history", defaultHistoryPath(), "history file, empty to disable")
+)
+
+func init() {
+	shellCmd.run = runShell
+}
+
+// maxHistory is the number of lines kept in the history file.
+const maxHistory = 1000
+
+func defaultHistoryPath() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".bleve_history")
+}
+
+const shellHelp = `  <query>             run a query of the current type
+  {...}               run a JSON search request
+  !N                  run line N of the history again
+  !!                  run the previous line again
+  :history            print the history
+  :settings           print the current settings
+  :type <type>        query type: query_string, match, match_phrase, term or prefix
+  :field <field>      field for queries other than query_string, "" for the default
+  :limit <n>          limit to first N results
+  :skip <n>           skip the first N results
+  :explain on|off     explain scores
+  :highlight on|off   highlight matches
+  :fields <a,b>       stored fields to return, * for all, "" for none
+  :sort <a,b>         sort order, "" for score
+  :facet <field[:n]>  add a facet, "" to remove all
+  :output <format>    output format: text, json or ndjson
+  :quit               leave the shell
+`
+
+type shell struct {
+	index   bleve.Index
+	out     io.Writer
+	printer *printer
+	qtype   string
+	field   string
+	options searchOptions
+	history []string
+	// number of lines in the history file
+	saved int
+}
+
+func runShell(cmd *command, args []string) (err error) {
+	if len(args) != 1 {
+		return errUsage
+	}
+	p, err := cmd.printer()
+	if err != nil {
+		return err
+	}
+	index, closeIndex, err := openIndex(args[0])
+	if err != nil {
+		return err
+	}
+	defer closeIndex(&err)
+
+	sh := &shell{
+		index:   index,
+		out:     os.Stdout,
+		printer: p,
+		qtype:   "query_string",
+		options: searchOptions{
+			limit:     10,
+			highlight: true,
+		},
+	}
+	sh.history, sh.saved, err = readHistory(*shellHistory)
+	if err != nil {
+		return err
+	}
+	return sh.run(os.Stdin, os.Stderr)
+}
+
+// run reads and executes lines from in until it is exhausted or :quit is
+// entered. Prompts and errors are written to prompt.
+func (sh *shell) run(in io.Reader, prompt io.Writer) error {
+	lines := bufio.NewScanner(in)
+	lines.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for {
+		fmt.Fprint(prompt, "bleve> ")
+		if !lines.Scan() {
+			fmt.Fprintln(prompt)
+			return lines.Err()
+		}
+		line := strings.TrimSpace(lines.Text())
+		if line == "" {
+			continue
+		}
+
+		line, err := sh.expandHistory(line)
+		if err != nil {
+			fmt.Fprintf(prompt, "error: %v\n", err)
+			continue
+		}
+		if line != ":history" {
+			err = sh.addHistory(line)
+			if err != nil {
+				fmt.Fprintf(prompt, "error saving history: %v\n", err)
+			}
+		}
+
+		if line == ":quit" || line == ":exit" {
+			return nil
+		}
+		err = sh.execute(line)
+		if err != nil {
+			fmt.Fprintf(prompt, "error: %v\n", err)
+		}
+	}
+}
+
+// expandHistory replaces a !N or !! line by the history line it refers to.
+func (sh *shell) expandHistory(line string) (string, error) {
+	if !strings.HasPrefix(line, "!") {
+		return line, nil
+	}
+	if len(sh.history) == 0 {
+		return "", fmt.Errorf("history is empty")
+	}
+	if line == "!!" {
+		return sh.history[len(sh.history)-1], nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 || n > len(sh.history) {
+		return "", fmt.Errorf("no history line '%s'", line[1:])
+	}
+	return sh.history[n-1], nil
+}
+
+func (sh *shell) execute(line string) error {
+	switch {
+	case strings.HasPrefix(line, ":"):
+		return sh.setting(line)
+	case strings.HasPrefix(line, "{"):
+		req, err := parseSearchRequest([]byte(line))
+		if err != nil {
+			return err
+		}
+		return search(sh.index, req, sh.printer)
+	}
+	query, err := buildQuery(sh.qtype, sh.field, line)
+	if err != nil {
+		return err
+	}
+	return search(sh.index, sh.options.request(query, sh.printer), sh.printer)
+}
+
+func (sh *shell) setting(line string) error {
+	name, value := line[1:], ""
+	if i := strings.IndexAny(name, " \t"); i >= 0 {
+		name, value = name[:i], strings.TrimSpace(name[i+1:])
+	}
+	value = strings.Trim(value, `"`)
+
+	var err error
+	switch name {
+	case "help":
+		fmt.Fprint(sh.out, shellHelp)
+	case "history":
+		for i, entry := range sh.history {
+			fmt.Fprintf(sh.out, "%5d  %s\n", i+1, entry)
+		}
+	case "settings":
+		fmt.Fprintf(sh.out, "type: %s\nfield: %s\nlimit: %d\nskip: %d\nexplain: %t\nhighlight: %t\nfields: %s\nsort: %s\nfacets: %s\noutput: %s\n",
+			sh.qtype, sh.field, sh.options.limit, sh.options.skip, sh.options.explain,
+			sh.options.highlight, strings.Join(sh.options.fields, ","),
+			strings.Join(sh.options.sort, ","), strings.Join(sh.options.facets, ","),
+			sh.printer.format)
+	case "type":
+		_, err = buildQuery(value, "", "")
+		if err == nil {
+			sh.qtype = value
+		}
+	case "field":
+		sh.field = value
+	case "limit":
+		sh.options.limit, err = parseCount(value, sh.options.limit)
+	case "skip":
+		sh.options.skip, err = parseCount(value, sh.options.skip)
+	case "explain":
+		sh.options.explain, err = parseSwitch(value)
+	case "highlight":
+		sh.options.highlight, err = parseSwitch(value)
+	case "fields":
+		sh.options.fields = splitList(value)
+	case "sort":
+		sh.options.sort = splitList(value)
+	case "facet":
+		if value == "" {
+			sh.options.facets = nil
+		} else if _, _, err = parseFacet(value); err == nil {
+			sh.options.facets = append(sh.options.facets, value)
+		}
+	case "output":
+		var p *printer
+		p, err = newPrinter(sh.out, value)
+		if err == nil {
+			sh.printer = p
+		}
+	default:
+		err = fmt.Errorf("unknown command ':%s', run :help for the list", name)
+	}
+	return err
+}
+
+func parseCount(value string, current int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return current, fmt.Errorf("expected a positive number, got '%s'", value)
+	}
+	return n, nil
+}
+
+func parseSwitch(value string) (bool, error) {
+	switch value {
+	case "on", "true":
+		return true, nil
+	case "off", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected on or off, got '%s'", value)
+}
+
+// readHistory returns the last maxHistory lines of the history file at path,
+// and its total number of lines.
+func readHistory(path string) ([]string, int, error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	var rv []string
+	lines := bufio.NewScanner(f)
+	lines.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lines.Scan() {
+		rv = append(rv, lines.Text())
+	}
+	total := len(rv)
+	if len(rv) > maxHistory {
+		rv = rv[len(rv)-maxHistory:]
+	}
+	return rv, total, lines.Err()
+}
+
+// addHistory records line in the history and appends it to the history
+// file, which is rewritten with the last maxHistory lines once it holds
+// twice as many.
+func (sh *shell) addHistory(line string) error {
+	sh.history = append(sh.history, line)
+	if len(sh.history) > maxHistory {
+		sh.history = sh.history[len(sh.history)-maxHistory:]
+	}
+	if *shellHistory == "" {
+		return nil
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	lines := []string{line}
+	rewrite := sh.saved+1 > 2*maxHistory
+	if rewrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		lines = sh.history
+	}
+	f, err := os.OpenFile(*shellHistory, flags, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(f, strings.Join(lines, "\n"))
+	cerr := f.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err == nil && rewrite {
+		sh.saved = len(lines)
+	} else if err == nil {
+		sh.saved++
+	}
+	return err
+}